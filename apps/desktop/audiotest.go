@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/pitch"
+)
+
+// The audio check plays A3, high enough for laptop speakers to reproduce,
+// and listens for it for a little longer than it sounds
+const (
+	testToneFreq     = 220.0
+	testToneLength   = 1.5 // Seconds
+	testListenLength = 2500 * time.Millisecond
+	testToneCents    = 50 // How close a heard pitch must be to count as the tone
+)
+
+// Audio check outcomes
+type audioTestResult int
+
+const (
+	testIdle audioTestResult = iota
+	testListening
+	testHeardTone       // The tone came back through the input: the whole chain works
+	testHeardInstrument // Something else was heard, so the input works
+	testHeardNothing
+)
+
+// audioTest checks the output and input by playing a tone and listening
+// for it, or for the instrument when the tone can't reach the input
+type audioTest struct {
+	input, output string // Device names
+	canPlay       bool   // Whether there's an output to play the tone on
+	result        audioTestResult
+	started       time.Time
+	heard         pitch.Result // What was heard, for the result message
+	peak          float64      // Loudest input level while listening
+}
+
+// OpenAudioTest shows the audio check screen
+func (a *App) OpenAudioTest() {
+	_, output := audio.DefaultDevices()
+	input := fmt.Sprintf("unavailable (%v)", a.inputErr)
+	if a.audioInput != nil {
+		input = a.audioInput.DeviceName()
+	}
+	if a.audioOutput == nil {
+		output = "unavailable"
+	}
+	a.audioTest = &audioTest{input: input, output: output, canPlay: a.audioOutput != nil}
+	a.state = StateAudioTest
+}
+
+// startAudioTest plays the test tone, if there's an output, and listens
+func (a *App) startAudioTest() {
+	t := a.audioTest
+	t.result = testListening
+	t.started = time.Now()
+	t.heard = pitch.Result{}
+	t.peak = 0
+
+	// The tone is above a bass's range, so widen detection to hear it
+	a.pitchDetector.SetRange(30, 1000)
+	if t.canPlay {
+		a.audioOutput.Play(audio.NewTone(testToneFreq, testToneLength, a.audioOutput.SampleRate()))
+	}
+}
+
+// updateAudioTest judges what the input hears while the check runs
+func (a *App) updateAudioTest() {
+	t := a.audioTest
+	if t.result != testListening {
+		return
+	}
+	t.peak = math.Max(t.peak, a.currentPitch.RMS)
+
+	if p := a.currentPitch; p.IsValid() {
+		if t.canPlay && isTestTone(p.Frequency) {
+			t.heard = p
+			t.result = testHeardTone
+			return
+		}
+		t.heard = p
+	}
+	if time.Since(t.started) < testListenLength {
+		return
+	}
+	if t.heard.IsValid() {
+		t.result = testHeardInstrument
+	} else {
+		t.result = testHeardNothing
+	}
+}
+
+// isTestTone reports whether a frequency is the test tone, allowing for
+// the detector picking the wrong octave
+func isTestTone(freq float64) bool {
+	cents := 1200 * math.Log2(freq/testToneFreq)
+	off := math.Abs(math.Remainder(cents, 1200))
+	return off <= testToneCents
+}
+
+func (a *App) handleAudioTestKey(e key.Event) {
+	switch e.Name {
+	case "T", key.NameReturn, key.NameEnter, key.NameSpace:
+		a.startAudioTest()
+	case "R":
+		if a.audioInput == nil {
+			a.RetryInput()
+			a.OpenAudioTest()
+		}
+	case key.NameEscape:
+		a.audioTest = nil
+		a.GoToMenu()
+	}
+}
+
+// message explains the outcome of the check
+func (t *audioTest) message() (string, color.NRGBA) {
+	good := render.Colors.Success
+	warn := render.Colors.Warning
+	switch t.result {
+	case testListening:
+		if !t.canPlay {
+			return "Listening... play a note on your instrument", render.Colors.Title
+		}
+		return "Playing a test tone and listening for it...", render.Colors.Title
+	case testHeardTone:
+		return fmt.Sprintf("All good: the tone came back through the input at %.1f Hz", t.heard.Frequency), good
+	case testHeardInstrument:
+		return fmt.Sprintf("Input works: heard %s. The tone didn't reach it, which is normal with headphones or a direct input", t.heard.FullNoteName()), good
+	case testHeardNothing:
+		if t.peak < 0.001 {
+			return "Heard nothing: the input is silent. Check the input device, its gain, and microphone access", warn
+		}
+		return "Heard sound but no clear pitch. Turn up the output or play a note on your instrument and try again", warn
+	default:
+		return "Press T to play a test tone. With headphones or a direct input, play a note on your instrument instead", render.Colors.Text
+	}
+}
+
+func (a *App) layoutAudioTestScreen(gtx layout.Context) layout.Dimensions {
+	t := a.audioTest
+	text, c := t.message()
+
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H5(a.theme, "Audio Check")
+			label.Color = render.Colors.Title
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, "Output: "+t.output)
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, "Input: "+t.input)
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, text)
+			label.Color = c
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return a.tabRenderer.DrawDetectedNote(gtx, a.currentPitch.FullNoteName(), a.currentPitch.Frequency, a.currentPitch.Confidence)
+			})
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			hint := "T test  •  Esc back"
+			if a.audioInput == nil {
+				hint = "T test  •  R retry input  •  Esc back"
+			}
+			label := material.Body2(a.theme, hint)
+			label.Color = render.Colors.Disabled
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"guitargame/apps/desktop/internal/export"
+	"guitargame/apps/desktop/internal/pack"
+	"guitargame/core/song"
+)
+
+// runCommand runs a command-line subcommand instead of the game. It
+// reports whether args named one, along with the exit status.
+func runCommand(args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch args[0] {
+	case "pack":
+		return runPack(args[1:]), true
+	case "export":
+		return runExport(args[1:]), true
+	case "validate":
+		return runValidate(args[1:]), true
+	case "tune":
+		return runTune(args[1:]), true
+	case "convert":
+		return runConvert(args[1:]), true
+	case "simulate":
+		return runSimulate(args[1:]), true
+	case "script":
+		return runScript(args[1:]), true
+	}
+	return 0, false
+}
+
+const packUsage = "usage: guitargame pack build [-o pack.zip] [-name name] [-version version] <dir>"
+
+// runPack handles "guitargame pack build"
+func runPack(args []string) int {
+	if len(args) == 0 || args[0] != "build" {
+		fmt.Fprintln(os.Stderr, packUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("pack build", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, packUsage)
+		flags.PrintDefaults()
+	}
+	out := flags.String("o", "", "archive to write (default <dir>.zip)")
+	name := flags.String("name", "", "pack name (default the directory name)")
+	version := flags.String("version", "", "pack version")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	dir := flags.Arg(0)
+	if *out == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		*out = abs + ".zip"
+	}
+
+	meta, err := pack.Build(dir, *out, pack.Options{Name: *name, Version: *version})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pack build failed:\n%v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Built %s (%q, %d charts)\n", *out, meta.Name, len(meta.Songs))
+	for _, s := range meta.Songs {
+		fmt.Printf("  %s: %s (%.0f BPM, %d notes)\n", s.File, s.Title, s.BPM, s.Notes)
+	}
+	return 0
+}
+
+const exportUsage = "usage: guitargame export [-format tab|musicxml|svg] [-o file] <chart>"
+
+// runExport handles "guitargame export"
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, exportUsage)
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "tab", "tab (ASCII tablature), musicxml, or svg (printable tablature)")
+	out := flags.String("o", "", "file to write, or - for standard output (default the chart name with the format's extension)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	if _, ok := export.Formats[*format]; !ok {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	chart := flags.Arg(0)
+	s, err := song.LoadSong(chart)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *out == "" {
+		written, err := export.ToFile(s, chart, *format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("Wrote %s\n", written)
+		return 0
+	}
+
+	data, err := export.Render(s, *format)
+	if err == nil {
+		if *out == "-" {
+			_, err = os.Stdout.Write(data)
+		} else {
+			err = os.WriteFile(*out, data, 0o644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+const validateUsage = "usage: guitargame validate [-json] <file|dir>..."
+
+// chartIssue is a problem found in a chart file, as "guitargame validate
+// -json" writes it
+type chartIssue struct {
+	File string `json:"file"`
+	song.Issue
+}
+
+// runValidate handles "guitargame validate", listing every problem found
+// in the charts given and those in the directories given. It fails if
+// any chart has errors; warnings alone pass.
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, validateUsage)
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", false, "write the problems found as a JSON array, for editors and scripts")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var files []string
+	for _, arg := range flags.Args() {
+		found, err := chartFiles(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		files = append(files, found...)
+	}
+
+	issues := []chartIssue{}
+	errors, warnings := 0, 0
+	for _, file := range files {
+		var found []song.Issue
+		if s, err := song.LoadSong(file); err != nil {
+			found = []song.Issue{{Severity: song.SeverityError, Message: err.Error()}}
+		} else {
+			found = s.Check()
+		}
+		for _, issue := range found {
+			issues = append(issues, chartIssue{File: file, Issue: issue})
+			if issue.Severity == song.SeverityError {
+				errors++
+			} else {
+				warnings++
+			}
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("%s: %s: %v\n", issue.File, issue.Severity, issue.Issue)
+		}
+		fmt.Fprintf(os.Stderr, "Checked %d charts: %d errors, %d warnings\n", len(files), errors, warnings)
+	}
+	if errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// chartFiles lists the charts at path: the file itself, or the charts in
+// a directory and those below it
+func chartFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && song.IsChartFile(p) && d.Name() != song.PackMetadataFile {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+const convertUsage = "usage: guitargame convert [-format yaml|json] [-instrument bass|guitar] [-tuning name] [-track n] <in> <out>  (in is a .yaml, .yml or .json chart or a .mid file; out is a chart or a directory)"
+
+// unimportedFormats are chart formats from other programs that charts
+// can't be converted from yet, by extension
+var unimportedFormats = map[string]string{
+	".gp3":      "Guitar Pro",
+	".gp4":      "Guitar Pro",
+	".gp5":      "Guitar Pro",
+	".gpx":      "Guitar Pro",
+	".gp":       "Guitar Pro",
+	".musicxml": "MusicXML",
+	".mxl":      "MusicXML",
+	".xml":      "MusicXML",
+	".txt":      "ASCII tab",
+	".tab":      "ASCII tab",
+}
+
+// runConvert handles "guitargame convert", rewriting a chart in the
+// format its new name's extension implies, or importing a MIDI file's
+// bass or guitar part as one
+func runConvert(args []string) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, convertUsage)
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "", "yaml or json, for a chart written into a directory (default the input's format, or yaml from MIDI)")
+	instrument := flags.String("instrument", "bass", "instrument to chart a MIDI file for: bass or guitar")
+	tuning := flags.String("tuning", "", "tuning to place a MIDI file's notes in: a name such as drop-d, or notes with octaves from the highest string such as G2,D2,A1,E1")
+	track := flags.Int("track", 0, "MIDI track to import, counted from 1 (default the one named or voiced for the instrument)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+	in, out := flags.Arg(0), flags.Arg(1)
+	if *format != "" && *format != "yaml" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	if name, ok := unimportedFormats[strings.ToLower(filepath.Ext(in))]; ok {
+		fmt.Fprintf(os.Stderr, "%s: %s files can't be converted yet; only .yaml, .yml and .json charts and .mid files can\n", in, name)
+		return 1
+	}
+	fromMIDI := song.IsMIDIFile(in)
+	if !fromMIDI && !song.IsChartFile(in) {
+		fmt.Fprintf(os.Stderr, "%s: not a chart or MIDI file\n", in)
+		return 1
+	}
+	if !fromMIDI {
+		midiOnly := false
+		flags.Visit(func(f *flag.Flag) {
+			midiOnly = midiOnly || f.Name == "instrument" || f.Name == "tuning" || f.Name == "track"
+		})
+		if midiOnly {
+			fmt.Fprintln(os.Stderr, "-instrument, -tuning and -track are only for importing MIDI files")
+			return 2
+		}
+	}
+	if info, err := os.Stat(out); err == nil && info.IsDir() {
+		name := filepath.Base(in)
+		if *format == "" && fromMIDI {
+			*format = "yaml"
+		}
+		if *format != "" {
+			name = strings.TrimSuffix(name, filepath.Ext(name)) + "." + *format
+		}
+		out = filepath.Join(out, name)
+	} else if *format != "" {
+		fmt.Fprintln(os.Stderr, "-format is only for writing into a directory; give the output file's extension instead")
+		return 2
+	}
+	if !song.IsChartFile(out) {
+		fmt.Fprintf(os.Stderr, "%s: charts can only be written as .yaml, .yml or .json\n", out)
+		return 2
+	}
+	if sameFile(in, out) {
+		fmt.Fprintf(os.Stderr, "%s: won't overwrite the chart being converted; choose another output\n", out)
+		return 2
+	}
+
+	var s *song.Song
+	var err error
+	if fromMIDI {
+		s, err = importMIDI(in, song.MIDIOptions{Instrument: *instrument, Tuning: *tuning, Track: *track})
+	} else {
+		s, err = song.LoadSong(in)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := s.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: warning: %v\n", in, err)
+	}
+	if err := song.SaveSong(s, out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if (s.Audio != "" || s.Cover != "") && filepath.Dir(out) != filepath.Dir(in) {
+		fmt.Fprintf(os.Stderr, "%s: audio and cover art are found next to the chart; copy them alongside it\n", out)
+	}
+	fmt.Printf("Wrote %s\n", out)
+	return 0
+}
+
+// importMIDI makes a chart from a MIDI file, titled after the file if
+// it doesn't name the song, warning about notes the instrument can't play
+func importMIDI(path string, opts song.MIDIOptions) (*song.Song, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, dropped, err := song.ImportMIDI(data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Title == "" {
+		s.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "%s: warning: left out %d notes out of the %s's range\n", path, dropped, s.Instrument().Name)
+	}
+	return s, nil
+}
+
+// sameFile reports whether two paths name the same existing file
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	return err == nil && os.SameFile(ai, bi)
+}
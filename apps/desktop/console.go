@@ -0,0 +1,130 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"log"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/config"
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/render"
+)
+
+// consoleLines is how many of the latest log lines the debug console shows
+const consoleLines = 18
+
+// openLog starts the log file in the config directory, and sends the
+// standard library's log there too, so messages from libraries aren't
+// lost
+func openLog() {
+	log.SetFlags(0)
+	log.SetOutput(logging.Writer(logging.LevelInfo))
+	dir, err := config.Dir()
+	if err == nil {
+		err = logging.Open(dir)
+	}
+	if err != nil {
+		logging.Warnf("not writing a log file: %v", err)
+		return
+	}
+	logging.Infof("Logging to %s", logging.Path())
+}
+
+// applyLogLevel logs only messages as serious as the launch settings ask
+func (a *App) applyLogLevel() {
+	if a.launch.LogLevel == "" {
+		return
+	}
+	l, err := logging.ParseLevel(a.launch.LogLevel)
+	if err != nil {
+		a.warn("%v", err)
+		return
+	}
+	logging.SetLevel(l)
+}
+
+// logHeard logs each note detected as it starts, with what it was
+// detected from, for looking into notes that aren't heard as played
+func (a *App) logHeard() {
+	heard := ""
+	if a.currentPitch.IsValid() {
+		heard = a.currentPitch.FullNoteName()
+	}
+	if heard == a.heard {
+		return
+	}
+	a.heard = heard
+	if p := a.currentPitch; heard != "" {
+		logging.Debugf("heard %s: %.1f Hz, %+d cents, confidence %.2f, level %.4f", heard, p.Frequency, p.Cents, p.Confidence, p.RMS)
+	}
+}
+
+// ToggleDebugConsole shows or hides the latest log lines over the
+// screen. Debug messages are logged while it's open.
+func (a *App) ToggleDebugConsole() {
+	a.debugConsole = !a.debugConsole
+	if a.debugConsole {
+		a.logLevel = logging.CurrentLevel()
+		logging.SetLevel(logging.LevelDebug)
+	} else {
+		logging.SetLevel(a.logLevel)
+	}
+}
+
+// layoutDebugConsole draws the latest log lines over the top of the
+// screen, and where the whole log is
+func (a *App) layoutDebugConsole(gtx layout.Context) {
+	if !a.debugConsole {
+		return
+	}
+	var children []layout.FlexChild
+	line := func(text string, c color.NRGBA) {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Caption(a.theme, text)
+			label.Font.Typeface = font.Typeface("monospace")
+			label.Color = c
+			label.MaxLines = 1
+			return label.Layout(gtx)
+		}))
+	}
+	path := logging.Path()
+	if path == "" {
+		path = "no log file"
+	}
+	line("Debug console (F12 to close)  •  "+path, render.Colors.Title)
+	for _, e := range logging.Recent(consoleLines) {
+		c := render.Colors.Text
+		switch e.Level {
+		case logging.LevelDebug:
+			c = render.Colors.Hint
+		case logging.LevelWarn:
+			c = render.Colors.Warning
+		case logging.LevelError:
+			c = render.Colors.Error
+		}
+		line(e.String(), c)
+	}
+
+	gtx.Constraints.Min = image.Point{}
+	layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			size := image.Pt(gtx.Constraints.Max.X, gtx.Constraints.Min.Y)
+			bg := render.Colors.Background
+			bg.A = 230
+			paint.FillShape(gtx.Ops, bg, clip.Rect{Max: size}.Op())
+			return layout.Dimensions{Size: size}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+			})
+		}),
+	)
+}
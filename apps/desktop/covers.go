@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/pack"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// coverSize is the size in pixels cover art is scaled down to when it's
+// decoded: enough for the pre-start screen, small enough to keep many
+const coverSize = 256
+
+// coverArt decodes songs' cover images in the background, so the menu
+// never waits on them, and keeps thumbnails of them
+type coverArt struct {
+	mu     sync.Mutex
+	thumbs map[string]*coverThumb
+}
+
+// coverThumb is one song's decoded cover; ok is false until it's ready
+// and stays false if it can't be read
+type coverThumb struct {
+	img paint.ImageOp
+	ok  bool
+}
+
+// coverKey identifies a cover image by where it's read from
+func coverKey(s *song.Song) string {
+	return s.Pack + "\x00" + s.Path + "\x00" + s.Cover
+}
+
+// Thumbnail returns a song's cover art, starting to decode it the first
+// time it's asked for
+func (c *coverArt) Thumbnail(s *song.Song) (paint.ImageOp, bool) {
+	if s.Cover == "" {
+		return paint.ImageOp{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.thumbs == nil {
+		c.thumbs = make(map[string]*coverThumb)
+	}
+	key := coverKey(s)
+	t, ok := c.thumbs[key]
+	if !ok {
+		t = &coverThumb{}
+		c.thumbs[key] = t
+		go c.decode(s, s.Cover, t)
+	}
+	return t.img, t.ok
+}
+
+// decode reads and scales down a cover image
+func (c *coverArt) decode(s *song.Song, name string, t *coverThumb) {
+	data, err := s.ReadAsset(name)
+	var img image.Image
+	if err == nil {
+		img, _, err = image.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		logging.Warnf("could not load cover art for %s: %v", s.Title, err)
+		return
+	}
+	thumb := paint.NewImageOp(pack.Thumbnail(img, coverSize))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.img, t.ok = thumb, true
+}
+
+// layoutCover draws a song's cover art filling a square, leaving a
+// placeholder while it loads. Songs without cover art take no room.
+func (a *App) layoutCover(gtx layout.Context, s *song.Song, size unit.Dp) layout.Dimensions {
+	if s.Cover == "" {
+		return layout.Dimensions{}
+	}
+	px := gtx.Dp(size)
+	gtx.Constraints = layout.Exact(image.Pt(px, px))
+	img, ok := a.covers.Thumbnail(s)
+	if !ok {
+		paint.FillShape(gtx.Ops, render.Colors.ListItemGlow, clip.Rect{Max: gtx.Constraints.Max}.Op())
+		return layout.Dimensions{Size: gtx.Constraints.Max}
+	}
+	return widget.Image{Src: img, Fit: widget.Cover, Position: layout.Center}.Layout(gtx)
+}
+
+// songCredits sums up who made a song and where it's from, leaving out
+// whatever the chart doesn't say
+func songCredits(s *song.Song, withGenre bool) string {
+	var parts []string
+	if s.Artist != "" {
+		parts = append(parts, s.Artist)
+	}
+	switch {
+	case s.Album != "" && s.Year != 0:
+		parts = append(parts, fmt.Sprintf("%s (%d)", s.Album, s.Year))
+	case s.Album != "":
+		parts = append(parts, s.Album)
+	case s.Year != 0:
+		parts = append(parts, strconv.Itoa(s.Year))
+	}
+	if withGenre && s.Genre != "" {
+		parts = append(parts, s.Genre)
+	}
+	return strings.Join(parts, "  •  ")
+}
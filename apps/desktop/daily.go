@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/generator"
+	"guitargame/apps/desktop/internal/history"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+// maxListedDailies is how many days of challenges the results screen lists
+const maxListedDailies = 7
+
+// todaysChallenge returns the daily challenge, generating it afresh once
+// the day changes
+func (a *App) todaysChallenge() *song.Song {
+	today := time.Now().Format("2006-01-02")
+	if a.daily == nil || a.dailyDate != today {
+		a.daily = generator.Daily(time.Now())
+		a.dailyDate = today
+	}
+	return a.daily
+}
+
+// playingDaily reports whether the game is today's challenge
+func (a *App) playingDaily() bool {
+	return a.daily != nil && a.gameState.Song == a.daily
+}
+
+// StartDaily waits on the pre-start screen to play today's challenge
+func (a *App) StartDaily() {
+	a.riff = nil
+	a.gameState = song.NewGameState(a.todaysChallenge())
+	a.hitDetector = game.NewHitDetector(a.gameState, a.feedbackY)
+	a.state = StatePreStart
+}
+
+// loadDailies reads how past daily challenges went from the practice history
+func (a *App) loadDailies() {
+	a.dailies, a.dailyStreak = nil, 0
+	if a.history == nil {
+		return
+	}
+	sessions, err := a.history.Sessions(time.Time{})
+	if err != nil {
+		a.warn("could not read daily challenges: %v", err)
+		return
+	}
+	a.dailies, a.dailyStreak = history.Dailies(sessions, time.Now())
+}
+
+// todaysDaily returns how today's challenge has gone so far
+func (a *App) todaysDaily() (history.DailyResult, bool) {
+	today := time.Now().Format("2006-01-02")
+	if len(a.dailies) == 0 || a.dailies[0].Day.Format("2006-01-02") != today {
+		return history.DailyResult{}, false
+	}
+	return a.dailies[0], true
+}
+
+// dailyLabel sums up today's challenge and the streak for the menu
+func (a *App) dailyLabel() string {
+	s := a.todaysChallenge()
+	text := "Daily challenge: " + s.Artist
+	switch r, ok := a.todaysDaily(); {
+	case ok && r.Score > 0:
+		text += fmt.Sprintf("  •  today's best %d (%.0f%%)", r.Score, r.Accuracy)
+	case ok:
+		text += "  •  not beaten yet, only full-speed runs count"
+	}
+	if a.dailyStreak > 0 {
+		text += fmt.Sprintf("  •  %d-day streak", a.dailyStreak)
+	}
+	return text + "  •  D to play"
+}
+
+func (a *App) layoutDailyChallenge(gtx layout.Context) layout.Dimensions {
+	label := material.Body2(a.theme, a.dailyLabel())
+	label.Color = render.Colors.Highlight
+	return layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(10)}.Layout(gtx, label.Layout)
+}
+
+// layoutDailyResults lists the best run of recent daily challenges on the
+// results screen of one
+func (a *App) layoutDailyResults(gtx layout.Context) layout.Dimensions {
+	if !a.playingDaily() || len(a.dailies) == 0 {
+		return layout.Dimensions{}
+	}
+	title := "Daily challenges"
+	if a.dailyStreak > 0 {
+		title += fmt.Sprintf("  •  %d-day streak", a.dailyStreak)
+	}
+	children := []layout.FlexChild{
+		layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, title)
+			label.Color = render.Colors.Highlight
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+	}
+	for _, r := range a.dailies[:min(len(a.dailies), maxListedDailies)] {
+		text := fmt.Sprintf("%s  •  not completed  •  %d tries", r.Day.Format("Mon Jan 2"), r.Attempts)
+		if r.Score > 0 {
+			text = fmt.Sprintf("%s  •  %d  •  %.0f%%  •  %d tries", r.Day.Format("Mon Jan 2"), r.Score, r.Accuracy, r.Attempts)
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
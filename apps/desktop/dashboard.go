@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/history"
+	"guitargame/apps/desktop/internal/render"
+)
+
+// dashboardWeeks is how many weeks of practice the progress screen charts
+const dashboardWeeks = 8
+
+// dashboardSongs is how many songs the progress screen shows at once
+const dashboardSongs = 5
+
+// dashboard is the progress screen, summarized from the practice history
+// when it opens
+type dashboard struct {
+	summary history.Summary
+	longest time.Duration // Longest week any one song was practiced
+	first   int           // First song shown
+}
+
+// OpenDashboard shows practice progress from the history
+func (a *App) OpenDashboard() {
+	d := &dashboard{}
+	if a.history != nil {
+		sessions, err := a.history.Sessions(time.Time{})
+		if err != nil {
+			a.warn("could not read practice history: %v", err)
+		}
+		d.summary = history.Summarize(sessions, time.Now(), dashboardWeeks)
+	}
+	for _, s := range d.summary.Songs {
+		for _, w := range s.Weeks {
+			d.longest = max(d.longest, w.Time)
+		}
+	}
+	a.dashboard = d
+	a.state = StateDashboard
+}
+
+func (a *App) handleDashboardKey(e key.Event) {
+	d := a.dashboard
+	switch e.Name {
+	case key.NameUpArrow:
+		if d.first > 0 {
+			d.first--
+		}
+	case key.NameDownArrow:
+		if d.first+dashboardSongs < len(d.summary.Songs) {
+			d.first++
+		}
+	case key.NameEscape, key.NameReturn, key.NameEnter:
+		a.dashboard = nil
+		a.state = StateMenu
+	}
+}
+
+func (a *App) layoutDashboardScreen(gtx layout.Context) layout.Dimensions {
+	d := a.dashboard
+	sum := d.summary
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H4(a.theme, "Progress")
+			label.Color = render.Colors.Title
+			return layout.Inset{Top: unit.Dp(20), Left: unit.Dp(20)}.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			streak := fmt.Sprintf("%d day streak", sum.Streak)
+			if sum.Streak == 1 {
+				streak = "1 day streak"
+			}
+			text := fmt.Sprintf("%.1f hours practiced  •  %s", sum.Total.Hours(), streak)
+			label := material.H6(a.theme, text)
+			label.Color = render.Colors.Highlight
+			return layout.Inset{Left: unit.Dp(20), Top: unit.Dp(10)}.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := fmt.Sprintf("Last %d weeks by song: bars for time practiced, gold marks for accuracy  •  ↑/↓ scroll  •  Esc back to menu", dashboardWeeks)
+			if a.history == nil {
+				text = "Practice history is unavailable  •  Esc back to menu"
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(10)}.Layout(gtx, label.Layout)
+		}),
+	}
+	if len(sum.Songs) == 0 && a.history != nil {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, "Nothing practiced in that time yet")
+			label.Color = render.Colors.Text
+			return layout.Inset{Left: unit.Dp(20)}.Layout(gtx, label.Layout)
+		}))
+	}
+	for _, s := range sum.Songs[d.first:min(len(sum.Songs), d.first+dashboardSongs)] {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.layoutSongProgress(gtx, s, d.longest)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+// layoutSongProgress shows one song's weekly chart with its totals
+func (a *App) layoutSongProgress(gtx layout.Context, s history.SongProgress, longest time.Duration) layout.Dimensions {
+	sessions := 0
+	for _, w := range s.Weeks {
+		sessions += w.Sessions
+	}
+	return layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := fmt.Sprintf("%s  •  %.0f minutes over %d sessions", s.Song, s.Time.Minutes(), sessions)
+				label := material.Body1(a.theme, text)
+				label.Color = render.Colors.Info
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return a.tabRenderer.DrawProgressChart(gtx, s.Weeks, longest)
+			}),
+		)
+	})
+}
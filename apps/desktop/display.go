@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gioui.org/app"
+	"gioui.org/io/key"
+	"gioui.org/layout"
+)
+
+// Limits and step of the display scale, changed with Ctrl and +/-
+const (
+	MinDisplayScale  = 0.5
+	MaxDisplayScale  = 3.0
+	displayScaleStep = 0.25
+)
+
+// handleDisplayKey handles the keys that work on every screen: F10 for
+// streaming mode and Shift+F10 for the capture window, F11 for
+// fullscreen, F12 for the debug console, and Ctrl with +, - or 0 to
+// scale. It reports whether the key was one of them.
+func (a *App) handleDisplayKey(e key.Event) bool {
+	switch e.Name {
+	case key.NameF10:
+		if e.Modifiers.Contain(key.ModShift) {
+			a.ToggleCaptureWindow()
+		} else {
+			a.ToggleStreaming()
+		}
+		return true
+	case key.NameF11:
+		a.ToggleFullscreen()
+		return true
+	case key.NameF12:
+		a.ToggleDebugConsole()
+		return true
+	}
+	if !e.Modifiers.Contain(key.ModShortcut) {
+		return false
+	}
+	switch e.Name {
+	case "=", "+":
+		a.ChangeDisplayScale(displayScaleStep)
+	case "-":
+		a.ChangeDisplayScale(-displayScaleStep)
+	case "0":
+		a.ChangeDisplayScale(1 - a.displayScale())
+	default:
+		return false
+	}
+	return true
+}
+
+// ToggleFullscreen switches the window between fullscreen and windowed,
+// and opens it the same way next time
+func (a *App) ToggleFullscreen() {
+	if a.window == nil {
+		return
+	}
+	a.fullscreen = !a.fullscreen
+	if a.fullscreen {
+		a.window.Option(app.Fullscreen.Option())
+	} else {
+		a.window.Option(app.Windowed.Option())
+	}
+	a.config.Fullscreen = a.fullscreen
+	a.saveSettings()
+}
+
+// ChangeDisplayScale enlarges or shrinks everything drawn, within
+// MinDisplayScale and MaxDisplayScale
+func (a *App) ChangeDisplayScale(delta float64) {
+	scale := math.Round((a.displayScale()+delta)*100) / 100
+	a.config.DisplayScale = max(MinDisplayScale, min(MaxDisplayScale, scale))
+	a.saveSettings()
+}
+
+// displayScale returns the player's display scale, 1 if unset
+func (a *App) displayScale() float64 {
+	if a.config.DisplayScale <= 0 {
+		return 1
+	}
+	return a.config.DisplayScale
+}
+
+// scaleMetric applies the display scale on top of the screen's density,
+// so text, spacing and the highway all grow together, with text larger
+// still when streaming
+func (a *App) scaleMetric(gtx *layout.Context) {
+	s := float32(a.displayScale())
+	gtx.Metric.PxPerDp *= s
+	gtx.Metric.PxPerSp *= s
+	if a.streaming {
+		gtx.Metric.PxPerSp *= streamingTextScale
+	}
+}
+
+// displayLabel describes the window mode and scale, for the pre-start screen
+func (a *App) displayLabel() string {
+	mode := "Windowed"
+	if a.fullscreen {
+		mode = "Fullscreen"
+	}
+	if a.streaming {
+		mode += ", streaming"
+	}
+	return fmt.Sprintf("Display: %s at %.0f%% scale  (F10 streaming, F11 fullscreen, Ctrl +/- to scale, Ctrl 0 to reset)", mode, a.displayScale()*100)
+}
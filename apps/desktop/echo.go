@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+// echoTarget is the accuracy a response needs to count as matching its call
+const echoTarget = 80.0
+
+// echo plays each bar of a song to the player and then has them play it back
+type echo struct {
+	*song.Echo
+	sounded int       // Calls sounded so far
+	scores  []float64 // Accuracy of each finished response
+}
+
+// StartEcho plays the song as call and response
+func (a *App) StartEcho() {
+	e := a.gameState.Song.CallAndResponse()
+	if len(e.Phrases) == 0 {
+		return
+	}
+	a.echo = &echo{Echo: e}
+
+	a.pitchDetector.SetRange(e.Song.FrequencyRange())
+	gs := song.NewGameState(e.Song)
+	gs.Speed = a.speed
+	a.applyPlaySettings(gs)
+	a.gameState = gs
+	a.hitDetector = game.NewHitDetector(gs, a.feedbackY)
+	a.recording, a.ghost = nil, nil
+	a.sessionStart = time.Now()
+	a.state = StatePlaying
+	gs.Start()
+}
+
+// soundCalls plays the notes of each call as they come due
+func (a *App) soundCalls() {
+	e := a.echo
+	gs := a.gameState
+	for ; e.sounded < len(e.Calls) && e.Calls[e.sounded].Time <= gs.CurrentTime; e.sounded++ {
+		note := &e.Calls[e.sounded]
+		if a.audioOutput != nil {
+			a.audioOutput.Play(audio.NewPluck(gs.Song.FrequencyAt(note), note.Duration/gs.Speed, a.audioOutput.SampleRate()))
+		}
+	}
+}
+
+// updateEcho scores each response once all its notes have been judged
+func (a *App) updateEcho(playLineX float32) {
+	e := a.echo
+	gs := a.gameState
+	for len(e.scores) < len(e.Phrases) {
+		p := e.Phrases[len(e.scores)]
+		accuracy, judged := gs.RegionAccuracy(p.Region)
+		if judged < len(gs.Song.NotesInRange(p.Start, p.End)) {
+			return
+		}
+		e.scores = append(e.scores, accuracy)
+		quality := song.HitPerfect
+		if accuracy < echoTarget {
+			quality = song.HitMiss
+		}
+		gs.FloatingText = append(gs.FloatingText, song.FloatingScore{
+			Text:      fmt.Sprintf("Bar %d: %.0f%%", p.Bar+1, accuracy),
+			X:         playLineX,
+			Y:         a.feedbackY(0),
+			StartTime: time.Now(),
+			Quality:   quality,
+		})
+	}
+}
+
+// matched counts the responses that matched their call
+func (e *echo) matched() int {
+	n := 0
+	for _, s := range e.scores {
+		if s >= echoTarget {
+			n++
+		}
+	}
+	return n
+}
+
+// layoutEchoStatus says whether to listen or play, and how the responses went
+func (a *App) layoutEchoStatus(gtx layout.Context) layout.Dimensions {
+	e := a.echo
+	t := a.gameState.CurrentTime
+	text := "Call and response: listen…"
+	for i, p := range e.Phrases {
+		if t >= p.Start && t < p.End {
+			text = fmt.Sprintf("Call and response: your turn, bar %d (%d of %d)", p.Bar+1, i+1, len(e.Phrases))
+		}
+	}
+	if len(e.scores) > 0 {
+		text += fmt.Sprintf("  •  %d of %d matched", e.matched(), len(e.scores))
+	}
+	label := material.Body2(a.theme, text+"  •  Esc to stop")
+	label.Color = render.Colors.Hint
+	return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, label.Layout)
+}
+
+// layoutEchoResults sums up the responses on the results screen
+func (a *App) layoutEchoResults(gtx layout.Context) layout.Dimensions {
+	e := a.echo
+	if e == nil || len(e.scores) == 0 {
+		return layout.Dimensions{}
+	}
+	text := fmt.Sprintf("Call and response: %d of %d bars echoed at %.0f%% or better", e.matched(), len(e.scores), echoTarget)
+	worst := 0
+	for i, s := range e.scores {
+		if s < e.scores[worst] {
+			worst = i
+		}
+	}
+	if e.scores[worst] < echoTarget {
+		text += fmt.Sprintf("  •  hardest: bar %d (%.0f%%)", e.Phrases[worst].Bar+1, e.scores[worst])
+	}
+	label := material.Body1(a.theme, text)
+	label.Color = render.Colors.Text
+	return layout.Center.Layout(gtx, label.Layout)
+}
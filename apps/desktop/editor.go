@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/editor"
+	"guitargame/apps/desktop/internal/export"
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// fretEntryWindow is how quickly a second digit must follow the first
+// to be read as a two-digit fret number
+const fretEntryWindow = 800 * time.Millisecond
+
+// OpenEditor switches to the chart editor for a song
+func (a *App) OpenEditor(s *song.Song) {
+	s.ResetProgress()
+	a.editor = editor.New(s, a.songsDir)
+	a.editorDrag = false
+	a.state = StateEditor
+}
+
+// NewChart adds an empty chart to the list and opens it in the editor
+func (a *App) NewChart() {
+	s := editor.NewSong()
+	a.exercises = append(a.exercises, s)
+	a.selectedIndex = len(a.exercises) - 1
+	a.OpenEditor(s)
+}
+
+// CloseEditor leaves the editor and returns to the menu. Edits stay in
+// memory so the chart can be played straight away, even if unsaved.
+func (a *App) CloseEditor() {
+	ed := a.editor
+	a.editor = nil
+	a.closePreview()
+
+	// Drop brand new charts that were never saved or given any notes
+	if ed != nil && ed.Song.Path == "" && len(ed.Song.Notes) == 0 && len(a.exercises) > 1 {
+		for i, s := range a.exercises {
+			if s == ed.Song {
+				a.exercises = append(a.exercises[:i], a.exercises[i+1:]...)
+				break
+			}
+		}
+		if a.selectedIndex >= len(a.exercises) {
+			a.selectedIndex = len(a.exercises) - 1
+		}
+	}
+
+	a.GoToMenu()
+}
+
+// audition plays a note through the synth so charts can be checked by ear
+func (a *App) audition(note *song.TabNote) {
+	if a.audioOutput == nil || note == nil {
+		return
+	}
+	freq := a.editor.Song.FrequencyAt(note)
+	a.audioOutput.Play(audio.NewPluck(freq, 1.2, a.audioOutput.SampleRate()))
+}
+
+func (a *App) handleEditorKey(e key.Event) {
+	ed := a.editor
+	shift := e.Modifiers.Contain(key.ModShift)
+
+	switch e.Name {
+	case key.NameLeftArrow, key.NameRightArrow:
+		steps := 1
+		if e.Name == key.NameLeftArrow {
+			steps = -1
+		}
+		if shift {
+			ed.MoveNote(steps, 0)
+		} else {
+			ed.MoveCursor(steps, 0)
+		}
+	case key.NameUpArrow, key.NameDownArrow:
+		strDelta := 1
+		if e.Name == key.NameUpArrow {
+			strDelta = -1
+		}
+		if a.tabRenderer.MirrorStrings {
+			strDelta = -strDelta // Keep the arrows matching the screen
+		}
+		if shift {
+			if ed.MoveNote(0, strDelta) {
+				a.audition(ed.NoteAtCursor())
+			}
+		} else {
+			ed.MoveCursor(0, strDelta)
+		}
+	case key.NameReturn, key.NameEnter, key.NameSpace:
+		a.audition(ed.Place())
+	case key.NameDeleteBackward, key.NameDeleteForward:
+		ed.Delete()
+	case "[":
+		ed.CycleSnap(-1)
+	case "]":
+		ed.CycleSnap(1)
+	case "P":
+		if e.Modifiers.Contain(key.ModShortcut) {
+			a.OpenPreview()
+		} else {
+			a.audition(ed.NoteAtCursor())
+		}
+	case "E":
+		if e.Modifiers.Contain(key.ModShortcut) {
+			a.exportChart()
+		}
+	case "S":
+		if e.Modifiers.Contain(key.ModShortcut) {
+			if err := ed.Save(); err != nil {
+				a.notify(toastError, "failed to save %s: %v", ed.Path, err)
+			} else {
+				logging.Infof("Saved %s", ed.Path)
+				a.reloadPreview()
+			}
+		}
+	case key.NameEscape:
+		a.CloseEditor()
+	default:
+		if len(e.Name) == 1 && e.Name[0] >= '0' && e.Name[0] <= '9' {
+			a.enterFretDigit(int(e.Name[0] - '0'))
+		}
+	}
+}
+
+// exportChart writes the chart as ASCII tab, MusicXML, and printable SVG
+// tab next to its file
+func (a *App) exportChart() {
+	ed := a.editor
+	for _, format := range []string{"tab", "musicxml", "svg"} {
+		out, err := export.ToFile(ed.Song, ed.Path, format)
+		if err != nil {
+			a.notify(toastError, "failed to export %s: %v", ed.Path, err)
+			return
+		}
+		logging.Infof("Exported %s", out)
+	}
+}
+
+// enterFretDigit sets the fret from typed digits, combining two quick
+// digits into one number (e.g. "1" then "2" for fret 12)
+func (a *App) enterFretDigit(digit int) {
+	ed := a.editor
+	fret := digit
+	if time.Since(a.lastFretDigit) < fretEntryWindow && ed.Fret > 0 && ed.Fret < 10 {
+		if combined := ed.Fret*10 + digit; combined <= editor.MaxFret {
+			fret = combined
+		}
+	}
+	a.lastFretDigit = time.Now()
+
+	ed.SetFret(fret)
+	if note := ed.NoteAtCursor(); note != nil {
+		a.audition(note)
+	}
+}
+
+// handleEditorPointer lets notes be placed, dragged, and deleted with the mouse
+func (a *App) handleEditorPointer(gtx layout.Context) {
+	ed := a.editor
+	for {
+		ev, ok := gtx.Event(pointer.Filter{
+			Target: ed,
+			Kinds:  pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel,
+		})
+		if !ok {
+			break
+		}
+		e, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		beat, str := a.tabRenderer.EditorPosition(ed, e.Position.X, e.Position.Y)
+		switch e.Kind {
+		case pointer.Press:
+			if str < 0 || str >= ed.StringCount() {
+				continue
+			}
+			ed.SetCursor(beat, str)
+			if e.Buttons.Contain(pointer.ButtonSecondary) {
+				ed.Delete()
+				continue
+			}
+			note := ed.NoteAtCursor()
+			if note == nil {
+				note = ed.Place()
+			} else {
+				ed.Fret = note.Fret
+			}
+			a.audition(note)
+			a.editorDrag = true
+		case pointer.Drag:
+			if a.editorDrag && ed.DragNote(beat, str) {
+				a.audition(ed.NoteAtCursor())
+			}
+		case pointer.Release, pointer.Cancel:
+			a.editorDrag = false
+		}
+	}
+}
+
+func (a *App) layoutEditorScreen(gtx layout.Context) layout.Dimensions {
+	a.handleEditorPointer(gtx)
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Left: unit.Dp(10), Top: unit.Dp(10)}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				title := fmt.Sprintf("Editing: %s  (%.0f BPM)", a.editor.Song.Title, a.editor.Song.BPM)
+				label := material.H6(a.theme, title)
+				label.Color = render.Colors.Title
+				return label.Layout(gtx)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			dims := a.tabRenderer.LayoutEditor(gtx, a.editor)
+
+			// Register for pointer input over the editing area
+			area := clip.Rect{Max: dims.Size}.Push(gtx.Ops)
+			event.Op(gtx.Ops, a.editor)
+			area.Pop()
+
+			return dims
+		}),
+	)
+}
@@ -0,0 +1,53 @@
+package main
+
+// recentLimit is how many recently played songs the menu lists
+const recentLimit = 5
+
+// loadFavorites reads which songs are starred from the practice history
+func (a *App) loadFavorites() {
+	a.favorites = nil
+	if a.history == nil {
+		return
+	}
+	favorites, err := a.history.Favorites()
+	if err != nil {
+		a.warn("could not read favorite songs: %v", err)
+		return
+	}
+	a.favorites = favorites
+}
+
+// loadRecent reads which songs were played last from the practice history
+func (a *App) loadRecent() {
+	a.recent = nil
+	if a.history == nil {
+		return
+	}
+	recent, err := a.history.Recent(recentLimit)
+	if err != nil {
+		a.warn("could not read recently played songs: %v", err)
+		return
+	}
+	a.recent = recent
+}
+
+// ToggleFavorite stars the selected song, or unstars it
+func (a *App) ToggleFavorite() {
+	if a.history == nil || a.selectedIndex >= len(a.exercises) {
+		return
+	}
+	key := sessionKey(a.exercises[a.selectedIndex])
+	favorite := !a.favorites[key]
+	if err := a.history.SetFavorite(key, favorite); err != nil {
+		a.warn("could not save favorite: %v", err)
+		return
+	}
+	if a.favorites == nil {
+		a.favorites = make(map[string]bool)
+	}
+	if favorite {
+		a.favorites[key] = true
+	} else {
+		delete(a.favorites, key)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// songFilter is one of the ways the song list can be narrowed down
+type songFilter int
+
+const (
+	filterArtist songFilter = iota
+	filterTuning
+	filterDifficulty
+	filterTempo
+	filterUnranked
+	filterCount
+)
+
+// tempoRange is a span of starting tempos, Max exclusive
+type tempoRange struct {
+	Name     string
+	Min, Max float64
+}
+
+// tempoRanges are the tempos the song list can be narrowed to, after "any"
+var tempoRanges = []tempoRange{
+	{Name: "under 90 BPM", Min: 0, Max: 90},
+	{Name: "90-120 BPM", Min: 90, Max: 120},
+	{Name: "120-150 BPM", Min: 120, Max: 150},
+	{Name: "150+ BPM", Min: 150, Max: 1e9},
+}
+
+// menuFilters narrow the song list alongside the search. Zero values
+// mean "any".
+type menuFilters struct {
+	active     bool // The filter bar has keyboard focus
+	field      songFilter
+	artist     string
+	tuning     string
+	difficulty int // 1 to song.MaxDifficulty
+	tempo      int // 1 + index into tempoRanges
+	unranked   bool
+}
+
+// filtered reports whether any filter is set
+func (f *menuFilters) filtered() bool {
+	return f.artist != "" || f.tuning != "" || f.difficulty != 0 || f.tempo != 0 || f.unranked
+}
+
+// passesFilters reports whether a song gets past every filter
+func (a *App) passesFilters(s *song.Song) bool {
+	f := &a.search.filters
+	if f.artist != "" && s.Artist != f.artist {
+		return false
+	}
+	if f.tuning != "" && s.GetTuning().Name() != f.tuning {
+		return false
+	}
+	if f.difficulty != 0 && s.Difficulty() != f.difficulty {
+		return false
+	}
+	if f.tempo != 0 {
+		r := tempoRanges[f.tempo-1]
+		if s.BPM < r.Min || s.BPM >= r.Max {
+			return false
+		}
+	}
+	if f.unranked {
+		if best, ok := a.bestOf(s, false); ok && best.Grade == "S" {
+			return false
+		}
+	}
+	return true
+}
+
+// filterChoices returns the distinct values in the library for a
+// filter that picks among them, sorted, after "" for any
+func (a *App) filterChoices(value func(*song.Song) string) []string {
+	choices := []string{""}
+	for _, s := range a.exercises {
+		if v := value(s); v != "" && !slices.Contains(choices, v) {
+			choices = append(choices, v)
+		}
+	}
+	slices.Sort(choices[1:])
+	return choices
+}
+
+// stepChoice moves from the current choice to the next or previous one,
+// wrapping around
+func stepChoice(choices []string, current string, dir int) string {
+	i := max(0, slices.Index(choices, current))
+	return choices[(i+dir+len(choices))%len(choices)]
+}
+
+// changeFilter steps the selected filter to its next or previous value
+func (a *App) changeFilter(dir int) {
+	f := &a.search.filters
+	switch f.field {
+	case filterArtist:
+		f.artist = stepChoice(a.filterChoices(func(s *song.Song) string { return s.Artist }), f.artist, dir)
+	case filterTuning:
+		f.tuning = stepChoice(a.filterChoices(func(s *song.Song) string { return s.GetTuning().Name() }), f.tuning, dir)
+	case filterDifficulty:
+		f.difficulty = (f.difficulty + dir + song.MaxDifficulty + 1) % (song.MaxDifficulty + 1)
+	case filterTempo:
+		n := len(tempoRanges) + 1
+		f.tempo = (f.tempo + dir + n) % n
+	case filterUnranked:
+		f.unranked = !f.unranked
+	}
+	a.keepSelectionVisible()
+}
+
+// clearFilter sets the selected filter back to any
+func (a *App) clearFilter() {
+	f := &a.search.filters
+	switch f.field {
+	case filterArtist:
+		f.artist = ""
+	case filterTuning:
+		f.tuning = ""
+	case filterDifficulty:
+		f.difficulty = 0
+	case filterTempo:
+		f.tempo = 0
+	case filterUnranked:
+		f.unranked = false
+	}
+	a.keepSelectionVisible()
+}
+
+// handleFilterKey handles menu keys while the filter bar has focus
+func (a *App) handleFilterKey(e key.Event) {
+	f := &a.search.filters
+	switch e.Name {
+	case key.NameLeftArrow:
+		f.field = (f.field - 1 + filterCount) % filterCount
+	case key.NameRightArrow:
+		f.field = (f.field + 1) % filterCount
+	case key.NameUpArrow:
+		a.changeFilter(-1)
+	case key.NameDownArrow:
+		a.changeFilter(1)
+	case key.NameDeleteBackward, key.NameDeleteForward:
+		a.clearFilter()
+	case key.NameReturn, key.NameEnter, key.NameEscape, "S":
+		f.active = false
+	}
+}
+
+// filterLabels describes each filter's current value
+func (a *App) filterLabels() [filterCount]string {
+	f := &a.search.filters
+	labels := [filterCount]string{
+		filterArtist:     "Artist: any",
+		filterTuning:     "Tuning: any",
+		filterDifficulty: "Difficulty: any",
+		filterTempo:      "Tempo: any",
+		filterUnranked:   "Not yet S: off",
+	}
+	if f.artist != "" {
+		labels[filterArtist] = "Artist: " + f.artist
+	}
+	if f.tuning != "" {
+		labels[filterTuning] = "Tuning: " + f.tuning
+	}
+	if f.difficulty != 0 {
+		labels[filterDifficulty] = "Difficulty: " + strings.Repeat("★", f.difficulty)
+	}
+	if f.tempo != 0 {
+		labels[filterTempo] = "Tempo: " + tempoRanges[f.tempo-1].Name
+	}
+	if f.unranked {
+		labels[filterUnranked] = "Not yet S: on"
+	}
+	return labels
+}
+
+// layoutFilterBar shows the filters while they're being changed or any
+// is set, with the one being changed highlighted
+func (a *App) layoutFilterBar(gtx layout.Context) layout.Dimensions {
+	f := &a.search.filters
+	if !f.active && !f.filtered() {
+		return layout.Dimensions{}
+	}
+	var children []layout.FlexChild
+	for i, text := range a.filterLabels() {
+		selected := f.active && songFilter(i) == f.field
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(16)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(a.theme, text)
+				label.Color = render.Colors.Hint
+				if selected {
+					label.Text = "▶ " + text
+					label.Color = render.Colors.Title
+				}
+				return label.Layout(gtx)
+			})
+		}))
+	}
+	hint := "S to change filters"
+	if f.active {
+		hint = "←/→ choose  ↑/↓ change  Backspace clear  Enter done"
+	}
+	matches := fmt.Sprintf("%d of %d songs", len(a.visibleExercises()), len(a.exercises))
+	inset := layout.Inset{Left: unit.Dp(20), Right: unit.Dp(20), Bottom: unit.Dp(10)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Caption(a.theme, matches+"  •  "+hint)
+				label.Color = render.Colors.Disabled
+				return label.Layout(gtx)
+			}),
+		)
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/config"
+)
+
+// Command-line flags, each taking the place of its config file setting
+// for the run without changing the file
+var (
+	deviceFlag     = flag.String("device", "", "audio input device: a name, part of one, or its number in the device list")
+	sampleRateFlag = flag.Float64("sample-rate", 0, "audio sample rate in Hz (default 48000)")
+	songsDirFlag   = flag.String("songs-dir", "", "directory to load charts from and save new ones to")
+	fullscreenFlag = flag.Bool("fullscreen", false, "open the window fullscreen")
+	streamFlag     = flag.Bool("stream", false, "lay out for livestreaming: chroma-key background, larger text, no debug readouts")
+	logLevelFlag   = flag.String("log-level", "", "least serious messages to log: debug, info, warn or error (default info)")
+	statsAddrFlag  = flag.String("stats-addr", "", "address to serve the state of play on for overlays, e.g. 127.0.0.1:8765")
+	oscAddrFlag    = flag.String("osc-addr", "", "host and port to send OSC messages about play to, e.g. 127.0.0.1:9000")
+)
+
+// launchSettings are the settings used at startup
+type launchSettings struct {
+	Device     string
+	SampleRate float64
+	SongsDir   string
+	Fullscreen bool
+	Streaming  bool
+	LogLevel   string
+	StatsAddr  string
+	OSCAddr    string
+
+	StatsOrigins []string // Set only in the config file
+}
+
+// launchSettingsFrom takes the startup settings from the config file,
+// overridden by any flags given
+func launchSettingsFrom(cfg *config.Config) launchSettings {
+	s := launchSettings{
+		Device:     cfg.Device,
+		SampleRate: cfg.SampleRate,
+		SongsDir:   cfg.SongsDir,
+		Fullscreen: cfg.Fullscreen,
+		Streaming:  cfg.Streaming,
+		LogLevel:   cfg.LogLevel,
+		StatsAddr:  cfg.StatsAddr,
+		OSCAddr:    cfg.OSCAddr,
+
+		StatsOrigins: cfg.StatsOrigins,
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "device":
+			s.Device = *deviceFlag
+		case "sample-rate":
+			s.SampleRate = *sampleRateFlag
+		case "songs-dir":
+			s.SongsDir = *songsDirFlag
+		case "fullscreen":
+			s.Fullscreen = *fullscreenFlag
+		case "stream":
+			s.Streaming = *streamFlag
+		case "log-level":
+			s.LogLevel = *logLevelFlag
+		case "stats-addr":
+			s.StatsAddr = *statsAddrFlag
+		case "osc-addr":
+			s.OSCAddr = *oscAddrFlag
+		}
+	})
+	if s.SampleRate <= 0 {
+		s.SampleRate = audio.DefaultSampleRate
+	}
+	return s
+}
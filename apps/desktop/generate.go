@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/generator"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+// Endless riffs are generated a few bars ahead of the play line
+const (
+	riffInitialBars = 8
+	riffExtendBars  = 4
+	riffLookahead   = 10.0 // Seconds of notes kept ahead of the play line
+)
+
+// riffField is a setting on the riff generator screen
+type riffField int
+
+const (
+	riffFieldGenre riffField = iota
+	riffFieldKey
+	riffFieldDensity
+	riffFieldTempo
+	riffFieldCount
+)
+
+// riffSetup holds the generator settings between runs
+type riffSetup struct {
+	field    riffField
+	template int // Index into generator.Templates
+	key      int // Index into generator.Keys
+	density  float64
+	bpm      float64
+}
+
+// OpenGenerator shows the endless riff setup screen
+func (a *App) OpenGenerator() {
+	if a.riffSetup == nil {
+		a.riffSetup = &riffSetup{
+			density: 0.4,
+			bpm:     generator.Templates[0].BPM,
+		}
+	}
+	a.state = StateGenerator
+}
+
+// StartRiff generates a fresh riff and waits on the pre-start screen
+func (a *App) StartRiff() {
+	rs := a.riffSetup
+	a.riff = generator.New(generator.Options{
+		Template: generator.Templates[rs.template],
+		Key:      generator.Keys[rs.key],
+		Density:  rs.density,
+		BPM:      rs.bpm,
+		Seed:     time.Now().UnixNano(),
+	})
+	a.riff.Extend(riffInitialBars)
+
+	a.gameState = song.NewGameState(a.riff.Song())
+	a.hitDetector = game.NewHitDetector(a.gameState, a.feedbackY)
+	a.state = StatePreStart
+}
+
+// extendRiff keeps generating bars so an endless riff never runs out
+func (a *App) extendRiff() {
+	s := a.riff.Song()
+	if a.gameState.CurrentTime+riffLookahead < s.Duration {
+		return
+	}
+	a.riff.Extend(riffExtendBars)
+	a.gameState.TotalNotes = len(s.Notes)
+}
+
+// EndRiff stops an endless run and shows the results so far
+func (a *App) EndRiff() {
+	a.stopMIDIClock()
+	if a.drummer != nil {
+		a.drummer.Reset(nil, nil)
+	}
+
+	// Only count the notes that have already scrolled past
+	played := 0
+	for _, n := range a.gameState.Song.Notes {
+		if n.Time <= a.gameState.CurrentTime {
+			played++
+		}
+	}
+	a.gameState.TotalNotes = played
+	a.gameState.IsPlaying = false
+	a.gameState.IsFinished = true
+	a.showResults()
+}
+
+func (a *App) handleGeneratorKey(e key.Event) {
+	rs := a.riffSetup
+	switch e.Name {
+	case key.NameUpArrow:
+		rs.field = (rs.field - 1 + riffFieldCount) % riffFieldCount
+	case key.NameDownArrow:
+		rs.field = (rs.field + 1) % riffFieldCount
+	case key.NameLeftArrow:
+		rs.change(-1)
+	case key.NameRightArrow:
+		rs.change(1)
+	case key.NameReturn, key.NameEnter, key.NameSpace:
+		a.StartRiff()
+	case key.NameEscape:
+		a.GoToMenu()
+	}
+}
+
+// change steps the selected setting up or down
+func (rs *riffSetup) change(dir int) {
+	switch rs.field {
+	case riffFieldGenre:
+		n := len(generator.Templates)
+		rs.template = (rs.template + dir + n) % n
+		rs.bpm = generator.Templates[rs.template].BPM
+	case riffFieldKey:
+		n := len(generator.Keys)
+		rs.key = (rs.key + dir + n) % n
+	case riffFieldDensity:
+		rs.density = max(0, min(1, rs.density+float64(dir)*0.1))
+	case riffFieldTempo:
+		rs.bpm = max(40, min(240, rs.bpm+float64(dir)*5))
+	}
+}
+
+func (a *App) layoutGeneratorScreen(gtx layout.Context) layout.Dimensions {
+	rs := a.riffSetup
+	values := [riffFieldCount]string{
+		riffFieldGenre:   "Style: " + generator.Templates[rs.template].Name,
+		riffFieldKey:     "Key: " + generator.Keys[rs.key],
+		riffFieldDensity: fmt.Sprintf("Density: %s", densityBar(rs.density)),
+		riffFieldTempo:   fmt.Sprintf("Tempo: %.0f BPM", rs.bpm),
+	}
+
+	children := []layout.FlexChild{
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H5(a.theme, "Endless Riff")
+			label.Color = render.Colors.Info
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+	}
+	for i, text := range values {
+		selected := riffField(i) == rs.field
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, text)
+			label.Color = render.Colors.Text
+			if selected {
+				label.Text = "▶ " + text + " ◀"
+				label.Color = render.Colors.Title
+			}
+			return layout.Center.Layout(gtx, label.Layout)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(30)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, "↑/↓ choose  ←/→ adjust  Enter play  Esc back")
+			label.Color = render.Colors.Success
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// densityBar draws a 0..1 setting as a text slider
+func densityBar(v float64) string {
+	const steps = 10
+	filled := int(v*steps + 0.5)
+	bar := ""
+	for i := 0; i < steps; i++ {
+		if i < filled {
+			bar += "■"
+		} else {
+			bar += "□"
+		}
+	}
+	return bar
+}
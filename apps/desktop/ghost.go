@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+// ghostPath is where the profile's best run of a song is kept, named by a
+// hash of its session key since that may be a path or a title
+func (a *App) ghostPath(s *song.Song) (string, error) {
+	dir, err := a.config.Dir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(sessionKey(s)))
+	return filepath.Join(dir, "ghosts", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// loadGhost reads the best run of a song, or nil if there isn't one
+func (a *App) loadGhost(s *song.Song) *game.Ghost {
+	path, err := a.ghostPath(s)
+	if err != nil {
+		a.warn("could not load best run: %v", err)
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	var ghost game.Ghost
+	if err == nil {
+		err = json.Unmarshal(data, &ghost)
+	}
+	if err != nil {
+		a.warn("could not load best run: %v", err)
+		return nil
+	}
+	return &ghost
+}
+
+// keepBestRun stores the run just finished as the song's ghost if it beat
+// the last one. Only full runs at full speed, scored on pitch, count.
+func (a *App) keepBestRun() {
+	gs := a.gameState
+	if a.recording == nil || !a.recording.Of(gs) || !gs.IsFinished || gs.Failed || gs.Speed < 1 || gs.RhythmOnly {
+		return
+	}
+	if a.ghost != nil && gs.Score <= a.ghost.Score {
+		return
+	}
+	a.ghost = a.recording.Ghost()
+	if err := a.saveGhost(gs.Song, a.ghost); err != nil {
+		a.warn("could not save best run: %v", err)
+	}
+}
+
+func (a *App) saveGhost(s *song.Song, ghost *game.Ghost) error {
+	path, err := a.ghostPath(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(ghost)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// layoutGhost shows whether the run is ahead of or behind the personal best
+func (a *App) layoutGhost(gtx layout.Context) layout.Dimensions {
+	if a.ghost == nil || !a.recording.Of(a.gameState) {
+		return layout.Dimensions{}
+	}
+	score := a.gameState.Score
+	ghost := a.ghost.ScoreAt(a.gameState.CurrentTime)
+	text := fmt.Sprintf("%+d ahead of your best", score-ghost)
+	if score < ghost {
+		text = fmt.Sprintf("%d behind your best", ghost-score)
+	}
+	return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return a.tabRenderer.DrawGhostBar(gtx, score, ghost, a.ghost.Score)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(a.theme, text)
+				label.Color = render.Colors.Hint
+				return label.Layout(gtx)
+			}),
+		)
+	})
+}
@@ -6,14 +6,21 @@ require (
 	gioui.org v0.9.0
 	github.com/coral/aubio-go v0.0.0-20190313043018-9658a1866288
 	github.com/gordonklaus/portaudio v0.0.0-20250206071425-98a94950218b
+	go.etcd.io/bbolt v1.4.3
+	go.starlark.net v0.0.0-20231121155337-90ade8b19d09
+	golang.org/x/image v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
+	guitargame/core v0.0.0
 )
 
 require (
 	gioui.org/shader v1.0.8 // indirect
+	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/go-text/typesetting v0.3.0 // indirect
 	golang.org/x/exp/shiny v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
-	golang.org/x/image v0.31.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// The shared game engine lives at the top of the repository
+replace guitargame/core => ../../core
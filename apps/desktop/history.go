@@ -0,0 +1,84 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"guitargame/apps/desktop/internal/history"
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/core/song"
+)
+
+// openHistory opens the profile's practice history, carrying on without
+// it if it can't be opened (such as while another copy of the game has it)
+func (a *App) openHistory() {
+	dir, err := a.config.Dir()
+	if err == nil {
+		a.history, err = history.Open(filepath.Join(dir, history.FileName))
+	}
+	if err != nil {
+		a.history = nil
+		a.warn("practice history unavailable: %v", err)
+	}
+}
+
+// closeHistory closes the practice history
+func (a *App) closeHistory() {
+	if a.history == nil {
+		return
+	}
+	if err := a.history.Close(); err != nil {
+		logging.Warnf("could not close practice history: %v", err)
+	}
+	a.history = nil
+}
+
+// logSession records the play that just ended in the practice history
+func (a *App) logSession() {
+	gs := a.gameState
+	if a.history == nil || a.sessionStart.IsZero() {
+		return
+	}
+	mode := "song"
+	switch {
+	case a.playingDaily():
+		mode = history.ModeDaily
+	case a.echo != nil:
+		mode = "echo"
+	case a.riff != nil:
+		mode = history.ModeRiff
+	case a.variations != nil:
+		mode = "variations"
+	}
+	offset, spread := song.TimingSpread(gs.HitTimings())
+	s := &history.Session{
+		Song:        gs.Song.Title,
+		Key:         sessionKey(gs.Song),
+		Hash:        gs.Song.ContentHash(),
+		Mode:        mode,
+		Date:        a.sessionStart,
+		Duration:    time.Since(a.sessionStart).Seconds(),
+		SongTime:    gs.CurrentTime,
+		Speed:       gs.Speed,
+		Completed:   gs.IsFinished && !gs.Failed,
+		Failed:      gs.Failed,
+		Score:       gs.Score,
+		Accuracy:    gs.Accuracy(),
+		NotesHit:    gs.NotesHit,
+		NotesMissed: gs.NotesMissed,
+		MaxCombo:    gs.MaxCombo,
+		Cents:       gs.AverageCents(),
+		Offset:      offset,
+		Spread:      spread,
+		WrongNotes:  gs.WrongNotes,
+		RhythmOnly:  gs.RhythmOnly,
+	}
+	a.sessionStart = time.Time{}
+	if err := a.history.Add(s); err != nil {
+		a.warn("could not record practice session: %v", err)
+	}
+	if s.Mode == history.ModeDaily {
+		a.loadDailies()
+	}
+	a.loadRecent()
+}
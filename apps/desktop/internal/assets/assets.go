@@ -0,0 +1,206 @@
+// Package assets provides the exercises, sounds, and fonts built into the
+// binary, overlaid by files in the user's own directories
+package assets
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/font/gofont"
+	"gioui.org/font/opentype"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/script"
+	"guitargame/core/song"
+)
+
+//go:embed songs sounds
+var embedded embed.FS
+
+// Asset kinds, each a subdirectory of an asset root
+const (
+	KindSongs  = "songs"
+	KindSounds = "sounds"
+	KindFonts  = "fonts"
+)
+
+// DefaultSoundPack is the name of the sound pack built into the binary
+const DefaultSoundPack = "Default"
+
+// Manager finds assets in user directories first and falls back to the
+// built-in ones, so a bare binary works out of the box
+type Manager struct {
+	roots    []string // User asset roots, highest priority first
+	songsDir string   // The one songs directory to use, if set
+}
+
+// NewManager creates a manager that looks in roots, in priority order
+func NewManager(roots ...string) *Manager {
+	return &Manager{roots: roots}
+}
+
+// UseSongsDir loads charts from dir, and saves new ones there, instead of
+// the first songs directory found under the roots
+func (m *Manager) UseSongsDir(dir string) {
+	m.songsDir = dir
+}
+
+// DefaultRoots are the working directory, the executable's directory,
+// and ~/.config/guitargame
+func DefaultRoots() []string {
+	roots := []string{"."}
+	if exe, err := os.Executable(); err == nil {
+		roots = append(roots, filepath.Dir(exe))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		roots = append(roots, filepath.Join(home, ".config", "guitargame"))
+	}
+	return roots
+}
+
+// Dirs returns the user directories that exist for a kind of asset, in
+// priority order
+func (m *Manager) Dirs(kind string) []string {
+	var dirs []string
+	for _, root := range m.roots {
+		dir := filepath.Join(root, kind)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Songs loads the charts from the first user songs directory that has
+// any, plus the built-in exercises it doesn't replace (by file name). It
+// also returns the directory new charts should be saved to, and the
+// charts there that couldn't be loaded.
+func (m *Manager) Songs() ([]*song.Song, string, []song.SkippedChart) {
+	songsDir := filepath.Join(".", KindSongs)
+	if len(m.roots) > 0 {
+		songsDir = filepath.Join(m.roots[0], KindSongs)
+	}
+	dirs := m.Dirs(KindSongs)
+	if m.songsDir != "" {
+		songsDir, dirs = m.songsDir, []string{m.songsDir}
+	}
+
+	var songs []*song.Song
+	var skipped []song.SkippedChart
+	for _, dir := range dirs {
+		loaded, bad, err := song.LoadLibrary(dir)
+		if err != nil {
+			continue
+		}
+		generated, failed := script.LoadDir(dir)
+		loaded, bad = append(loaded, generated...), append(bad, failed...)
+		if len(loaded) > 0 {
+			songs, songsDir, skipped = loaded, dir, bad
+			break
+		}
+	}
+
+	entries, _ := fs.ReadDir(embedded, KindSongs)
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(songsDir, e.Name())); err == nil {
+			continue // Replaced by the user's copy
+		}
+		data, err := embedded.ReadFile(path.Join(KindSongs, e.Name()))
+		if err != nil {
+			continue
+		}
+		s, err := song.ParseSong(data)
+		if err != nil {
+			continue
+		}
+		songs = append(songs, s)
+	}
+
+	sort.SliceStable(songs, func(i, j int) bool {
+		return songs[i].Title < songs[j].Title
+	})
+	return songs, songsDir, skipped
+}
+
+// SoundPacks returns the names of the available sound packs, starting
+// with the built-in one. User packs are folders in a sounds directory.
+func (m *Manager) SoundPacks() []string {
+	names := []string{DefaultSoundPack}
+	seen := map[string]bool{DefaultSoundPack: true}
+	for _, dir := range m.Dirs(KindSounds) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() && !seen[e.Name()] && hasSounds(filepath.Join(dir, e.Name())) {
+				seen[e.Name()] = true
+				names = append(names, e.Name())
+			}
+		}
+	}
+	return names
+}
+
+func hasSounds(dir string) bool {
+	for _, sound := range audio.Sounds {
+		if _, err := os.Stat(filepath.Join(dir, string(sound)+".wav")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// SoundPack loads a sound pack by name. Sounds a user pack doesn't
+// provide come from the built-in pack.
+func (m *Manager) SoundPack(name string) (*audio.SoundPack, error) {
+	sub, err := fs.Sub(embedded, path.Join(KindSounds, "default"))
+	if err != nil {
+		return nil, err
+	}
+	builtin, err := audio.LoadSoundPack(DefaultSoundPack, sub, nil)
+	if err != nil || name == DefaultSoundPack {
+		return builtin, err
+	}
+
+	for _, dir := range m.Dirs(KindSounds) {
+		packDir := filepath.Join(dir, name)
+		if hasSounds(packDir) {
+			return audio.LoadSoundPack(name, os.DirFS(packDir), builtin)
+		}
+	}
+	return builtin, nil
+}
+
+// Fonts returns any TrueType or OpenType fonts in the user's fonts
+// directories, followed by the built-in Go fonts
+func (m *Manager) Fonts() []font.FontFace {
+	var faces []font.FontFace
+	for _, dir := range m.Dirs(KindFonts) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".ttf", ".otf", ".ttc", ".otc":
+			default:
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				continue
+			}
+			if collection, err := opentype.ParseCollection(data); err == nil {
+				faces = append(faces, collection...)
+			}
+		}
+	}
+	return append(faces, gofont.Collection()...)
+}
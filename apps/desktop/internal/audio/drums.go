@@ -0,0 +1,117 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+)
+
+// DrumKind selects which drum sound a DrumVoice synthesizes
+type DrumKind int
+
+const (
+	DrumKick DrumKind = iota
+	DrumSnare
+	DrumHiHat
+	DrumOpenHiHat
+	DrumClick       // Metronome tick
+	DrumClickAccent // Metronome tick on the downbeat
+)
+
+// DrumVoice is a synthesized drum hit
+type DrumVoice struct {
+	kind       DrumKind
+	sampleRate float64
+	gain       float64
+	n          int // samples rendered so far
+	length     int
+	phase      float64
+	lastNoise  float64
+}
+
+// NewDrum creates a drum hit; velocity ranges from 0 to 1
+func NewDrum(kind DrumKind, velocity, sampleRate float64) *DrumVoice {
+	lengths := map[DrumKind]float64{
+		DrumKick:        0.35,
+		DrumSnare:       0.25,
+		DrumHiHat:       0.08,
+		DrumOpenHiHat:   0.35,
+		DrumClick:       0.04,
+		DrumClickAccent: 0.05,
+	}
+	return &DrumVoice{
+		kind:       kind,
+		sampleRate: sampleRate,
+		gain:       velocity,
+		length:     int(lengths[kind] * sampleRate),
+	}
+}
+
+// Process implements Voice
+func (d *DrumVoice) Process(out []float32) bool {
+	for i := range out {
+		if d.n >= d.length {
+			return false
+		}
+		t := float64(d.n) / d.sampleRate
+		out[i] += float32(d.sample(t) * d.gain)
+		d.n++
+	}
+	return true
+}
+
+func (d *DrumVoice) sample(t float64) float64 {
+	switch d.kind {
+	case DrumKick:
+		// Sine with a fast downward pitch sweep
+		freq := 45 + 75*math.Exp(-t/0.03)
+		d.phase += 2 * math.Pi * freq / d.sampleRate
+		return math.Sin(d.phase) * math.Exp(-t/0.12)
+	case DrumSnare:
+		// Body tone plus a burst of noise for the wires
+		d.phase += 2 * math.Pi * 185 / d.sampleRate
+		body := math.Sin(d.phase) * math.Exp(-t/0.04)
+		wires := (rand.Float64()*2 - 1) * math.Exp(-t/0.07)
+		return 0.4*body + 0.5*wires
+	case DrumClick, DrumClickAccent:
+		// Short sine blip, pitched up for the downbeat
+		freq := 1000.0
+		if d.kind == DrumClickAccent {
+			freq = 1500
+		}
+		d.phase += 2 * math.Pi * freq / d.sampleRate
+		return 0.5 * math.Sin(d.phase) * math.Exp(-t/0.008)
+	default:
+		// High-passed noise; open hats ring longer
+		decay := 0.015
+		if d.kind == DrumOpenHiHat {
+			decay = 0.09
+		}
+		noise := rand.Float64()*2 - 1
+		highPassed := noise - d.lastNoise
+		d.lastNoise = noise
+		return 0.25 * highPassed * math.Exp(-t/decay)
+	}
+}
+
+// Delayed starts a voice after a number of samples of silence, letting
+// callers schedule sounds ahead of time with sample accuracy
+type Delayed struct {
+	Voice
+	delay int
+}
+
+// NewDelayed wraps v so it starts after delay seconds
+func NewDelayed(v Voice, delay, sampleRate float64) *Delayed {
+	return &Delayed{Voice: v, delay: int(math.Max(0, delay) * sampleRate)}
+}
+
+// Process implements Voice
+func (d *Delayed) Process(out []float32) bool {
+	if d.delay >= len(out) {
+		d.delay -= len(out)
+		return true
+	}
+	start := d.delay
+	d.delay = 0
+	return d.Voice.Process(out[start:])
+}
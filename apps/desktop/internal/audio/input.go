@@ -1,10 +1,15 @@
 package audio
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/gordonklaus/portaudio"
+
+	"guitargame/apps/desktop/internal/logging"
 )
 
 const (
@@ -12,60 +17,150 @@ const (
 	DefaultBufferSize = 2048
 )
 
+// errNoStream is returned when the stream couldn't be reopened after a restart
+var errNoStream = errors.New("audio input stream is not open")
+
 type AudioInput struct {
 	stream     *portaudio.Stream
+	device     string // Requested device; empty for the default
+	deviceName string // Device the stream is open on
 	buffer     []float32
 	sampleRate float64
 	bufferSize int
 	mu         sync.Mutex
 	latest     []float32
+	callbacks  uint64 // Buffers delivered by the stream, including silent ones
 }
 
-func NewAudioInput(sampleRate float64, bufferSize int) (*AudioInput, error) {
+// NewAudioInput opens a mono input stream on a device, chosen as by
+// FindInputDevice, or on the default device if device is empty
+func NewAudioInput(device string, sampleRate float64, bufferSize int) (*AudioInput, error) {
 	if err := portaudio.Initialize(); err != nil {
 		return nil, fmt.Errorf("failed to initialize PortAudio: %w", err)
 	}
 
 	input := &AudioInput{
+		device:     device,
 		buffer:     make([]float32, bufferSize),
 		latest:     make([]float32, bufferSize),
 		sampleRate: sampleRate,
 		bufferSize: bufferSize,
 	}
 
-	stream, err := portaudio.OpenDefaultStream(
-		1,          // input channels (mono)
-		0,          // output channels
-		sampleRate, // sample rate
-		bufferSize, // frames per buffer
-		input.processAudio,
-	)
-	if err != nil {
+	if err := input.open(); err != nil {
 		portaudio.Terminate()
-		return nil, fmt.Errorf("failed to open audio stream: %w", err)
+		return nil, err
 	}
-
-	input.stream = stream
 	return input, nil
 }
 
+func (a *AudioInput) open() error {
+	dev, err := portaudio.DefaultInputDevice()
+	if a.device != "" {
+		dev, err = FindInputDevice(a.device)
+	}
+	if err != nil {
+		return err
+	}
+	params := portaudio.HighLatencyParameters(dev, nil)
+	params.Input.Channels = 1
+	params.SampleRate = a.sampleRate
+	params.FramesPerBuffer = a.bufferSize
+	stream, err := portaudio.OpenStream(params, a.processAudio)
+	if err != nil {
+		return fmt.Errorf("failed to open audio stream on %s: %w", dev.Name, err)
+	}
+	a.stream = stream
+	a.deviceName = dev.Name
+	return nil
+}
+
+// FindInputDevice finds an input device by its number in the device
+// list, its name, or failing those a part of its name. PortAudio must
+// already be initialized.
+func FindInputDevice(device string) (*portaudio.DeviceInfo, error) {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, err
+	}
+	if i, err := strconv.Atoi(device); err == nil {
+		if i < 0 || i >= len(devices) || devices[i].MaxInputChannels == 0 {
+			return nil, fmt.Errorf("no input device number %d", i)
+		}
+		return devices[i], nil
+	}
+	var partial *portaudio.DeviceInfo
+	for _, d := range devices {
+		if d.MaxInputChannels == 0 {
+			continue
+		}
+		if strings.EqualFold(d.Name, device) {
+			return d, nil
+		}
+		if partial == nil && strings.Contains(strings.ToLower(d.Name), strings.ToLower(device)) {
+			partial = d
+		}
+	}
+	if partial == nil {
+		return nil, fmt.Errorf("no input device matching %q", device)
+	}
+	return partial, nil
+}
+
+// Restart closes and reopens the input stream, e.g. after the user grants
+// microphone access or the device stops delivering audio
+func (a *AudioInput) Restart() error {
+	if a.stream != nil {
+		a.stream.Stop()
+		a.stream.Close()
+		a.stream = nil
+	}
+
+	a.mu.Lock()
+	clear(a.latest)
+	a.mu.Unlock()
+
+	if err := a.open(); err != nil {
+		return err
+	}
+	return a.stream.Start()
+}
+
 func (a *AudioInput) processAudio(in []float32) {
 	a.mu.Lock()
 	copy(a.latest, in)
+	a.callbacks++
 	a.mu.Unlock()
 }
 
+// Callbacks counts the buffers the stream has delivered, which stops
+// going up if the device or driver stalls
+func (a *AudioInput) Callbacks() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.callbacks
+}
+
 func (a *AudioInput) Start() error {
+	if a.stream == nil {
+		return errNoStream
+	}
 	return a.stream.Start()
 }
 
 func (a *AudioInput) Stop() error {
+	if a.stream == nil {
+		return nil
+	}
 	return a.stream.Stop()
 }
 
 func (a *AudioInput) Close() error {
-	if err := a.stream.Close(); err != nil {
-		return err
+	if a.stream != nil {
+		if err := a.stream.Close(); err != nil {
+			return err
+		}
+		a.stream = nil
 	}
 	return portaudio.Terminate()
 }
@@ -85,6 +180,25 @@ func (a *AudioInput) BufferSize() int {
 	return a.bufferSize
 }
 
+// DeviceName names the device being listened to
+func (a *AudioInput) DeviceName() string {
+	return a.deviceName
+}
+
+// DefaultDevices names the default input and output devices, which are
+// the ones the game uses unless told otherwise. PortAudio must already be
+// initialized.
+func DefaultDevices() (input, output string) {
+	input, output = "none", "none"
+	if d, err := portaudio.DefaultInputDevice(); err == nil {
+		input = d.Name
+	}
+	if d, err := portaudio.DefaultOutputDevice(); err == nil {
+		output = d.Name
+	}
+	return input, output
+}
+
 func ListDevices() error {
 	if err := portaudio.Initialize(); err != nil {
 		return err
@@ -96,10 +210,10 @@ func ListDevices() error {
 		return err
 	}
 
-	fmt.Println("Available audio devices:")
+	logging.Infof("Available audio devices:")
 	for i, d := range devices {
 		if d.MaxInputChannels > 0 {
-			fmt.Printf("  [%d] %s (inputs: %d, sample rate: %.0f)\n",
+			logging.Infof("  [%d] %s (inputs: %d, sample rate: %.0f)",
 				i, d.Name, d.MaxInputChannels, d.DefaultSampleRate)
 		}
 	}
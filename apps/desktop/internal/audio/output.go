@@ -0,0 +1,125 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// DefaultOutputBufferSize is kept small so auditioned notes feel immediate
+const DefaultOutputBufferSize = 512
+
+// Voice is a sound source that can be mixed into the output stream
+type Voice interface {
+	// Process adds the voice's next samples to out and reports whether
+	// the voice is still sounding
+	Process(out []float32) bool
+}
+
+// Mixer sums any number of voices into a single mono signal
+type Mixer struct {
+	mu     sync.Mutex
+	voices []Voice
+	Gain   float32
+}
+
+// NewMixer creates an empty mixer
+func NewMixer() *Mixer {
+	return &Mixer{Gain: 0.8}
+}
+
+// Play starts a voice; it is dropped automatically once finished
+func (m *Mixer) Play(v Voice) {
+	m.mu.Lock()
+	m.voices = append(m.voices, v)
+	m.mu.Unlock()
+}
+
+// Process fills out with the mix of all active voices
+func (m *Mixer) Process(out []float32) {
+	for i := range out {
+		out[i] = 0
+	}
+
+	m.mu.Lock()
+	active := m.voices[:0]
+	for _, v := range m.voices {
+		if v.Process(out) {
+			active = append(active, v)
+		}
+	}
+	for i := len(active); i < len(m.voices); i++ {
+		m.voices[i] = nil
+	}
+	m.voices = active
+	m.mu.Unlock()
+
+	for i := range out {
+		s := out[i] * m.Gain
+		if s > 1 {
+			s = 1
+		} else if s < -1 {
+			s = -1
+		}
+		out[i] = s
+	}
+}
+
+// AudioOutput plays mixed voices on the default output device
+type AudioOutput struct {
+	stream     *portaudio.Stream
+	sampleRate float64
+	mixer      *Mixer
+}
+
+// NewAudioOutput opens a mono output stream on the default device
+func NewAudioOutput(sampleRate float64, bufferSize int) (*AudioOutput, error) {
+	if err := portaudio.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize PortAudio: %w", err)
+	}
+
+	output := &AudioOutput{
+		sampleRate: sampleRate,
+		mixer:      NewMixer(),
+	}
+
+	stream, err := portaudio.OpenDefaultStream(
+		0,          // input channels
+		1,          // output channels (mono)
+		sampleRate, // sample rate
+		bufferSize, // frames per buffer
+		output.mixer.Process,
+	)
+	if err != nil {
+		portaudio.Terminate()
+		return nil, fmt.Errorf("failed to open output stream: %w", err)
+	}
+
+	output.stream = stream
+	return output, nil
+}
+
+func (o *AudioOutput) Start() error {
+	return o.stream.Start()
+}
+
+func (o *AudioOutput) Stop() error {
+	return o.stream.Stop()
+}
+
+func (o *AudioOutput) Close() error {
+	if err := o.stream.Close(); err != nil {
+		return err
+	}
+	return portaudio.Terminate()
+}
+
+// Play mixes a voice into the output
+func (o *AudioOutput) Play(v Voice) {
+	o.mixer.Play(v)
+}
+
+func (o *AudioOutput) SampleRate() float64 {
+	return o.sampleRate
+}
@@ -4,23 +4,27 @@ import (
 	"math"
 
 	aubio "github.com/coral/aubio-go"
+
+	"guitargame/core/pitch"
 )
 
-var noteNames = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+// Range used until SetRange picks an instrument: a bass from its low B
+// up to the harmonics of a 6-string's high C
+const (
+	DefaultMinFrequency = 20
+	DefaultMaxFrequency = 1000
+)
 
-type PitchResult struct {
-	Frequency  float64
-	Confidence float64
-	Note       string
-	Octave     int
-	Cents      int
-	RMS        float64
-}
+// DetectorAlgorithm names the pitch detection method in use
+const DetectorAlgorithm = "yin"
 
 type PitchDetector struct {
 	detector   *aubio.Pitch
 	sampleRate float64
 	bufferSize int
+
+	// Frequencies outside this range get no confidence
+	minFreq, maxFreq float64
 }
 
 func NewPitchDetector(bufferSize int, sampleRate float64) *PitchDetector {
@@ -34,10 +38,17 @@ func NewPitchDetector(bufferSize int, sampleRate float64) *PitchDetector {
 		detector:   detector,
 		sampleRate: sampleRate,
 		bufferSize: bufferSize,
+		minFreq:    DefaultMinFrequency,
+		maxFreq:    DefaultMaxFrequency,
 	}
 }
 
-func (p *PitchDetector) Detect(samples []float32) PitchResult {
+// SetRange limits detection to an instrument's frequency range in Hz
+func (p *PitchDetector) SetRange(minFreq, maxFreq float64) {
+	p.minFreq, p.maxFreq = minFreq, maxFreq
+}
+
+func (p *PitchDetector) Detect(samples []float32) pitch.Result {
 	data := make([]float64, len(samples))
 	for i, s := range samples {
 		data[i] = float64(s)
@@ -58,18 +69,9 @@ func (p *PitchDetector) Detect(samples []float32) PitchResult {
 	}
 
 	rms := computeRMS(samples)
-	conf := computeConfidence(freq, rms)
+	conf := p.computeConfidence(freq, rms)
 
-	note, octave, cents := frequencyToNote(freq)
-
-	return PitchResult{
-		Frequency:  freq,
-		Confidence: conf,
-		Note:       note,
-		Octave:     octave,
-		Cents:      cents,
-		RMS:        rms,
-	}
+	return pitch.NewResult(freq, conf, rms, pitch.HarmonicBrightness(samples, p.sampleRate, freq))
 }
 
 func computeRMS(samples []float32) float64 {
@@ -83,8 +85,8 @@ func computeRMS(samples []float32) float64 {
 	return math.Sqrt(sum / float64(len(samples)))
 }
 
-func computeConfidence(freq, rms float64) float64 {
-	if freq < 20 || freq > 500 {
+func (p *PitchDetector) computeConfidence(freq, rms float64) float64 {
+	if freq < p.minFreq || freq > p.maxFreq {
 		return 0
 	}
 	if rms < 0.001 {
@@ -99,52 +101,3 @@ func (p *PitchDetector) Close() {
 		p.detector.Free()
 	}
 }
-
-func frequencyToNote(freq float64) (string, int, int) {
-	if freq < 20 || freq > 5000 {
-		return "", 0, 0
-	}
-
-	midiNote := 12*math.Log2(freq/440) + 69
-	noteNum := int(math.Round(midiNote))
-	cents := int((midiNote - float64(noteNum)) * 100)
-
-	name := noteNames[((noteNum%12)+12)%12]
-	octave := (noteNum / 12) - 1
-
-	return name, octave, cents
-}
-
-func NoteToFrequency(note string, octave int) float64 {
-	noteIndex := -1
-	for i, n := range noteNames {
-		if n == note {
-			noteIndex = i
-			break
-		}
-	}
-	if noteIndex == -1 {
-		return 0
-	}
-
-	midiNote := (octave+1)*12 + noteIndex
-	return 440 * math.Pow(2, float64(midiNote-69)/12)
-}
-
-func (r PitchResult) NoteName() string {
-	if r.Note == "" {
-		return "--"
-	}
-	return r.Note
-}
-
-func (r PitchResult) FullNoteName() string {
-	if r.Note == "" {
-		return "--"
-	}
-	return r.Note + string(rune('0'+r.Octave))
-}
-
-func (r PitchResult) IsValid() bool {
-	return r.Note != "" && r.Confidence > 0.5
-}
@@ -0,0 +1,57 @@
+package audio
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Sound names a sound in a pack. Each is loaded from a WAV file of the
+// same name (e.g. hit.wav).
+type Sound string
+
+const (
+	SoundHit             Sound = "hit"
+	SoundMiss            Sound = "miss"
+	SoundMetronome       Sound = "metronome"
+	SoundMetronomeAccent Sound = "metronome-accent"
+)
+
+// Sounds are all the sounds a pack can provide
+var Sounds = []Sound{SoundHit, SoundMiss, SoundMetronome, SoundMetronomeAccent}
+
+// SoundPack is a set of feedback and metronome sounds
+type SoundPack struct {
+	Name   string
+	sounds map[Sound]*Sample
+}
+
+// LoadSoundPack loads a pack's WAV files from fsys. Sounds it doesn't
+// provide are taken from fallback, if given.
+func LoadSoundPack(name string, fsys fs.FS, fallback *SoundPack) (*SoundPack, error) {
+	p := &SoundPack{Name: name, sounds: make(map[Sound]*Sample)}
+	for _, sound := range Sounds {
+		f, err := fsys.Open(string(sound) + ".wav")
+		if err != nil {
+			if fallback != nil {
+				p.sounds[sound] = fallback.sounds[sound]
+			}
+			continue
+		}
+		sample, err := DecodeWAV(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s.wav: %w", name, sound, err)
+		}
+		p.sounds[sound] = sample
+	}
+	return p, nil
+}
+
+// Voice returns a voice playing one of the pack's sounds, or nil if the
+// pack doesn't have it
+func (p *SoundPack) Voice(sound Sound, gain, sampleRate float64) Voice {
+	if p == nil || p.sounds[sound] == nil {
+		return nil
+	}
+	return NewSampleVoice(p.sounds[sound], gain, sampleRate)
+}
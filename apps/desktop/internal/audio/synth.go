@@ -0,0 +1,106 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Pluck is a Karplus-Strong plucked string voice, close enough to a
+// bass note to audition charts by ear
+type Pluck struct {
+	delay     []float32
+	pos       int
+	remaining int
+	decay     float32
+	gain      float32
+}
+
+// NewPluck creates a plucked note at freq Hz lasting duration seconds
+func NewPluck(freq, duration, sampleRate float64) *Pluck {
+	if freq <= 0 {
+		freq = 41.2
+	}
+	period := int(sampleRate / freq)
+	if period < 2 {
+		period = 2
+	}
+
+	// Excite the string with a burst of noise
+	delay := make([]float32, period)
+	for i := range delay {
+		delay[i] = rand.Float32()*2 - 1
+	}
+
+	return &Pluck{
+		delay:     delay,
+		remaining: int(duration * sampleRate),
+		decay:     0.996,
+		gain:      0.6,
+	}
+}
+
+// Process implements Voice
+func (p *Pluck) Process(out []float32) bool {
+	n := len(p.delay)
+	for i := range out {
+		if p.remaining <= 0 {
+			return false
+		}
+
+		next := (p.pos + 1) % n
+		sample := p.delay[p.pos]
+
+		// Average adjacent samples: a gentle low-pass that makes the tone ring
+		p.delay[p.pos] = (sample + p.delay[next]) * 0.5 * p.decay
+
+		// Short fade-out at the end to avoid a click
+		gain := p.gain
+		if p.remaining < 256 {
+			gain *= float32(p.remaining) / 256
+		}
+		out[i] += sample * gain
+
+		p.pos = next
+		p.remaining--
+	}
+	return true
+}
+
+// Tone is a steady sine wave, for checking that the output can be heard
+type Tone struct {
+	phase, step float64
+	remaining   int
+	fade        int // Samples faded out at the end to avoid a click
+	gain        float32
+}
+
+// NewTone creates a sine tone at freq Hz lasting duration seconds
+func NewTone(freq, duration, sampleRate float64) *Tone {
+	return &Tone{
+		step:      2 * math.Pi * freq / sampleRate,
+		remaining: int(duration * sampleRate),
+		fade:      int(0.02 * sampleRate),
+		gain:      0.5,
+	}
+}
+
+// Process implements Voice
+func (t *Tone) Process(out []float32) bool {
+	for i := range out {
+		if t.remaining <= 0 {
+			return false
+		}
+		gain := t.gain
+		if t.remaining < t.fade {
+			gain *= float32(t.remaining) / float32(t.fade)
+		}
+		out[i] += float32(math.Sin(t.phase)) * gain
+
+		t.phase += t.step
+		if t.phase > 2*math.Pi {
+			t.phase -= 2 * math.Pi
+		}
+		t.remaining--
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package audio
+
+import "time"
+
+const (
+	// DefaultStallTimeout is how long the input may go without delivering
+	// a buffer before it's considered stalled
+	DefaultStallTimeout = 2 * time.Second
+
+	// maxWatchdogBackoff caps the wait between restarts of an input that
+	// stays stalled, e.g. an unplugged interface
+	maxWatchdogBackoff = time.Minute
+)
+
+// Watchdog restarts an input stream that has stopped delivering audio.
+// It counts the stream's callbacks rather than comparing samples, since a
+// muted or gated input legitimately delivers the same silent buffer.
+type Watchdog struct {
+	input     *AudioInput
+	timeout   time.Duration
+	backoff   time.Duration // Grows while restarts don't help
+	callbacks uint64
+	changed   time.Time
+}
+
+// NewWatchdog watches an input for stalls
+func NewWatchdog(input *AudioInput, timeout time.Duration) *Watchdog {
+	return &Watchdog{
+		input:     input,
+		timeout:   timeout,
+		backoff:   timeout,
+		callbacks: input.Callbacks(),
+		changed:   time.Now(),
+	}
+}
+
+// Check restarts the stream if it hasn't delivered a buffer for too long.
+// It reports whether a restart was attempted and any error from it.
+func (w *Watchdog) Check() (restarted bool, err error) {
+	now := time.Now()
+	if n := w.input.Callbacks(); n != w.callbacks {
+		w.callbacks = n
+		w.changed = now
+		w.backoff = w.timeout
+		return false, nil
+	}
+	if now.Sub(w.changed) < w.backoff {
+		return false, nil
+	}
+
+	w.changed = now
+	w.backoff = min(w.backoff*2, maxWatchdogBackoff)
+	return true, w.input.Restart()
+}
+
+// Reset restarts the stall timer, e.g. after the stream was deliberately
+// restarted elsewhere
+func (w *Watchdog) Reset() {
+	w.callbacks = w.input.Callbacks()
+	w.changed = time.Now()
+	w.backoff = w.timeout
+}
@@ -0,0 +1,229 @@
+package backing
+
+import (
+	"sort"
+	"strings"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/core/song"
+)
+
+// BeatsPerBar is the bar length all drum patterns are written in
+const BeatsPerBar = 4
+
+// lookahead is how far ahead of the song clock hits are scheduled (seconds).
+// Hits are queued with a sample-accurate delay, so frame jitter doesn't
+// affect the groove.
+const lookahead = 0.1
+
+// Hit is a single drum stroke within a bar
+type Hit struct {
+	Beat     float64 // Position within the bar, in beats from the downbeat
+	Drum     audio.DrumKind
+	Velocity float64
+}
+
+// Pattern is a one-bar drum groove that repeats for the whole song
+type Pattern struct {
+	Name string
+	Hits []Hit // In beat order
+}
+
+// Player is where the drummer sends its sounds
+type Player interface {
+	Play(v audio.Voice)
+	SampleRate() float64
+}
+
+// Patterns are the built-in feels, in the order they are cycled through
+var Patterns = []*Pattern{
+	{
+		Name: "Rock",
+		Hits: merge(
+			eighths(audio.DrumHiHat, 0.6),
+			[]Hit{
+				{Beat: 0, Drum: audio.DrumKick, Velocity: 1},
+				{Beat: 1, Drum: audio.DrumSnare, Velocity: 0.9},
+				{Beat: 2, Drum: audio.DrumKick, Velocity: 1},
+				{Beat: 2.5, Drum: audio.DrumKick, Velocity: 0.8},
+				{Beat: 3, Drum: audio.DrumSnare, Velocity: 0.9},
+			},
+		),
+	},
+	{
+		Name: "Funk",
+		Hits: merge(
+			sixteenths(audio.DrumHiHat),
+			[]Hit{
+				{Beat: 0, Drum: audio.DrumKick, Velocity: 1},
+				{Beat: 0.75, Drum: audio.DrumKick, Velocity: 0.8},
+				{Beat: 1, Drum: audio.DrumSnare, Velocity: 0.9},
+				{Beat: 1.75, Drum: audio.DrumSnare, Velocity: 0.25},
+				{Beat: 2.5, Drum: audio.DrumKick, Velocity: 0.9},
+				{Beat: 3, Drum: audio.DrumSnare, Velocity: 0.9},
+				{Beat: 3.25, Drum: audio.DrumSnare, Velocity: 0.25},
+				{Beat: 3.5, Drum: audio.DrumOpenHiHat, Velocity: 0.5},
+			},
+		),
+	},
+	{
+		Name: "Swing",
+		Hits: []Hit{
+			// Ride pattern on swung eighths ("ding, ding-da ding, ding-da")
+			{Beat: 0, Drum: audio.DrumKick, Velocity: 0.6},
+			{Beat: 0, Drum: audio.DrumOpenHiHat, Velocity: 0.5},
+			{Beat: 1, Drum: audio.DrumOpenHiHat, Velocity: 0.6},
+			{Beat: 1, Drum: audio.DrumHiHat, Velocity: 0.7},
+			{Beat: 1 + 2.0/3, Drum: audio.DrumOpenHiHat, Velocity: 0.35},
+			{Beat: 2, Drum: audio.DrumKick, Velocity: 0.6},
+			{Beat: 2, Drum: audio.DrumOpenHiHat, Velocity: 0.5},
+			{Beat: 3, Drum: audio.DrumOpenHiHat, Velocity: 0.6},
+			{Beat: 3, Drum: audio.DrumHiHat, Velocity: 0.7},
+			{Beat: 3 + 2.0/3, Drum: audio.DrumOpenHiHat, Velocity: 0.35},
+		},
+	},
+}
+
+// Metronome is a plain click with an accented downbeat, used for count-ins
+// and recording rather than as a backing feel
+var Metronome = &Pattern{
+	Name: "Metronome",
+	Hits: []Hit{
+		{Beat: 0, Drum: audio.DrumClickAccent, Velocity: 1},
+		{Beat: 1, Drum: audio.DrumClick, Velocity: 0.8},
+		{Beat: 2, Drum: audio.DrumClick, Velocity: 0.8},
+		{Beat: 3, Drum: audio.DrumClick, Velocity: 0.8},
+	},
+}
+
+// PatternByName finds a built-in pattern (case-insensitive), or nil
+func PatternByName(name string) *Pattern {
+	for _, p := range Patterns {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+func eighths(drum audio.DrumKind, velocity float64) []Hit {
+	var hits []Hit
+	for i := 0; i < BeatsPerBar*2; i++ {
+		v := velocity
+		if i%2 == 1 {
+			v *= 0.7
+		}
+		hits = append(hits, Hit{Beat: float64(i) / 2, Drum: drum, Velocity: v})
+	}
+	return hits
+}
+
+func sixteenths(drum audio.DrumKind) []Hit {
+	var hits []Hit
+	for i := 0; i < BeatsPerBar*4; i++ {
+		v := 0.3
+		if i%2 == 0 {
+			v = 0.55
+		}
+		hits = append(hits, Hit{Beat: float64(i) / 4, Drum: drum, Velocity: v})
+	}
+	return hits
+}
+
+// merge combines hit lists into one list in beat order
+func merge(lists ...[]Hit) []Hit {
+	var all []Hit
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Beat < all[j].Beat
+	})
+	return all
+}
+
+// Drummer plays a pattern in time with a song's tempo map
+type Drummer struct {
+	player  Player
+	song    *song.Song
+	pattern *Pattern
+	sounds  *audio.SoundPack // Metronome sounds; synthesized if nil
+	speed   float64          // How fast the song clock runs; 1 is as charted
+
+	bar int // Bar of the next hit to schedule
+	hit int // Index of the next hit within the pattern
+}
+
+// NewDrummer creates a drummer that plays through player
+func NewDrummer(player Player) *Drummer {
+	return &Drummer{player: player, speed: 1}
+}
+
+// SetSounds makes metronome clicks use a sound pack
+func (d *Drummer) SetSounds(p *audio.SoundPack) {
+	d.sounds = p
+}
+
+// Reset prepares to play a pattern from the start of a song; a nil
+// pattern silences the drummer
+func (d *Drummer) Reset(s *song.Song, p *Pattern) {
+	d.song = s
+	d.pattern = p
+	d.speed = 1
+	d.bar = 0
+	d.hit = 0
+}
+
+// SetSpeed plays along with a song clock running slower or faster than
+// the chart's tempo, as when practicing at reduced speed
+func (d *Drummer) SetSpeed(speed float64) {
+	if speed > 0 {
+		d.speed = speed
+	}
+}
+
+// Update schedules every hit due before the song clock plus the lookahead
+func (d *Drummer) Update(songTime float64) {
+	if d.pattern == nil || d.song == nil || len(d.pattern.Hits) == 0 {
+		return
+	}
+	if songTime > d.song.Duration {
+		return
+	}
+
+	for {
+		h := d.pattern.Hits[d.hit]
+		hitTime := d.song.BeatToTime(float64(d.bar*BeatsPerBar) + h.Beat)
+		if hitTime > songTime+lookahead {
+			return
+		}
+
+		// Hits that are already late (e.g. after a stall) are skipped
+		// rather than played in a burst
+		if hitTime >= songTime-0.02 {
+			sr := d.player.SampleRate()
+			d.player.Play(audio.NewDelayed(d.voice(h, sr), (hitTime-songTime)/d.speed, sr))
+		}
+
+		d.hit++
+		if d.hit == len(d.pattern.Hits) {
+			d.hit = 0
+			d.bar++
+		}
+	}
+}
+
+// voice creates the sound for a hit, taking clicks from the sound pack
+func (d *Drummer) voice(h Hit, sampleRate float64) audio.Voice {
+	var v audio.Voice
+	switch h.Drum {
+	case audio.DrumClick:
+		v = d.sounds.Voice(audio.SoundMetronome, h.Velocity, sampleRate)
+	case audio.DrumClickAccent:
+		v = d.sounds.Voice(audio.SoundMetronomeAccent, h.Velocity, sampleRate)
+	}
+	if v == nil {
+		v = audio.NewDrum(h.Drum, h.Velocity, sampleRate)
+	}
+	return v
+}
@@ -0,0 +1,304 @@
+// Package config loads and saves the player's settings
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the settings file inside the config directory
+const FileName = "config.yaml"
+
+// Handedness settings
+const (
+	RightHanded       = "right"
+	LeftHanded        = "left"         // Low string on top, as seen when looking down at a left-handed bass
+	LeftHandedHighway = "left-highway" // Mirrored strings, and notes scroll left to right
+)
+
+// What is written on each note of the highway
+const (
+	NoteLabelsFret = "fret"      // Fret numbers, like tab
+	NoteLabelsName = "note-name" // Note names, for learning the fretboard
+	NoteLabelsBoth = "both"      // Fret numbers with note names underneath
+)
+
+// Views of the notes being played
+const (
+	HighwayTab       = "tab"       // Scrolling sideways along the strings
+	HighwayFretboard = "fretboard" // Falling towards a fretboard
+)
+
+// Timing window presets
+const (
+	TimingEasy   = "easy"
+	TimingNormal = "normal"
+	TimingHard   = "hard"
+	TimingCustom = "custom" // The windows in CustomTiming
+)
+
+// Config holds the settings that persist between runs
+type Config struct {
+	Version    int    `yaml:"version"` // Schema version; see migrate.go
+	Handedness string `yaml:"handedness,omitempty"`
+	Fretless   bool   `yaml:"fretless,omitempty"` // Score every song by intonation, as charts marked fretless are
+	NoteLabels string `yaml:"note_labels,omitempty"`
+	Highway    string `yaml:"highway,omitempty"`
+
+	// StrictOpenStrings refuses open strings played fretted and the
+	// reverse, unless a chart's note says otherwise
+	StrictOpenStrings bool `yaml:"strict_open_strings,omitempty"`
+
+	// WrongNotePenalty makes notes that match nothing break the combo
+	// and cost points
+	WrongNotePenalty bool `yaml:"wrong_note_penalty,omitempty"`
+
+	// RhythmOnly scores when notes are played and not their pitch, for
+	// muted strings or instruments the pitch detector struggles with
+	RhythmOnly bool `yaml:"rhythm_only,omitempty"`
+
+	// FailMode ends a song early when misses drain the health meter
+	FailMode bool `yaml:"fail_mode,omitempty"`
+
+	// Mode is the name of the game mode songs are played in; the
+	// standard rules if unset
+	Mode string `yaml:"mode,omitempty"`
+
+	// Timing is the preset for how close to a note a hit must be, and
+	// CustomTiming the windows used when it's TimingCustom
+	Timing       string        `yaml:"timing,omitempty"`
+	CustomTiming TimingWindows `yaml:"custom_timing,omitempty"`
+
+	// The speed trainer raises the tempo by TrainerStepBPM each time round
+	// the loop is played with at least TrainerTarget percent accuracy
+	TrainerStepBPM float64 `yaml:"trainer_step_bpm,omitempty"`
+	TrainerTarget  float64 `yaml:"trainer_target,omitempty"`
+
+	// The riff repeater loops a section slowly when more than
+	// RepeaterMissPercent of its notes are missed, until a time round is
+	// played with RepeaterRecoverPercent accuracy at full speed
+	RiffRepeater           bool    `yaml:"riff_repeater,omitempty"`
+	RepeaterMissPercent    float64 `yaml:"repeater_miss_percent,omitempty"`
+	RepeaterRecoverPercent float64 `yaml:"repeater_recover_percent,omitempty"`
+
+	// Telemetry opts in to sending anonymous usage counts to
+	// TelemetryEndpoint; nothing is sent without both
+	Telemetry         bool   `yaml:"telemetry,omitempty"`
+	TelemetryEndpoint string `yaml:"telemetry_endpoint,omitempty"`
+
+	// Device is the audio input (a name, part of one, or its number in
+	// the device list; empty for the system default), and SampleRate the
+	// rate audio runs at (0 for the default). Both are read at startup
+	// from the default profile.
+	Device     string  `yaml:"device,omitempty"`
+	SampleRate float64 `yaml:"sample_rate,omitempty"`
+
+	// SongsDir, when set, is the one directory charts are loaded from and
+	// new ones saved to, instead of the first songs directory found
+	SongsDir string `yaml:"songs_dir,omitempty"`
+
+	// LogLevel is the least serious level of message logged: "debug",
+	// "info" (the default), "warn" or "error". It's read at startup from
+	// the default profile.
+	LogLevel string `yaml:"log_level,omitempty"`
+
+	// StatsAddr, when set, is where the state of play is served for stream
+	// overlays and practice loggers, e.g. "127.0.0.1:8765". It's read at
+	// startup from the default profile.
+	StatsAddr string `yaml:"stats_addr,omitempty"`
+
+	// StatsOrigins are the web origins, e.g. "http://localhost:3000",
+	// whose pages may read the state of play; other web pages never can.
+	// Overlays loaded from files (such as OBS browser sources) send the
+	// origin "null", which must be listed for them to work.
+	StatsOrigins []string `yaml:"stats_origins,omitempty"`
+
+	// OSCAddr, when set, is the host and port OSC messages about play are
+	// sent to, e.g. "127.0.0.1:9000". It's read at startup from the
+	// default profile.
+	OSCAddr string `yaml:"osc_addr,omitempty"`
+
+	// Theme is the color scheme: a built-in one, or one of Themes, the
+	// player's own
+	Theme  string                 `yaml:"theme,omitempty"`
+	Themes map[string]CustomTheme `yaml:"themes,omitempty"`
+
+	// ScrollSpeed multiplies how fast notes scroll (0 for normal speed),
+	// which follows the song's tempo unless ConstantScroll is set
+	ScrollSpeed    float64 `yaml:"scroll_speed,omitempty"`
+	ConstantScroll bool    `yaml:"constant_scroll,omitempty"`
+
+	// CollapsedGroups are the menu's song groups whose songs are hidden
+	// under their headings: "favorites", "recent" or "all"
+	CollapsedGroups []string `yaml:"collapsed_groups,omitempty"`
+
+	// Fullscreen opens the window fullscreen, and DisplayScale enlarges
+	// everything on top of the screen's own scaling (0 for none), for
+	// playing from across the room
+	Fullscreen   bool    `yaml:"fullscreen,omitempty"`
+	DisplayScale float64 `yaml:"display_scale,omitempty"`
+
+	// Streaming lays the game out to be composited into a livestream: a
+	// flat ChromaKey background ("#rrggbb", green if empty) to key out,
+	// larger text and no debugging readouts. CaptureWindow also shows the
+	// highway alone in a window of its own, to capture while the game is
+	// played from the main one.
+	Streaming     bool   `yaml:"streaming,omitempty"`
+	ChromaKey     string `yaml:"chroma_key,omitempty"`
+	CaptureWindow bool   `yaml:"capture_window,omitempty"`
+
+	// LatencyMs is how long after a note is played the game hears it,
+	// measured or set by the player; hits are judged that much earlier
+	LatencyMs float64 `yaml:"latency_ms,omitempty"`
+
+	// Last is what was played last, to pick up from on the next run
+	Last Session `yaml:"last,omitempty"`
+
+	// Profile is the name of the profile the config belongs to
+	Profile string `yaml:"-"`
+
+	path string // File the config was loaded from and saves to
+}
+
+// TimingWindows are hit timing windows in milliseconds either side of a note
+type TimingWindows struct {
+	PerfectMs float64 `yaml:"perfect_ms"`
+	GoodMs    float64 `yaml:"good_ms"`
+	OKMs      float64 `yaml:"ok_ms"`
+}
+
+// CustomTheme is a color scheme of the player's own: a built-in theme
+// with some of its colors changed, each written "#rrggbb" or "#rrggbbaa"
+type CustomTheme struct {
+	Base   string            `yaml:"base,omitempty"` // Built-in theme to start from; dark if empty
+	Colors map[string]string `yaml:"colors,omitempty"`
+}
+
+// Session records the song last played and how it was practiced
+type Session struct {
+	Song  string  `yaml:"song,omitempty"`  // Chart file, or the title of a built-in song
+	Speed float64 `yaml:"speed,omitempty"` // Practice speed; 0 is full speed
+
+	// The passage last looped, as bar numbers counted from 1; 0 if none
+	LoopFirstBar int `yaml:"loop_first_bar,omitempty"`
+	LoopLastBar  int `yaml:"loop_last_bar,omitempty"`
+}
+
+// Dir returns the directory the config file lives in, ~/.config/guitargame
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "guitargame"), nil
+}
+
+// Load reads the default profile's config file, returning defaults if
+// there isn't one yet
+func Load() (*Config, error) {
+	return LoadProfile(DefaultProfile)
+}
+
+// LoadFile reads a config file, returning defaults if it doesn't exist
+func LoadFile(path string) (*Config, error) {
+	c := Default()
+	c.path = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	data, migrated, err := migrate(path, data)
+	if err != nil {
+		return c, err
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return c, err
+	}
+	if c.Version > Version {
+		return c, fmt.Errorf("%s: %w; changes won't be saved", path, ErrNewerVersion)
+	}
+	if migrated {
+		return c, c.Save()
+	}
+	return c, nil
+}
+
+// Default returns the settings used before the player changes anything
+func Default() *Config {
+	return &Config{
+		Version:                Version,
+		Handedness:             RightHanded,
+		Timing:                 TimingNormal,
+		CustomTiming:           TimingWindows{PerfectMs: 50, GoodMs: 100, OKMs: 150},
+		TrainerStepBPM:         5,
+		TrainerTarget:          90,
+		RepeaterMissPercent:    30,
+		RepeaterRecoverPercent: 85,
+	}
+}
+
+// Save writes the config back to the file it was loaded from
+func (c *Config) Save() error {
+	if c.Version > Version {
+		return ErrNewerVersion
+	}
+	if c.path == "" {
+		dir, err := ProfileDir(c.Profile)
+		if err != nil {
+			return err
+		}
+		c.path = filepath.Join(dir, FileName)
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o644)
+}
+
+// Dir returns the directory of the profile the config belongs to, where
+// its scores and history are kept too
+func (c *Config) Dir() (string, error) {
+	if c.path != "" {
+		return filepath.Dir(c.path), nil
+	}
+	return ProfileDir(c.Profile)
+}
+
+// NextTiming cycles the timing presets from easy to custom
+func (c *Config) NextTiming() {
+	switch c.Timing {
+	case TimingEasy:
+		c.Timing = TimingNormal
+	case TimingNormal:
+		c.Timing = TimingHard
+	case TimingHard:
+		c.Timing = TimingCustom
+	default:
+		c.Timing = TimingEasy
+	}
+}
+
+// NextHandedness cycles right-handed, left-handed, and left-handed with
+// a mirrored highway
+func (c *Config) NextHandedness() {
+	switch c.Handedness {
+	case LeftHanded:
+		c.Handedness = LeftHandedHighway
+	case LeftHandedHighway:
+		c.Handedness = RightHanded
+	default:
+		c.Handedness = LeftHanded
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the schema version of config files this build writes. Bump
+// it and add a migration whenever a setting is renamed, moved, or changes
+// meaning, so older files are upgraded instead of losing settings.
+const Version = 1
+
+// Migration upgrades a decoded config file by one schema version
+type Migration func(doc map[string]any) error
+
+// migrations[i] upgrades a file from version i to version i+1
+var migrations = []Migration{
+	// Files from before the config was versioned need no changes
+	func(doc map[string]any) error { return nil },
+}
+
+// ErrNewerVersion is returned when saving would overwrite a config file
+// written by a newer version of the game, which would lose its settings
+var ErrNewerVersion = errors.New("config file is from a newer version of the game")
+
+// migrate upgrades config file data to the current schema version. The
+// original file is first backed up next to path as <path>.v<N>.bak.
+// It reports whether anything was migrated.
+func migrate(path string, data []byte) ([]byte, bool, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return data, false, err
+	}
+	if doc == nil {
+		return data, false, nil // Empty, or only comments: nothing to upgrade
+	}
+	version, err := docVersion(doc)
+	if err != nil || version >= Version {
+		return data, false, err
+	}
+
+	if err := backup(fmt.Sprintf("%s.v%d.bak", path, version), data); err != nil {
+		return data, false, fmt.Errorf("backing up before migration: %w", err)
+	}
+	for v := version; v < Version; v++ {
+		if err := migrations[v](doc); err != nil {
+			return data, false, fmt.Errorf("migrating from version %d: %w", v, err)
+		}
+	}
+	doc["version"] = Version
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return data, false, err
+	}
+	return out, true, nil
+}
+
+// docVersion reads a decoded file's schema version; files from before
+// versioning have none and are version 0
+func docVersion(doc map[string]any) (int, error) {
+	v, ok := doc["version"]
+	if !ok {
+		return 0, nil
+	}
+	version, ok := v.(int)
+	if !ok || version < 0 {
+		return 0, fmt.Errorf("invalid config version %v", v)
+	}
+	return version, nil
+}
+
+// backup writes a copy of a file's original contents, keeping any backup
+// already there from an earlier migration
+func backup(path string, data []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Files with no settings in them load as the defaults, untouched
+func TestLoadFileWithoutSettings(t *testing.T) {
+	for name, contents := range map[string]string{
+		"empty":    "",
+		"comments": "# just a comment\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			c, err := LoadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Version != Default().Version {
+				t.Errorf("version %d, want the default %d", c.Version, Default().Version)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != contents {
+				t.Errorf("file rewritten to %q", data)
+			}
+		})
+	}
+}
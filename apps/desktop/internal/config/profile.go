@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// profilesDir holds a directory per named profile, each with its own
+// config file, scores and history. The default profile keeps them at the
+// top of the config directory, where they were before profiles.
+const profilesDir = "profiles"
+
+// maxProfileName is the longest a profile name may be
+const maxProfileName = 32
+
+// DefaultProfile is the profile used when none is chosen
+const DefaultProfile = ""
+
+// ProfileDir returns the directory a profile's files are kept in
+func ProfileDir(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil || name == DefaultProfile {
+		return dir, err
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profilesDir, name), nil
+}
+
+// Profiles returns the names of the profiles created, sorted
+func Profiles() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, profilesDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && ValidateProfileName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CreateProfile makes a new, empty profile
+func CreateProfile(name string) error {
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return err
+	}
+	if name == DefaultProfile {
+		return errors.New("a profile needs a name")
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	return os.MkdirAll(dir, 0o755)
+}
+
+// ValidateProfileName checks a name can be used as a profile directory
+func ValidateProfileName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("a profile needs a name")
+	case len(name) > maxProfileName:
+		return fmt.Errorf("profile names can be at most %d characters", maxProfileName)
+	case strings.TrimSpace(name) != name || strings.HasPrefix(name, "."):
+		return fmt.Errorf("profile name %q can't start or end with a space or start with a dot", name)
+	case strings.ContainsAny(name, `/\:*?"<>|`):
+		return fmt.Errorf(`profile name %q can't contain any of / \ : * ? " < > |`, name)
+	}
+	return nil
+}
+
+// LoadProfile reads a profile's config file, returning defaults if there
+// isn't one yet
+func LoadProfile(name string) (*Config, error) {
+	dir, err := ProfileDir(name)
+	if err != nil {
+		c := Default()
+		c.Profile = name
+		return c, err
+	}
+	c, err := LoadFile(filepath.Join(dir, FileName))
+	c.Profile = name
+	return c, err
+}
@@ -0,0 +1,269 @@
+package editor
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"guitargame/core/song"
+)
+
+// MaxFret is the highest fret the editor will place
+const MaxFret = 24
+
+// SnapDivisions are the available grid sizes, in beats
+var SnapDivisions = []float64{1, 0.5, 1.0 / 3, 0.25, 1.0 / 6}
+
+// beatEpsilon is how close two beats must be to count as the same grid position
+const beatEpsilon = 0.001
+
+// Editor holds the state of a chart being edited
+type Editor struct {
+	Song *song.Song
+	Path string // Where Save writes the chart
+
+	CursorBeat   float64
+	CursorString int
+	Fret         int // Fret used for newly placed notes
+	SnapIndex    int
+	Dirty        bool
+}
+
+// New creates an editor for a song. If the song has no file yet,
+// it will be saved to a new file in dir.
+func New(s *song.Song, dir string) *Editor {
+	path := s.Path
+	if path == "" {
+		path = newChartPath(dir, s.Title)
+	}
+	return &Editor{
+		Song:         s,
+		Path:         path,
+		CursorString: song.StringE,
+	}
+}
+
+// NewSong creates an empty chart with sensible defaults
+func NewSong() *song.Song {
+	s := &song.Song{
+		Title:  "Untitled",
+		Artist: "Unknown",
+		BPM:    90,
+		Tuning: song.TuningStandard,
+	}
+	s.CalculateDuration()
+	return s
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// newChartPath picks an unused file name in dir based on the title
+func newChartPath(dir, title string) string {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if slug == "" {
+		slug = "untitled"
+	}
+	path := filepath.Join(dir, slug+".yaml")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.yaml", slug, i))
+	}
+}
+
+// Snap returns the current grid size in beats
+func (e *Editor) Snap() float64 {
+	return SnapDivisions[e.SnapIndex]
+}
+
+// SnapLabel returns the grid size as a fraction of a beat
+func (e *Editor) SnapLabel() string {
+	snap := e.Snap()
+	if snap >= 1 {
+		return fmt.Sprintf("%.0f beat", snap)
+	}
+	return fmt.Sprintf("1/%.0f beat", 1/snap)
+}
+
+// CycleSnap switches to the next grid size
+func (e *Editor) CycleSnap(delta int) {
+	n := len(SnapDivisions)
+	e.SnapIndex = ((e.SnapIndex+delta)%n + n) % n
+	e.CursorBeat = e.SnapBeat(e.CursorBeat)
+}
+
+// SnapBeat rounds a beat to the nearest grid position
+func (e *Editor) SnapBeat(beat float64) float64 {
+	snap := e.Snap()
+	snapped := math.Round(beat/snap) * snap
+	if snapped < 0 {
+		return 0
+	}
+	return snapped
+}
+
+// CursorTime returns the cursor position in seconds
+func (e *Editor) CursorTime() float64 {
+	return e.Song.NoteTime(e.CursorBeat)
+}
+
+// StringCount returns the number of strings in the song's tuning
+func (e *Editor) StringCount() int {
+	return len(e.Song.GetTuning())
+}
+
+// MoveCursor moves the cursor by a number of grid steps and strings
+func (e *Editor) MoveCursor(steps, strDelta int) {
+	e.CursorBeat = e.SnapBeat(e.CursorBeat + float64(steps)*e.Snap())
+	e.CursorString = clamp(e.CursorString+strDelta, 0, e.StringCount()-1)
+}
+
+// SetCursor places the cursor at a beat and string, snapping to the grid
+func (e *Editor) SetCursor(beat float64, str int) {
+	e.CursorBeat = e.SnapBeat(beat)
+	e.CursorString = clamp(str, 0, e.StringCount()-1)
+}
+
+// SetFret sets the fret for new notes and updates the note under the cursor
+func (e *Editor) SetFret(fret int) {
+	e.Fret = clamp(fret, 0, MaxFret)
+	if note := e.NoteAtCursor(); note != nil && note.Fret != e.Fret {
+		note.Fret = e.Fret
+		e.Dirty = true
+	}
+}
+
+// NoteIndexAt returns the index of the note at a beat and string, or -1
+func (e *Editor) NoteIndexAt(beat float64, str int) int {
+	for i := range e.Song.Notes {
+		note := &e.Song.Notes[i]
+		if note.String == str && math.Abs(e.Song.NoteBeat(note.Time)-beat) < beatEpsilon {
+			return i
+		}
+	}
+	return -1
+}
+
+// NoteAtCursor returns the note under the cursor, if any
+func (e *Editor) NoteAtCursor() *song.TabNote {
+	if i := e.NoteIndexAt(e.CursorBeat, e.CursorString); i >= 0 {
+		return &e.Song.Notes[i]
+	}
+	return nil
+}
+
+// Place puts a note with the current fret at the cursor, replacing any
+// note already there, and returns it
+func (e *Editor) Place() *song.TabNote {
+	if note := e.NoteAtCursor(); note != nil {
+		note.Fret = e.Fret
+		e.Dirty = true
+		return note
+	}
+
+	e.Song.Notes = append(e.Song.Notes, song.TabNote{
+		Time:     e.CursorTime(),
+		String:   e.CursorString,
+		Fret:     e.Fret,
+		Duration: e.Song.BeatDuration() * 0.9,
+	})
+	e.changed()
+	return e.NoteAtCursor()
+}
+
+// Delete removes the note under the cursor
+func (e *Editor) Delete() bool {
+	i := e.NoteIndexAt(e.CursorBeat, e.CursorString)
+	if i < 0 {
+		return false
+	}
+	e.Song.Notes = append(e.Song.Notes[:i], e.Song.Notes[i+1:]...)
+	e.changed()
+	return true
+}
+
+// MoveNote moves the note under the cursor by grid steps and strings,
+// taking the cursor with it. It reports whether the note moved; moves
+// onto an occupied position are refused.
+func (e *Editor) MoveNote(steps, strDelta int) bool {
+	i := e.NoteIndexAt(e.CursorBeat, e.CursorString)
+	if i < 0 {
+		return false
+	}
+	beat := e.SnapBeat(e.CursorBeat + float64(steps)*e.Snap())
+	str := clamp(e.CursorString+strDelta, 0, e.StringCount()-1)
+	return e.moveNoteTo(i, beat, str)
+}
+
+// DragNote moves the note at the cursor to a new position (used by the mouse)
+func (e *Editor) DragNote(beat float64, str int) bool {
+	i := e.NoteIndexAt(e.CursorBeat, e.CursorString)
+	if i < 0 {
+		return false
+	}
+	return e.moveNoteTo(i, e.SnapBeat(beat), clamp(str, 0, e.StringCount()-1))
+}
+
+func (e *Editor) moveNoteTo(i int, beat float64, str int) bool {
+	if e.NoteIndexAt(beat, str) >= 0 {
+		return false
+	}
+	e.Song.Notes[i].Time = e.Song.NoteTime(beat)
+	e.Song.Notes[i].String = str
+	e.CursorBeat = beat
+	e.CursorString = str
+	e.changed()
+	return true
+}
+
+// changed re-sorts notes and refreshes derived song data after an edit
+func (e *Editor) changed() {
+	e.Song.SortNotes()
+	e.Song.CalculateDuration()
+	e.Dirty = true
+}
+
+// Save writes the chart back to YAML. Notes are stored as beats so the
+// file stays readable and tempo changes only need the BPM edited.
+func (e *Editor) Save() error {
+	chart := *e.Song
+	chart.Notes = make([]song.TabNote, len(e.Song.Notes))
+	defaultDuration := e.Song.BeatDuration() * 0.9
+	for i, note := range e.Song.Notes {
+		n := song.TabNote{
+			Beat:       song.Beat(e.Song.NoteBeat(note.Time)),
+			String:     note.String,
+			Fret:       note.Fret,
+			Duration:   note.Duration,
+			Substitute: note.Substitute,
+		}
+		if math.Abs(n.Duration-defaultDuration) < 0.001 {
+			n.Duration = 0
+		}
+		chart.Notes[i] = n
+	}
+
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0755); err != nil {
+		return err
+	}
+	if err := song.SaveSong(&chart, e.Path); err != nil {
+		return err
+	}
+	e.Song.Path = e.Path
+	e.Dirty = false
+	return nil
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
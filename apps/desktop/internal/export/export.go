@@ -0,0 +1,43 @@
+// Package export writes charts in formats other programs can read or
+// print
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"guitargame/core/song"
+)
+
+// Formats and the file extensions they're written with
+var Formats = map[string]string{
+	"tab":      ".txt",
+	"musicxml": ".musicxml",
+	"svg":      ".svg",
+}
+
+// Render writes a chart in one of the Formats
+func Render(s *song.Song, format string) ([]byte, error) {
+	switch format {
+	case "tab":
+		return []byte(ASCIITab(s)), nil
+	case "musicxml":
+		return MusicXML(s)
+	case "svg":
+		return []byte(SVG(s)), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// ToFile exports a chart next to path, replacing its extension with the
+// format's, and returns the file written
+func ToFile(s *song.Song, path, format string) (string, error) {
+	data, err := Render(s, format)
+	if err != nil {
+		return "", err
+	}
+	out := strings.TrimSuffix(path, filepath.Ext(path)) + Formats[format]
+	return out, os.WriteFile(out, data, 0o644)
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"math"
+	"sort"
+
+	"guitargame/core/song"
+)
+
+// BeatsPerBar is the time signature charts are exported in. Charts don't
+// record one, and the drum backing also assumes 4/4.
+const BeatsPerBar = 4
+
+// gridSteps are the beat subdivisions tried, simplest first, when
+// laying notes out on a grid
+var gridSteps = []int{1, 2, 4, 3, 6, 8, 12, 16, 24}
+
+// gridEpsilon is how far (in beats) a note may sit from a grid line
+const gridEpsilon = 0.01
+
+// event is a note placed on the export grid
+type event struct {
+	step   int // Grid position from the start of the song
+	length int // Grid steps the note sounds for, at least 1
+	note   *song.TabNote
+}
+
+// mark is a section name placed on the export grid
+type mark struct {
+	step int
+	name string
+}
+
+// grid is a chart's notes quantized to a whole number of steps per beat
+type grid struct {
+	stepsPerBeat int
+	events       []event // Sorted by position, then string
+	sections     []mark  // Sorted by position
+	bars         int
+}
+
+// quantize picks the coarsest grid that every note lands on and places
+// the notes on it
+func quantize(s *song.Song) *grid {
+	beats := make([]float64, len(s.Notes))
+	for i := range s.Notes {
+		beats[i] = s.NoteBeat(s.Notes[i].Time)
+	}
+
+	steps := gridSteps[len(gridSteps)-1]
+	for _, candidate := range gridSteps {
+		fits := true
+		for _, b := range beats {
+			pos := b * float64(candidate)
+			if math.Abs(pos-math.Round(pos)) > gridEpsilon*float64(candidate) {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			steps = candidate
+			break
+		}
+	}
+
+	g := &grid{stepsPerBeat: steps}
+	for i := range s.Notes {
+		n := &s.Notes[i]
+		start := int(math.Round(beats[i] * float64(steps)))
+		end := start + 1
+		if n.Duration > 0 {
+			// Chart durations are usually shortened to 90% for articulation
+			length := (s.NoteBeat(n.Time+n.Duration) - beats[i]) / 0.9
+			end = max(end, start+int(math.Round(length*float64(steps))))
+		}
+		g.events = append(g.events, event{step: start, length: end - start, note: n})
+	}
+	sort.SliceStable(g.events, func(i, j int) bool {
+		if g.events[i].step != g.events[j].step {
+			return g.events[i].step < g.events[j].step
+		}
+		return g.events[i].note.String < g.events[j].note.String
+	})
+
+	// Sections are usually on a beat but needn't be on the notes' grid
+	for _, sec := range s.Sections {
+		g.sections = append(g.sections, mark{step: int(math.Round(sec.Beat * float64(steps))), name: sec.Name})
+	}
+	sort.SliceStable(g.sections, func(i, j int) bool { return g.sections[i].step < g.sections[j].step })
+
+	stepsPerBar := steps * BeatsPerBar
+	g.bars = 1
+	if n := len(g.events); n > 0 {
+		g.bars = g.events[n-1].step/stepsPerBar + 1
+	}
+	return g
+}
+
+// sectionsIn returns the sections starting between two grid steps
+func (g *grid) sectionsIn(from, to int) []mark {
+	var marks []mark
+	for _, m := range g.sections {
+		if m.step >= from && m.step < to {
+			marks = append(marks, m)
+		}
+	}
+	return marks
+}
+
+// stepsPerBar returns the grid steps in one bar
+func (g *grid) stepsPerBar() int {
+	return g.stepsPerBeat * BeatsPerBar
+}
@@ -0,0 +1,246 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"guitargame/core/song"
+)
+
+const musicXMLDoctype = `<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 4.0 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">` + "\n"
+
+// MusicXML elements, in the order the schema requires
+type (
+	scorePartwise struct {
+		XMLName        xml.Name       `xml:"score-partwise"`
+		Version        string         `xml:"version,attr"`
+		Work           work           `xml:"work"`
+		Identification identification `xml:"identification"`
+		PartList       []scorePart    `xml:"part-list>score-part"`
+		Parts          []part         `xml:"part"`
+	}
+	work struct {
+		Title string `xml:"work-title"`
+	}
+	identification struct {
+		Creators []creator `xml:"creator,omitempty"`
+		Software string    `xml:"encoding>software"`
+	}
+	creator struct {
+		Type string `xml:"type,attr"`
+		Name string `xml:",chardata"`
+	}
+	scorePart struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"part-name"`
+	}
+	part struct {
+		ID       string    `xml:"id,attr"`
+		Measures []measure `xml:"measure"`
+	}
+	measure struct {
+		Number   int   `xml:"number,attr"`
+		Elements []any `xml:",any"` // attributes, direction, and note elements in order
+	}
+	attributes struct {
+		XMLName   xml.Name  `xml:"attributes"`
+		Divisions int       `xml:"divisions"`
+		Fifths    int       `xml:"key>fifths"`
+		Beats     int       `xml:"time>beats"`
+		BeatType  int       `xml:"time>beat-type"`
+		Clef      clefXML   `xml:"clef"`
+		Transpose transpose `xml:"transpose"`
+	}
+	clefXML struct {
+		Sign string `xml:"sign"`
+		Line int    `xml:"line"`
+	}
+	transpose struct {
+		Diatonic     int `xml:"diatonic"`
+		Chromatic    int `xml:"chromatic"`
+		OctaveChange int `xml:"octave-change"`
+	}
+	direction struct {
+		XMLName   xml.Name `xml:"direction"`
+		Placement string   `xml:"placement,attr"`
+		BeatUnit  string   `xml:"direction-type>metronome>beat-unit"`
+		PerMinute float64  `xml:"direction-type>metronome>per-minute"`
+		Sound     sound    `xml:"sound"`
+	}
+	sound struct {
+		Tempo float64 `xml:"tempo,attr"`
+	}
+	noteXML struct {
+		XMLName   xml.Name   `xml:"note"`
+		Chord     *struct{}  `xml:"chord"`
+		Pitch     *pitch     `xml:"pitch"`
+		Rest      *struct{}  `xml:"rest"`
+		Duration  int        `xml:"duration"`
+		Type      string     `xml:"type,omitempty"`
+		Dot       *struct{}  `xml:"dot"`
+		Technical *technical `xml:"notations>technical"`
+	}
+	pitch struct {
+		Step   string `xml:"step"`
+		Alter  int    `xml:"alter,omitempty"`
+		Octave int    `xml:"octave"`
+	}
+	technical struct {
+		String int `xml:"string"`
+		Fret   int `xml:"fret"`
+	}
+)
+
+// Sharp spellings of the pitch classes as MusicXML steps
+var (
+	pitchSteps = [12]string{"C", "C", "D", "D", "E", "F", "F", "G", "G", "A", "A", "B"}
+	pitchAlter = [12]int{0, 1, 0, 1, 0, 0, 1, 0, 1, 0, 1, 0}
+)
+
+// noteTypes maps lengths in 32nd notes to MusicXML note types; dotted
+// lengths are 1.5 times these
+var noteTypes = map[int]string{32: "whole", 16: "half", 8: "quarter", 4: "eighth", 2: "16th", 1: "32nd"}
+
+// MusicXML renders a chart as a MusicXML 4.0 score in 4/4 with the
+// string and fret of every note, so notation software can show both
+// standard notation and tab
+func MusicXML(s *song.Song) ([]byte, error) {
+	g := quantize(s)
+	inst := s.Instrument()
+
+	score := scorePartwise{
+		Version:        "4.0",
+		Work:           work{Title: s.Title},
+		Identification: identification{Software: "guitargame"},
+		PartList:       []scorePart{{ID: "P1", Name: strings.ToUpper(inst.Name[:1]) + inst.Name[1:]}},
+	}
+	if s.Artist != "" {
+		score.Identification.Creators = []creator{{Type: "composer", Name: s.Artist}}
+	}
+
+	// Guitar and bass are written an octave above where they sound
+	clef := clefXML{Sign: "F", Line: 4}
+	if inst == song.InstrumentGuitar {
+		clef = clefXML{Sign: "G", Line: 2}
+	}
+
+	perBar := g.stepsPerBar()
+	tempos := tempoSteps(s, g.stepsPerBeat)
+	p := part{ID: "P1"}
+	next := 0 // Next event to place
+	for bar := 0; bar < g.bars; bar++ {
+		m := measure{Number: bar + 1}
+		if bar == 0 {
+			m.Elements = append(m.Elements, attributes{
+				Divisions: g.stepsPerBeat,
+				Beats:     BeatsPerBar,
+				BeatType:  4,
+				Clef:      clef,
+				Transpose: transpose{OctaveChange: -1},
+			})
+		}
+
+		pos, end := bar*perBar, (bar+1)*perBar
+		for next < len(g.events) && g.events[next].step < end {
+			// Notes struck together become a chord
+			step := g.events[next].step
+			chord := next
+			length := 1
+			for chord < len(g.events) && g.events[chord].step == step {
+				length = max(length, g.events[chord].length)
+				chord++
+			}
+			nextStep := end
+			if chord < len(g.events) {
+				nextStep = min(nextStep, g.events[chord].step)
+			}
+			length = min(length, nextStep-step)
+
+			if step > pos {
+				m.Elements = append(m.Elements, restNote(step-pos, g.stepsPerBeat))
+			}
+			for len(tempos) > 0 && tempos[0].step <= step {
+				m.Elements = append(m.Elements, tempoDirection(tempos[0].bpm))
+				tempos = tempos[1:]
+			}
+			for i := next; i < chord; i++ {
+				m.Elements = append(m.Elements, pitchedNote(s, g.events[i].note, length, g.stepsPerBeat, i > next))
+			}
+			pos = step + length
+			next = chord
+		}
+		if pos < end {
+			m.Elements = append(m.Elements, restNote(end-pos, g.stepsPerBeat))
+		}
+		p.Measures = append(p.Measures, m)
+	}
+	score.Parts = []part{p}
+
+	out, err := xml.MarshalIndent(score, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header+musicXMLDoctype), append(out, '\n')...), nil
+}
+
+type tempoAt struct {
+	step int
+	bpm  float64
+}
+
+// tempoSteps places the starting tempo and tempo changes on the grid
+func tempoSteps(s *song.Song, stepsPerBeat int) []tempoAt {
+	tempos := []tempoAt{{step: 0, bpm: s.BPMAt(0)}}
+	for _, tc := range s.Tempo {
+		if tc.BPM > 0 && tc.Beat > 0 {
+			tempos = append(tempos, tempoAt{step: int(tc.Beat * float64(stepsPerBeat)), bpm: tc.BPM})
+		}
+	}
+	return tempos
+}
+
+func tempoDirection(bpm float64) direction {
+	return direction{Placement: "above", BeatUnit: "quarter", PerMinute: bpm, Sound: sound{Tempo: bpm}}
+}
+
+func restNote(length, stepsPerBeat int) noteXML {
+	n := noteXML{Rest: &struct{}{}, Duration: length}
+	n.Type, n.Dot = noteType(length, stepsPerBeat)
+	return n
+}
+
+func pitchedNote(s *song.Song, tn *song.TabNote, length, stepsPerBeat int, chord bool) noteXML {
+	written := s.MIDINoteAt(tn) + 12
+	n := noteXML{
+		Pitch: &pitch{
+			Step:   pitchSteps[written%12],
+			Alter:  pitchAlter[written%12],
+			Octave: written/12 - 1,
+		},
+		Duration:  length,
+		Technical: &technical{String: tn.String + 1, Fret: tn.Fret},
+	}
+	if chord {
+		n.Chord = &struct{}{}
+	}
+	n.Type, n.Dot = noteType(length, stepsPerBeat)
+	return n
+}
+
+// noteType names a length in grid steps as a plain or dotted note value,
+// leaving it blank for lengths that aren't one (e.g. triplets)
+func noteType(length, stepsPerBeat int) (string, *struct{}) {
+	if length*8%stepsPerBeat != 0 {
+		return "", nil
+	}
+	thirtySeconds := length * 8 / stepsPerBeat
+	if t, ok := noteTypes[thirtySeconds]; ok {
+		return t, nil
+	}
+	if thirtySeconds%3 == 0 {
+		if t, ok := noteTypes[thirtySeconds*2/3]; ok {
+			return t, &struct{}{}
+		}
+	}
+	return "", nil
+}
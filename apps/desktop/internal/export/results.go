@@ -0,0 +1,87 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"guitargame/core/game"
+)
+
+// resultsHeader names the CSV columns of ResultsCSV
+var resultsHeader = []string{"time", "string", "fret", "expected", "detected", "offset_ms", "cents", "quality"}
+
+// ResultsCSV writes a run's per-note results as CSV, one row per note
+func ResultsCSV(w io.Writer, results []game.NoteResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(resultsHeader); err != nil {
+		return err
+	}
+	num := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	for _, r := range results {
+		row := []string{
+			num(r.Time),
+			strconv.Itoa(r.String),
+			strconv.Itoa(r.Fret),
+			r.Expected,
+			r.Detected,
+			num(r.OffsetMs),
+			num(r.Cents),
+			r.Quality,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ResultsJSON writes a run's per-note results as a JSON array
+func ResultsJSON(w io.Writer, results []game.NoteResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ResultsToFiles writes the results of a run of a song, played at a time,
+// as both CSV and JSON in dir, and returns the files written
+func ResultsToFiles(results []game.NoteResult, dir, title string, at time.Time) ([]string, error) {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if slug == "" {
+		slug = "untitled"
+	}
+	base := slug + "-" + at.Format("2006-01-02-150405")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	formats := []struct {
+		ext   string
+		write func(io.Writer, []game.NoteResult) error
+	}{{".csv", ResultsCSV}, {".json", ResultsJSON}}
+	var written []string
+	for _, format := range formats {
+		path := filepath.Join(dir, base+format.ext)
+		f, err := os.Create(path)
+		if err != nil {
+			return written, err
+		}
+		err = format.write(f, results)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	"guitargame/core/song"
+)
+
+// Page layout for SVG tab, in SVG user units (about a point each)
+const (
+	svgWidth       = 800
+	svgMargin      = 40
+	svgStringGap   = 14 // Distance between string lines
+	svgSystemGap   = 36 // Space between lines of tab, for bar numbers and section names
+	svgHeaderLines = 3
+	svgLineHeight  = 22
+	svgFont        = "Helvetica, Arial, sans-serif"
+)
+
+// SVG renders a chart as printable tablature, four bars to a line with
+// bar numbers and section names, laid out on a page-width drawing
+func SVG(s *song.Song) string {
+	g := quantize(s)
+	tuning := s.GetTuning()
+	perBar := g.stepsPerBar()
+
+	nameWidth := 20.0
+	left := svgMargin + nameWidth
+	barWidth := float64(svgWidth-svgMargin) - left
+	barWidth /= barsPerLine
+	stepWidth := barWidth / float64(perBar)
+	systemHeight := float64(len(tuning)-1)*svgStringGap + svgSystemGap
+	systems := (g.bars + barsPerLine - 1) / barsPerLine
+	top := float64(svgMargin + svgHeaderLines*svgLineHeight)
+	height := top + float64(systems)*systemHeight + svgMargin
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%s" viewBox="0 0 %d %s" font-family="%s">`+"\n",
+		svgWidth, num(height), svgWidth, num(height), svgFont)
+	b.WriteString(`<rect width="100%" height="100%" fill="white"/>` + "\n")
+
+	// Header: title, artist, and the chart's settings
+	y := float64(svgMargin)
+	svgText(&b, svgMargin, y, 20, "bold", s.Title)
+	if s.Artist != "" {
+		y += svgLineHeight
+		svgText(&b, svgMargin, y, 13, "", s.Artist)
+	}
+	info := fmt.Sprintf("Tuning: %s   BPM: %g", tuning.Name(), s.BPM)
+	if s.Capo > 0 {
+		info += fmt.Sprintf("   Capo: %d", s.Capo)
+	}
+	if s.Swing > 0 {
+		info += fmt.Sprintf("   Swing: %g%%", s.Swing)
+	}
+	y += svgLineHeight
+	svgText(&b, svgMargin, y, 11, "", info)
+
+	// Frets on each string at each grid step
+	frets := make([]map[int]string, len(tuning))
+	for i := range frets {
+		frets[i] = make(map[int]string)
+	}
+	for _, ev := range g.events {
+		if ev.note.String >= 0 && ev.note.String < len(tuning) {
+			frets[ev.note.String][ev.step] = strconv.Itoa(ev.note.Fret)
+		}
+	}
+
+	for sys := 0; sys < systems; sys++ {
+		first := sys * barsPerLine
+		last := min(first+barsPerLine, g.bars)
+		stringsTop := top + float64(sys)*systemHeight + svgSystemGap - 8
+		stringsBottom := stringsTop + float64(len(tuning)-1)*svgStringGap
+		right := left + float64(last-first)*barWidth
+
+		// Bar numbers and section names above the staff
+		for bar := first; bar < last; bar++ {
+			svgText(&b, left+float64(bar-first)*barWidth+2, stringsTop-8, 9, "", strconv.Itoa(bar+1))
+		}
+		for _, m := range g.sectionsIn(first*perBar, last*perBar) {
+			x := left + float64(m.step-first*perBar)*stepWidth + 2
+			svgText(&b, x, stringsTop-20, 11, "bold", m.name)
+		}
+
+		for str, st := range tuning {
+			sy := stringsTop + float64(str)*svgStringGap
+			svgText(&b, svgMargin, sy+4, 11, "", st.Note)
+			fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#888" stroke-width="0.7"/>`+"\n",
+				num(left), num(sy), num(right), num(sy))
+		}
+		for bar := first; bar <= last; bar++ {
+			x := left + float64(bar-first)*barWidth
+			fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="black" stroke-width="1"/>`+"\n",
+				num(x), num(stringsTop), num(x), num(stringsBottom))
+		}
+
+		// Frets are drawn over a white box so the string line doesn't
+		// strike through them
+		for str := range tuning {
+			sy := stringsTop + float64(str)*svgStringGap
+			for step := first * perBar; step < last*perBar; step++ {
+				f, ok := frets[str][step]
+				if !ok {
+					continue
+				}
+				x := left + float64(step-first*perBar)*stepWidth + stepWidth/2
+				w := 7 * float64(len(f))
+				fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="12" fill="white"/>`+"\n",
+					num(x-w/2), num(sy-6), num(w))
+				fmt.Fprintf(&b, `<text x="%s" y="%s" font-size="11" text-anchor="middle">%s</text>`+"\n",
+					num(x), num(sy+4), f)
+			}
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// svgText writes a left-aligned text element
+func svgText(b *strings.Builder, x, y, size float64, weight, text string) {
+	fmt.Fprintf(b, `<text x="%s" y="%s" font-size="%s"`, num(x), num(y), num(size))
+	if weight != "" {
+		fmt.Fprintf(b, ` font-weight="%s"`, weight)
+	}
+	fmt.Fprintf(b, ">%s</text>\n", html.EscapeString(text))
+}
+
+// num formats a coordinate without needless decimals
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
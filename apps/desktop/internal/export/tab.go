@@ -0,0 +1,98 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"guitargame/core/song"
+)
+
+// barsPerLine is how many bars each line of ASCII tab holds
+const barsPerLine = 4
+
+// ASCIITab renders a chart as plain-text tablature, four bars to a line
+// with the bar numbers and section names above
+func ASCIITab(s *song.Song) string {
+	g := quantize(s)
+	tuning := s.GetTuning()
+
+	var b strings.Builder
+	b.WriteString(s.Title + "\n")
+	if s.Artist != "" {
+		b.WriteString(s.Artist + "\n")
+	}
+	fmt.Fprintf(&b, "Tuning: %s  BPM: %g", tuning.Name(), s.BPM)
+	if s.Capo > 0 {
+		fmt.Fprintf(&b, "  Capo: %d", s.Capo)
+	}
+	if s.Swing > 0 {
+		fmt.Fprintf(&b, "  Swing: %g%%", s.Swing)
+	}
+	columns := "columns"
+	if g.stepsPerBeat == 1 {
+		columns = "column"
+	}
+	fmt.Fprintf(&b, "  (%d %s per beat)\n\n", g.stepsPerBeat, columns)
+
+	// Frets on each string at each grid step
+	frets := make([]map[int]string, len(tuning))
+	digits := 1
+	for i := range frets {
+		frets[i] = make(map[int]string)
+	}
+	for _, ev := range g.events {
+		if ev.note.String < 0 || ev.note.String >= len(tuning) {
+			continue
+		}
+		f := strconv.Itoa(ev.note.Fret)
+		frets[ev.note.String][ev.step] = f
+		digits = max(digits, len(f))
+	}
+	cellWidth := digits + 1
+
+	nameWidth := 1
+	for _, st := range tuning {
+		nameWidth = max(nameWidth, len(st.Note))
+	}
+
+	perBar := g.stepsPerBar()
+	for first := 0; first < g.bars; first += barsPerLine {
+		last := min(first+barsPerLine, g.bars)
+
+		// Section names sit over the step they start on
+		if marks := g.sectionsIn(first*perBar, last*perBar); len(marks) > 0 {
+			line := []byte(strings.Repeat(" ", nameWidth+(last-first)*(perBar*cellWidth+1)))
+			for _, m := range marks {
+				bar := m.step/perBar - first
+				col := nameWidth + bar*(perBar*cellWidth+1) + 1 + m.step%perBar*cellWidth
+				for len(line) < col+len(m.name)+1 {
+					line = append(line, ' ')
+				}
+				copy(line[col:], m.name+" ")
+			}
+			b.WriteString(strings.TrimRight(string(line), " ") + "\n")
+		}
+
+		// Bar numbers line up with the bar lines below them
+		numbers := strings.Repeat(" ", nameWidth)
+		for bar := first; bar < last; bar++ {
+			numbers += fmt.Sprintf("%-*d", perBar*cellWidth+1, bar+1)
+		}
+		b.WriteString(strings.TrimRight(numbers, " ") + "\n")
+
+		for str, st := range tuning {
+			fmt.Fprintf(&b, "%-*s", nameWidth, st.Note)
+			for bar := first; bar < last; bar++ {
+				b.WriteByte('|')
+				for step := bar * perBar; step < (bar+1)*perBar; step++ {
+					cell := frets[str][step]
+					b.WriteString(cell + strings.Repeat("-", cellWidth-len(cell)))
+				}
+			}
+			b.WriteString("|\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
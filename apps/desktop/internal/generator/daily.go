@@ -0,0 +1,32 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"guitargame/core/song"
+)
+
+// dailyBars is the length of a daily challenge
+const dailyBars = 8
+
+// Daily generates the challenge for a date. Everyone gets the same one on
+// the same day, so its scores can be compared between days and players.
+func Daily(date time.Time) *song.Song {
+	y, m, d := date.Date()
+	rng := rand.New(rand.NewSource(int64(y*10000 + int(m)*100 + d)))
+	t := Templates[rng.Intn(len(Templates))]
+	r := New(Options{
+		Template: t,
+		Key:      Keys[rng.Intn(len(Keys))],
+		Density:  0.3 + 0.4*rng.Float64(),
+		BPM:      t.BPM,
+		Seed:     rng.Int63(),
+	})
+	r.Extend(dailyBars)
+	s := r.Song()
+	s.Title = fmt.Sprintf("Daily Challenge %s", date.Format("2006-01-02"))
+	s.Artist = fmt.Sprintf("%s in %s", t.Name, r.Key)
+	return s
+}
@@ -0,0 +1,256 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"guitargame/core/song"
+)
+
+// BeatsPerBar is the bar length of generated riffs
+const BeatsPerBar = 4
+
+// Fretboard range generated notes are kept within
+const (
+	maxFret   = 12
+	rangeSpan = 19 // Semitones above the key's lowest root
+)
+
+// Keys are the root notes offered, in the order they are cycled through
+var Keys = []string{"E", "F", "F#", "G", "G#", "A", "A#", "B", "C", "C#", "D", "D#"}
+
+// Template describes the style of a genre
+type Template struct {
+	Name        string
+	Scale       []int     // Scale degrees allowed for passing notes (semitones over the key)
+	Progression []int     // Chord root for each bar (semitones over the key), repeated
+	ChordTones  []int     // Preferred intervals over the chord root on strong beats
+	Steps       int       // Grid steps per beat
+	Anchors     []float64 // Beats in each bar that always play the chord root
+	BPM         float64   // Suggested tempo
+}
+
+// Templates are the built-in genres
+var Templates = []*Template{
+	{
+		Name:        "Rock",
+		Scale:       []int{0, 3, 5, 7, 10},
+		Progression: []int{0, 0, 5, 7},
+		ChordTones:  []int{0, 0, 7, 12},
+		Steps:       2,
+		Anchors:     []float64{0, 2},
+		BPM:         110,
+	},
+	{
+		Name:        "Funk",
+		Scale:       []int{0, 2, 3, 5, 7, 9, 10},
+		Progression: []int{0, 0, 5, 0},
+		ChordTones:  []int{0, 0, 7, 10, 12},
+		Steps:       4,
+		Anchors:     []float64{0},
+		BPM:         96,
+	},
+	{
+		Name:        "Blues",
+		Scale:       []int{0, 3, 4, 5, 7, 9, 10},
+		Progression: []int{0, 5, 0, 0, 5, 5, 0, 0, 7, 5, 0, 7},
+		ChordTones:  []int{0, 4, 7, 9, 10},
+		Steps:       1,
+		Anchors:     []float64{0},
+		BPM:         100,
+	},
+	{
+		Name:        "Pop",
+		Scale:       []int{0, 2, 4, 5, 7, 9, 11},
+		Progression: []int{0, 7, 9, 5},
+		ChordTones:  []int{0, 0, 7, 12},
+		Steps:       2,
+		Anchors:     []float64{0, 2},
+		BPM:         100,
+	},
+}
+
+// Options configure a generated riff
+type Options struct {
+	Template *Template
+	Key      string  // Root note, one of Keys
+	Density  float64 // 0..1, how many off-anchor grid steps get a note
+	BPM      float64
+	Seed     int64
+}
+
+// Riff generates an endless bassline one bar at a time
+type Riff struct {
+	Options
+
+	rng      *rand.Rand
+	song     *song.Song
+	keyRoot  int // MIDI note of the lowest playable root
+	bar      int // Next bar to generate
+	lastNote int
+	lastFret int
+}
+
+// New creates a riff generator and its (initially empty) song
+func New(opts Options) *Riff {
+	if opts.Template == nil {
+		opts.Template = Templates[0]
+	}
+	if opts.BPM <= 0 {
+		opts.BPM = opts.Template.BPM
+	}
+	opts.Density = math.Max(0, math.Min(1, opts.Density))
+
+	// Lowest root at or above the open E string
+	keyRoot := song.TuningStandard[len(song.TuningStandard)-1].MIDINote()
+	for _, k := range Keys {
+		if k == opts.Key {
+			break
+		}
+		keyRoot++
+	}
+
+	s := &song.Song{
+		Title:  fmt.Sprintf("%s Riff in %s", opts.Template.Name, opts.Key),
+		Artist: "Generated",
+		BPM:    opts.BPM,
+		Tuning: song.TuningStandard,
+	}
+	s.CalculateDuration()
+
+	return &Riff{
+		Options:  opts,
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		song:     s,
+		keyRoot:  keyRoot,
+		lastNote: keyRoot,
+		lastFret: 3,
+	}
+}
+
+// Song returns the chart being generated
+func (r *Riff) Song() *song.Song {
+	return r.song
+}
+
+// Extend appends bars to the song and returns how many notes were added
+func (r *Riff) Extend(bars int) int {
+	before := len(r.song.Notes)
+	for i := 0; i < bars; i++ {
+		r.generateBar()
+	}
+	r.song.CalculateDuration()
+	return len(r.song.Notes) - before
+}
+
+func (r *Riff) generateBar() {
+	t := r.Template
+	prog := t.Progression
+	chordRoot := r.keyRoot + prog[r.bar%len(prog)]
+	nextRoot := r.keyRoot + prog[(r.bar+1)%len(prog)]
+	barStart := float64(r.bar * BeatsPerBar)
+	stepBeats := 1.0 / float64(t.Steps)
+	totalSteps := BeatsPerBar * t.Steps
+
+	var beats []float64
+	var midiNotes []int
+	for step := 0; step < totalSteps; step++ {
+		beat := float64(step) * stepBeats
+		anchor := containsBeat(t.Anchors, beat)
+		if !anchor && r.rng.Float64() >= r.Density {
+			continue
+		}
+
+		var note int
+		switch {
+		case anchor:
+			note = chordRoot
+		case step == totalSteps-1:
+			// Lead into the next bar's root from a step away, from above
+			// if below would leave the range
+			step := 1 + r.rng.Intn(2)
+			if r.rng.Intn(2) == 0 && nextRoot-step >= r.keyRoot {
+				step = -step
+			}
+			note = nextRoot + step
+		case step%t.Steps == 0:
+			note = chordRoot + t.ChordTones[r.rng.Intn(len(t.ChordTones))]
+		default:
+			note = r.passingNote()
+		}
+		note = r.fold(note)
+
+		beats = append(beats, barStart+beat)
+		midiNotes = append(midiNotes, note)
+		r.lastNote = note
+	}
+
+	for i, beat := range beats {
+		// Each note rings until the next one, up to a beat
+		length := 1.0
+		if i+1 < len(beats) {
+			length = math.Min(length, beats[i+1]-beat)
+		}
+		pos, ok := r.song.GetTuning().NearestPosition(midiNotes[i], maxFret, r.lastFret)
+		if !ok {
+			continue
+		}
+		if pos.Fret > 0 {
+			r.lastFret = pos.Fret
+		}
+		r.song.Notes = append(r.song.Notes, song.TabNote{
+			Time:     r.song.BeatToTime(beat),
+			Beat:     song.Beat(beat),
+			String:   pos.String,
+			Fret:     pos.Fret,
+			Duration: r.song.BeatToTime(length) * 0.9,
+		})
+	}
+	r.bar++
+}
+
+// passingNote picks a scale tone a few semitones from the previous note
+func (r *Riff) passingNote() int {
+	var candidates []int
+	for d := -4; d <= 4; d++ {
+		n := r.lastNote + d
+		if d != 0 && r.inScale(n) && n >= r.keyRoot && n <= r.keyRoot+rangeSpan {
+			candidates = append(candidates, n)
+		}
+	}
+	if len(candidates) == 0 {
+		return r.lastNote
+	}
+	return candidates[r.rng.Intn(len(candidates))]
+}
+
+func (r *Riff) inScale(note int) bool {
+	degree := ((note-r.keyRoot)%12 + 12) % 12
+	for _, d := range r.Template.Scale {
+		if d == degree {
+			return true
+		}
+	}
+	return false
+}
+
+// fold moves a note by octaves until it is within the playable range
+func (r *Riff) fold(note int) int {
+	for note < r.keyRoot {
+		note += 12
+	}
+	for note > r.keyRoot+rangeSpan {
+		note -= 12
+	}
+	return note
+}
+
+func containsBeat(beats []float64, beat float64) bool {
+	for _, b := range beats {
+		if math.Abs(b-beat) < 0.001 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+
+	"guitargame/core/song"
+)
+
+// VaryMode selects how a chart is varied on each pass through it
+type VaryMode int
+
+const (
+	VaryOff       VaryMode = iota
+	VaryOctaves            // Move some notes up or down an octave
+	VaryRhythm             // Push or delay some notes by an eighth
+	VaryTranspose          // Play the whole line in another key
+	VaryMixed              // A different one of the above each pass
+)
+
+func (m VaryMode) String() string {
+	switch m {
+	case VaryOctaves:
+		return "Octave jumps"
+	case VaryRhythm:
+		return "Rhythmic displacement"
+	case VaryTranspose:
+		return "New key each pass"
+	case VaryMixed:
+		return "Mixed"
+	default:
+		return "Off"
+	}
+}
+
+// Next returns the following mode, wrapping around
+func (m VaryMode) Next() VaryMode {
+	return (m + 1) % (VaryMixed + 1)
+}
+
+// How much of a chart a variation touches
+const (
+	varyShare        = 0.3 // Share of notes moved by octave and rhythm variations
+	maxTranspose     = 5   // Semitones either way
+	displacement     = 0.5 // Beats a displaced note moves
+	variationMaxFret = 15  // Highest fret variations place notes on
+)
+
+// Variator rewrites a copy of a chart with a fresh variation on each
+// pass, so a loop can't be played from memory
+type Variator struct {
+	Mode VaryMode
+
+	rng      *rand.Rand
+	song     *song.Song
+	original []song.TabNote
+	beats    []float64 // Beat of each original note
+}
+
+// NewVariator varies a copy of s, leaving s itself untouched
+func NewVariator(s *song.Song, mode VaryMode, seed int64) *Variator {
+	cp := *s
+	cp.Notes = append([]song.TabNote(nil), s.Notes...)
+	v := &Variator{
+		Mode:     mode,
+		rng:      rand.New(rand.NewSource(seed)),
+		song:     &cp,
+		original: append([]song.TabNote(nil), s.Notes...),
+	}
+	for i := range s.Notes {
+		v.beats = append(v.beats, s.NoteBeat(s.Notes[i].Time))
+	}
+	return v
+}
+
+// Song returns the chart being varied
+func (v *Variator) Song() *song.Song {
+	return v.song
+}
+
+// Vary replaces the chart's notes with a new variation of the original
+// and describes what changed. The number and order of notes stay the same.
+func (v *Variator) Vary() string {
+	mode := v.Mode
+	if mode == VaryMixed {
+		mode = VaryOctaves + VaryMode(v.rng.Intn(int(VaryTranspose)))
+	}
+
+	s := v.song
+	tuning := s.GetTuning()
+	beats := append([]float64(nil), v.beats...)
+	copy(s.Notes, v.original)
+
+	var desc string
+	switch mode {
+	case VaryOctaves:
+		moved := 0
+		for i := range s.Notes {
+			if v.rng.Float64() >= varyShare {
+				continue
+			}
+			shift := 12
+			if v.rng.Intn(2) == 0 {
+				shift = -12
+			}
+			if v.refret(&s.Notes[i], tuning, shift) || v.refret(&s.Notes[i], tuning, -shift) {
+				moved++
+			}
+		}
+		desc = fmt.Sprintf("Octave jumps: %d of %d notes", moved, len(s.Notes))
+	case VaryRhythm:
+		moved := 0
+		for i := range beats {
+			if v.rng.Float64() >= varyShare {
+				continue
+			}
+			shift := displacement
+			if v.rng.Intn(2) == 0 {
+				shift = -displacement
+			}
+			// Only move into empty space, so notes keep their order,
+			to := beats[i] + shift
+			// and the last note stays inside the loop
+			if to < 0 || (i > 0 && to <= beats[i-1]) || (i+1 < len(beats) && to >= beats[i+1]) || (i+1 == len(beats) && shift > 0) {
+				continue
+			}
+			beats[i] = to
+			moved++
+		}
+		desc = fmt.Sprintf("Pushed or delayed an eighth: %d of %d notes", moved, len(s.Notes))
+	case VaryTranspose:
+		shift := 1 + v.rng.Intn(maxTranspose)
+		if v.rng.Intn(2) == 0 {
+			shift = -shift
+		}
+		for i := range s.Notes {
+			// Fold notes that fall off the neck back by an octave
+			_ = v.refret(&s.Notes[i], tuning, shift) || v.refret(&s.Notes[i], tuning, shift-12) || v.refret(&s.Notes[i], tuning, shift+12)
+		}
+		desc = fmt.Sprintf("Transposed %+d semitones", shift)
+	}
+
+	for i := range s.Notes {
+		s.Notes[i].Beat = song.Beat(beats[i])
+		s.Notes[i].Time = s.NoteTime(beats[i])
+	}
+	return desc
+}
+
+// refret moves a note by semitones to the nearest place it can be
+// played, reporting false (and leaving it alone) if there is none
+func (v *Variator) refret(note *song.TabNote, tuning song.Tuning, semitones int) bool {
+	pos, ok := tuning.NearestPosition(note.MIDINoteWithTuning(tuning)+semitones, variationMaxFret, note.Fret)
+	if !ok {
+		return false
+	}
+	note.String, note.Fret = pos.String, pos.Fret
+	return true
+}
@@ -0,0 +1,51 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// ModeDaily is the mode daily challenge sessions are recorded under
+const ModeDaily = "daily"
+
+// DailyResult is the best completed run of one day's challenge
+type DailyResult struct {
+	Day      time.Time
+	Score    int
+	Accuracy float64
+	Attempts int
+}
+
+// Dailies returns the best completed run of each day's challenge, newest
+// first, and how many days in a row up to now it has been completed
+func Dailies(sessions []Session, now time.Time) ([]DailyResult, int) {
+	best := make(map[time.Time]*DailyResult)
+	completed := make(map[time.Time]bool)
+	for _, s := range sessions {
+		if s.Mode != ModeDaily {
+			continue
+		}
+		d := day(s.Date)
+		r := best[d]
+		if r == nil {
+			r = &DailyResult{Day: d}
+			best[d] = r
+		}
+		r.Attempts++
+		if !s.Completed || s.Speed < 1 || s.RhythmOnly {
+			continue
+		}
+		completed[d] = true
+		if s.Score > r.Score {
+			r.Score = s.Score
+			r.Accuracy = s.Accuracy
+		}
+	}
+
+	results := make([]DailyResult, 0, len(best))
+	for _, r := range best {
+		results = append(results, *r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Day.After(results[j].Day) })
+	return results, streak(completed, day(now))
+}
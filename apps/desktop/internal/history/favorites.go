@@ -0,0 +1,57 @@
+package history
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// favoritesBucket holds the keys of starred songs
+var favoritesBucket = []byte("favorites")
+
+// Favorites returns the keys of the songs starred as favorites
+func (d *DB) Favorites() (map[string]bool, error) {
+	favorites := make(map[string]bool)
+	err := d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(favoritesBucket).ForEach(func(k, _ []byte) error {
+			favorites[string(k)] = true
+			return nil
+		})
+	})
+	return favorites, err
+}
+
+// SetFavorite stars or unstars a song by its key
+func (d *DB) SetFavorite(key string, favorite bool) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(favoritesBucket)
+		if !favorite {
+			return b.Delete([]byte(key))
+		}
+		return b.Put([]byte(key), []byte{1})
+	})
+}
+
+// Recent returns the keys of up to n of the songs played most recently,
+// latest first and each only once. Generated riffs and daily challenges
+// aren't included, as they can't be picked to play again.
+func (d *DB) Recent(n int) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	err := d.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(sessionsBucket).Cursor()
+		for k, v := c.Last(); k != nil && len(keys) < n; k, v = c.Prev() {
+			var s Session
+			if err := json.Unmarshal(v, &s); err != nil {
+				return err
+			}
+			if s.Mode == ModeRiff || s.Mode == ModeDaily || seen[s.Key] {
+				continue
+			}
+			seen[s.Key] = true
+			keys = append(keys, s.Key)
+		}
+		return nil
+	})
+	return keys, err
+}
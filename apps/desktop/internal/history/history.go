@@ -0,0 +1,126 @@
+// Package history records every practice session in a local database,
+// so progress can be charted and looked back on
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FileName is the history database kept in the config directory
+const FileName = "history.db"
+
+// openTimeout bounds the wait for another running copy of the game to let
+// go of the database
+const openTimeout = time.Second
+
+var sessionsBucket = []byte("sessions")
+
+// ModeRiff is the mode endless riff sessions are recorded under
+const ModeRiff = "riff"
+
+// Session is one play of a song, finished or not
+type Session struct {
+	ID          uint64    `json:"-"`
+	Song        string    `json:"song"`             // Title
+	Key         string    `json:"key"`              // Chart file, or title for built-in songs
+	Hash        string    `json:"hash"`             // Chart content hash
+	Mode        string    `json:"mode"`             // What kind of play: song, riff, variations, echo or daily
+	Date        time.Time `json:"date"`             // When play started
+	Duration    float64   `json:"duration"`         // Seconds spent playing
+	SongTime    float64   `json:"song_time"`        // How far into the song play got, in seconds
+	Speed       float64   `json:"speed"`            // Practice speed, 1 being full speed
+	Completed   bool      `json:"completed"`        // Played to the end without failing
+	Failed      bool      `json:"failed,omitempty"` // Health ran out in fail mode
+	Score       int       `json:"score"`
+	Accuracy    float64   `json:"accuracy"` // Percent of notes hit
+	NotesHit    int       `json:"notes_hit"`
+	NotesMissed int       `json:"notes_missed"`
+	MaxCombo    int       `json:"max_combo"`
+	Cents       float64   `json:"cents"`  // Average intonation error of hits
+	Offset      float64   `json:"offset"` // Mean timing of hits, in seconds late (negative is early)
+	Spread      float64   `json:"spread"` // Standard deviation of hit timing, in seconds
+	WrongNotes  int       `json:"wrong_notes,omitempty"`
+	RhythmOnly  bool      `json:"rhythm_only,omitempty"` // Scored on timing alone, whatever was played
+}
+
+// DB is the history database
+type DB struct {
+	db *bolt.DB
+}
+
+// Open opens, or creates, the history database at path
+func Open(path string) (*DB, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{sessionsBucket, favoritesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Add records a session, setting its ID
+func (d *DB) Add(s *Session) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(itob(id), data); err != nil {
+			return err
+		}
+		s.ID = id
+		return nil
+	})
+}
+
+// Sessions returns the sessions started at or after a time, oldest first
+func (d *DB) Sessions(since time.Time) ([]Session, error) {
+	var sessions []Session
+	err := d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(k, v []byte) error {
+			var s Session
+			if err := json.Unmarshal(v, &s); err != nil {
+				return err
+			}
+			if s.Date.Before(since) {
+				return nil
+			}
+			s.ID = binary.BigEndian.Uint64(k)
+			sessions = append(sessions, s)
+			return nil
+		})
+	})
+	return sessions, err
+}
+
+// itob encodes an ID so keys sort in the order sessions were added
+func itob(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
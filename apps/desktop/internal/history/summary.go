@@ -0,0 +1,111 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary is practice totals and per-song progress over recent weeks
+type Summary struct {
+	Total  time.Duration // All practice ever recorded
+	Streak int           // Days in a row practiced, up to today or yesterday
+	Songs  []SongProgress
+}
+
+// SongProgress is how practice of one song went over recent weeks
+type SongProgress struct {
+	Song  string
+	Time  time.Duration  // Practiced over all the weeks
+	Weeks []WeekProgress // Oldest first, ending with the current week
+}
+
+// WeekProgress is a week's practice of a song
+type WeekProgress struct {
+	Time     time.Duration
+	Accuracy float64 // Percent of notes hit, weighted by notes played
+	Sessions int
+}
+
+// Summarize totals every session and charts those in the last few weeks
+// (each ending on now's day) song by song, most practiced first
+func Summarize(sessions []Session, now time.Time, weeks int) Summary {
+	var sum Summary
+	days := make(map[time.Time]bool)
+	today := day(now)
+	start := today.AddDate(0, 0, 1-7*weeks)
+
+	type tally struct {
+		progress SongProgress
+		hit      []int // Notes hit and judged each week, for accuracy
+		judged   []int
+	}
+	bySong := make(map[string]*tally)
+	for _, s := range sessions {
+		played := time.Duration(s.Duration * float64(time.Second))
+		sum.Total += played
+		d := day(s.Date)
+		days[d] = true
+		if d.Before(start) || d.After(today) {
+			continue
+		}
+		t := bySong[s.Key]
+		if t == nil {
+			t = &tally{
+				progress: SongProgress{Song: s.Song, Weeks: make([]WeekProgress, weeks)},
+				hit:      make([]int, weeks),
+				judged:   make([]int, weeks),
+			}
+			bySong[s.Key] = t
+		}
+		w := int(math.Round(d.Sub(start).Hours()/24)) / 7
+		week := &t.progress.Weeks[w]
+		week.Time += played
+		week.Sessions++
+		t.progress.Time += played
+		if s.RhythmOnly {
+			continue // Not comparable with the accuracy of full play
+		}
+		t.hit[w] += s.NotesHit
+		t.judged[w] += s.NotesHit + s.NotesMissed
+	}
+
+	for _, t := range bySong {
+		for w := range t.progress.Weeks {
+			if t.judged[w] > 0 {
+				t.progress.Weeks[w].Accuracy = float64(t.hit[w]) / float64(t.judged[w]) * 100
+			}
+		}
+		sum.Songs = append(sum.Songs, t.progress)
+	}
+	sort.Slice(sum.Songs, func(i, j int) bool {
+		if sum.Songs[i].Time != sum.Songs[j].Time {
+			return sum.Songs[i].Time > sum.Songs[j].Time
+		}
+		return sum.Songs[i].Song < sum.Songs[j].Song
+	})
+
+	sum.Streak = streak(days, today)
+	return sum
+}
+
+// streak counts the days in a row in a set up to today, or yesterday: a
+// streak isn't broken until a whole day passes without practice
+func streak(days map[time.Time]bool, today time.Time) int {
+	n := 0
+	d := today
+	if !days[d] {
+		d = d.AddDate(0, 0, -1)
+	}
+	for days[d] {
+		n++
+		d = d.AddDate(0, 0, -1)
+	}
+	return n
+}
+
+// day returns midnight at the start of a time's local day
+func day(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,172 @@
+// Package livestats serves the state of play on a local HTTP endpoint,
+// for stream overlays and tools that log practice as it happens. GET
+// /state returns the latest snapshot as JSON, and a WebSocket on /ws is
+// sent each new one.
+package livestats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Snapshot is the state of play at one moment
+type Snapshot struct {
+	Screen     string  `json:"screen"` // "menu", "playing", "results" or another screen
+	Song       string  `json:"song,omitempty"`
+	Artist     string  `json:"artist,omitempty"`
+	Time       float64 `json:"time"` // Seconds into the song
+	Duration   float64 `json:"duration"`
+	Score      int     `json:"score"`
+	Combo      int     `json:"combo"`
+	Multiplier int     `json:"multiplier"`
+	Accuracy   float64 `json:"accuracy"` // Percentage of the notes judged so far that were hit
+	Hits       int     `json:"hits"`
+	Misses     int     `json:"misses"`
+	Note       *Note   `json:"note,omitempty"`  // The next note to play
+	Pitch      *Pitch  `json:"pitch,omitempty"` // What's being heard, if anything
+}
+
+// Note is a note of the chart
+type Note struct {
+	Name   string  `json:"name"` // With its octave, e.g. "A1"
+	String int     `json:"string"`
+	Fret   int     `json:"fret"`
+	Time   float64 `json:"time"`
+}
+
+// Pitch is a note being heard
+type Pitch struct {
+	Name      string  `json:"name"`
+	Frequency float64 `json:"frequency"`
+	Cents     int     `json:"cents"`
+}
+
+// writeTimeout bounds how long a slow listener can hold up a send
+const writeTimeout = 2 * time.Second
+
+// Server publishes snapshots to whoever asks
+type Server struct {
+	listener net.Listener
+	http     *http.Server
+
+	mu      sync.Mutex
+	latest  []byte
+	clients map[*wsConn]struct{}
+	origins []string // Web origins allowed besides none
+}
+
+// Start listens on addr, e.g. "127.0.0.1:8765". Anything that can reach
+// the address can read the state of play, so keep it on localhost unless
+// the overlay runs on another machine. Browsers may only read it from
+// pages with one of origins, so a web page the player visits can't.
+func Start(addr string, origins []string) (*Server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: l, latest: []byte("{}"), clients: map[*wsConn]struct{}{}, origins: origins}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /state", s.serveState)
+	mux.HandleFunc("GET /ws", s.serveWebSocket)
+	s.http = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go s.http.Serve(l)
+	return s, nil
+}
+
+// Addr returns the address being listened on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Publish makes a snapshot the latest, sending it to every WebSocket
+// listener if it differs from the last
+func (s *Server) Publish(snap Snapshot) {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(data, s.latest) {
+		return
+	}
+	s.latest = data
+	for c := range s.clients {
+		c.send(data)
+	}
+}
+
+// Close stops serving and disconnects every listener
+func (s *Server) Close() error {
+	s.mu.Lock()
+	for c := range s.clients {
+		c.close()
+	}
+	s.clients = nil
+	s.mu.Unlock()
+	err := s.http.Close()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// allowOrigin reports whether a request may read the state of play: it
+// comes from outside a browser, which sends no origin, or from an origin
+// allowed when starting. Any site can send "null" from a sandboxed frame,
+// so it's only allowed when listed.
+func (s *Server) allowOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || slices.Contains(s.origins, origin)
+}
+
+func (s *Server) serveState(w http.ResponseWriter, r *http.Request) {
+	if !s.allowOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	s.mu.Lock()
+	data := s.latest
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if origin := r.Header.Get("Origin"); origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Vary", "Origin")
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
+
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !s.allowOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	c, err := upgrade(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	if s.clients == nil {
+		s.mu.Unlock()
+		c.close()
+		return
+	}
+	s.clients[c] = struct{}{}
+	c.send(s.latest)
+	s.mu.Unlock()
+
+	c.readUntilClosed()
+
+	s.mu.Lock()
+	delete(s.clients, c)
+	s.mu.Unlock()
+	c.close()
+}
@@ -0,0 +1,215 @@
+// Package logging writes the game's log to stderr and to a file in the
+// config directory, so there's always one to attach to a bug report. The
+// file is rotated as it grows, and the latest lines are kept for the
+// on-screen debug console.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is how serious a message is; messages below the level set are
+// dropped
+type Level int
+
+// Levels, least serious first
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel reads a level by name, as given in the config file or on
+// the command line
+func ParseLevel(s string) (Level, error) {
+	if i := slices.Index(levelNames, strings.ToLower(s)); i >= 0 {
+		return Level(i), nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q (want %s)", s, strings.Join(levelNames, ", "))
+}
+
+// FileName is the log file in the directory given to Open. When it
+// reaches maxSize, and at each start, it's moved aside to FileName.1,
+// keeping up to maxBackups earlier files.
+const (
+	FileName   = "guitargame.log"
+	maxSize    = 1 << 20
+	maxBackups = 3
+)
+
+// recentEntries is how many of the latest entries Recent can return
+const recentEntries = 200
+
+// Entry is one logged message
+type Entry struct {
+	Time  time.Time
+	Level Level
+	Text  string
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf("%s %-5s %s", e.Time.Format("2006-01-02 15:04:05.000"), strings.ToUpper(e.Level.String()), e.Text)
+}
+
+var (
+	mu     sync.Mutex
+	level  = LevelInfo
+	path   string
+	file   *os.File
+	size   int64
+	recent []Entry
+)
+
+// SetLevel drops messages less serious than l from then on
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// CurrentLevel returns the level set
+func CurrentLevel() Level {
+	mu.Lock()
+	defer mu.Unlock()
+	return level
+}
+
+// Open starts writing the log to FileName in dir as well as stderr,
+// moving the last run's log aside first
+func Open(dir string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	path = filepath.Join(dir, FileName)
+	return rotate()
+}
+
+// Close stops writing the log file
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// Path returns the log file being written, "" if there isn't one
+func Path() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return ""
+	}
+	return path
+}
+
+// Recent returns up to n of the latest entries, oldest first
+func Recent(n int) []Entry {
+	mu.Lock()
+	defer mu.Unlock()
+	return slices.Clone(recent[max(0, len(recent)-n):])
+}
+
+// Debugf logs detail only wanted when looking into a problem
+func Debugf(format string, args ...any) { logf(LevelDebug, format, args...) }
+
+// Infof logs something the player might like to know happened
+func Infof(format string, args ...any) { logf(LevelInfo, format, args...) }
+
+// Warnf logs something that went wrong that play carries on without
+func Warnf(format string, args ...any) { logf(LevelWarn, format, args...) }
+
+// Errorf logs something that went wrong that the player will notice
+func Errorf(format string, args ...any) { logf(LevelError, format, args...) }
+
+// Writer returns a writer that logs each line written to it at level l,
+// for the standard library's log package to write to, so messages from
+// libraries end up in the log file too
+func Writer(l Level) io.Writer {
+	return lineWriter(l)
+}
+
+type lineWriter Level
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	for line := range strings.Lines(string(p)) {
+		logf(Level(w), "%s", strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+func logf(l Level, format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if l < level {
+		return
+	}
+	e := Entry{Time: time.Now(), Level: l, Text: fmt.Sprintf(format, args...)}
+	line := e.String() + "\n"
+	io.WriteString(os.Stderr, line)
+	if file != nil && size+int64(len(line)) > maxSize {
+		if err := rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not rotate %s, no longer writing to it: %v\n", path, err)
+		}
+	}
+	if file != nil {
+		n, _ := file.WriteString(line)
+		size += int64(n)
+	}
+	recent = append(recent, e)
+	if len(recent) > 2*recentEntries {
+		recent = slices.Clone(recent[len(recent)-recentEntries:])
+	}
+}
+
+// rotate shifts the earlier log files along, moves the current one to
+// be the first of them and starts a new one. It's called with mu held.
+func rotate() error {
+	if file != nil {
+		file.Close()
+		file = nil
+	}
+	for i := maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backup(i), backup(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(path, backup(1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	file, size = f, 0
+	return nil
+}
+
+// backup names the i'th most recent earlier log file
+func backup(i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
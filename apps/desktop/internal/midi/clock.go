@@ -0,0 +1,121 @@
+package midi
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/core/song"
+)
+
+// MIDI real-time messages
+const (
+	TimingClock byte = 0xF8
+	Start       byte = 0xFA
+	Continue    byte = 0xFB
+	Stop        byte = 0xFC
+)
+
+// PulsesPerBeat is the MIDI beat clock resolution (24 per quarter note)
+const PulsesPerBeat = 24
+
+// OpenOutput opens a raw MIDI device for writing, such as an ALSA
+// rawmidi node (/dev/snd/midiC1D0) or a FIFO read by a MIDI bridge
+func OpenOutput(path string) (io.WriteCloser, error) {
+	return os.OpenFile(path, os.O_WRONLY, 0)
+}
+
+// Open opens a MIDI output given on the command line: a device path
+// (anything with a slash in it), or else the name of a port to create
+func Open(target string) (io.WriteCloser, error) {
+	if strings.ContainsRune(target, '/') || strings.ContainsRune(target, filepath.Separator) {
+		return OpenOutput(target)
+	}
+	return OpenVirtual(target)
+}
+
+// Clock sends MIDI beat clock that follows a song's tempo map, so
+// external drum machines and metronomes play along with the chart
+type Clock struct {
+	out io.Writer
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewClock creates a clock that writes to out
+func NewClock(out io.Writer) *Clock {
+	return &Clock{out: out}
+}
+
+// Start sends a MIDI Start message and then clock pulses timed from
+// start, which should be the moment beat 0 of the song is played. Speed
+// scales the song's tempo, 1 being as charted.
+func (c *Clock) Start(s *song.Song, start time.Time, speed float64) {
+	c.Stop()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go c.run(s, start, speed, c.stop, c.done)
+}
+
+// Stop halts the clock and sends a MIDI Stop message. It is safe to
+// call when the clock is not running.
+func (c *Clock) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (c *Clock) run(s *song.Song, start time.Time, speed float64, stop, done chan struct{}) {
+	defer close(done)
+
+	if !c.send(Start) {
+		return
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for pulse := 0; ; pulse++ {
+		// Schedule each pulse from the song start rather than the previous
+		// pulse so timing errors don't accumulate
+		beat := float64(pulse) / PulsesPerBeat
+		at := start.Add(time.Duration(s.BeatToTime(beat) / speed * float64(time.Second)))
+		timer.Reset(time.Until(at))
+
+		select {
+		case <-stop:
+			c.send(Stop)
+			return
+		case <-timer.C:
+		}
+
+		if !c.send(TimingClock) {
+			return
+		}
+	}
+}
+
+func (c *Clock) send(msg byte) bool {
+	if _, err := c.out.Write([]byte{msg}); err != nil {
+		logging.Warnf("MIDI clock stopped: %v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package midi
+
+import (
+	"io"
+	"math"
+
+	"guitargame/core/game"
+	"guitargame/core/pitch"
+)
+
+// MIDI channel voice messages, before the channel is added
+const (
+	NoteOff byte = 0x80
+	NoteOn  byte = 0x90
+)
+
+// A note must be heard for noteOnReadings readings in a row before it's
+// played, so the detector settling on the attack doesn't play stray
+// notes, and go unheard for noteOffReadings before it's stopped
+const (
+	noteOnReadings  = 2
+	noteOffReadings = 3
+)
+
+// NoteOut plays the notes heard on a MIDI output, turning an instrument
+// into a MIDI controller: a note on when a note starts or is played again,
+// and a note off when it stops or another starts
+type NoteOut struct {
+	out     io.Writer
+	channel byte
+
+	playing  int // MIDI note sounding, -1 if none
+	heard    int // Note the latest readings agree on, -1 if none
+	readings int // How many readings in a row have heard it
+	lastRMS  float64
+}
+
+// NewNoteOut creates a NoteOut that writes to out on a channel from 1 to
+// 16
+func NewNoteOut(out io.Writer, channel int) *NoteOut {
+	return &NoteOut{out: out, channel: byte(max(1, min(16, channel)) - 1), playing: -1, heard: -1}
+}
+
+// Update takes the latest pitch reading, starting and stopping notes as
+// they're heard. Call it for every reading.
+func (n *NoteOut) Update(r pitch.Result) error {
+	note := -1
+	if r.IsValid() {
+		note = r.MIDINote()
+	}
+	onset := game.IsOnset(r, n.lastRMS)
+	n.lastRMS = r.RMS
+	if note == n.heard {
+		n.readings++
+	} else {
+		n.heard, n.readings = note, 1
+	}
+
+	switch {
+	case note < 0:
+		if n.readings >= noteOffReadings {
+			return n.Off()
+		}
+	case note == n.playing:
+		// Played again: start it afresh
+		if onset {
+			return n.play(note, r.RMS)
+		}
+	case n.readings >= noteOnReadings:
+		return n.play(note, r.RMS)
+	}
+	return nil
+}
+
+// Off stops the note sounding, if any
+func (n *NoteOut) Off() error {
+	if n.playing < 0 {
+		return nil
+	}
+	note := n.playing
+	n.playing = -1
+	_, err := n.out.Write([]byte{NoteOff | n.channel, byte(note), 0})
+	return err
+}
+
+// play stops the note sounding and starts another, as loud as it was
+// played
+func (n *NoteOut) play(note int, rms float64) error {
+	if err := n.Off(); err != nil {
+		return err
+	}
+	if note < 0 || note > 127 {
+		return nil
+	}
+	n.playing = note
+	_, err := n.out.Write([]byte{NoteOn | n.channel, byte(note), velocity(rms)})
+	return err
+}
+
+// velocity maps an input level to a note velocity: 127 at full scale,
+// falling about 42 for every tenth as loud
+func velocity(rms float64) byte {
+	if rms <= 0 {
+		return 1
+	}
+	return byte(max(1, min(127, math.Round(127+42*math.Log10(rms)))))
+}
@@ -0,0 +1,106 @@
+//go:build darwin
+
+package midi
+
+/*
+#cgo LDFLAGS: -framework CoreMIDI -framework CoreFoundation
+
+#include <stdlib.h>
+#include <CoreMIDI/CoreMIDI.h>
+
+// openPort creates a MIDI client with one source other apps can read from
+static OSStatus openPort(const char *name, MIDIClientRef *client, MIDIEndpointRef *source) {
+	CFStringRef cfName = CFStringCreateWithCString(NULL, name, kCFStringEncodingUTF8);
+	OSStatus err = MIDIClientCreate(cfName, NULL, NULL, client);
+	if (err == noErr) {
+		err = MIDISourceCreate(*client, cfName, source);
+		if (err != noErr) {
+			MIDIClientDispose(*client);
+		}
+	}
+	CFRelease(cfName);
+	return err;
+}
+
+// sendPort hands MIDI bytes to the apps reading the source, to be
+// played now
+static OSStatus sendPort(MIDIEndpointRef source, const Byte *data, UInt16 size) {
+	Byte buf[256];
+	MIDIPacketList *list = (MIDIPacketList *)buf;
+	MIDIPacket *packet = MIDIPacketListInit(list);
+	packet = MIDIPacketListAdd(list, sizeof(buf), packet, 0, size, data);
+	if (packet == NULL) {
+		return kMIDIMessageSendErr;
+	}
+	return MIDIReceived(source, list);
+}
+
+static void closePort(MIDIClientRef client, MIDIEndpointRef source) {
+	MIDIEndpointDispose(source);
+	MIDIClientDispose(client);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"unsafe"
+)
+
+// maxVirtualWrite is the most bytes sent to a CoreMIDI source at once,
+// leaving room in sendPort's packet list for its header
+const maxVirtualWrite = 200
+
+// coreMIDIPort is a CoreMIDI source the game created
+type coreMIDIPort struct {
+	mu     sync.Mutex // The clock and notes may share the port
+	client C.MIDIClientRef
+	source C.MIDIEndpointRef
+	closed bool
+}
+
+// OpenVirtual creates a MIDI port other programs can connect to, such
+// as a soft synth or DAW. On macOS it's a CoreMIDI source, listed among
+// the MIDI inputs of other apps.
+func OpenVirtual(name string) (io.WriteCloser, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	p := &coreMIDIPort{}
+	if status := C.openPort(cname, &p.client, &p.source); status != C.noErr {
+		return nil, coreMIDIError(status)
+	}
+	return p, nil
+}
+
+func (p *coreMIDIPort) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, errors.New("MIDI port is closed")
+	}
+	for sent := 0; sent < len(b); {
+		n := min(len(b)-sent, maxVirtualWrite)
+		if status := C.sendPort(p.source, (*C.Byte)(unsafe.Pointer(&b[sent])), C.UInt16(n)); status != C.noErr {
+			return sent, coreMIDIError(status)
+		}
+		sent += n
+	}
+	return len(b), nil
+}
+
+func (p *coreMIDIPort) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		C.closePort(p.client, p.source)
+		p.closed = true
+	}
+	return nil
+}
+
+func coreMIDIError(status C.OSStatus) error {
+	return fmt.Errorf("CoreMIDI error %d", int(status))
+}
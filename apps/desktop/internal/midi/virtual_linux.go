@@ -0,0 +1,139 @@
+//go:build linux
+
+package midi
+
+/*
+#cgo LDFLAGS: -lasound
+
+#include <stdlib.h>
+#include <alsa/asoundlib.h>
+
+typedef struct {
+	snd_seq_t *seq;
+	snd_midi_event_t *encoder;
+	int port;
+} virtualPort;
+
+// openPort creates a sequencer client with one output port other
+// clients can subscribe to
+static virtualPort *openPort(const char *name, int *err) {
+	virtualPort *p = calloc(1, sizeof(virtualPort));
+	if (p == NULL) {
+		*err = -ENOMEM;
+		return NULL;
+	}
+	*err = snd_seq_open(&p->seq, "default", SND_SEQ_OPEN_OUTPUT, 0);
+	if (*err < 0) {
+		free(p);
+		return NULL;
+	}
+	snd_seq_set_client_name(p->seq, name);
+	p->port = snd_seq_create_simple_port(p->seq, name,
+		SND_SEQ_PORT_CAP_READ | SND_SEQ_PORT_CAP_SUBS_READ,
+		SND_SEQ_PORT_TYPE_MIDI_GENERIC | SND_SEQ_PORT_TYPE_APPLICATION);
+	if (p->port < 0) {
+		*err = p->port;
+		snd_seq_close(p->seq);
+		free(p);
+		return NULL;
+	}
+	// The encoder gathers a message's bytes; only short messages are sent
+	*err = snd_midi_event_new(32, &p->encoder);
+	if (*err < 0) {
+		snd_seq_close(p->seq);
+		free(p);
+		return NULL;
+	}
+	return p;
+}
+
+// sendPort turns MIDI bytes into sequencer events and delivers them to
+// the port's subscribers straight away
+static int sendPort(virtualPort *p, const unsigned char *data, long size) {
+	snd_seq_event_t ev;
+	while (size > 0) {
+		snd_seq_ev_clear(&ev);
+		long n = snd_midi_event_encode(p->encoder, data, size, &ev);
+		if (n <= 0) {
+			return n < 0 ? (int)n : -EINVAL;
+		}
+		data += n;
+		size -= n;
+		if (ev.type == SND_SEQ_EVENT_NONE) {
+			continue; // The message isn't complete yet
+		}
+		snd_seq_ev_set_source(&ev, p->port);
+		snd_seq_ev_set_subs(&ev);
+		snd_seq_ev_set_direct(&ev);
+		int err = snd_seq_event_output_direct(p->seq, &ev);
+		if (err < 0) {
+			return err;
+		}
+	}
+	return 0;
+}
+
+static void closePort(virtualPort *p) {
+	snd_midi_event_free(p->encoder);
+	snd_seq_delete_simple_port(p->seq, p->port);
+	snd_seq_close(p->seq);
+	free(p);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"unsafe"
+)
+
+// alsaPort is an ALSA sequencer port the game created
+type alsaPort struct {
+	mu   sync.Mutex // The clock and notes may share the port
+	port *C.virtualPort
+}
+
+// OpenVirtual creates a MIDI port other programs can connect to, such
+// as a soft synth or DAW. On Linux it's an ALSA sequencer port, listed
+// by aconnect -l and connected with aconnect or a patchbay.
+func OpenVirtual(name string) (io.WriteCloser, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	var result C.int
+	port := C.openPort(cname, &result)
+	if port == nil {
+		return nil, alsaError(result)
+	}
+	return &alsaPort{port: port}, nil
+}
+
+func (p *alsaPort) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.port == nil {
+		return 0, errors.New("MIDI port is closed")
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if result := C.sendPort(p.port, (*C.uchar)(unsafe.Pointer(&b[0])), C.long(len(b))); result < 0 {
+		return 0, alsaError(result)
+	}
+	return len(b), nil
+}
+
+func (p *alsaPort) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.port != nil {
+		C.closePort(p.port)
+		p.port = nil
+	}
+	return nil
+}
+
+func alsaError(result C.int) error {
+	return errors.New(C.GoString(C.snd_strerror(result)))
+}
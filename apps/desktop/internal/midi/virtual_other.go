@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package midi
+
+import (
+	"errors"
+	"io"
+)
+
+// OpenVirtual is only supported on Linux and macOS. Elsewhere, create
+// a port with a loopback driver and give the device instead.
+func OpenVirtual(name string) (io.WriteCloser, error) {
+	return nil, errors.New("MIDI ports can only be created on Linux and macOS; give the path of a MIDI device instead")
+}
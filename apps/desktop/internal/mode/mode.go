@@ -0,0 +1,118 @@
+// Package mode holds game modes: ways of playing a song that change the
+// rules or add to the screen, hooked into play without touching the
+// game's own state machine. Modes register themselves by name; the one
+// the player picks is set up on each game's state as it starts.
+package mode
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/widget/material"
+
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// Mode is a way of playing. Embed Base to implement only the hooks a mode
+// needs.
+type Mode interface {
+	// Name is what the mode is listed and saved as
+	Name() string
+	// Description says in a line what the mode changes
+	Description() string
+
+	// Start readies the mode for a game about to begin, and may change
+	// its settings
+	Start(gs *song.GameState)
+	// Update is called each frame of play, after the pitch heard has been
+	// checked for hits
+	Update(gs *song.GameState, heard pitch.Result)
+	// Judge may change the quality a note was judged at before it's scored
+	Judge(gs *song.GameState, note *song.TabNote, quality song.HitQuality) song.HitQuality
+	// Score returns what a judged note scores, given the points it would
+	// otherwise earn
+	Score(gs *song.GameState, note *song.TabNote, quality song.HitQuality, points int) int
+	// Layout draws over the highway during play
+	Layout(gtx layout.Context, th *material.Theme, gs *song.GameState)
+}
+
+// Base is a mode that changes nothing, for embedding
+type Base struct{}
+
+func (Base) Start(*song.GameState)                {}
+func (Base) Update(*song.GameState, pitch.Result) {}
+
+func (Base) Judge(_ *song.GameState, _ *song.TabNote, quality song.HitQuality) song.HitQuality {
+	return quality
+}
+
+func (Base) Score(_ *song.GameState, _ *song.TabNote, _ song.HitQuality, points int) int {
+	return points
+}
+
+func (Base) Layout(layout.Context, *material.Theme, *song.GameState) {}
+
+// Standard is the mode songs are played in unless another is chosen
+const Standard = "Standard"
+
+type standard struct{ Base }
+
+func (standard) Name() string        { return Standard }
+func (standard) Description() string { return "the usual rules" }
+
+var (
+	modes = map[string]Mode{}
+	names []string // In the order registered
+)
+
+func init() {
+	Register(standard{})
+}
+
+// Register adds a mode to those the player can choose. It panics if a
+// mode of the same name is already registered, so it's meant to be
+// called from init.
+func Register(m Mode) {
+	name := m.Name()
+	if _, ok := modes[name]; ok {
+		panic(fmt.Sprintf("mode: %q registered twice", name))
+	}
+	modes[name] = m
+	names = append(names, name)
+}
+
+// Get returns the mode registered under a name, or Standard if there's
+// none, as for a saved mode that's no longer built in
+func Get(name string) Mode {
+	if m, ok := modes[name]; ok {
+		return m
+	}
+	return modes[Standard]
+}
+
+// Names lists the registered modes, Standard first
+func Names() []string {
+	return names
+}
+
+// Next returns the name of the mode after the named one, wrapping round
+func Next(name string) string {
+	for i, n := range names {
+		if n == name {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
+
+// Attach starts a mode on a game and hooks its judging and scoring in
+func Attach(m Mode, gs *song.GameState) {
+	m.Start(gs)
+	gs.Judge = func(note *song.TabNote, quality song.HitQuality) song.HitQuality {
+		return m.Judge(gs, note, quality)
+	}
+	gs.Points = func(note *song.TabNote, quality song.HitQuality, points int) int {
+		return m.Score(gs, note, quality, points)
+	}
+}
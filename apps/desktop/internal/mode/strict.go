@@ -0,0 +1,20 @@
+package mode
+
+import "guitargame/core/song"
+
+// strict counts only perfect hits; anything looser is a miss
+type strict struct{ Base }
+
+func init() {
+	Register(strict{})
+}
+
+func (strict) Name() string        { return "Strict" }
+func (strict) Description() string { return "only perfect hits count, anything looser is a miss" }
+
+func (strict) Judge(_ *song.GameState, _ *song.TabNote, quality song.HitQuality) song.HitQuality {
+	if quality != song.HitPerfect {
+		return song.HitMiss
+	}
+	return quality
+}
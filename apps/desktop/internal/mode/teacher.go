@@ -0,0 +1,63 @@
+package mode
+
+import (
+	"fmt"
+	"image"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// teacherMinHits is how many hits the teacher waits for before remarking
+// on timing, and teacherDrift how far off on average is worth a remark
+const (
+	teacherMinHits = 8
+	teacherDrift   = 0.03 // Seconds
+)
+
+// teacher talks the player through a song: which note comes next and
+// where it's played, and whether they're rushing or dragging
+type teacher struct{ Base }
+
+func init() {
+	Register(teacher{})
+}
+
+func (teacher) Name() string { return "Teacher" }
+func (teacher) Description() string {
+	return "names each next note and where to play it, and comments on timing"
+}
+
+func (teacher) Layout(gtx layout.Context, th *material.Theme, gs *song.GameState) {
+	lines := []string{}
+	if note := gs.NextNote(); note != nil {
+		tuning := gs.Song.GetTuning()
+		where := "open"
+		if note.Fret > 0 {
+			where = fmt.Sprintf("fret %d", note.Fret)
+		}
+		lines = append(lines, fmt.Sprintf("Next: %s%d on the %s string, %s",
+			note.NoteWithTuning(tuning), note.OctaveWithTuning(tuning), tuning[note.String].Note, where))
+	}
+	if gs.NotesHit >= teacherMinHits {
+		switch offset := gs.AverageOffset(); {
+		case offset > teacherDrift:
+			lines = append(lines, fmt.Sprintf("You're dragging by %.0f ms: play a touch earlier", offset*1000))
+		case offset < -teacherDrift:
+			lines = append(lines, fmt.Sprintf("You're rushing by %.0f ms: wait for the beat", -offset*1000))
+		}
+	}
+
+	defer op.Offset(image.Pt(gtx.Dp(20), gtx.Dp(8))).Push(gtx.Ops).Pop()
+	gtx.Constraints.Min = image.Point{}
+	for _, text := range lines {
+		label := material.Body1(th, text)
+		label.Color = render.Colors.Info
+		dims := label.Layout(gtx)
+		op.Offset(image.Pt(0, dims.Size.Y+gtx.Dp(2))).Add(gtx.Ops)
+	}
+}
@@ -0,0 +1,83 @@
+// Package osc sends Open Sound Control messages over UDP, for lighting
+// rigs, visuals and DAWs to follow play
+package osc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+)
+
+// Sender sends messages to one host and port
+type Sender struct {
+	conn net.Conn
+}
+
+// Dial starts sending to addr, e.g. "127.0.0.1:9000". Nothing is sent
+// until Send is called, and UDP doesn't say whether anything's listening.
+func Dial(addr string) (*Sender, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Sender{conn: conn}, nil
+}
+
+// Send sends a message to an address pattern such as "/hit". Arguments
+// may be int, int32, float32, float64 or string.
+func (s *Sender) Send(address string, args ...any) error {
+	msg, err := Encode(address, args...)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Write(msg)
+	return err
+}
+
+// Close stops sending
+func (s *Sender) Close() error {
+	return s.conn.Close()
+}
+
+// Encode builds a message. Integers are sent as 32-bit ints and floats as
+// 32-bit floats, the types every receiver understands.
+func Encode(address string, args ...any) ([]byte, error) {
+	tags := []byte{','}
+	var data []byte
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case int:
+			tags = append(tags, 'i')
+			data = binary.BigEndian.AppendUint32(data, uint32(int32(v)))
+		case int32:
+			tags = append(tags, 'i')
+			data = binary.BigEndian.AppendUint32(data, uint32(v))
+		case float32:
+			tags = append(tags, 'f')
+			data = binary.BigEndian.AppendUint32(data, math.Float32bits(v))
+		case float64:
+			tags = append(tags, 'f')
+			data = binary.BigEndian.AppendUint32(data, math.Float32bits(float32(v)))
+		case string:
+			tags = append(tags, 's')
+			data = appendString(data, v)
+		default:
+			return nil, fmt.Errorf("osc: can't send %T", arg)
+		}
+	}
+	msg := appendString(nil, address)
+	msg = appendString(msg, string(tags))
+	return append(msg, data...), nil
+}
+
+// appendString appends an OSC string: null-terminated and padded with
+// nulls to a multiple of four bytes
+func appendString(b []byte, s string) []byte {
+	b = append(b, s...)
+	pad := 4 - len(s)%4
+	for range pad {
+		b = append(b, 0)
+	}
+	return b
+}
@@ -0,0 +1,333 @@
+// Package pack builds distributable song pack archives
+package pack
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Cover art decoders
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+	"gopkg.in/yaml.v3"
+
+	"guitargame/core/song"
+)
+
+// Thumbnails are scaled to fit a square of this many pixels
+const thumbnailSize = 256
+
+// thumbnailDir is where generated thumbnails go inside the archive
+const thumbnailDir = "thumbnails"
+
+// Metadata describes a pack's contents so it can be listed without
+// opening every chart
+type Metadata struct {
+	Name    string     `yaml:"name"`
+	Version string     `yaml:"version,omitempty"`
+	Created string     `yaml:"created"`
+	Songs   []SongInfo `yaml:"songs"`
+}
+
+// SongInfo summarizes one chart in a pack
+type SongInfo struct {
+	File      string  `yaml:"file"`
+	Title     string  `yaml:"title"`
+	Artist    string  `yaml:"artist,omitempty"`
+	Album     string  `yaml:"album,omitempty"`
+	Genre     string  `yaml:"genre,omitempty"`
+	Year      int     `yaml:"year,omitempty"`
+	BPM       float64 `yaml:"bpm"`
+	Tuning    string  `yaml:"tuning"`
+	Notes     int     `yaml:"notes"`
+	Duration  float64 `yaml:"duration"` // Seconds
+	Audio     string  `yaml:"audio,omitempty"`
+	Cover     string  `yaml:"cover,omitempty"`
+	Thumbnail string  `yaml:"thumbnail,omitempty"`
+}
+
+// Options configure a pack build
+type Options struct {
+	Name    string // Defaults to the directory name
+	Version string
+}
+
+// Build validates the charts and assets in dir and writes them to a pack
+// archive at out, along with generated metadata and cover thumbnails.
+// Nothing is written if any chart fails validation.
+func Build(dir, out string, opts Options) (*Metadata, error) {
+	if opts.Name == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		opts.Name = filepath.Base(abs)
+	}
+
+	files, err := listFiles(dir, out)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &Metadata{
+		Name:    opts.Name,
+		Version: opts.Version,
+		Created: time.Now().UTC().Format(time.RFC3339),
+	}
+	thumbnails := make(map[string][]byte)
+	var problems []error
+
+	for _, rel := range files {
+		if !song.IsChartFile(rel) {
+			continue
+		}
+		info, thumb, err := checkChart(dir, rel)
+		if err != nil {
+			for _, e := range splitErrors(err) {
+				problems = append(problems, fmt.Errorf("%s: %w", rel, e))
+			}
+			continue
+		}
+		if thumb != nil {
+			thumbnails[info.Thumbnail] = thumb
+		}
+		meta.Songs = append(meta.Songs, *info)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	if len(meta.Songs) == 0 {
+		return nil, fmt.Errorf("no charts found in %s", dir)
+	}
+
+	if err := writeArchive(dir, out, files, thumbnails, meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// listFiles returns every file under dir as a slash-separated relative
+// path, skipping hidden files and the archive being written
+func listFiles(dir, out string) ([]string, error) {
+	outAbs, _ := filepath.Abs(out)
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if abs, _ := filepath.Abs(p); abs == outAbs {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == song.PackMetadataFile || strings.HasPrefix(rel, thumbnailDir+"/") {
+			return nil // Left over from unpacking a built pack; regenerated below
+		}
+		files = append(files, rel)
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// checkChart validates a chart and the assets it refers to, and makes a
+// thumbnail of its cover art
+func checkChart(dir, rel string) (*SongInfo, []byte, error) {
+	s, err := song.LoadSong(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, nil, err
+	}
+	var problems []error
+	if err := s.Validate(); err != nil {
+		problems = append(problems, err)
+	}
+
+	info := &SongInfo{
+		File:     rel,
+		Title:    s.Title,
+		Artist:   s.Artist,
+		Album:    s.Album,
+		Genre:    s.Genre,
+		Year:     s.Year,
+		BPM:      s.BPM,
+		Tuning:   s.GetTuning().Name(),
+		Notes:    len(s.Notes),
+		Duration: math.Round(s.Duration*100) / 100,
+	}
+
+	if s.Audio != "" {
+		asset, err := assetPath(dir, rel, s.Audio)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Errorf("audio: %w", err))
+		case !strings.EqualFold(path.Ext(asset), ".wav"):
+			problems = append(problems, fmt.Errorf("audio: %s is not a WAV file", s.Audio))
+		default:
+			info.Audio = asset
+		}
+	}
+
+	var thumb []byte
+	if s.Cover != "" {
+		asset, err := assetPath(dir, rel, s.Cover)
+		if err == nil {
+			thumb, err = makeThumbnail(filepath.Join(dir, filepath.FromSlash(asset)))
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("cover: %w", err))
+		} else {
+			info.Cover = asset
+			info.Thumbnail = path.Join(thumbnailDir, strings.TrimSuffix(rel, path.Ext(rel))+".png")
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, errors.Join(problems...)
+	}
+	return info, thumb, nil
+}
+
+// assetPath resolves a file referenced by a chart to a path relative to
+// the pack root, checking it exists inside the pack
+func assetPath(dir, chart, ref string) (string, error) {
+	rel := path.Join(path.Dir(chart), filepath.ToSlash(ref))
+	if rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+		return "", fmt.Errorf("%s is outside the pack", ref)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+		return "", fmt.Errorf("%s not found", ref)
+	}
+	return rel, nil
+}
+
+// makeThumbnail scales an image down to fit thumbnailSize and encodes it as PNG
+func makeThumbnail(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Thumbnail(src, thumbnailSize)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Thumbnail scales an image down to fit a square of size pixels, keeping
+// its shape. Smaller images are copied as they are.
+func Thumbnail(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	scale := min(1, float64(size)/float64(max(b.Dx(), b.Dy())))
+	w := max(1, int(float64(b.Dx())*scale))
+	h := max(1, int(float64(b.Dy())*scale))
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+func writeArchive(dir, out string, files []string, thumbnails map[string][]byte, meta *Metadata) (err error) {
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(out)
+		}
+	}()
+
+	zw := zip.NewWriter(f)
+
+	var metaData bytes.Buffer
+	enc := yaml.NewEncoder(&metaData)
+	enc.SetIndent(2)
+	if err := enc.Encode(meta); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := writeEntry(zw, song.PackMetadataFile, &metaData); err != nil {
+		return err
+	}
+
+	for _, rel := range files {
+		src, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		err = writeEntry(zw, rel, src)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(thumbnails))
+	for name := range thumbnails {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writeEntry(zw, name, bytes.NewReader(thumbnails[name])); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// splitErrors flattens joined errors so each can be reported on its own line
+func splitErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, splitErrors(e)...)
+	}
+	return errs
+}
+
+func writeEntry(zw *zip.Writer, name string, r io.Reader) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
@@ -0,0 +1,26 @@
+// Package permission checks whether the operating system lets the app
+// use the microphone
+package permission
+
+// Status is the microphone authorization state
+type Status int
+
+const (
+	StatusNotDetermined Status = iota // The user hasn't been asked yet
+	StatusRestricted                  // Blocked by policy (e.g. parental controls)
+	StatusDenied                      // The user said no
+	StatusAuthorized
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusNotDetermined:
+		return "not determined"
+	case StatusRestricted:
+		return "restricted"
+	case StatusDenied:
+		return "denied"
+	default:
+		return "authorized"
+	}
+}
@@ -0,0 +1,48 @@
+//go:build darwin
+
+package permission
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework Foundation
+
+#import <AVFoundation/AVFoundation.h>
+
+static int microphoneStatus(void) {
+	if (@available(macOS 10.14, *)) {
+		return (int)[AVCaptureDevice authorizationStatusForMediaType:AVMediaTypeAudio];
+	}
+	return 3; // Older systems don't ask
+}
+
+static void requestMicrophone(void) {
+	if (@available(macOS 10.14, *)) {
+		[AVCaptureDevice requestAccessForMediaType:AVMediaTypeAudio completionHandler:^(BOOL granted) {}];
+	}
+}
+*/
+import "C"
+
+import "os/exec"
+
+// microphoneSettingsURL opens the Microphone page of Privacy & Security
+const microphoneSettingsURL = "x-apple.systempreferences:com.apple.preference.security?Privacy_Microphone"
+
+// Microphone returns the current microphone authorization. The values
+// match AVAuthorizationStatus.
+func Microphone() Status {
+	return Status(C.microphoneStatus())
+}
+
+// RequestMicrophone shows the system prompt asking for microphone access
+// if the user hasn't answered it yet. The answer arrives asynchronously;
+// poll Microphone to see it.
+func RequestMicrophone() {
+	C.requestMicrophone()
+}
+
+// OpenSettings opens the system settings page where microphone access
+// can be granted
+func OpenSettings() error {
+	return exec.Command("open", microphoneSettingsURL).Start()
+}
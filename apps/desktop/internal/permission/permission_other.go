@@ -0,0 +1,19 @@
+//go:build !darwin
+
+package permission
+
+import "errors"
+
+// Microphone returns the current microphone authorization. Other
+// platforms don't gate microphone access per app.
+func Microphone() Status {
+	return StatusAuthorized
+}
+
+// RequestMicrophone does nothing on this platform
+func RequestMicrophone() {}
+
+// OpenSettings is only supported on macOS
+func OpenSettings() error {
+	return errors.New("microphone settings can only be opened on macOS")
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"gioui.org/io/semantic"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+
+	"guitargame/apps/desktop/internal/editor"
+	"guitargame/core/song"
+)
+
+// Screen reader descriptions of what's drawn rather than written. Text
+// labels describe themselves, so these cover the highway, the editor
+// grid, and the meters.
+
+// describeArea attaches a screen reader description to an area
+func describeArea(gtx layout.Context, size image.Point, description string) {
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	semantic.DescriptionOp(description).Add(gtx.Ops)
+}
+
+// stringName names a string by its open note, e.g. "A string"
+func stringName(tuning song.Tuning, str int) string {
+	if str < 0 || str >= len(tuning) {
+		return "unknown string"
+	}
+	return tuning[str].Note + " string"
+}
+
+// describeNote reads out a note's position and pitch
+func describeNote(s *song.Song, note *song.TabNote) string {
+	fret := fmt.Sprintf("fret %d", note.Fret)
+	if note.Fret == 0 {
+		fret = "open"
+	}
+	return fmt.Sprintf("%s %s, %s%d", stringName(s.GetTuning(), note.String), fret, s.NoteAt(note), s.OctaveAt(note))
+}
+
+// describePlay says which notes are coming up, for following the highway
+// by ear
+func describePlay(state *song.GameState) string {
+	const upcoming = 3
+
+	var next []string
+	for i := range state.Song.Notes {
+		note := &state.Song.Notes[i]
+		if note.Hit || !state.InPlay(note) || note.Time < state.CurrentTime {
+			continue
+		}
+		beats := (note.Time - state.CurrentTime) * state.Song.BPM / 60
+		next = append(next, fmt.Sprintf("%s in %.1f beats", describeNote(state.Song, note), beats))
+		if len(next) == upcoming {
+			break
+		}
+	}
+	if len(next) == 0 {
+		return "No more notes"
+	}
+	return "Next: " + strings.Join(next, "; ")
+}
+
+// describeEditor reads out the editor cursor and the note under it
+func describeEditor(ed *editor.Editor) string {
+	at := fmt.Sprintf("Beat %s, %s", song.Beat(ed.CursorBeat), stringName(ed.Song.GetTuning(), ed.CursorString))
+	if note := ed.NoteAtCursor(); note != nil {
+		return at + ": " + describeNote(ed.Song, note)
+	}
+	return fmt.Sprintf("%s: empty, fret %d will be placed", at, ed.Fret)
+}
+
+// describeCents reads out the intonation meter
+func describeCents(cents float64, ok bool) string {
+	switch {
+	case !ok:
+		return "Intonation: no note"
+	case cents > song.InTuneCents:
+		return fmt.Sprintf("Intonation: %.0f cents sharp", cents)
+	case cents < -song.InTuneCents:
+		return fmt.Sprintf("Intonation: %.0f cents flat", -cents)
+	default:
+		return "Intonation: in tune"
+	}
+}
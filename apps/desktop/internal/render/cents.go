@@ -0,0 +1,83 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/core/song"
+)
+
+// Cents meter geometry in pixels
+const (
+	centsMeterWidth  = 200
+	centsMeterHeight = 18
+	centsMeterRange  = 50 // Cents either side of the centre the meter shows
+)
+
+// DrawCentsMeter draws a tuner-style meter of how far the played pitch
+// is from the next note, for fretless and intonation practice. Pitches
+// beyond the meter's range pin the needle to the end.
+func (r *TabRenderer) DrawCentsMeter(gtx layout.Context, cents float64, ok bool) layout.Dimensions {
+	inset := layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(10)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				describeArea(gtx, image.Pt(centsMeterWidth, centsMeterHeight), describeCents(cents, ok))
+				fillRect(gtx, image.Rect(0, 0, centsMeterWidth, centsMeterHeight), Colors.MeterTrack)
+
+				// Ticks at the centre, a quarter tone, and the ends
+				for _, tick := range []int{-50, -25, 0, 25, 50} {
+					x := centsX(float64(tick))
+					h := centsMeterHeight / 4
+					if tick == 0 {
+						h = 0
+					}
+					fillRect(gtx, image.Rect(x, h, x+1, centsMeterHeight-h), Colors.MeterTick)
+				}
+
+				if ok {
+					x := centsX(cents)
+					fillRect(gtx, image.Rect(x-1, -3, x+2, centsMeterHeight+3), centsColor(cents))
+				}
+				return layout.Dimensions{Size: image.Pt(centsMeterWidth, centsMeterHeight)}
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text, c := "-- ¢", Colors.Disabled
+				if ok {
+					text, c = fmt.Sprintf("%+.0f ¢", cents), centsColor(cents)
+				}
+				label := material.Body2(r.theme, text)
+				label.Color = c
+				return label.Layout(gtx)
+			}),
+		)
+	})
+}
+
+// centsX places a deviation on the meter
+func centsX(cents float64) int {
+	cents = math.Max(-centsMeterRange, math.Min(centsMeterRange, cents))
+	return int(math.Round((cents + centsMeterRange) / (2 * centsMeterRange) * (centsMeterWidth - 1)))
+}
+
+// centsColor shades a deviation green when in tune through to red
+func centsColor(cents float64) color.NRGBA {
+	off := math.Abs(cents)
+	switch {
+	case off <= song.InTuneCents:
+		return Colors.NotePerfect
+	case off <= 15:
+		return Colors.NoteGood
+	case off <= 30:
+		return Colors.NoteOK
+	default:
+		return Colors.NoteMiss
+	}
+}
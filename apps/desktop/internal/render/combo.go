@@ -0,0 +1,122 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/core/song"
+)
+
+// Combo display timings and sizes
+const (
+	comboNearNext    = 3   // Notes before the next multiplier at which the badge starts to glow
+	comboBrokenTime  = 1.5 // Seconds "Combo broken" stays up
+	comboBrokenShake = 4   // dp
+)
+
+// drawCombo shows the combo and the multiplier it earns as a badge that
+// glows when the next multiplier is close and burns at the highest, and
+// says so for a moment when a combo is broken
+func (r *TabRenderer) drawCombo(gtx layout.Context, state *song.GameState) layout.Dimensions {
+	now := time.Now()
+	if since := now.Sub(state.ComboBrokenAt).Seconds(); state.BrokenCombo > 0 && since < comboBrokenTime {
+		return r.drawComboBroken(gtx, state.BrokenCombo, since)
+	}
+
+	comboColor := Colors.Text
+	if state.Multiplier() > 1 {
+		comboColor = Colors.Combo
+	}
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(r.theme, fmt.Sprintf("Combo: %d", state.Combo))
+			label.Color = comboColor
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if state.Multiplier() <= 1 {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return r.drawMultiplier(gtx, state, now)
+			})
+		}),
+	)
+}
+
+// drawMultiplier draws the multiplier badge
+func (r *TabRenderer) drawMultiplier(gtx layout.Context, state *song.GameState, now time.Time) layout.Dimensions {
+	pulse := float32(0.5 + 0.5*math.Sin(float64(now.UnixMilli())/1000*2*math.Pi*2))
+	next := state.NextMultiplierAt()
+	glowing := next > 0 && next-state.Combo <= comboNearNext
+	burning := next == 0
+
+	return layout.Stack{Alignment: layout.Center}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			size := gtx.Constraints.Min
+			radius := size.Y / 3
+			if burning {
+				r.drawFlames(gtx, size, now)
+			}
+			if glowing || burning {
+				g := gtx.Dp(unit.Dp(4))
+				glow := image.Rect(-g, -g, size.X+g, size.Y+g)
+				paint.FillShape(gtx.Ops, withAlpha(Colors.Combo, uint8(40+80*pulse)), clip.UniformRRect(glow, radius+g).Op(gtx.Ops))
+			}
+			paint.FillShape(gtx.Ops, Colors.Combo, clip.UniformRRect(image.Rectangle{Max: size}, radius).Op(gtx.Ops))
+			return layout.Dimensions{Size: size}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Left: unit.Dp(6), Right: unit.Dp(6), Top: unit.Dp(1), Bottom: unit.Dp(1)}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body1(r.theme, fmt.Sprintf("x%d", state.Multiplier()))
+				label.Color = Colors.Background
+				return label.Layout(gtx)
+			})
+		}),
+	)
+}
+
+// drawFlames draws flickering flames rising from the top of a badge
+func (r *TabRenderer) drawFlames(gtx layout.Context, size image.Point, now time.Time) {
+	const flames = 3
+	t := float64(now.UnixMilli()) / 1000
+	for i := 0; i < flames; i++ {
+		w := float32(size.X) / flames
+		x := w * (float32(i) + 0.5)
+		flicker := float32(0.75 + 0.25*math.Sin(t*11+float64(i)*2.1))
+		h := float32(size.Y) * 0.9 * flicker
+		base := float32(size.Y) / 2
+
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(f32.Pt(x-w/2, base))
+		p.QuadTo(f32.Pt(x-w/2, base-h*0.6), f32.Pt(x, base-h))
+		p.QuadTo(f32.Pt(x+w/2, base-h*0.6), f32.Pt(x+w/2, base))
+		p.Close()
+		paint.FillShape(gtx.Ops, Colors.Flame, clip.Outline{Path: p.End()}.Op())
+	}
+}
+
+// drawComboBroken says a combo was broken, shaking and then fading
+func (r *TabRenderer) drawComboBroken(gtx layout.Context, combo int, since float64) layout.Dimensions {
+	fade := 1 - since/comboBrokenTime
+	shake := 0
+	if since < comboBrokenTime/3 {
+		shake = int(float64(gtx.Dp(comboBrokenShake)) * fade * math.Sin(since*40))
+	}
+	defer op.Offset(image.Pt(shake, 0)).Push(gtx.Ops).Pop()
+	label := material.Body1(r.theme, fmt.Sprintf("Combo broken! (%d)", combo))
+	label.Color = withAlpha(Colors.Error, uint8(255*fade))
+	return label.Layout(gtx)
+}
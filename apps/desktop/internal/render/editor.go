@@ -0,0 +1,160 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/editor"
+)
+
+// editorPlayLineX puts the editing cursor in the middle of the view
+const editorPlayLineX = 0.5
+
+// beatsPerBar is used to emphasize bar lines in the editor grid
+const beatsPerBar = 4
+
+// editorGeometry remembers the last editor layout so pointer
+// positions can be mapped back to beats and strings
+type editorGeometry struct {
+	playLineX       float32
+	tabTop          float32
+	pixelsPerSecond float32
+	cursorTime      float64
+}
+
+// LayoutEditor renders the chart editor around the editor cursor
+func (r *TabRenderer) LayoutEditor(gtx layout.Context, ed *editor.Editor) layout.Dimensions {
+	width := float32(gtx.Constraints.Max.X)
+	height := float32(gtx.Constraints.Max.Y)
+	r.setMetric(gtx)
+
+	playLineX := width * editorPlayLineX
+	pixelsPerSecond := r.pixelsPerBeat() * float32(ed.Song.BPM/60.0)
+	tabTop := r.headerBottom()
+	stringCount := ed.StringCount()
+	cursorTime := ed.CursorTime()
+	r.stringCount = stringCount
+
+	r.editorGeom = editorGeometry{
+		playLineX:       playLineX,
+		tabTop:          tabTop,
+		pixelsPerSecond: pixelsPerSecond,
+		cursorTime:      cursorTime,
+	}
+
+	r.drawBackground(gtx, int(width), int(height))
+	r.drawEditorGrid(gtx, ed, width, tabTop, stringCount)
+	r.drawStrings(gtx, int(width), tabTop, stringCount)
+	r.drawEditorCursor(gtx, ed, playLineX, tabTop)
+	r.drawNotes(gtx, ed.Song, cursorTime, playLineX, tabTop, pixelsPerSecond, math.Inf(-1))
+	r.drawStringLabels(gtx, tabTop, ed.Song.GetTuning())
+	r.drawEditorStatus(gtx, ed, tabTop+r.stringSpacing()*float32(stringCount)+20)
+	describeArea(gtx, image.Pt(int(width), int(height)), describeEditor(ed))
+
+	return layout.Dimensions{Size: image.Pt(int(width), int(height))}
+}
+
+// EditorPosition maps a point in the editor view to the nearest beat and string
+func (r *TabRenderer) EditorPosition(ed *editor.Editor, x, y float32) (beat float64, str int) {
+	g := r.editorGeom
+	if g.pixelsPerSecond <= 0 {
+		return ed.CursorBeat, ed.CursorString
+	}
+	t := g.cursorTime + float64((x-g.playLineX)/g.pixelsPerSecond)
+	beat = ed.Song.NoteBeat(t)
+	row := int(math.Floor(float64((y - g.tabTop) / r.stringSpacing())))
+	return beat, r.stringRow(row)
+}
+
+func (r *TabRenderer) drawEditorGrid(gtx layout.Context, ed *editor.Editor, width, tabTop float32, stringCount int) {
+	g := r.editorGeom
+	top := int(tabTop)
+	bottom := int(tabTop + r.stringSpacing()*float32(stringCount))
+
+	// Visible beat range
+	leftBeat := ed.Song.NoteBeat(g.cursorTime - float64(g.playLineX/g.pixelsPerSecond))
+	rightBeat := ed.Song.NoteBeat(g.cursorTime + float64((width-g.playLineX)/g.pixelsPerSecond))
+
+	snap := ed.Snap()
+	first := math.Max(0, math.Floor(leftBeat/snap)*snap)
+	for beat := first; beat <= rightBeat; beat += snap {
+		x := int(g.playLineX + float32(ed.Song.NoteTime(beat)-g.cursorTime)*g.pixelsPerSecond)
+		if x < 50 {
+			continue
+		}
+
+		lineColor := Colors.GridSub
+		lineWidth := 1
+		if isWholeBeat(beat) {
+			lineColor = Colors.GridBeat
+			if int(math.Round(beat))%beatsPerBar == 0 {
+				lineColor = Colors.GridBar
+				lineWidth = 2
+			}
+		}
+		fillRect(gtx, image.Rect(x, top, x+lineWidth, bottom), lineColor)
+	}
+}
+
+func (r *TabRenderer) drawEditorCursor(gtx layout.Context, ed *editor.Editor, playLineX, tabTop float32) {
+	x := int(playLineX)
+	y := int(tabTop + float32(r.stringRow(ed.CursorString))*r.stringSpacing() + r.stringSpacing()/2)
+	half := int(r.stringSpacing() / 2)
+
+	fillRect(gtx, image.Rect(x-half, y-half, x+half, y+half), Colors.CursorFill)
+
+	// Outline
+	fillRect(gtx, image.Rect(x-half, y-half, x+half, y-half+2), Colors.Cursor)
+	fillRect(gtx, image.Rect(x-half, y+half-2, x+half, y+half), Colors.Cursor)
+	fillRect(gtx, image.Rect(x-half, y-half, x-half+2, y+half), Colors.Cursor)
+	fillRect(gtx, image.Rect(x+half-2, y-half, x+half, y+half), Colors.Cursor)
+}
+
+func (r *TabRenderer) drawEditorStatus(gtx layout.Context, ed *editor.Editor, y float32) {
+	tuning := ed.Song.GetTuning()
+	stringName := "?"
+	if ed.CursorString < len(tuning) {
+		stringName = tuning[ed.CursorString].Note
+	}
+
+	status := fmt.Sprintf("Beat %.2f  •  String %s  •  Fret %d  •  Snap %s  •  %d notes",
+		ed.CursorBeat, stringName, ed.Fret, ed.SnapLabel(), len(ed.Song.Notes))
+	if ed.Dirty {
+		status += "  •  modified"
+	}
+
+	lines := []struct {
+		text string
+		c    color.NRGBA
+	}{
+		{status, Colors.Title},
+		{ed.Path, Colors.Disabled},
+		{"←/→ move  ↑/↓ string  0-9 fret  Enter place  Del delete  Shift+arrows move note  [/] snap  P play  Ctrl+P preview  Ctrl+S save  Ctrl+E export  Esc back",
+			Colors.Disabled},
+	}
+	for i, line := range lines {
+		offset := op.Offset(image.Pt(20, int(y)+i*24)).Push(gtx.Ops)
+		label := material.Body2(r.theme, line.text)
+		label.Color = line.c
+		label.Layout(gtx)
+		offset.Pop()
+	}
+}
+
+func isWholeBeat(beat float64) bool {
+	return math.Abs(beat-math.Round(beat)) < 0.001
+}
+
+func fillRect(gtx layout.Context, rect image.Rectangle, c color.NRGBA) {
+	defer clip.Rect(rect).Push(gtx.Ops).Pop()
+	paint.ColorOp{Color: c}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+}
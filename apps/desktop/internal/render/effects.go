@@ -0,0 +1,79 @@
+package render
+
+import (
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+
+	"guitargame/core/song"
+)
+
+// Note animation timings in song seconds, and sizes relative to a note
+const (
+	approachTime   = 0.5  // Notes start growing this long before the play line
+	approachGrowth = 0.25 // How much bigger a note is when it reaches the line
+	flashTime      = 0.35 // How long a hit's ring and particles last
+	flashParticles = 8
+	shakeTime      = 0.35 // How long a missed note shakes
+	shakeAmplitude = 6    // dp
+	shakeCycles    = 4
+)
+
+// noteRadius is the radius of a note circle in dp
+const noteRadius = 18
+
+// approachScale returns how much to enlarge a note that hasn't been
+// played yet as it nears the play line
+func approachScale(note *song.TabNote, currentTime float64) float32 {
+	dt := note.Time - currentTime
+	if note.Hit || dt < 0 || dt > approachTime {
+		return 1
+	}
+	return 1 + approachGrowth*float32(1-dt/approachTime)
+}
+
+// missShake returns how far sideways to move a note that was just
+// missed, settling as the shake ends
+func (r *TabRenderer) missShake(note *song.TabNote, judgedTime float64) float32 {
+	since := judgedTime - note.HitTime
+	if !note.Hit || note.HitQuality != song.HitMiss || since < 0 || since > shakeTime {
+		return 0
+	}
+	fade := 1 - since/shakeTime
+	return r.dp(shakeAmplitude) * float32(fade*math.Sin(since/shakeTime*shakeCycles*2*math.Pi))
+}
+
+// drawHitFlashes draws an expanding ring and a burst of particles on the
+// play line for each note just hit, to keep the eye on the hit point
+func (r *TabRenderer) drawHitFlashes(gtx layout.Context, s *song.Song, judgedTime float64, playLineX, tabTop float32) {
+	for i := range s.Notes {
+		note := &s.Notes[i]
+		since := judgedTime - note.HitTime
+		if !note.Hit || note.HitQuality == song.HitMiss || since < 0 || since > flashTime {
+			continue
+		}
+		progress := float32(since / flashTime)
+		center := f32.Pt(playLineX, tabTop+float32(r.stringRow(note.String))*r.stringSpacing()+r.stringSpacing()/2)
+		c := withAlpha(QualityColor(note.HitQuality), uint8(255*(1-progress)))
+
+		// Ring
+		radius := r.dp(noteRadius) * (1 + 1.5*progress)
+		var ring clip.Path
+		ring.Begin(gtx.Ops)
+		ring.MoveTo(center.Add(f32.Pt(radius, 0)))
+		ring.ArcTo(center, center, 2*math.Pi)
+		paint.FillShape(gtx.Ops, c, clip.Stroke{Path: ring.End(), Width: r.dp(3)}.Op())
+
+		// Particles flying outward
+		distance := r.dp(noteRadius) * (1 + 2*progress)
+		size := r.dp(3) * (1 - progress/2)
+		for p := 0; p < flashParticles; p++ {
+			angle := 2 * math.Pi * (float64(p) + 0.5) / flashParticles
+			at := center.Add(f32.Pt(float32(math.Cos(angle))*distance, float32(math.Sin(angle))*distance))
+			r.drawNoteCircle(gtx, at.X, at.Y, size, c)
+		}
+	}
+}
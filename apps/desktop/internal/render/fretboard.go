@@ -0,0 +1,82 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"gioui.org/layout"
+
+	"guitargame/core/song"
+)
+
+// Fretboard geometry in pixels
+const (
+	fretboardFrets      = 12
+	fretboardLabelWidth = 30
+	fretboardFretWidth  = 44
+	fretboardRowHeight  = 22
+)
+
+// fretMarkers are the frets with inlay dots
+var fretMarkers = []int{3, 5, 7, 9, 12}
+
+// DrawFretboard draws the neck up to the 12th fret with every position of
+// a scale or arpeggio built on a root (a pitch class, 0 = C) marked and
+// named, roots picked out. pitchOffset is how far the sounding notes sit
+// above the frets, from a capo or transposition.
+func (r *TabRenderer) DrawFretboard(gtx layout.Context, tuning song.Tuning, sc song.Scale, root, pitchOffset int) layout.Dimensions {
+	rows := len(tuning)
+	neck := image.Rect(fretboardLabelWidth+fretboardFretWidth, fretboardRowHeight/2, fretboardLabelWidth+(fretboardFrets+1)*fretboardFretWidth, rows*fretboardRowHeight+fretboardRowHeight/2)
+	size := image.Pt(neck.Max.X+fretboardFretWidth/2, neck.Max.Y+fretboardRowHeight)
+	describeArea(gtx, size, describeScale(sc, root))
+
+	fillRect(gtx, neck, Colors.Fretboard)
+	for _, f := range fretMarkers {
+		x := fretboardLabelWidth + f*fretboardFretWidth + fretboardFretWidth/2
+		fillRect(gtx, image.Rect(x-3, neck.Max.Y+4, x+3, neck.Max.Y+10), Colors.FretMarker)
+	}
+	for f := 0; f <= fretboardFrets; f++ {
+		x := fretboardLabelWidth + (f+1)*fretboardFretWidth
+		w := 1
+		if f == 0 {
+			w = 3 // The nut
+		}
+		fillRect(gtx, image.Rect(x-w, neck.Min.Y, x+w, neck.Max.Y), Colors.Fret)
+	}
+
+	for str := range tuning {
+		row := str
+		if r.MirrorStrings {
+			row = rows - 1 - str
+		}
+		y := row*fretboardRowHeight + fretboardRowHeight
+		fillRect(gtx, image.Rect(neck.Min.X, y, neck.Max.X, y+1), Colors.String)
+		r.drawGraphLabel(gtx, image.Pt(4, y-fretboardRowHeight/2+2), tuning[str].Note)
+
+		open := tuning[str].MIDINote() + pitchOffset
+		for f := 0; f <= fretboardFrets; f++ {
+			pc := (open + f) % 12
+			if !sc.Contains(root, pc) {
+				continue
+			}
+			c := Colors.ScaleNote
+			if pc == root {
+				c = Colors.ScaleRootNote
+			}
+			x := float32(fretboardLabelWidth + f*fretboardFretWidth + fretboardFretWidth/2)
+			r.drawNoteCircle(gtx, x, float32(y), fretboardRowHeight/2-1, c)
+			r.drawNoteLabel(gtx, x, float32(y), song.PitchClassName(pc), Colors.NoteText, true)
+		}
+	}
+	return layout.Dimensions{Size: size}
+}
+
+// describeScale reads out the notes a fretboard shows
+func describeScale(sc song.Scale, root int) string {
+	var names []string
+	for _, i := range sc.Intervals {
+		names = append(names, song.PitchClassName(root+i))
+	}
+	return fmt.Sprintf("%s %s on the fretboard: %s", song.PitchClassName(root), sc.Name, strings.Join(names, ", "))
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"gioui.org/layout"
+)
+
+// Ghost bar geometry in pixels
+const (
+	ghostBarWidth  = 200
+	ghostBarHeight = 12
+)
+
+// DrawGhostBar races the score against a personal best: a translucent bar
+// for where the best run's score was at this point in the song, with the
+// current score drawn through it, both on the scale of the best final score
+func (r *TabRenderer) DrawGhostBar(gtx layout.Context, score, ghost, best int) layout.Dimensions {
+	size := image.Pt(ghostBarWidth, ghostBarHeight)
+	describeArea(gtx, size, fmt.Sprintf("Score %d, personal best had %d by now", score, ghost))
+	fillRect(gtx, image.Rectangle{Max: size}, Colors.MeterTrack)
+
+	scale := float64(max(best, score, 1))
+	width := func(s int) int { return int(math.Round(float64(s) / scale * ghostBarWidth)) }
+	fillRect(gtx, image.Rect(0, 0, width(ghost), ghostBarHeight), Colors.Ghost)
+	c := Colors.GhostAhead
+	if score < ghost {
+		c = Colors.GhostBehind
+	}
+	fillRect(gtx, image.Rect(0, ghostBarHeight/3, width(score), ghostBarHeight-ghostBarHeight/3), c)
+	return layout.Dimensions{Size: size}
+}
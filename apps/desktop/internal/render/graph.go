@@ -0,0 +1,170 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget/material"
+
+	"guitargame/core/song"
+)
+
+// Tempo graph geometry in pixels
+const (
+	tempoGraphHeight   = 160
+	tempoGraphMaxBar   = 40 // Widest a lap's bar is drawn
+	tempoGraphAxisText = 60 // Room left of the bars for tempo labels
+)
+
+// Timing scatter geometry: height in pixels, and the offset in seconds
+// either side of the beat it shows; hits further out are pinned to the edge
+const (
+	timingScatterHeight = 80
+	timingScatterRange  = 0.15
+	timingDotSize       = 4
+)
+
+// Timing histogram geometry: height in pixels, and the width in seconds
+// of each bar; it spans the same offsets as the scatter
+const (
+	timingHistogramHeight = 80
+	timingHistogramBin    = 0.01
+)
+
+// TempoLap is one time round a speed trainer loop
+type TempoLap struct {
+	BPM      float64
+	Accuracy float64
+	Passed   bool // Cleared the target accuracy, so the tempo went up
+}
+
+// DrawTempoGraph charts the tempo of each lap of a speed trainer, with
+// cleared laps highlighted and marked with a tick
+func (r *TabRenderer) DrawTempoGraph(gtx layout.Context, laps []TempoLap) layout.Dimensions {
+	width := gtx.Constraints.Max.X
+	size := image.Pt(width, tempoGraphHeight)
+	if len(laps) == 0 {
+		return layout.Dimensions{Size: size}
+	}
+
+	lo, hi := laps[0].BPM, laps[0].BPM
+	for _, l := range laps {
+		lo, hi = math.Min(lo, l.BPM), math.Max(hi, l.BPM)
+	}
+	// Start the axis a little below the slowest lap so it still has a bar
+	lo = math.Max(0, lo-10)
+	if hi-lo < 20 {
+		hi = lo + 20
+	}
+
+	plotWidth := width - tempoGraphAxisText
+	barWidth := min(tempoGraphMaxBar, plotWidth/len(laps))
+	barHeight := func(bpm float64) int {
+		return int((bpm - lo) / (hi - lo) * tempoGraphHeight)
+	}
+
+	fillRect(gtx, image.Rect(tempoGraphAxisText-2, 0, tempoGraphAxisText, tempoGraphHeight), Colors.GraphAxis)
+	fillRect(gtx, image.Rect(tempoGraphAxisText-2, tempoGraphHeight-2, width, tempoGraphHeight), Colors.GraphAxis)
+	for _, bpm := range []float64{lo, hi} {
+		r.drawGraphLabel(gtx, image.Pt(0, tempoGraphHeight-barHeight(bpm)-10), fmt.Sprintf("%.0f", bpm))
+	}
+
+	for i, l := range laps {
+		x := tempoGraphAxisText + i*barWidth
+		top := tempoGraphHeight - barHeight(l.BPM)
+		c := Colors.GraphFailed
+		if l.Passed {
+			c = Colors.GraphPassed
+		}
+		fillRect(gtx, image.Rect(x+2, top, x+barWidth-2, tempoGraphHeight-2), c)
+		if l.Passed && barWidth >= hitSymbolSize+4 {
+			drawHitSymbol(gtx, float32(x)+float32(barWidth)/2, float32(top)-hitSymbolSize, song.HitPerfect, Colors.HitSymbol)
+		}
+	}
+	return layout.Dimensions{Size: size}
+}
+
+// DrawTimingScatter plots how early or late each hit was across a song
+// of the given length, late above the centre line and early below, with
+// the mean marked and a band one standard deviation either side of it
+func (r *TabRenderer) DrawTimingScatter(gtx layout.Context, hits []song.HitTiming, duration float64) layout.Dimensions {
+	width := gtx.Constraints.Max.X
+	size := image.Pt(width, timingScatterHeight)
+	if len(hits) == 0 || duration <= 0 {
+		return layout.Dimensions{Size: size}
+	}
+
+	plotWidth := width - tempoGraphAxisText
+	offsetY := func(offset float64) int {
+		offset = math.Max(-timingScatterRange, math.Min(timingScatterRange, offset))
+		return int((0.5 - offset/timingScatterRange/2) * timingScatterHeight)
+	}
+
+	mean, stddev := song.TimingSpread(hits)
+	fillRect(gtx, image.Rect(tempoGraphAxisText, offsetY(mean+stddev), width, offsetY(mean-stddev)+1), Colors.GraphSpread)
+	fillRect(gtx, image.Rect(tempoGraphAxisText-2, 0, tempoGraphAxisText, timingScatterHeight), Colors.GraphAxis)
+	fillRect(gtx, image.Rect(tempoGraphAxisText, offsetY(0), width, offsetY(0)+1), Colors.GraphAxis)
+	fillRect(gtx, image.Rect(tempoGraphAxisText, offsetY(mean), width, offsetY(mean)+1), Colors.GraphMean)
+	r.drawGraphLabel(gtx, image.Pt(0, 0), fmt.Sprintf("late %.0f", timingScatterRange*1000))
+	r.drawGraphLabel(gtx, image.Pt(0, timingScatterHeight-14), fmt.Sprintf("early %.0f", timingScatterRange*1000))
+
+	for _, h := range hits {
+		x := tempoGraphAxisText + int(math.Min(h.Time/duration, 1)*float64(plotWidth-timingDotSize))
+		y := offsetY(h.Offset) - timingDotSize/2
+		fillRect(gtx, image.Rect(x, y, x+timingDotSize, y+timingDotSize), Colors.GraphHit)
+	}
+	return layout.Dimensions{Size: size}
+}
+
+// DrawTimingHistogram counts hits by how early or late they were, early
+// on the left and late on the right, each bar colored by the quality its
+// offset scores within the timing windows
+func (r *TabRenderer) DrawTimingHistogram(gtx layout.Context, hits []song.HitTiming, windows song.TimingWindows) layout.Dimensions {
+	width := gtx.Constraints.Max.X
+	size := image.Pt(width, timingHistogramHeight)
+	bins := make([]int, int(math.Round(2*timingScatterRange/timingHistogramBin)))
+	if len(hits) == 0 {
+		return layout.Dimensions{Size: size}
+	}
+
+	tallest := 0
+	for _, h := range hits {
+		offset := math.Max(-timingScatterRange, math.Min(timingScatterRange, h.Offset))
+		i := min(len(bins)-1, int((offset+timingScatterRange)/timingHistogramBin))
+		bins[i]++
+		tallest = max(tallest, bins[i])
+	}
+
+	plotWidth := width - tempoGraphAxisText
+	barWidth := plotWidth / len(bins)
+	plotHeight := timingHistogramHeight - 16 // Room below for the labels
+	centre := tempoGraphAxisText + barWidth*len(bins)/2
+	fillRect(gtx, image.Rect(tempoGraphAxisText, plotHeight, tempoGraphAxisText+barWidth*len(bins), plotHeight+2), Colors.GraphAxis)
+	fillRect(gtx, image.Rect(centre-1, 0, centre+1, plotHeight), Colors.GraphAxis)
+	r.drawGraphLabel(gtx, image.Pt(0, 0), fmt.Sprintf("most %d", tallest))
+	r.drawGraphLabel(gtx, image.Pt(tempoGraphAxisText, plotHeight+2), fmt.Sprintf("early %.0f", timingScatterRange*1000))
+	r.drawGraphLabel(gtx, image.Pt(centre+4, plotHeight+2), "on the beat")
+	r.drawGraphLabel(gtx, image.Pt(tempoGraphAxisText+barWidth*len(bins)-50, plotHeight+2), fmt.Sprintf("late %.0f", timingScatterRange*1000))
+
+	for i, n := range bins {
+		if n == 0 {
+			continue
+		}
+		mid := (float64(i)+0.5)*timingHistogramBin - timingScatterRange
+		x := tempoGraphAxisText + i*barWidth
+		top := plotHeight - n*plotHeight/tallest
+		fillRect(gtx, image.Rect(x+1, top, x+barWidth-1, plotHeight), QualityColor(windows.Judge(mid)))
+	}
+	return layout.Dimensions{Size: size}
+}
+
+// drawGraphLabel writes a small axis label at a point
+func (r *TabRenderer) drawGraphLabel(gtx layout.Context, at image.Point, txt string) {
+	defer op.Offset(at).Push(gtx.Ops).Pop()
+	label := material.Caption(r.theme, txt)
+	label.Color = Colors.NoteName
+	label.Layout(gtx)
+}
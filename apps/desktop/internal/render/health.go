@@ -0,0 +1,33 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"gioui.org/layout"
+)
+
+// Health meter geometry in pixels
+const (
+	healthMeterWidth  = 120
+	healthMeterHeight = 12
+)
+
+// drawHealthMeter draws how much health is left in fail mode, turning
+// from green through yellow to red as it drains
+func (r *TabRenderer) drawHealthMeter(gtx layout.Context, health float64) layout.Dimensions {
+	size := image.Pt(healthMeterWidth, healthMeterHeight)
+	describeArea(gtx, size, fmt.Sprintf("Health %.0f%%", health*100))
+	fillRect(gtx, image.Rectangle{Max: size}, Colors.MeterTrack)
+
+	c := Colors.HealthHigh
+	switch {
+	case health < 0.25:
+		c = Colors.HealthLow
+	case health < 0.5:
+		c = Colors.HealthMid
+	}
+	fillRect(gtx, image.Rect(0, 0, int(math.Round(health*healthMeterWidth)), healthMeterHeight), c)
+	return layout.Dimensions{Size: size}
+}
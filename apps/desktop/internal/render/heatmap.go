@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+
+	"guitargame/core/song"
+)
+
+// Position heat map geometry in pixels
+const (
+	heatLabelWidth = 50
+	heatCellWidth  = 64
+	heatCellHeight = 18
+)
+
+// DrawPositionHeatMap tabulates hits by string (rows, highest first) and
+// fret region (columns), each cell coloured from green to red by its
+// share of misses, with totals for each string and region
+func (r *TabRenderer) DrawPositionHeatMap(gtx layout.Context, stats song.PositionStats, tuning song.Tuning) layout.Dimensions {
+	cols := len(song.FretRegions) + 1
+	rows := len(stats.Cells) + 1
+	size := image.Pt(heatLabelWidth+cols*heatCellWidth, (rows+1)*heatCellHeight)
+	describeArea(gtx, size, describePositions(stats, tuning))
+
+	for i, region := range song.FretRegions {
+		r.drawGraphLabel(gtx, image.Pt(heatLabelWidth+i*heatCellWidth+4, 0), region.Name)
+	}
+	r.drawGraphLabel(gtx, image.Pt(heatLabelWidth+(cols-1)*heatCellWidth+4, 0), "All")
+
+	cell := func(row, col int, c song.NoteCount) {
+		x, y := heatLabelWidth+col*heatCellWidth, (row+1)*heatCellHeight
+		fillRect(gtx, image.Rect(x+1, y+1, x+heatCellWidth-1, y+heatCellHeight-1), heatColor(c))
+		if c.Total() > 0 {
+			r.drawGraphLabel(gtx, image.Pt(x+4, y+1), fmt.Sprintf("%d/%d", c.Hit, c.Total()))
+		}
+	}
+	for str, cells := range stats.Cells {
+		name := "?"
+		if str < len(tuning) {
+			name = tuning[str].Note
+		}
+		r.drawGraphLabel(gtx, image.Pt(0, (str+1)*heatCellHeight+1), name)
+		for i, c := range cells {
+			cell(str, i, c)
+		}
+		cell(str, cols-1, stats.OnString(str))
+	}
+	r.drawGraphLabel(gtx, image.Pt(0, rows*heatCellHeight+1), "All")
+	var all song.NoteCount
+	for i := range song.FretRegions {
+		c := stats.InRegion(i)
+		cell(rows-1, i, c)
+		all.Hit += c.Hit
+		all.Missed += c.Missed
+	}
+	cell(rows-1, cols-1, all)
+	return layout.Dimensions{Size: size}
+}
+
+// heatColor shades a cell from the theme's HeatLow (no misses) to
+// HeatHigh (all missed)
+func heatColor(c song.NoteCount) color.NRGBA {
+	if c.Total() == 0 {
+		return Colors.HeatEmpty
+	}
+	missed := float64(c.Missed) / float64(c.Total())
+	mix := func(from, to uint8) uint8 {
+		return uint8(float64(from) + (float64(to)-float64(from))*missed)
+	}
+	low, high := Colors.HeatLow, Colors.HeatHigh
+	return color.NRGBA{R: mix(low.R, high.R), G: mix(low.G, high.G), B: mix(low.B, high.B), A: 255}
+}
+
+// describePositions reads out the string with the most misses
+func describePositions(stats song.PositionStats, tuning song.Tuning) string {
+	worst, worstMissed := -1, 0
+	for str := range stats.Cells {
+		if m := stats.OnString(str).Missed; m > worstMissed {
+			worst, worstMissed = str, m
+		}
+	}
+	if worst < 0 {
+		return "Hits by string and fret: no misses"
+	}
+	return fmt.Sprintf("Hits by string and fret: most misses on the %s, %d", stringName(tuning, worst), worstMissed)
+}
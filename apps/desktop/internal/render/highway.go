@@ -0,0 +1,153 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+
+	"guitargame/core/song"
+)
+
+// Highway is a view of a song's notes moving towards where they're played
+type Highway interface {
+	Layout(gtx layout.Context, state *song.GameState) layout.Dimensions
+	// PlayLinePos returns where across a view of the given width hit
+	// feedback is shown
+	PlayLinePos(width float32) float32
+	// FeedbackY returns the height at which hit feedback for a string is shown
+	FeedbackY(str int) float32
+}
+
+// Fretboard highway geometry in dp
+const (
+	neckRowHeight  = 24
+	neckMinFrets   = 12
+	neckSideMargin = 30
+	neckNoteRadius = 15
+)
+
+// Colors of each string on the fretboard highway, from the lowest
+var stringColors = []color.NRGBA{
+	{R: 230, G: 70, B: 70, A: 255},   // Red
+	{R: 240, G: 210, B: 60, A: 255},  // Yellow
+	{R: 70, G: 150, B: 255, A: 255},  // Blue
+	{R: 255, G: 140, B: 40, A: 255},  // Orange
+	{R: 80, G: 210, B: 100, A: 255},  // Green
+	{R: 190, G: 100, B: 230, A: 255}, // Purple
+	{R: 120, G: 220, B: 220, A: 255}, // Cyan
+}
+
+// FretboardHighway draws notes falling towards a fretboard at the bottom,
+// each above the fret it's played at. It shares the tab view's settings.
+type FretboardHighway struct {
+	*TabRenderer
+
+	height float32 // Of the last view drawn, to place hit feedback
+}
+
+// NewFretboardHighway creates a fretboard highway using a tab renderer's settings
+func NewFretboardHighway(tab *TabRenderer) *FretboardHighway {
+	return &FretboardHighway{TabRenderer: tab}
+}
+
+// Layout renders the complete fretboard highway
+func (r *FretboardHighway) Layout(gtx layout.Context, state *song.GameState) layout.Dimensions {
+	width := float32(gtx.Constraints.Max.X)
+	height := float32(gtx.Constraints.Max.Y)
+	r.height = height
+	r.setMetric(gtx)
+	s := state.Song
+	tuning := s.GetTuning()
+	r.stringCount = len(tuning)
+
+	frets := neckMinFrets
+	for i := range s.Notes {
+		frets = max(frets, s.Notes[i].Fret)
+	}
+	margin := r.dp(neckSideMargin)
+	laneWidth := (width - 2*margin) / float32(frets+1)
+	fretX := func(fret int) float32 {
+		x := margin + (float32(fret)+0.5)*laneWidth
+		if r.MirrorHighway {
+			x = width - x
+		}
+		return x
+	}
+
+	r.drawBackground(gtx, int(width), int(height))
+
+	// Lanes above each fret, then the neck with its frets and strings
+	top := r.dp(r.TabAreaPadding)
+	neckTop := r.neckTop()
+	neckBottom := neckTop + float32(len(tuning))*r.dp(neckRowHeight)
+	for f := 0; f <= frets; f++ {
+		x := fretX(f)
+		fillRect(gtx, image.Rect(int(x-laneWidth/2)+1, int(top), int(x+laneWidth/2)-1, int(neckTop)), Colors.FretLane)
+	}
+	fillRect(gtx, image.Rect(int(margin), int(neckTop), int(width-margin), int(neckBottom)), Colors.Fretboard)
+	for f := 0; f <= frets; f++ {
+		x := fretX(f) + laneWidth/2
+		if r.MirrorHighway {
+			x = fretX(f) - laneWidth/2
+		}
+		w := 1
+		if f == 0 {
+			w = 3 // The nut
+		}
+		fillRect(gtx, image.Rect(int(x)-w, int(neckTop), int(x)+w, int(neckBottom)), Colors.Fret)
+		r.drawGraphLabel(gtx, image.Pt(int(fretX(f))-6, int(neckBottom)+4), fmt.Sprintf("%d", f))
+	}
+	for str := range tuning {
+		y := r.FeedbackY(str)
+		fillRect(gtx, image.Rect(int(margin), int(y), int(width-margin), int(y)+2), Colors.String)
+		r.drawGraphLabel(gtx, image.Pt(4, int(y)-9), tuning[str].Note)
+	}
+	fillRect(gtx, image.Rect(int(margin), int(neckTop)-2, int(width-margin), int(neckTop)+1), Colors.PlayLine)
+
+	// Notes fall at the tempo's pace until they reach their string on the neck
+	pixelsPerSecond := r.scrollRate(s.BPM)
+	for i := range s.Notes {
+		note := &s.Notes[i]
+		y := r.FeedbackY(note.String) - float32(note.Time-state.CurrentTime)*pixelsPerSecond
+		if y < top || (note.Hit && y > neckBottom) {
+			continue
+		}
+		c := noteStateColor(note)
+		if !note.Hit {
+			c = stringColors[(len(tuning)-1-note.String)%len(stringColors)]
+		}
+		x := fretX(note.Fret)
+		r.drawNoteCircle(gtx, x, y, r.dp(neckNoteRadius)*approachScale(note, state.CurrentTime), c)
+		if r.NoteLabels == LabelNoteName {
+			r.drawNoteLabel(gtx, x, y, fmt.Sprintf("%s%d", s.NoteAt(note), s.OctaveAt(note)), Colors.NoteText, true)
+		} else {
+			r.drawFretNumber(gtx, x, y, note.Fret)
+		}
+		if note.Hit {
+			drawHitSymbol(gtx, x+r.dp(neckNoteRadius)+hitSymbolSize, y, note.HitQuality, Colors.HitSymbol)
+		}
+	}
+
+	r.drawFloatingText(gtx, state)
+
+	describeArea(gtx, image.Pt(int(width), int(height)), describePlay(state))
+
+	return layout.Dimensions{Size: image.Pt(int(width), int(height))}
+}
+
+// neckTop is where the fretboard starts, near the bottom of the view
+func (r *FretboardHighway) neckTop() float32 {
+	return r.height - float32(r.stringCount)*r.dp(neckRowHeight) - r.dp(30)
+}
+
+// PlayLinePos puts hit feedback in the middle of the neck
+func (r *FretboardHighway) PlayLinePos(width float32) float32 {
+	return width / 2
+}
+
+// FeedbackY returns the height of a string on the neck
+func (r *FretboardHighway) FeedbackY(str int) float32 {
+	return r.neckTop() + (float32(r.stringRow(str))+0.5)*r.dp(neckRowHeight)
+}
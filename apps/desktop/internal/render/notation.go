@@ -0,0 +1,229 @@
+package render
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+
+	"guitargame/core/song"
+)
+
+// NotationMode selects whether a standard-notation staff or the rhythms
+// alone are drawn
+type NotationMode int
+
+const (
+	NotationOff    NotationMode = iota
+	NotationAbove               // Staff above the tab
+	NotationOnly                // Staff instead of the tab
+	NotationRhythm              // Stems, beams and rests above the tab
+)
+
+func (m NotationMode) String() string {
+	switch m {
+	case NotationAbove:
+		return "Staff above tab"
+	case NotationOnly:
+		return "Staff only"
+	case NotationRhythm:
+		return "Rhythm above tab"
+	default:
+		return "Off"
+	}
+}
+
+// Next returns the following notation mode, wrapping around
+func (m NotationMode) Next() NotationMode {
+	return (m + 1) % (NotationRhythm + 1)
+}
+
+// Staff geometry in pixels
+const (
+	staffLineGap   = 10  // Distance between staff lines
+	notationHeight = 110 // Height of the notation lane, with room for ledger lines
+	noteheadRX     = 6
+	noteheadRY     = 4.5
+	stemLength     = 35
+)
+
+// clef describes how sounding pitches map onto the staff
+type clef struct {
+	middleLine int // Diatonic step of the middle staff line
+	octaveUp   bool
+	bass       bool
+}
+
+// clefFor picks the clef an instrument is written in. Bass and guitar
+// are both written an octave above where they sound.
+func clefFor(inst *song.Instrument) clef {
+	if inst == song.InstrumentGuitar {
+		return clef{middleLine: diatonicStep(71), octaveUp: true} // Treble clef, B4
+	}
+	return clef{middleLine: diatonicStep(50), octaveUp: true, bass: true} // Bass clef, D3
+}
+
+// Letter positions (C=0 ... B=6) and sharps for each pitch class
+var (
+	pitchLetter = [12]int{0, 0, 1, 1, 2, 3, 3, 4, 4, 5, 5, 6}
+	pitchSharp  = [12]bool{false, true, false, true, false, false, true, false, true, false, true, false}
+)
+
+// diatonicStep counts staff positions (lines and spaces) from C-1
+func diatonicStep(midiNote int) int {
+	return (midiNote/12)*7 + pitchLetter[midiNote%12]
+}
+
+// notationValue classifies a note's length in beats for drawing
+type notationValue int
+
+const (
+	valueSixteenth notationValue = iota
+	valueEighth
+	valueQuarter
+	valueHalf
+	valueWhole
+)
+
+// noteValue rounds a note's length in beats to the nearest plain note value
+func noteValue(beats float64) notationValue {
+	switch {
+	case beats >= 3:
+		return valueWhole
+	case beats >= 1.5:
+		return valueHalf
+	case beats >= 0.75:
+		return valueQuarter
+	case beats >= 0.375:
+		return valueEighth
+	default:
+		return valueSixteenth
+	}
+}
+
+// drawNotation draws the scrolling staff in a lane starting at top
+func (r *TabRenderer) drawNotation(gtx layout.Context, s *song.Song, currentTime float64, playLineX, top, pixelsPerSecond float32) {
+	width := float32(gtx.Constraints.Max.X)
+	cl := clefFor(s.Instrument())
+	middleY := top + notationHeight/2
+	stepY := func(step int) float32 {
+		return middleY - float32(step-cl.middleLine)*staffLineGap/2
+	}
+
+	// Five staff lines around the middle line
+	for i := -2; i <= 2; i++ {
+		y := int(middleY) + i*staffLineGap
+		fillRect(gtx, image.Rect(50, y, int(width)-10, y+1), Colors.Staff)
+	}
+	r.drawClef(gtx, cl, middleY)
+
+	timeAtLeft, timeAtRight := visibleTimes(width, playLineX, currentTime, pixelsPerSecond)
+
+	for i := range s.Notes {
+		note := &s.Notes[i]
+		if note.Time < timeAtLeft-1 || note.Time > timeAtRight+1 {
+			continue
+		}
+		x := playLineX + float32(note.Time-currentTime)*pixelsPerSecond
+		if x < 60 {
+			continue // Keep clear of the clef
+		}
+
+		midiNote := s.MIDINoteAt(note)
+		if cl.octaveUp {
+			midiNote += 12
+		}
+		step := diatonicStep(midiNote)
+		y := stepY(step)
+
+		// Ledger lines for notes above or below the staff
+		for ledger := cl.middleLine - 6; ledger >= step; ledger -= 2 {
+			r.drawLedger(gtx, x, stepY(ledger))
+		}
+		for ledger := cl.middleLine + 6; ledger <= step; ledger += 2 {
+			r.drawLedger(gtx, x, stepY(ledger))
+		}
+
+		beats := note.Duration * s.BPMAt(s.TimeToBeat(note.Time)) / 60
+		// Chart durations are usually shortened slightly for articulation
+		value := noteValue(beats / 0.9)
+		noteColor := noteStateColor(note)
+		r.drawNotehead(gtx, x, y, value >= valueHalf, noteColor)
+		if value != valueWhole {
+			r.drawStem(gtx, x, y, step < cl.middleLine, value, noteColor)
+		}
+		if pitchSharp[midiNote%12] {
+			r.drawNoteLabel(gtx, x-18, y, "#", Colors.Accidental, true)
+		}
+	}
+}
+
+// drawClef draws a simple F (bass) or G (treble) clef sign at the start of the staff
+func (r *TabRenderer) drawClef(gtx layout.Context, cl clef, middleY float32) {
+	x := float32(58)
+	if !cl.bass {
+		r.drawNoteLabel(gtx, x, middleY, "G", Colors.Staff, false)
+		return
+	}
+
+	// The F clef curls from the F line (one line above the middle) and
+	// has two dots either side of it
+	fLine := middleY - staffLineGap
+	var curl clip.Path
+	curl.Begin(gtx.Ops)
+	curl.MoveTo(f32.Pt(x-4, fLine))
+	curl.CubeTo(f32.Pt(x-4, fLine-12), f32.Pt(x+12, fLine-10), f32.Pt(x+10, fLine+4))
+	curl.CubeTo(f32.Pt(x+8, fLine+16), f32.Pt(x-2, fLine+26), f32.Pt(x-8, fLine+30))
+	paint.FillShape(gtx.Ops, Colors.Staff, clip.Stroke{Path: curl.End(), Width: 2.5}.Op())
+
+	r.drawNoteCircle(gtx, x-3, fLine+1, 3, Colors.Staff)
+	r.drawNoteCircle(gtx, x+16, fLine-staffLineGap/2, 1.5, Colors.Staff)
+	r.drawNoteCircle(gtx, x+16, fLine+staffLineGap/2, 1.5, Colors.Staff)
+}
+
+func (r *TabRenderer) drawLedger(gtx layout.Context, x, y float32) {
+	fillRect(gtx, image.Rect(int(x)-10, int(y), int(x)+11, int(y)+1), Colors.StaffLedger)
+}
+
+// drawNotehead draws an oval notehead; half and whole notes are hollow
+func (r *TabRenderer) drawNotehead(gtx layout.Context, x, y float32, hollow bool, c color.NRGBA) {
+	head := clip.Ellipse{
+		Min: image.Pt(int(x-noteheadRX), int(y-noteheadRY)),
+		Max: image.Pt(int(x+noteheadRX), int(y+noteheadRY)),
+	}
+	if !hollow {
+		paint.FillShape(gtx.Ops, c, head.Op(gtx.Ops))
+		return
+	}
+	paint.FillShape(gtx.Ops, c, clip.Stroke{Path: head.Path(gtx.Ops), Width: 2}.Op())
+}
+
+// drawStem draws the stem and any flags; notes low on the staff have
+// stems going up on the right, high notes down on the left
+func (r *TabRenderer) drawStem(gtx layout.Context, x, y float32, up bool, value notationValue, c color.NRGBA) {
+	stemX, dir := x+noteheadRX-1, float32(-1)
+	if !up {
+		stemX, dir = x-noteheadRX, 1
+	}
+	end := y + dir*stemLength
+	fillRect(gtx, image.Rect(int(stemX), int(min(y, end)), int(stemX)+2, int(max(y, end))), c)
+
+	flags := 0
+	switch value {
+	case valueEighth:
+		flags = 1
+	case valueSixteenth:
+		flags = 2
+	}
+	for i := 0; i < flags; i++ {
+		fy := end - dir*float32(i*7)
+		var flag clip.Path
+		flag.Begin(gtx.Ops)
+		flag.MoveTo(f32.Pt(stemX+1, fy))
+		flag.QuadTo(f32.Pt(stemX+10, fy-dir*6), f32.Pt(stemX+8, fy-dir*16))
+		paint.FillShape(gtx.Ops, c, clip.Stroke{Path: flag.End(), Width: 2}.Op())
+	}
+}
@@ -0,0 +1,62 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"time"
+
+	"gioui.org/layout"
+
+	"guitargame/apps/desktop/internal/history"
+)
+
+// Progress chart geometry in pixels
+const (
+	progressChartHeight = 60
+	progressWeekWidth   = 48
+	progressMarkHeight  = 3
+)
+
+// DrawProgressChart charts a song's practice week by week: a bar for the
+// time practiced, on the scale of the longest week, and a mark at the
+// week's accuracy from 0 at the bottom to 100% at the top
+func (r *TabRenderer) DrawProgressChart(gtx layout.Context, weeks []history.WeekProgress, longest time.Duration) layout.Dimensions {
+	size := image.Pt(len(weeks)*progressWeekWidth, progressChartHeight)
+	describeArea(gtx, size, describeProgress(weeks))
+	fillRect(gtx, image.Rect(0, progressChartHeight-2, size.X, progressChartHeight), Colors.GraphAxis)
+	if longest <= 0 {
+		return layout.Dimensions{Size: size}
+	}
+
+	for i, w := range weeks {
+		if w.Sessions == 0 {
+			continue
+		}
+		x := i * progressWeekWidth
+		h := int(math.Round(float64(w.Time) / float64(longest) * (progressChartHeight - 2)))
+		fillRect(gtx, image.Rect(x+6, progressChartHeight-2-h, x+progressWeekWidth-6, progressChartHeight-2), Colors.GraphFailed)
+		y := int(math.Round((1 - w.Accuracy/100) * (progressChartHeight - progressMarkHeight - 2)))
+		fillRect(gtx, image.Rect(x+2, y, x+progressWeekWidth-2, y+progressMarkHeight), Colors.GraphMean)
+	}
+	return layout.Dimensions{Size: size}
+}
+
+// describeProgress reads out the latest week practiced
+func describeProgress(weeks []history.WeekProgress) string {
+	for i := len(weeks) - 1; i >= 0; i-- {
+		w := weeks[i]
+		if w.Sessions == 0 {
+			continue
+		}
+		when := "this week"
+		switch ago := len(weeks) - 1 - i; {
+		case ago == 1:
+			when = "last week"
+		case ago > 1:
+			when = fmt.Sprintf("%d weeks ago", ago)
+		}
+		return fmt.Sprintf("Practice by week: %s, %.0f minutes at %.0f%% accuracy", when, w.Time.Minutes(), w.Accuracy)
+	}
+	return "Practice by week: none"
+}
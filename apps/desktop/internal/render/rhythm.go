@@ -0,0 +1,218 @@
+package render
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+
+	"guitargame/core/song"
+)
+
+// Rhythm lane geometry in pixels
+const (
+	rhythmHeight  = 60 // Height of the rhythm lane, with room for stems
+	beamThickness = 4
+	beamGap       = 7 // Between the two beams of sixteenths
+	beamStub      = 9 // Length of a second beam on a lone sixteenth
+)
+
+// rhythmGrid is the finest step rhythms are written in, in beats: a
+// sixteenth note
+const rhythmGrid = 0.25
+
+// restValues are the rests gaps are written with, longest first, in beats
+var restValues = []float64{1, 0.5, 0.25}
+
+// rhythmEvent is a note or rest of the rhythm lane
+type rhythmEvent struct {
+	beat   float64       // Start, in beats
+	length float64       // In beats
+	note   *song.TabNote // First note of the chord played; nil for a rest
+}
+
+// rhythmEvents writes a song's notes as rhythms on a sixteenth grid. A
+// chord counts once, each note lasts until the next unless it ends a good
+// while before, and the gap after one that does is filled with rests.
+func rhythmEvents(s *song.Song) []rhythmEvent {
+	var events []rhythmEvent
+	for i := 0; i < len(s.Notes); {
+		note := &s.Notes[i]
+		beat := quantizeBeat(s.TimeToBeat(note.Time))
+		j := i + 1
+		for j < len(s.Notes) && quantizeBeat(s.TimeToBeat(s.Notes[j].Time)) == beat {
+			j++
+		}
+
+		// Chart durations are usually shortened slightly for articulation
+		sounding := quantizeBeat((s.TimeToBeat(note.Time+note.Duration) - s.TimeToBeat(note.Time)) / 0.9)
+		sounding = max(sounding, rhythmGrid)
+		if j == len(s.Notes) {
+			events = append(events, rhythmEvent{beat: beat, length: sounding, note: note})
+			break
+		}
+
+		// Gaps shorter than an eighth are articulation rather than rests
+		next := quantizeBeat(s.TimeToBeat(s.Notes[j].Time))
+		if next-sounding-beat < 0.5 {
+			sounding = next - beat
+		}
+		events = append(events, rhythmEvent{beat: beat, length: sounding, note: note})
+		events = appendRests(events, beat+sounding, next)
+		i = j
+	}
+	return events
+}
+
+// appendRests fills the gap between two beats with the longest rests that
+// start on their own grid, so rests never cross a beat
+func appendRests(events []rhythmEvent, from, to float64) []rhythmEvent {
+	for from < to {
+		for _, length := range restValues {
+			fits := math.Mod(from, length) == 0 && from+length <= to
+			if fits || length == rhythmGrid {
+				events = append(events, rhythmEvent{beat: from, length: length})
+				from += length
+				break
+			}
+		}
+	}
+	return events
+}
+
+// quantizeBeat rounds a beat to the rhythm grid
+func quantizeBeat(beat float64) float64 {
+	return math.Round(beat/rhythmGrid) * rhythmGrid
+}
+
+// drawRhythm draws the notes' rhythms in a lane starting at top: stems
+// and flags, beams joining the notes shorter than a beat within each
+// beat, and rests in the gaps
+func (r *TabRenderer) drawRhythm(gtx layout.Context, s *song.Song, currentTime float64, playLineX, top, pixelsPerSecond float32) {
+	width := float32(gtx.Constraints.Max.X)
+	baseY := top + rhythmHeight - 12
+	fillRect(gtx, image.Rect(50, int(baseY), int(width)-10, int(baseY)+1), Colors.StaffLedger)
+
+	timeAtLeft, timeAtRight := visibleTimes(width, playLineX, currentTime, pixelsPerSecond)
+	xAt := func(beat float64) float32 {
+		return playLineX + float32(s.BeatToTime(beat)-currentTime)*pixelsPerSecond
+	}
+
+	events := rhythmEvents(s)
+	for i := 0; i < len(events); {
+		e := events[i]
+		if t := s.BeatToTime(e.beat); t < timeAtLeft-1 || t > timeAtRight+1 {
+			i++
+			continue
+		}
+		if e.note == nil {
+			r.drawRest(gtx, xAt(e.beat), baseY-stemLength/2, e.length)
+			i++
+			continue
+		}
+
+		// Notes shorter than a beat that start in the same beat are beamed
+		j := i + 1
+		for e.length < 1 && j < len(events) && events[j].note != nil && events[j].length < 1 &&
+			math.Floor(events[j].beat) == math.Floor(e.beat) {
+			j++
+		}
+		if j-i > 1 {
+			r.drawBeamed(gtx, events[i:j], xAt, baseY)
+		} else {
+			value := noteValue(e.length)
+			c := noteStateColor(e.note)
+			r.drawNotehead(gtx, xAt(e.beat), baseY, value >= valueHalf, c)
+			if value != valueWhole {
+				r.drawStem(gtx, xAt(e.beat), baseY, true, value, c)
+			}
+		}
+		i = j
+	}
+}
+
+// drawBeamed draws a group of eighths and sixteenths joined by a beam,
+// with a second beam between sixteenths
+func (r *TabRenderer) drawBeamed(gtx layout.Context, group []rhythmEvent, xAt func(float64) float32, baseY float32) {
+	stemX := make([]float32, len(group))
+	for i, e := range group {
+		x := xAt(e.beat)
+		c := noteStateColor(e.note)
+		r.drawNotehead(gtx, x, baseY, false, c)
+		r.drawStem(gtx, x, baseY, true, valueQuarter, c)
+		stemX[i] = x + noteheadRX - 1
+	}
+
+	beamY := baseY - stemLength
+	r.drawBeam(gtx, stemX[0], stemX[len(stemX)-1], beamY)
+	for i, e := range group {
+		if noteValue(e.length) != valueSixteenth {
+			continue
+		}
+		switch {
+		case i+1 < len(group) && noteValue(group[i+1].length) == valueSixteenth:
+			r.drawBeam(gtx, stemX[i], stemX[i+1], beamY+beamGap)
+		case i > 0 && noteValue(group[i-1].length) == valueSixteenth:
+			// Joined by the beam from the previous note
+		case i+1 < len(group):
+			r.drawBeam(gtx, stemX[i], towards(stemX[i], stemX[i+1], beamStub), beamY+beamGap)
+		default:
+			r.drawBeam(gtx, stemX[i], towards(stemX[i], stemX[i-1], beamStub), beamY+beamGap)
+		}
+	}
+}
+
+// towards returns the point a distance from x in the direction of target,
+// which is to the left on a mirrored highway
+func towards(x, target, distance float32) float32 {
+	if target < x {
+		return x - distance
+	}
+	return x + distance
+}
+
+// drawBeam draws a beam between two stems, from the stem tops down
+func (r *TabRenderer) drawBeam(gtx layout.Context, x1, x2, y float32) {
+	left, right := min(x1, x2), max(x1, x2)
+	fillRect(gtx, image.Rect(int(left), int(y), int(right)+2, int(y)+beamThickness), Colors.Staff)
+}
+
+// drawRest draws a quarter, eighth or sixteenth rest centred at y
+func (r *TabRenderer) drawRest(gtx layout.Context, x, y float32, length float64) {
+	if length >= 1 {
+		var zigzag clip.Path
+		zigzag.Begin(gtx.Ops)
+		zigzag.MoveTo(f32.Pt(x-2, y-12))
+		zigzag.LineTo(f32.Pt(x+3, y-5))
+		zigzag.LineTo(f32.Pt(x-3, y+1))
+		zigzag.LineTo(f32.Pt(x+3, y+7))
+		zigzag.QuadTo(f32.Pt(x-5, y+6), f32.Pt(x, y+13))
+		paint.FillShape(gtx.Ops, Colors.Staff, clip.Stroke{Path: zigzag.End(), Width: 2.5}.Op())
+		return
+	}
+
+	// An eighth rest is a slanting stroke with one hook, a sixteenth two
+	hooks := 1
+	if length < 0.5 {
+		hooks = 2
+	}
+	c := Colors.Staff
+	var stroke clip.Path
+	stroke.Begin(gtx.Ops)
+	stroke.MoveTo(f32.Pt(x+4, y-8))
+	stroke.LineTo(f32.Pt(x-2, y+10))
+	paint.FillShape(gtx.Ops, c, clip.Stroke{Path: stroke.End(), Width: 2}.Op())
+	for i := range hooks {
+		hy := y - 8 + float32(i*7)
+		hx := x + 4 - float32(i)*7/3
+		r.drawNoteCircle(gtx, hx-6, hy, 2.5, c)
+		var hook clip.Path
+		hook.Begin(gtx.Ops)
+		hook.MoveTo(f32.Pt(hx-6, hy+1))
+		hook.QuadTo(f32.Pt(hx-3, hy+3), f32.Pt(hx, hy))
+		paint.FillShape(gtx.Ops, c, clip.Stroke{Path: hook.End(), Width: 1.5}.Op())
+	}
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/core/song"
+)
+
+// Song progress bar geometry in pixels
+const (
+	songProgressHeight  = 6
+	songProgressSection = 12 // Height of the marks at section starts
+)
+
+// DrawSongProgress draws a thin bar across the view filled up to the
+// current time, with a mark where each section starts, and the section
+// and time played out of the song's length beside it
+func (r *TabRenderer) DrawSongProgress(gtx layout.Context, state *song.GameState) layout.Dimensions {
+	s := state.Song
+	if s.Duration <= 0 {
+		return layout.Dimensions{}
+	}
+	t := math.Max(0, math.Min(state.CurrentTime, s.Duration))
+	text := fmt.Sprintf("%s / %s", formatClock(t), formatClock(s.Duration))
+	if name := sectionAt(s, t); name != "" {
+		text = name + "  •  " + text
+	}
+
+	inset := layout.Inset{Left: unit.Dp(10), Right: unit.Dp(20), Top: unit.Dp(4)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				width := gtx.Constraints.Max.X
+				size := image.Pt(width, songProgressSection)
+				describeArea(gtx, size, "Song progress: "+text)
+				top := (songProgressSection - songProgressHeight) / 2
+				fillRect(gtx, image.Rect(0, top, width, top+songProgressHeight), Colors.MeterTrack)
+				x := func(t float64) int { return int(math.Round(t / s.Duration * float64(width))) }
+				fillRect(gtx, image.Rect(0, top, x(t), top+songProgressHeight), Colors.Accent)
+				for _, sec := range s.Sections {
+					if sx := x(s.NoteTime(sec.Beat)); sx > 0 && sx < width {
+						fillRect(gtx, image.Rect(sx-1, 0, sx+1, songProgressSection), Colors.MeterTick)
+					}
+				}
+				return layout.Dimensions{Size: size}
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Caption(r.theme, text)
+				label.Color = Colors.Text
+				return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, label.Layout)
+			}),
+		)
+	})
+}
+
+// sectionAt names the section being played at a time, if any
+func sectionAt(s *song.Song, t float64) string {
+	name := ""
+	for _, sec := range s.Sections {
+		if s.NoteTime(sec.Beat) > t {
+			break
+		}
+		name = sec.Name
+	}
+	return name
+}
+
+// formatClock writes a time in seconds as minutes and seconds
+func formatClock(t float64) string {
+	secs := int(t)
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
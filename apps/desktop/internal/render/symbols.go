@@ -0,0 +1,55 @@
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+
+	"guitargame/core/song"
+)
+
+// hitSymbolSize is the width of a hit symbol in pixels
+const hitSymbolSize = 14
+
+// drawHitSymbol marks how well a note was hit, centered on a point: a
+// tick for Perfect, a double ring for Good, a ring for OK, and a cross
+// for Miss
+func drawHitSymbol(gtx layout.Context, x, y float32, quality song.HitQuality, c color.NRGBA) {
+	const half = hitSymbolSize / 2
+	const width = 2.5
+	center := f32.Pt(x, y)
+
+	stroke := func(points ...f32.Point) {
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(points[0])
+		for _, pt := range points[1:] {
+			p.LineTo(pt)
+		}
+		paint.FillShape(gtx.Ops, c, clip.Stroke{Path: p.End(), Width: width}.Op())
+	}
+	ring := func(radius float32) {
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(center.Add(f32.Pt(radius, 0)))
+		p.ArcTo(center, center, 2*math.Pi)
+		paint.FillShape(gtx.Ops, c, clip.Stroke{Path: p.End(), Width: width}.Op())
+	}
+
+	switch quality {
+	case song.HitPerfect:
+		stroke(center.Add(f32.Pt(-half, 0)), center.Add(f32.Pt(-half/3, half*2/3)), center.Add(f32.Pt(half, -half*2/3)))
+	case song.HitGood:
+		ring(half)
+		ring(half / 3)
+	case song.HitOK:
+		ring(half)
+	default:
+		stroke(center.Add(f32.Pt(-half, -half)), center.Add(f32.Pt(half, half)))
+		stroke(center.Add(f32.Pt(-half, half)), center.Add(f32.Pt(half, -half)))
+	}
+}
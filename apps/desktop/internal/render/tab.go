@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
 	"time"
 
+	"gioui.org/f32"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
@@ -14,101 +16,246 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget/material"
 
-	"guitargame/apps/desktop/internal/song"
+	"guitargame/core/song"
 )
 
+// NoteLabelMode selects what is written on each note
+type NoteLabelMode int
+
+const (
+	LabelFret     NoteLabelMode = iota // Fret number inside the circle
+	LabelNoteName                      // Note name with octave (e.g. "G#1") inside the circle
+	LabelBoth                          // Fret number inside, note name underneath
+)
+
+func (m NoteLabelMode) String() string {
+	switch m {
+	case LabelNoteName:
+		return "Note names"
+	case LabelBoth:
+		return "Fret + note name"
+	default:
+		return "Fret numbers"
+	}
+}
+
+// Next returns the following label mode, wrapping around
+func (m NoteLabelMode) Next() NoteLabelMode {
+	return (m + 1) % (LabelBoth + 1)
+}
+
 // TabRenderer renders scrolling bass tablature
 type TabRenderer struct {
 	theme *material.Theme
 
-	// Layout constants
+	// Layout constants, in dp so the highway keeps its size on dense or
+	// scaled displays
 	StringSpacing  float32
 	PlayLineX      float32 // X position of the "now" line (right side)
-	PixelsPerBeat  float32 // How many pixels per beat
+	PixelsPerBeat  float32 // How far notes scroll per beat
 	TabAreaHeight  float32
 	TabAreaPadding float32
+
+	// ConstantScroll moves notes at PixelsPerSecond whatever the tempo,
+	// instead of PixelsPerBeat, and ScrollSpeed multiplies either (0 is 1)
+	ConstantScroll  bool
+	PixelsPerSecond float32
+	ScrollSpeed     float32
+
+	ShowStringCrossings bool          // Connect upcoming notes that change string
+	NoteLabels          NoteLabelMode // What to write on each note
+	Notation            NotationMode  // Standard-notation staff or rhythms alongside the tab, or the staff instead
+	DynamicZoom         bool          // Spread out busy passages
+	HideDebug           bool          // Leave out readouts only useful for debugging, such as the frequency heard
+
+	// Left-handed layouts
+	MirrorStrings bool // Lowest string on top
+	MirrorHighway bool // Play line on the left, notes scrolling left to right
+
+	stringCount int     // Strings in the song being drawn, for mirroring
+	pxPerDp     float32 // Of the last layout
+	zoom        zoomState
+
+	editorGeom editorGeometry
 }
 
 // NewTabRenderer creates a new tab renderer
 func NewTabRenderer(theme *material.Theme) *TabRenderer {
 	return &TabRenderer{
-		theme:          theme,
-		StringSpacing:  40,
-		PlayLineX:      0.75, // 75% from left
-		PixelsPerBeat:  80,
-		TabAreaHeight:  200,
-		TabAreaPadding: 20,
+		theme:           theme,
+		StringSpacing:   40,
+		PlayLineX:       0.75, // 75% from left
+		PixelsPerBeat:   80,
+		PixelsPerSecond: 160, // The same as 120 BPM
+		TabAreaHeight:   200,
+		TabAreaPadding:  20,
+		pxPerDp:         1,
+
+		ShowStringCrossings: true,
 	}
 }
 
-// Colors - high contrast for readability
-var (
-	ColorBackground  = color.NRGBA{R: 20, G: 20, B: 30, A: 255}
-	ColorString      = color.NRGBA{R: 140, G: 140, B: 160, A: 255}  // Brighter strings
-	ColorPlayLine    = color.NRGBA{R: 100, G: 220, B: 255, A: 255}  // Brighter play line
-	ColorNoteDefault = color.NRGBA{R: 255, G: 255, B: 255, A: 255}  // White notes
-	ColorNotePerfect = color.NRGBA{R: 50, G: 255, B: 100, A: 255}   // Bright green
-	ColorNoteGood    = color.NRGBA{R: 180, G: 255, B: 50, A: 255}   // Yellow-green
-	ColorNoteOK      = color.NRGBA{R: 255, G: 220, B: 50, A: 255}   // Yellow
-	ColorNoteMiss    = color.NRGBA{R: 255, G: 80, B: 80, A: 255}    // Red
-	ColorFloatText   = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
-)
-
-// StringNames for bass guitar
-var StringNames = []string{"G", "D", "A", "E"}
-
 // Layout renders the complete tab view
 func (r *TabRenderer) Layout(gtx layout.Context, state *song.GameState) layout.Dimensions {
 	width := float32(gtx.Constraints.Max.X)
 	height := float32(gtx.Constraints.Max.Y)
+	r.setMetric(gtx)
 
 	// Calculate play line position
-	playLineX := width * r.PlayLineX
+	playLineX := r.PlayLinePos(width)
 
-	// Calculate pixels per second based on BPM
-	beatsPerSecond := state.Song.BPM / 60.0
-	pixelsPerSecond := r.PixelsPerBeat * float32(beatsPerSecond)
+	// Calculate pixels per second from the scroll settings; a mirrored
+	// highway scrolls the other way
+	pixelsPerSecond := r.scrollRate(state.Song.BPM) * r.updateZoom(state)
+	if r.MirrorHighway {
+		pixelsPerSecond = -pixelsPerSecond
+	}
+	r.stringCount = len(state.Song.GetTuning())
 
 	// Draw background
 	r.drawBackground(gtx, int(width), int(height))
 
-	// Calculate tab area bounds
-	tabTop := r.TabAreaPadding + 60 // Leave room for header
-	tabHeight := r.StringSpacing * 5 // 4 strings + padding
+	// Standard notation, in its own lane below the header
+	if r.Notation == NotationAbove || r.Notation == NotationOnly {
+		laneTop := r.headerBottom()
+		r.drawNotation(gtx, state.Song, state.CurrentTime, playLineX, laneTop, pixelsPerSecond)
+		r.drawPlayLine(gtx, playLineX, laneTop+10, notationHeight-20)
+	}
+
+	if r.Notation == NotationRhythm {
+		r.drawRhythm(gtx, state.Song, state.CurrentTime, playLineX, r.headerBottom(), pixelsPerSecond)
+	}
+
+	if r.Notation != NotationOnly {
+		// Calculate tab area bounds
+		tuning := state.Song.GetTuning()
+		tabTop := r.tabTop()
+		tabHeight := r.stringSpacing() * float32(len(tuning)+1) // Strings + padding
+
+		// Draw string lines
+		r.drawStrings(gtx, int(width), tabTop, len(tuning))
 
-	// Draw string lines
-	r.drawStrings(gtx, int(width), tabTop, tabHeight)
+		// Bar lines and beat ticks scroll with the notes
+		r.drawBeatGrid(gtx, state.Song, state.CurrentTime, playLineX, tabTop, pixelsPerSecond, len(tuning))
 
-	// Draw play line (the "now" indicator)
-	r.drawPlayLine(gtx, playLineX, tabTop, tabHeight)
+		// Draw play line (the "now" indicator)
+		r.drawPlayLine(gtx, playLineX, tabTop, tabHeight)
 
-	// Draw notes
-	r.drawNotes(gtx, state, playLineX, tabTop, pixelsPerSecond)
+		// Draw notes
+		r.drawNotes(gtx, state.Song, state.CurrentTime, playLineX, tabTop, pixelsPerSecond, state.JudgedTime())
+
+		// Flashes where notes were hit
+		r.drawHitFlashes(gtx, state.Song, state.JudgedTime(), playLineX, tabTop)
+
+		// Draw string labels on left
+		r.drawStringLabels(gtx, tabTop, tuning)
+	}
 
 	// Draw floating score text
 	r.drawFloatingText(gtx, state)
 
-	// Draw string labels on left
-	r.drawStringLabels(gtx, tabTop)
+	describeArea(gtx, image.Pt(int(width), int(height)), describePlay(state))
 
 	return layout.Dimensions{Size: image.Pt(int(width), int(height))}
 }
 
+// PlayLinePos returns the x position of the play line in a view of the given width
+func (r *TabRenderer) PlayLinePos(width float32) float32 {
+	if r.MirrorHighway {
+		return width * (1 - r.PlayLineX)
+	}
+	return width * r.PlayLineX
+}
+
+// setMetric takes the display density from a layout, for converting the
+// highway's sizes to pixels
+func (r *TabRenderer) setMetric(gtx layout.Context) {
+	if gtx.Metric.PxPerDp > 0 {
+		r.pxPerDp = gtx.Metric.PxPerDp
+	}
+}
+
+// dp converts a size in dp to pixels
+func (r *TabRenderer) dp(v float32) float32 {
+	return v * r.pxPerDp
+}
+
+// stringSpacing returns the distance between strings in pixels
+func (r *TabRenderer) stringSpacing() float32 {
+	return r.dp(r.StringSpacing)
+}
+
+// pixelsPerBeat returns how far notes scroll per beat in pixels
+func (r *TabRenderer) pixelsPerBeat() float32 {
+	return r.dp(r.PixelsPerBeat)
+}
+
+// scrollRate returns how many pixels a second notes move in a song
+// starting at a tempo
+func (r *TabRenderer) scrollRate(bpm float64) float32 {
+	rate := r.pixelsPerBeat() * float32(bpm/60)
+	if r.ConstantScroll {
+		rate = r.dp(r.PixelsPerSecond)
+	}
+	if r.ScrollSpeed > 0 {
+		rate *= r.ScrollSpeed
+	}
+	return rate
+}
+
+// stringRow returns which lane, counting from the top, a string is drawn in
+func (r *TabRenderer) stringRow(str int) int {
+	if r.MirrorStrings {
+		return r.stringCount - 1 - str
+	}
+	return str
+}
+
+// visibleTimes returns the earliest and latest song times on screen
+func visibleTimes(width, playLineX float32, currentTime float64, pixelsPerSecond float32) (from, to float64) {
+	left := currentTime - float64(playLineX/pixelsPerSecond)
+	right := currentTime + float64((width-playLineX)/pixelsPerSecond)
+	return min(left, right), max(left, right)
+}
+
+// headerBottom is where the highway starts, below the score header
+func (r *TabRenderer) headerBottom() float32 {
+	return r.dp(r.TabAreaPadding + 60)
+}
+
+// tabTop is where the first string lane starts, below the header and
+// any notation staff or rhythm lane
+func (r *TabRenderer) tabTop() float32 {
+	switch r.Notation {
+	case NotationAbove:
+		return r.headerBottom() + notationHeight
+	case NotationRhythm:
+		return r.headerBottom() + rhythmHeight
+	}
+	return r.headerBottom()
+}
+
+// FeedbackY returns the height at which hit feedback for a string is
+// drawn, just above the string's line
+func (r *TabRenderer) FeedbackY(str int) float32 {
+	return r.tabTop() + float32(r.stringRow(str))*r.stringSpacing()
+}
+
 func (r *TabRenderer) drawBackground(gtx layout.Context, width, height int) {
 	defer clip.Rect{Max: image.Pt(width, height)}.Push(gtx.Ops).Pop()
-	paint.ColorOp{Color: ColorBackground}.Add(gtx.Ops)
+	paint.ColorOp{Color: Colors.Background}.Add(gtx.Ops)
 	paint.PaintOp{}.Add(gtx.Ops)
 }
 
-func (r *TabRenderer) drawStrings(gtx layout.Context, width int, tabTop, tabHeight float32) {
-	for i := 0; i < 4; i++ {
-		y := int(tabTop + float32(i)*r.StringSpacing + r.StringSpacing/2)
+func (r *TabRenderer) drawStrings(gtx layout.Context, width int, tabTop float32, strings int) {
+	for i := 0; i < strings; i++ {
+		y := int(tabTop + float32(i)*r.stringSpacing() + r.stringSpacing()/2)
 
 		defer clip.Rect{
 			Min: image.Pt(50, y),
 			Max: image.Pt(width-10, y+2),
 		}.Push(gtx.Ops).Pop()
-		paint.ColorOp{Color: ColorString}.Add(gtx.Ops)
+		paint.ColorOp{Color: Colors.String}.Add(gtx.Ops)
 		paint.PaintOp{}.Add(gtx.Ops)
 	}
 }
@@ -119,7 +266,7 @@ func (r *TabRenderer) drawPlayLine(gtx layout.Context, x, tabTop, tabHeight floa
 		Min: image.Pt(int(x)-1, int(tabTop)-10),
 		Max: image.Pt(int(x)+2, int(tabTop+tabHeight)),
 	}.Push(gtx.Ops).Pop()
-	paint.ColorOp{Color: ColorPlayLine}.Add(gtx.Ops)
+	paint.ColorOp{Color: Colors.PlayLine}.Add(gtx.Ops)
 	paint.PaintOp{}.Add(gtx.Ops)
 
 	// Glow effect (wider, more transparent)
@@ -127,57 +274,149 @@ func (r *TabRenderer) drawPlayLine(gtx layout.Context, x, tabTop, tabHeight floa
 		Min: image.Pt(int(x)-4, int(tabTop)-10),
 		Max: image.Pt(int(x)+5, int(tabTop+tabHeight)),
 	}.Push(gtx.Ops).Pop()
-	paint.ColorOp{Color: color.NRGBA{R: 100, G: 200, B: 255, A: 50}}.Add(gtx.Ops)
+	paint.ColorOp{Color: Colors.PlayLineGlow}.Add(gtx.Ops)
 	paint.PaintOp{}.Add(gtx.Ops)
 }
 
-func (r *TabRenderer) drawNotes(gtx layout.Context, state *song.GameState, playLineX, tabTop, pixelsPerSecond float32) {
-	currentTime := state.CurrentTime
+// drawBeatGrid draws a line across the strings at each bar and a fainter
+// one at each beat. Charts have no time signature, so bars are
+// song.BeatsPerBar long.
+func (r *TabRenderer) drawBeatGrid(gtx layout.Context, s *song.Song, currentTime float64, playLineX, tabTop, pixelsPerSecond float32, strings int) {
+	timeAtLeft, timeAtRight := visibleTimes(float32(gtx.Constraints.Max.X), playLineX, currentTime, pixelsPerSecond)
+	first := max(0, int(math.Floor(s.TimeToBeat(timeAtLeft))))
+	last := int(math.Ceil(s.TimeToBeat(min(timeAtRight, s.Duration))))
+	top := int(tabTop + r.stringSpacing()/2)
+	bottom := int(tabTop+float32(strings-1)*r.stringSpacing()+r.stringSpacing()/2) + 2
+	for beat := first; beat <= last; beat++ {
+		x := int(playLineX + float32(s.BeatToTime(float64(beat))-currentTime)*pixelsPerSecond)
+		c, w := Colors.BeatTick, 1
+		if beat%song.BeatsPerBar == 0 {
+			c, w = Colors.BarLine, 2
+		}
+		rect := clip.Rect{Min: image.Pt(x, top), Max: image.Pt(x+w, bottom)}.Push(gtx.Ops)
+		paint.ColorOp{Color: c}.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		rect.Pop()
+	}
+}
 
+// drawNotes draws the notes in view. Notes grow as they near the play line
+// and missed ones shake, judged at judgedTime; the editor passes -Inf to
+// draw them still.
+func (r *TabRenderer) drawNotes(gtx layout.Context, s *song.Song, currentTime float64, playLineX, tabTop, pixelsPerSecond float32, judgedTime float64) {
 	// Calculate visible time range
 	// Notes to the right of play line are in the future
 	// Notes to the left have already passed
-	timeAtLeft := currentTime - float64(playLineX/pixelsPerSecond)
-	timeAtRight := currentTime + float64((float32(gtx.Constraints.Max.X)-playLineX)/pixelsPerSecond)
+	timeAtLeft, timeAtRight := visibleTimes(float32(gtx.Constraints.Max.X), playLineX, currentTime, pixelsPerSecond)
 
-	for i := range state.Song.Notes {
-		note := &state.Song.Notes[i]
+	noteX := func(note *song.TabNote) float32 {
+		return playLineX + float32(note.Time-currentTime)*pixelsPerSecond
+	}
+	noteY := func(note *song.TabNote) float32 {
+		return tabTop + float32(r.stringRow(note.String))*r.stringSpacing() + r.stringSpacing()/2
+	}
+
+	// String crossing cues go underneath the notes
+	if r.ShowStringCrossings {
+		for i := range s.Notes {
+			note := &s.Notes[i]
+			if note.Hit || note.Time < currentTime || note.Time > timeAtRight+1 || !s.IsStringCrossing(i) {
+				continue
+			}
+			prev := &s.Notes[i-1]
+			r.drawStringCrossing(gtx, f32.Pt(noteX(prev), noteY(prev)), f32.Pt(noteX(note), noteY(note)))
+		}
+	}
+
+	for i := range s.Notes {
+		note := &s.Notes[i]
 
 		// Skip notes outside visible range
 		if note.Time < timeAtLeft-1 || note.Time > timeAtRight+1 {
 			continue
 		}
 
-		// Calculate X position
-		timeDelta := note.Time - currentTime
-		noteX := playLineX + float32(timeDelta)*pixelsPerSecond
+		// Calculate position from time and string
+		x, y := noteX(note), noteY(note)
+		radius := r.dp(noteRadius)
+		if !math.IsInf(judgedTime, -1) {
+			x += r.missShake(note, judgedTime)
+			radius *= approachScale(note, currentTime)
+		}
 
-		// Calculate Y position based on string
-		noteY := tabTop + float32(note.String)*r.StringSpacing + r.StringSpacing/2
+		// Draw note background circle, colored by how it was hit
+		r.drawNoteCircle(gtx, x, y, radius, noteStateColor(note))
+
+		// Draw fret number and/or note name
+		noteName := fmt.Sprintf("%s%d", s.NoteAt(note), s.OctaveAt(note))
+		switch r.NoteLabels {
+		case LabelNoteName:
+			r.drawNoteLabel(gtx, x, y, noteName, Colors.NoteText, false)
+		case LabelBoth:
+			r.drawFretNumber(gtx, x, y, note.Fret)
+			r.drawNoteLabel(gtx, x, y+r.dp(26), noteName, Colors.NoteName, true)
+		default:
+			r.drawFretNumber(gtx, x, y, note.Fret)
+		}
 
-		// Determine note color based on state
-		noteColor := ColorNoteDefault
+		// Played notes also show their hit quality by shape
 		if note.Hit {
-			switch note.HitQuality {
-			case song.HitPerfect:
-				noteColor = ColorNotePerfect
-			case song.HitGood:
-				noteColor = ColorNoteGood
-			case song.HitOK:
-				noteColor = ColorNoteOK
-			case song.HitMiss:
-				noteColor = ColorNoteMiss
-			}
+			drawHitSymbol(gtx, x, y-r.dp(30), note.HitQuality, Colors.HitSymbol)
 		}
+	}
+}
 
-		// Draw note background circle
-		r.drawNoteCircle(gtx, noteX, noteY, 18, noteColor)
+// noteStateColor colors a note by whether and how well it was hit
+func noteStateColor(note *song.TabNote) color.NRGBA {
+	if !note.Hit {
+		return Colors.NoteDefault
+	}
+	return QualityColor(note.HitQuality)
+}
 
-		// Draw fret number
-		r.drawFretNumber(gtx, noteX, noteY, note.Fret)
+// QualityColor returns the color of a hit quality
+func QualityColor(quality song.HitQuality) color.NRGBA {
+	switch quality {
+	case song.HitPerfect:
+		return Colors.NotePerfect
+	case song.HitGood:
+		return Colors.NoteGood
+	case song.HitOK:
+		return Colors.NoteOK
+	default:
+		return Colors.NoteMiss
 	}
 }
 
+// drawStringCrossing draws a connector between two notes on different
+// strings with an arrowhead showing which way the hand moves
+func (r *TabRenderer) drawStringCrossing(gtx layout.Context, from, to f32.Point) {
+	var line clip.Path
+	line.Begin(gtx.Ops)
+	line.MoveTo(from)
+	line.LineTo(to)
+	paint.FillShape(gtx.Ops, Colors.Crossing, clip.Stroke{Path: line.End(), Width: 3}.Op())
+
+	// Arrowhead just outside the destination note's circle
+	dir := to.Sub(from)
+	length := float32(math.Hypot(float64(dir.X), float64(dir.Y)))
+	if length < 1 {
+		return
+	}
+	dir = dir.Mul(1 / length)
+	normal := f32.Pt(-dir.Y, dir.X)
+	tip := to.Sub(dir.Mul(20))
+	base := tip.Sub(dir.Mul(10))
+
+	var arrow clip.Path
+	arrow.Begin(gtx.Ops)
+	arrow.MoveTo(tip)
+	arrow.LineTo(base.Add(normal.Mul(6)))
+	arrow.LineTo(base.Sub(normal.Mul(6)))
+	arrow.Close()
+	paint.FillShape(gtx.Ops, Colors.Crossing, clip.Outline{Path: arrow.End()}.Op())
+}
+
 func (r *TabRenderer) drawNoteCircle(gtx layout.Context, x, y, radius float32, c color.NRGBA) {
 	// Draw filled circle for note
 	center := image.Pt(int(x), int(y))
@@ -194,25 +433,33 @@ func (r *TabRenderer) drawNoteCircle(gtx layout.Context, x, y, radius float32, c
 }
 
 func (r *TabRenderer) drawFretNumber(gtx layout.Context, x, y float32, fret int) {
-	// Position text centered on the note
-	offset := op.Offset(image.Pt(int(x)-8, int(y)-10)).Push(gtx.Ops)
+	r.drawNoteLabel(gtx, x, y, fmt.Sprintf("%d", fret), Colors.NoteText, false)
+}
 
-	label := material.Body1(r.theme, fmt.Sprintf("%d", fret))
-	label.Color = color.NRGBA{R: 30, G: 30, B: 40, A: 255}
-	label.Alignment = text.Middle
-	label.Layout(gtx)
+// drawNoteLabel writes text centered on a point; small text is used
+// for secondary labels outside the note circle
+func (r *TabRenderer) drawNoteLabel(gtx layout.Context, x, y float32, txt string, c color.NRGBA, small bool) {
+	box := image.Pt(48, 24)
+	defer op.Offset(image.Pt(int(x)-box.X/2, int(y)-box.Y/2)).Push(gtx.Ops).Pop()
+	gtx.Constraints = layout.Exact(box)
 
-	offset.Pop()
+	label := material.Body1(r.theme, txt)
+	if small {
+		label = material.Caption(r.theme, txt)
+	}
+	label.Color = c
+	label.Alignment = text.Middle
+	layout.Center.Layout(gtx, label.Layout)
 }
 
-func (r *TabRenderer) drawStringLabels(gtx layout.Context, tabTop float32) {
-	for i, name := range StringNames {
-		y := tabTop + float32(i)*r.StringSpacing + r.StringSpacing/2 - 10
+func (r *TabRenderer) drawStringLabels(gtx layout.Context, tabTop float32, tuning song.Tuning) {
+	for i, st := range tuning {
+		y := tabTop + float32(r.stringRow(i))*r.stringSpacing() + r.stringSpacing()/2 - 10
 
 		offset := op.Offset(image.Pt(15, int(y))).Push(gtx.Ops)
 
-		label := material.Body1(r.theme, name)
-		label.Color = color.NRGBA{R: 150, G: 150, B: 150, A: 255}
+		label := material.Body1(r.theme, st.Note)
+		label.Color = Colors.Text
 		label.Layout(gtx)
 
 		offset.Pop()
@@ -232,17 +479,11 @@ func (r *TabRenderer) drawFloatingText(gtx layout.Context, state *song.GameState
 		yOffset := float32(elapsed * 50) // Move up 50 pixels over 1 second
 		alpha := uint8(255 * (1 - elapsed))
 
-		// Choose color based on quality
-		var textColor color.NRGBA
-		switch ft.Quality {
-		case song.HitPerfect:
-			textColor = color.NRGBA{R: 100, G: 255, B: 100, A: alpha}
-		case song.HitGood:
-			textColor = color.NRGBA{R: 200, G: 255, B: 100, A: alpha}
-		case song.HitOK:
-			textColor = color.NRGBA{R: 255, G: 255, B: 100, A: alpha}
-		default:
-			textColor = color.NRGBA{R: 255, G: 100, B: 100, A: alpha}
+		// Color by quality, with the quality's symbol in front of feedback
+		// on played notes so it doesn't rely on color alone
+		textColor := withAlpha(QualityColor(ft.Quality), alpha)
+		if ft.Judged {
+			drawHitSymbol(gtx, ft.X-30, ft.Y-yOffset+r.dp(14), ft.Quality, textColor)
 		}
 
 		offset := op.Offset(image.Pt(int(ft.X)-20, int(ft.Y-yOffset))).Push(gtx.Ops)
@@ -263,7 +504,7 @@ func (r *TabRenderer) DrawHeader(gtx layout.Context, state *song.GameState) layo
 			inset := layout.Inset{Left: unit.Dp(10), Top: unit.Dp(10)}
 			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				label := material.H6(r.theme, state.Song.Title)
-				label.Color = color.NRGBA{R: 200, G: 200, B: 200, A: 255}
+				label.Color = Colors.Title
 				return label.Layout(gtx)
 			})
 		}),
@@ -272,26 +513,28 @@ func (r *TabRenderer) DrawHeader(gtx layout.Context, state *song.GameState) layo
 			// Score
 			inset := layout.Inset{Right: unit.Dp(20), Top: unit.Dp(10)}
 			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-				return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceEnd}.Layout(gtx,
+				return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceEnd, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !state.FailMode {
+							return layout.Dimensions{}
+						}
+						return layout.Inset{Right: unit.Dp(20)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return r.drawHealthMeter(gtx, state.Health)
+						})
+					}),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						label := material.Body1(r.theme, fmt.Sprintf("Score: %d", state.Score))
-						label.Color = color.NRGBA{R: 255, G: 215, B: 0, A: 255}
+						label.Color = Colors.Highlight
 						return label.Layout(gtx)
 					}),
 					layout.Rigid(layout.Spacer{Width: unit.Dp(20)}.Layout),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						comboColor := color.NRGBA{R: 150, G: 150, B: 150, A: 255}
-						if state.Combo >= 10 {
-							comboColor = color.NRGBA{R: 255, G: 150, B: 50, A: 255}
-						}
-						label := material.Body1(r.theme, fmt.Sprintf("Combo: %d", state.Combo))
-						label.Color = comboColor
-						return label.Layout(gtx)
+						return r.drawCombo(gtx, state)
 					}),
 					layout.Rigid(layout.Spacer{Width: unit.Dp(20)}.Layout),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						label := material.Body1(r.theme, fmt.Sprintf("%.0f%%", state.Accuracy()))
-						label.Color = color.NRGBA{R: 100, G: 200, B: 255, A: 255}
+						label.Color = Colors.Accent
 						return label.Layout(gtx)
 					}),
 				)
@@ -309,7 +552,7 @@ func (r *TabRenderer) DrawDetectedNote(gtx layout.Context, noteName string, freq
 				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						label := material.Body2(r.theme, "Playing: ")
-						label.Color = color.NRGBA{R: 120, G: 120, B: 120, A: 255}
+						label.Color = Colors.Hint
 						return label.Layout(gtx)
 					}),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -317,9 +560,9 @@ func (r *TabRenderer) DrawDetectedNote(gtx layout.Context, noteName string, freq
 						if noteName == "" {
 							displayNote = "--"
 						}
-						noteColor := color.NRGBA{R: 100, G: 255, B: 150, A: 255}
+						noteColor := Colors.DetectedNote
 						if noteName == "" {
-							noteColor = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+							noteColor = Colors.Disabled
 						}
 						label := material.H6(r.theme, displayNote)
 						label.Color = noteColor
@@ -327,8 +570,11 @@ func (r *TabRenderer) DrawDetectedNote(gtx layout.Context, noteName string, freq
 					}),
 					layout.Rigid(layout.Spacer{Width: unit.Dp(15)}.Layout),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if r.HideDebug {
+							return layout.Dimensions{}
+						}
 						label := material.Body2(r.theme, fmt.Sprintf("%.1f Hz", frequency))
-						label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+						label.Color = Colors.Disabled
 						return label.Layout(gtx)
 					}),
 				)
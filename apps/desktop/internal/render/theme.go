@@ -0,0 +1,507 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"gioui.org/widget/material"
+)
+
+// Theme is a color scheme for everything the game draws
+type Theme struct {
+	// Screens and text
+	Background color.NRGBA
+	Title      color.NRGBA // Headings
+	Text       color.NRGBA // Secondary text
+	Hint       color.NRGBA // Key hints and settings
+	Disabled   color.NRGBA
+	Highlight  color.NRGBA // Scores and bests
+	Info       color.NRGBA
+	Accent     color.NRGBA
+	Success    color.NRGBA
+	Selected   color.NRGBA // The chosen item in a list
+	Warning    color.NRGBA
+	Error      color.NRGBA
+	Combo      color.NRGBA // A combo earning a multiplier
+	Flame      color.NRGBA // Around the highest multiplier
+
+	// Lists and text fields
+	ListItem         color.NRGBA
+	ListItemSelected color.NRGBA
+	ListItemGlow     color.NRGBA
+	Input            color.NRGBA
+	InputHint        color.NRGBA
+
+	// The highway
+	String       color.NRGBA
+	PlayLine     color.NRGBA
+	PlayLineGlow color.NRGBA
+	BarLine      color.NRGBA
+	BeatTick     color.NRGBA
+	Crossing     color.NRGBA // String change cue
+	FretLane     color.NRGBA // Lane each fret's notes fall down on the fretboard highway
+
+	// Notes, by whether and how well they were hit
+	NoteDefault  color.NRGBA
+	NotePerfect  color.NRGBA
+	NoteGood     color.NRGBA
+	NoteOK       color.NRGBA
+	NoteMiss     color.NRGBA
+	NoteText     color.NRGBA // Text inside notes
+	NoteName     color.NRGBA // Note names under notes
+	HitSymbol    color.NRGBA // Hit quality shapes above played notes
+	DetectedNote color.NRGBA
+
+	// Chart editor
+	GridBar    color.NRGBA
+	GridBeat   color.NRGBA
+	GridSub    color.NRGBA
+	Cursor     color.NRGBA
+	CursorFill color.NRGBA
+
+	// Ghost of the best run
+	Ghost       color.NRGBA
+	GhostAhead  color.NRGBA
+	GhostBehind color.NRGBA
+
+	// Standard notation
+	Staff       color.NRGBA
+	Accidental  color.NRGBA
+	StaffLedger color.NRGBA
+
+	// Charts on the results and progress screens
+	GraphAxis   color.NRGBA
+	GraphPassed color.NRGBA
+	GraphFailed color.NRGBA
+	GraphSpread color.NRGBA
+	GraphMean   color.NRGBA
+	GraphHit    color.NRGBA
+	HeatEmpty   color.NRGBA
+	HeatLow     color.NRGBA // Positions with no misses
+	HeatHigh    color.NRGBA // Positions with every note missed
+
+	// Fretboard diagrams
+	Fretboard     color.NRGBA
+	Fret          color.NRGBA
+	FretMarker    color.NRGBA
+	ScaleNote     color.NRGBA
+	ScaleRootNote color.NRGBA
+
+	// Meters
+	MeterTrack color.NRGBA
+	MeterTick  color.NRGBA
+	HealthHigh color.NRGBA
+	HealthMid  color.NRGBA
+	HealthLow  color.NRGBA
+}
+
+// Names of the built-in themes
+const (
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeRedGreen     = "colorblind"             // For deuteranopia and protanopia
+	ThemeBlueYellow   = "colorblind-blue-yellow" // For tritanopia
+)
+
+// DarkTheme is the default theme
+var DarkTheme = Theme{
+	Background: rgb(20, 20, 30),
+	Title:      rgb(200, 200, 200),
+	Text:       rgb(150, 150, 150),
+	Hint:       rgb(120, 120, 120),
+	Disabled:   rgb(100, 100, 100),
+	Highlight:  rgb(255, 215, 0),
+	Info:       rgb(150, 200, 255),
+	Accent:     rgb(100, 200, 255),
+	Success:    rgb(100, 200, 100),
+	Selected:   rgb(255, 150, 100),
+	Warning:    rgb(255, 200, 100),
+	Error:      rgb(255, 100, 100),
+	Combo:      rgb(255, 150, 50),
+	Flame:      rgba(255, 80, 40, 200),
+
+	ListItem:         rgb(35, 35, 45),
+	ListItemSelected: rgb(50, 70, 90),
+	ListItemGlow:     rgba(100, 200, 255, 50),
+	Input:            rgb(220, 220, 220),
+	InputHint:        rgb(80, 80, 80),
+
+	String:       rgb(140, 140, 160),
+	PlayLine:     rgb(100, 220, 255),
+	PlayLineGlow: rgba(100, 200, 255, 50),
+	BarLine:      rgb(110, 110, 135),
+	BeatTick:     rgb(60, 60, 78),
+	Crossing:     rgba(255, 160, 40, 200),
+	FretLane:     rgb(50, 50, 65),
+
+	NoteDefault:  rgb(255, 255, 255),
+	NotePerfect:  rgb(50, 255, 100),
+	NoteGood:     rgb(180, 255, 50),
+	NoteOK:       rgb(255, 220, 50),
+	NoteMiss:     rgb(255, 80, 80),
+	NoteText:     rgb(30, 30, 40),
+	NoteName:     rgb(170, 170, 190),
+	HitSymbol:    rgb(235, 235, 245),
+	DetectedNote: rgb(100, 255, 150),
+
+	GridBar:    rgb(120, 120, 140),
+	GridBeat:   rgb(70, 70, 90),
+	GridSub:    rgb(40, 40, 55),
+	Cursor:     rgb(255, 200, 60),
+	CursorFill: rgba(255, 200, 60, 60),
+
+	Ghost:       rgba(220, 220, 255, 90),
+	GhostAhead:  rgb(255, 215, 0),
+	GhostBehind: rgb(255, 140, 90),
+
+	Staff:       rgb(110, 110, 130),
+	Accidental:  rgb(200, 200, 220),
+	StaffLedger: rgb(90, 90, 110),
+
+	GraphAxis:   rgb(80, 80, 100),
+	GraphPassed: rgb(50, 200, 100),
+	GraphFailed: rgb(110, 110, 130),
+	GraphSpread: rgba(100, 150, 255, 50),
+	GraphMean:   rgb(255, 215, 0),
+	GraphHit:    rgb(100, 200, 255),
+	HeatEmpty:   rgb(35, 35, 48),
+	HeatLow:     rgb(60, 150, 60),
+	HeatHigh:    rgb(220, 40, 60),
+
+	Fretboard:     rgb(45, 35, 30),
+	Fret:          rgb(150, 150, 160),
+	FretMarker:    rgb(90, 80, 75),
+	ScaleNote:     rgb(100, 180, 255),
+	ScaleRootNote: rgb(255, 150, 60),
+
+	MeterTrack: rgb(50, 50, 65),
+	MeterTick:  rgb(110, 110, 130),
+	HealthHigh: rgb(100, 220, 100),
+	HealthMid:  rgb(255, 210, 80),
+	HealthLow:  rgb(255, 90, 90),
+}
+
+// LightTheme is dark text on a pale background, for bright rooms
+var LightTheme = Theme{
+	Background: rgb(240, 238, 232),
+	Title:      rgb(30, 30, 40),
+	Text:       rgb(70, 70, 80),
+	Hint:       rgb(105, 105, 115),
+	Disabled:   rgb(160, 160, 165),
+	Highlight:  rgb(180, 130, 0),
+	Info:       rgb(30, 100, 180),
+	Accent:     rgb(0, 120, 200),
+	Success:    rgb(30, 140, 50),
+	Selected:   rgb(210, 90, 30),
+	Warning:    rgb(200, 120, 0),
+	Error:      rgb(200, 40, 40),
+	Combo:      rgb(220, 110, 0),
+	Flame:      rgba(220, 50, 20, 200),
+
+	ListItem:         rgb(228, 226, 220),
+	ListItemSelected: rgb(200, 220, 240),
+	ListItemGlow:     rgba(0, 120, 200, 40),
+	Input:            rgb(30, 30, 40),
+	InputHint:        rgb(150, 150, 155),
+
+	String:       rgb(90, 90, 110),
+	PlayLine:     rgb(0, 140, 220),
+	PlayLineGlow: rgba(0, 140, 220, 50),
+	BarLine:      rgb(120, 120, 140),
+	BeatTick:     rgb(205, 203, 212),
+	Crossing:     rgba(230, 120, 0, 200),
+	FretLane:     rgb(225, 222, 214),
+
+	NoteDefault:  rgb(60, 60, 75),
+	NotePerfect:  rgb(20, 170, 60),
+	NoteGood:     rgb(120, 170, 20),
+	NoteOK:       rgb(210, 160, 0),
+	NoteMiss:     rgb(210, 50, 50),
+	NoteText:     rgb(255, 255, 255),
+	NoteName:     rgb(90, 90, 110),
+	HitSymbol:    rgb(40, 40, 50),
+	DetectedNote: rgb(20, 150, 80),
+
+	GridBar:    rgb(120, 120, 140),
+	GridBeat:   rgb(185, 185, 195),
+	GridSub:    rgb(222, 220, 214),
+	Cursor:     rgb(220, 140, 0),
+	CursorFill: rgba(220, 140, 0, 60),
+
+	Ghost:       rgba(60, 60, 120, 90),
+	GhostAhead:  rgb(180, 130, 0),
+	GhostBehind: rgb(210, 100, 50),
+
+	Staff:       rgb(120, 120, 140),
+	Accidental:  rgb(50, 50, 70),
+	StaffLedger: rgb(150, 150, 165),
+
+	GraphAxis:   rgb(150, 150, 165),
+	GraphPassed: rgb(30, 150, 60),
+	GraphFailed: rgb(170, 170, 185),
+	GraphSpread: rgba(40, 90, 220, 50),
+	GraphMean:   rgb(180, 130, 0),
+	GraphHit:    rgb(0, 120, 200),
+	HeatEmpty:   rgb(225, 222, 214),
+	HeatLow:     rgb(120, 190, 120),
+	HeatHigh:    rgb(220, 60, 60),
+
+	Fretboard:     rgb(200, 170, 130),
+	Fret:          rgb(110, 110, 120),
+	FretMarker:    rgb(170, 140, 105),
+	ScaleNote:     rgb(30, 110, 210),
+	ScaleRootNote: rgb(220, 100, 20),
+
+	MeterTrack: rgb(215, 212, 205),
+	MeterTick:  rgb(140, 140, 155),
+	HealthHigh: rgb(40, 160, 60),
+	HealthMid:  rgb(210, 160, 0),
+	HealthLow:  rgb(210, 50, 50),
+}
+
+// HighContrastTheme is the dark theme pushed to black, white and fully
+// saturated colors
+var HighContrastTheme = highContrast()
+
+func highContrast() Theme {
+	t := DarkTheme
+	t.Background = rgb(0, 0, 0)
+	t.Title = rgb(255, 255, 255)
+	t.Text = rgb(235, 235, 235)
+	t.Hint = rgb(210, 210, 210)
+	t.Disabled = rgb(150, 150, 150)
+	t.Highlight = rgb(255, 230, 0)
+	t.Info = rgb(120, 200, 255)
+	t.Accent = rgb(0, 220, 255)
+	t.Selected = rgb(255, 140, 0)
+	t.Warning = rgb(255, 200, 0)
+	t.ListItem = rgb(20, 20, 20)
+	t.ListItemSelected = rgb(0, 70, 120)
+	t.Input = rgb(255, 255, 255)
+	t.InputHint = rgb(170, 170, 170)
+	t.Error = rgb(255, 60, 60)
+
+	t.String = rgb(255, 255, 255)
+	t.PlayLine = rgb(0, 255, 255)
+	t.BarLine = rgb(200, 200, 200)
+	t.BeatTick = rgb(90, 90, 90)
+	t.FretLane = rgb(30, 30, 30)
+
+	t.NoteDefault = rgb(255, 255, 255)
+	t.NotePerfect = rgb(0, 255, 0)
+	t.NoteGood = rgb(0, 200, 255)
+	t.NoteOK = rgb(255, 255, 0)
+	t.NoteMiss = rgb(255, 0, 0)
+	t.NoteText = rgb(0, 0, 0)
+	t.NoteName = rgb(255, 255, 255)
+	t.HitSymbol = rgb(255, 255, 255)
+
+	t.GraphAxis = rgb(200, 200, 200)
+	t.MeterTrack = rgb(40, 40, 40)
+	t.MeterTick = rgb(200, 200, 200)
+	t.HeatEmpty = rgb(25, 25, 25)
+	return t
+}
+
+// RedGreenTheme is the dark theme with hits, misses and meters told apart
+// by blue, yellow and vermilion instead of green and red, from the
+// Okabe-Ito palette
+var RedGreenTheme = redGreen()
+
+func redGreen() Theme {
+	t := DarkTheme
+	blue, paleBlue := rgb(86, 180, 233), rgb(190, 215, 255)
+	yellow, vermilion := rgb(240, 228, 66), rgb(213, 94, 0)
+	t.Success = blue
+	t.Error = vermilion
+	t.NotePerfect = blue
+	t.NoteGood = paleBlue
+	t.NoteOK = yellow
+	t.NoteMiss = vermilion
+	t.DetectedNote = blue
+	t.GraphPassed = blue
+	t.HeatLow = rgb(0, 114, 178)
+	t.HeatHigh = vermilion
+	t.HealthHigh = blue
+	t.HealthMid = yellow
+	t.HealthLow = vermilion
+	t.GhostBehind = vermilion
+	return t
+}
+
+// BlueYellowTheme is the dark theme with hits and misses told apart by
+// teal and red, which stay distinct without blue-yellow vision
+var BlueYellowTheme = blueYellow()
+
+func blueYellow() Theme {
+	t := DarkTheme
+	teal, paleTeal := rgb(0, 200, 200), rgb(170, 235, 235)
+	pink, red := rgb(255, 150, 190), rgb(220, 30, 50)
+	t.Success = teal
+	t.Error = red
+	t.Highlight = pink
+	t.NotePerfect = teal
+	t.NoteGood = paleTeal
+	t.NoteOK = pink
+	t.NoteMiss = red
+	t.DetectedNote = teal
+	t.GraphPassed = teal
+	t.GraphMean = pink
+	t.HeatLow = rgb(0, 150, 150)
+	t.HeatHigh = red
+	t.HealthHigh = teal
+	t.HealthMid = pink
+	t.HealthLow = red
+	t.GhostAhead = pink
+	t.GhostBehind = red
+	return t
+}
+
+// Colors is the theme being drawn with
+var Colors = DarkTheme
+
+// BuiltinTheme returns one of the themes that come with the game
+func BuiltinTheme(name string) (Theme, bool) {
+	switch name {
+	case ThemeDark, "":
+		return DarkTheme, true
+	case ThemeLight:
+		return LightTheme, true
+	case ThemeHighContrast:
+		return HighContrastTheme, true
+	case ThemeRedGreen:
+		return RedGreenTheme, true
+	case ThemeBlueYellow:
+		return BlueYellowTheme, true
+	}
+	return Theme{}, false
+}
+
+// BuiltinThemes lists the names of the built-in themes
+func BuiltinThemes() []string {
+	return []string{ThemeDark, ThemeLight, ThemeHighContrast, ThemeRedGreen, ThemeBlueYellow}
+}
+
+// Set changes one of the theme's colors by name (e.g. "note_perfect") to
+// a color written "#rrggbb" or "#rrggbbaa"
+func (t *Theme) Set(name, value string) error {
+	c, ok := t.named()[name]
+	if !ok {
+		return fmt.Errorf("no theme color called %q", name)
+	}
+	parsed, err := ParseColor(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*c = parsed
+	return nil
+}
+
+// named returns each of the theme's colors by the name players use for it
+func (t *Theme) named() map[string]*color.NRGBA {
+	return map[string]*color.NRGBA{
+		"background":      &t.Background,
+		"title":           &t.Title,
+		"text":            &t.Text,
+		"hint":            &t.Hint,
+		"disabled":        &t.Disabled,
+		"highlight":       &t.Highlight,
+		"info":            &t.Info,
+		"accent":          &t.Accent,
+		"success":         &t.Success,
+		"selected":        &t.Selected,
+		"warning":         &t.Warning,
+		"error":           &t.Error,
+		"combo":           &t.Combo,
+		"flame":           &t.Flame,
+		"list_item":       &t.ListItem,
+		"list_selected":   &t.ListItemSelected,
+		"list_glow":       &t.ListItemGlow,
+		"input":           &t.Input,
+		"input_hint":      &t.InputHint,
+		"string":          &t.String,
+		"play_line":       &t.PlayLine,
+		"play_line_glow":  &t.PlayLineGlow,
+		"bar_line":        &t.BarLine,
+		"beat_tick":       &t.BeatTick,
+		"crossing":        &t.Crossing,
+		"fret_lane":       &t.FretLane,
+		"note":            &t.NoteDefault,
+		"note_perfect":    &t.NotePerfect,
+		"note_good":       &t.NoteGood,
+		"note_ok":         &t.NoteOK,
+		"note_miss":       &t.NoteMiss,
+		"note_text":       &t.NoteText,
+		"note_name":       &t.NoteName,
+		"hit_symbol":      &t.HitSymbol,
+		"detected_note":   &t.DetectedNote,
+		"grid_bar":        &t.GridBar,
+		"grid_beat":       &t.GridBeat,
+		"grid_sub":        &t.GridSub,
+		"cursor":          &t.Cursor,
+		"cursor_fill":     &t.CursorFill,
+		"ghost":           &t.Ghost,
+		"ghost_ahead":     &t.GhostAhead,
+		"ghost_behind":    &t.GhostBehind,
+		"staff":           &t.Staff,
+		"accidental":      &t.Accidental,
+		"staff_ledger":    &t.StaffLedger,
+		"graph_axis":      &t.GraphAxis,
+		"graph_passed":    &t.GraphPassed,
+		"graph_failed":    &t.GraphFailed,
+		"graph_spread":    &t.GraphSpread,
+		"graph_mean":      &t.GraphMean,
+		"graph_hit":       &t.GraphHit,
+		"heat_empty":      &t.HeatEmpty,
+		"heat_low":        &t.HeatLow,
+		"heat_high":       &t.HeatHigh,
+		"fretboard":       &t.Fretboard,
+		"fret":            &t.Fret,
+		"fret_marker":     &t.FretMarker,
+		"scale_note":      &t.ScaleNote,
+		"scale_root_note": &t.ScaleRootNote,
+		"meter_track":     &t.MeterTrack,
+		"meter_tick":      &t.MeterTick,
+		"health_high":     &t.HealthHigh,
+		"health_mid":      &t.HealthMid,
+		"health_low":      &t.HealthLow,
+	}
+}
+
+// ParseColor reads a color written "#rrggbb" or "#rrggbbaa"
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return color.NRGBA{}, fmt.Errorf("%q is not a color like #ff8800", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// ApplyMaterial colors a material theme's defaults to match
+func (t Theme) ApplyMaterial(th *material.Theme) {
+	th.Palette.Bg = t.Background
+	th.Palette.Fg = t.Title
+	th.Palette.ContrastBg = t.Accent
+	th.Palette.ContrastFg = t.Background
+}
+
+// withAlpha returns a color with a different opacity
+func withAlpha(c color.NRGBA, a uint8) color.NRGBA {
+	c.A = a
+	return c
+}
+
+func rgb(r, g, b uint8) color.NRGBA {
+	return color.NRGBA{R: r, G: g, B: b, A: 255}
+}
+
+func rgba(r, g, b, a uint8) color.NRGBA {
+	return color.NRGBA{R: r, G: g, B: b, A: a}
+}
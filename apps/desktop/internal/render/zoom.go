@@ -0,0 +1,58 @@
+package render
+
+import (
+	"math"
+	"time"
+
+	"guitargame/core/song"
+)
+
+// Dynamic zoom spreads busy passages out so fast fills stay readable,
+// measuring how busy the next few beats are and easing towards the zoom
+// that suits them
+const (
+	maxZoom             = 2.0
+	zoomLookaheadBeats  = 4
+	relaxedNotesPerBeat = 2.0 // Eighth notes are drawn at normal zoom; busier passages zoom in
+	zoomRate            = 2.0 // How quickly zoom follows density, per second
+)
+
+// zoomState eases dynamic zoom between frames
+type zoomState struct {
+	level   float64
+	updated time.Time
+}
+
+// updateZoom returns how much to stretch the highway for the notes coming
+// up, or 1 when dynamic zoom is off
+func (r *TabRenderer) updateZoom(state *song.GameState) float32 {
+	now := time.Now()
+	last := r.zoom.updated
+	r.zoom.updated = now
+	if !r.DynamicZoom {
+		r.zoom.level = 1
+		return 1
+	}
+
+	target := zoomTarget(state.Song, state.CurrentTime)
+	if r.zoom.level == 0 || last.IsZero() {
+		r.zoom.level = target
+	}
+	// Ease exponentially so a sudden fill doesn't jolt the view
+	step := 1 - math.Exp(-zoomRate*now.Sub(last).Seconds())
+	r.zoom.level += (target - r.zoom.level) * step
+	return float32(r.zoom.level)
+}
+
+// zoomTarget is the zoom that suits the density of notes starting at t
+func zoomTarget(s *song.Song, t float64) float64 {
+	end := s.BeatToTime(s.TimeToBeat(t) + zoomLookaheadBeats)
+	notes := 0
+	for i := range s.Notes {
+		if s.Notes[i].Time >= t && s.Notes[i].Time < end {
+			notes++
+		}
+	}
+	density := float64(notes) / zoomLookaheadBeats
+	return math.Max(1, math.Min(maxZoom, density/relaxedNotesPerBeat))
+}
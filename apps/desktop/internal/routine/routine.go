@@ -0,0 +1,127 @@
+// Package routine holds practice routines: named plans of songs and
+// generated riffs to work through in order, scheduled on days of the week
+package routine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the routines file kept in a profile's directory
+const FileName = "routines.yaml"
+
+// Routine is a named practice plan
+type Routine struct {
+	Name  string   `yaml:"name"`
+	Days  []string `yaml:"days,omitempty"` // Days it's scheduled on ("mon" to "sun"); every day if none
+	Items []Item   `yaml:"items"`
+}
+
+// Item is one step of a routine: a song, or a generated riff
+type Item struct {
+	Song    string  `yaml:"song,omitempty"`    // Chart file, or title of a built-in song
+	Riff    string  `yaml:"riff,omitempty"`    // Genre of a generated riff to play instead, e.g. "Funk"
+	Key     string  `yaml:"key,omitempty"`     // Key of a generated riff; E if unset
+	Tempo   float64 `yaml:"tempo,omitempty"`   // Target tempo in BPM; full speed if unset
+	Minutes float64 `yaml:"minutes,omitempty"` // How long to keep playing it; once through if unset
+}
+
+// file is the layout of the routines file
+type file struct {
+	Routines []Routine `yaml:"routines"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Load reads the routines in a file; it's fine for there not to be one
+func Load(path string) ([]Routine, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, r := range f.Routines {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return f.Routines, nil
+}
+
+// Save writes routines to a file
+func Save(path string, routines []Routine) error {
+	data, err := yaml.Marshal(file{Routines: routines})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (r Routine) validate() error {
+	if r.Name == "" {
+		return errors.New("routine without a name")
+	}
+	for _, d := range r.Days {
+		if _, ok := weekdays[strings.ToLower(d)]; !ok {
+			return fmt.Errorf("routine %q: unknown day %q (use mon to sun)", r.Name, d)
+		}
+	}
+	for i, it := range r.Items {
+		if (it.Song == "") == (it.Riff == "") {
+			return fmt.Errorf("routine %q: item %d needs either a song or a riff", r.Name, i+1)
+		}
+	}
+	return nil
+}
+
+// ScheduledOn reports whether the routine is planned for a day of the week
+func (r Routine) ScheduledOn(day time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, d := range r.Days {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Minutes returns how long the timed items of the routine take
+func (r Routine) Minutes() float64 {
+	total := 0.0
+	for _, it := range r.Items {
+		total += it.Minutes
+	}
+	return total
+}
+
+// Today returns the routines scheduled for a time's day
+func Today(routines []Routine, now time.Time) []Routine {
+	var today []Routine
+	for _, r := range routines {
+		if r.ScheduledOn(now.Weekday()) {
+			today = append(today, r)
+		}
+	}
+	return today
+}
@@ -0,0 +1,97 @@
+// Package scores keeps the best score, grade, accuracy and combo reached
+// on each song
+package scores
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileName is the scores file kept in the config directory
+const FileName = "scores.json"
+
+// grades from best to worst
+const grades = "SABCDF"
+
+// Best is a song's personal bests. Each is the best reached on any run,
+// not necessarily the same one.
+type Best struct {
+	Score    int     `json:"score"`
+	Grade    string  `json:"grade"`
+	Accuracy float64 `json:"accuracy"` // Percent of notes hit
+	MaxCombo int     `json:"max_combo"`
+}
+
+// Store holds the personal bests of every song played, keyed by chart
+// content hash so an edited chart starts afresh
+type Store struct {
+	path string
+	best map[string]Best
+}
+
+// Open reads the scores file at path; it's fine for there not to be one yet
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, best: make(map[string]Best)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.best); err != nil {
+		return nil, err
+	}
+	if s.best == nil {
+		s.best = make(map[string]Best)
+	}
+	return s, nil
+}
+
+// Best returns a song's personal bests, if it has been played
+func (s *Store) Best(hash string) (Best, bool) {
+	b, ok := s.best[hash]
+	return b, ok
+}
+
+// Record merges a run into a song's bests, saving them if any improved
+func (s *Store) Record(hash string, run Best) error {
+	prev, played := s.best[hash]
+	if !played {
+		s.best[hash] = run
+		return s.save()
+	}
+	best := prev
+	best.Score = max(best.Score, run.Score)
+	if betterGrade(run.Grade, prev.Grade) {
+		best.Grade = run.Grade
+	}
+	best.Accuracy = max(best.Accuracy, run.Accuracy)
+	best.MaxCombo = max(best.MaxCombo, run.MaxCombo)
+	if best == prev {
+		return nil
+	}
+	s.best[hash] = best
+	return s.save()
+}
+
+// betterGrade reports whether grade a ranks above grade b
+func betterGrade(a, b string) bool {
+	i, j := strings.Index(grades, a), strings.Index(grades, b)
+	return i >= 0 && (j < 0 || i < j)
+}
+
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.best, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
@@ -0,0 +1,301 @@
+// Package script runs exercise generator scripts. A script is written in
+// Starlark (a small dialect of Python) and emits songs built from notes, so
+// teachers can write parameterized exercises without recompiling the game.
+//
+// Scripts see these builtins:
+//
+//	params                        values passed with -p name=value (a dict)
+//	note(beat, string, fret, duration=0)
+//	pitch("A1")                   MIDI note number of a note name
+//	place(midi, near=0, max_fret=12, tuning="", instrument="")
+//	                              (string, fret) to play a MIDI note, or None
+//	emit(title, bpm, notes, artist="", tuning="", instrument="", drums="", swing=0)
+//
+// Fields are as in a chart file: strings count from the highest, and a
+// note's duration is in seconds (a beat, if left out).
+package script
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+	"gopkg.in/yaml.v3"
+
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/core/song"
+)
+
+// maxSteps bounds how long a script may run, so a runaway loop in a
+// script in the songs directory can't hang the game
+const maxSteps = 50_000_000
+
+// defaultMaxFret is the highest fret place() uses unless told otherwise
+const defaultMaxFret = 12
+
+// Run runs a script, returning the songs it emits. Params are offered to
+// the script as strings, or numbers when they parse as one.
+func Run(path string, params map[string]string) ([]*song.Song, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var songs []*song.Song
+	thread := &starlark.Thread{
+		Name: path,
+		Print: func(_ *starlark.Thread, msg string) {
+			logging.Infof("%s: %s", filepath.Base(path), msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+
+	predeclared := starlark.StringDict{
+		"params": paramsDict(params),
+		"note":   starlark.NewBuiltin("note", builtinNote),
+		"pitch":  starlark.NewBuiltin("pitch", builtinPitch),
+		"place":  starlark.NewBuiltin("place", builtinPlace),
+		"emit": starlark.NewBuiltin("emit", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			s, err := emit(b, args, kwargs)
+			if err != nil {
+				return nil, err
+			}
+			s.Generator = path
+			songs = append(songs, s)
+			return starlark.None, nil
+		}),
+	}
+
+	opts := &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+	if _, err := starlark.ExecFileOptions(opts, thread, path, src, predeclared); err != nil {
+		var evalErr *starlark.EvalError
+		if errors.As(err, &evalErr) {
+			return nil, errors.New(evalErr.Backtrace())
+		}
+		return nil, err
+	}
+	if len(songs) == 0 {
+		return nil, errors.New("the script didn't emit any songs")
+	}
+	return songs, nil
+}
+
+// LoadDir runs every script in a directory with no params, returning the
+// songs they emit and the scripts that failed
+func LoadDir(dir string) ([]*song.Song, []song.SkippedChart) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+
+	var songs []*song.Song
+	var failed []song.SkippedChart
+	for _, e := range entries {
+		if e.IsDir() || !song.IsScriptFile(e.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		generated, err := Run(path, nil)
+		if err != nil {
+			failed = append(failed, song.SkippedChart{Path: path, Err: err})
+			continue
+		}
+		songs = append(songs, generated...)
+	}
+	return songs, failed
+}
+
+// ParseParams parses name=value pairs, as given on the command line
+func ParseParams(pairs []string) (map[string]string, error) {
+	params := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("param %q should be name=value", p)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// paramsDict makes the script's params, turning numbers into numbers
+func paramsDict(params map[string]string) *starlark.Dict {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	d := starlark.NewDict(len(params))
+	for _, name := range names {
+		value := params[name]
+		var v starlark.Value = starlark.String(value)
+		if i, err := strconv.Atoi(value); err == nil {
+			v = starlark.MakeInt(i)
+		} else if f, err := strconv.ParseFloat(value, 64); err == nil {
+			v = starlark.Float(f)
+		}
+		d.SetKey(starlark.String(name), v)
+	}
+	return d
+}
+
+// number is a builtin's argument that may be an int or a float
+type number float64
+
+func (n *number) Unpack(v starlark.Value) error {
+	f, ok := starlark.AsFloat(v)
+	if !ok {
+		return fmt.Errorf("got %s, want a number", v.Type())
+	}
+	*n = number(f)
+	return nil
+}
+
+// builtinNote makes a note: note(beat, string, fret, duration=0)
+func builtinNote(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var beat, duration number
+	var str, fret int
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "beat", &beat, "string", &str, "fret", &fret, "duration?", &duration); err != nil {
+		return nil, err
+	}
+	if beat < 0 || str < 0 || fret < 0 || duration < 0 {
+		return nil, errors.New("beat, string, fret and duration can't be negative")
+	}
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"beat":     starlark.Float(beat),
+		"string":   starlark.MakeInt(str),
+		"fret":     starlark.MakeInt(fret),
+		"duration": starlark.Float(duration),
+	}), nil
+}
+
+// builtinPitch returns the MIDI note of a note name: pitch("A1")
+func builtinPitch(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &name); err != nil {
+		return nil, err
+	}
+	midi, err := parsePitch(name)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.MakeInt(midi), nil
+}
+
+// parsePitch parses a note name with its octave, such as "E1" or "Bb2"
+func parsePitch(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	i := 1
+	if len(name) > 1 && (name[1] == '#' || name[1] == 'b') {
+		i = 2
+	}
+	if name == "" || !strings.ContainsRune("ABCDEFG", rune(name[0])) {
+		return 0, fmt.Errorf("%q isn't a note name like A1 or C#2", name)
+	}
+	octave, err := strconv.Atoi(name[i:])
+	if err != nil {
+		return 0, fmt.Errorf("%q isn't a note name like A1 or C#2", name)
+	}
+	return song.StringTuning{Note: name[:i], Octave: octave}.MIDINote(), nil
+}
+
+// builtinPlace finds where to play a MIDI note near a fret:
+// place(midi, near=0, max_fret=12, tuning="", instrument="")
+func builtinPlace(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var midi, near int
+	maxFret := defaultMaxFret
+	var tuning, instrument string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "midi", &midi, "near?", &near, "max_fret?", &maxFret, "tuning?", &tuning, "instrument?", &instrument); err != nil {
+		return nil, err
+	}
+	s := &song.Song{InstrumentName: instrument}
+	pos, ok := s.Instrument().ParseTuning(tuning).NearestPosition(midi, maxFret, near)
+	if !ok {
+		return starlark.None, nil
+	}
+	return starlark.Tuple{starlark.MakeInt(pos.String), starlark.MakeInt(pos.Fret)}, nil
+}
+
+// emit builds a song from the script's arguments: emit(title, bpm, notes,
+// artist="", tuning="", instrument="", drums="", swing=0)
+func emit(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (*song.Song, error) {
+	s := &song.Song{}
+	var bpm, swing number
+	var notes *starlark.List
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"title", &s.Title, "bpm", &bpm, "notes", &notes,
+		"artist?", &s.Artist, "tuning?", &s.TuningStr, "instrument?", &s.InstrumentName,
+		"drums?", &s.Drums, "swing?", &swing); err != nil {
+		return nil, err
+	}
+	s.BPM, s.Swing = float64(bpm), float64(swing)
+
+	for i := 0; i < notes.Len(); i++ {
+		n, err := toNote(notes.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("note %d: %v", i+1, err)
+		}
+		s.Notes = append(s.Notes, n)
+	}
+
+	// Load it as a chart would be, so beats become times and it's checked
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := song.ParseSong(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := loaded.Validate(); err != nil {
+		return nil, fmt.Errorf("%q: %v", s.Title, err)
+	}
+	return loaded, nil
+}
+
+// toNote reads a note made by note()
+func toNote(v starlark.Value) (song.TabNote, error) {
+	st, ok := v.(*starlarkstruct.Struct)
+	if !ok {
+		return song.TabNote{}, fmt.Errorf("got %s, want a note()", v.Type())
+	}
+	var n song.TabNote
+	var beat float64
+	fields := []struct {
+		name string
+		dst  any
+	}{
+		{"beat", &beat},
+		{"string", &n.String},
+		{"fret", &n.Fret},
+		{"duration", &n.Duration},
+	}
+	for _, f := range fields {
+		attr, err := st.Attr(f.name)
+		if err != nil {
+			return song.TabNote{}, fmt.Errorf("got a struct without %s, want a note()", f.name)
+		}
+		switch dst := f.dst.(type) {
+		case *int:
+			err = starlark.AsInt(attr, dst)
+		case *float64:
+			var ok bool
+			if *dst, ok = starlark.AsFloat(attr); !ok {
+				err = fmt.Errorf("%s is %s, want a number", f.name, attr.Type())
+			}
+		}
+		if err != nil {
+			return song.TabNote{}, err
+		}
+	}
+	n.Beat = song.Beat(beat)
+	return n, nil
+}
@@ -0,0 +1,150 @@
+// Package telemetry sends anonymous usage counts, for players who opt in,
+// to help decide which features and platforms to work on. Nothing sent
+// identifies the player or what they played.
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"guitargame/apps/desktop/internal/logging"
+)
+
+// Files kept in the config directory: counts not yet sent, and a marker
+// that's there while the game runs, so finding it at startup means the
+// last run crashed
+const (
+	countsFile  = "telemetry.json"
+	runningFile = "telemetry.running"
+)
+
+// sendTimeout bounds how long a report may take to post
+const sendTimeout = 10 * time.Second
+
+// Counts are the usage counts gathered since the last report was sent
+type Counts struct {
+	SongsPlayed int `json:"songs_played"`
+	Crashes     int `json:"crashes"`
+}
+
+// Report is everything posted to the endpoint
+type Report struct {
+	Counts
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Detector string `json:"detector"` // Pitch detection algorithm
+}
+
+// Recorder counts usage and posts it to an endpoint
+type Recorder struct {
+	endpoint string
+	dir      string
+	detector string
+
+	mu     sync.Mutex
+	counts Counts
+}
+
+// Start begins recording in dir, counting a crash if the last run didn't
+// end cleanly, and posts the counts gathered so far in the background
+func Start(dir, endpoint, detector string) (*Recorder, error) {
+	r := &Recorder{endpoint: endpoint, dir: dir, detector: detector}
+	if data, err := os.ReadFile(filepath.Join(dir, countsFile)); err == nil {
+		if err := json.Unmarshal(data, &r.counts); err != nil {
+			logging.Warnf("discarding unreadable usage counts: %v", err)
+		}
+	}
+	running := filepath.Join(dir, runningFile)
+	if _, err := os.Stat(running); err == nil {
+		r.counts.Crashes++
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(running, nil, 0o644); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.save()
+	r.mu.Unlock()
+
+	go r.send()
+	return r, nil
+}
+
+// SongPlayed counts a song being started
+func (r *Recorder) SongPlayed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts.SongsPlayed++
+	r.save()
+}
+
+// End marks the run as having ended cleanly
+func (r *Recorder) End() {
+	if err := os.Remove(filepath.Join(r.dir, runningFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logging.Warnf("could not clear the running marker: %v", err)
+	}
+}
+
+// send posts the counts and, once the endpoint has them, takes them off
+// what's pending
+func (r *Recorder) send() {
+	r.mu.Lock()
+	sent := r.counts
+	r.mu.Unlock()
+	if sent == (Counts{}) {
+		return
+	}
+
+	report := Report{Counts: sent, OS: runtime.GOOS, Arch: runtime.GOARCH, Detector: r.detector}
+	if err := post(r.endpoint, report); err != nil {
+		logging.Warnf("could not send usage stats: %v", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts.SongsPlayed -= sent.SongsPlayed
+	r.counts.Crashes -= sent.Crashes
+	r.save()
+}
+
+func post(endpoint string, report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: sendTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", endpoint, resp.Status)
+	}
+	return nil
+}
+
+// save writes the pending counts; the caller holds the lock
+func (r *Recorder) save() {
+	data, err := json.Marshal(r.counts)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(r.dir, countsFile), data, 0o644)
+	}
+	if err != nil {
+		logging.Warnf("could not save usage counts: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package transcribe
+
+import (
+	"math"
+
+	"guitargame/core/song"
+)
+
+// Detection tuning
+const (
+	minConfidence   = 0.5  // Frames below this are treated as silence
+	minNoteLength   = 0.06 // Shorter blips are discarded (seconds)
+	releaseTime     = 0.08 // Silence needed to end a note (seconds)
+	changeFrames    = 2    // Frames a new pitch must hold before it counts
+	reattackRatio   = 2.0  // RMS rise over the decayed level that counts as a new pluck
+	minReattackRMS  = 0.01
+	maxFret         = 24
+	defaultNearFret = 3
+)
+
+// Frame is one pitch-detector reading
+type Frame struct {
+	Time       float64 // Seconds since recording started
+	Frequency  float64
+	Confidence float64
+	RMS        float64
+}
+
+func (f Frame) valid() bool {
+	return f.Confidence > minConfidence && f.Frequency >= 20
+}
+
+func (f Frame) midiNote() int {
+	return int(math.Round(12*math.Log2(f.Frequency/440) + 69))
+}
+
+// rawNote is a detected note before quantization
+type rawNote struct {
+	start, end float64
+	midiNote   int
+}
+
+// activeNote is the note currently ringing
+type activeNote struct {
+	rawNote
+	lastSeen float64
+
+	// Levels for re-attack detection: the loudest reading so far and the
+	// quietest one since then
+	peakRMS  float64
+	floorRMS float64
+}
+
+// Transcriber turns a stream of pitch readings into tab notes
+type Transcriber struct {
+	BPM         float64
+	Subdivision int // Grid steps per beat (e.g. 2 for eighth notes)
+	Tuning      song.Tuning
+
+	notes   []rawNote
+	current *activeNote
+
+	// Pending pitch change, confirmed after changeFrames readings
+	candidate      int
+	candidateCount int
+	candidateStart float64
+}
+
+// New creates a transcriber that quantizes to subdivision steps per beat at bpm
+func New(bpm float64, subdivision int, tuning song.Tuning) *Transcriber {
+	if subdivision < 1 {
+		subdivision = 1
+	}
+	return &Transcriber{
+		BPM:         bpm,
+		Subdivision: subdivision,
+		Tuning:      tuning,
+	}
+}
+
+// Add feeds the next detector reading
+func (t *Transcriber) Add(f Frame) {
+	if !f.valid() {
+		t.candidateCount = 0
+		if t.current != nil && f.Time-t.current.lastSeen > releaseTime {
+			t.finish(t.current.lastSeen)
+		}
+		return
+	}
+
+	midiNote := f.midiNote()
+	switch {
+	case t.current == nil:
+		t.begin(midiNote, f)
+	case midiNote != t.current.midiNote:
+		// Require the new pitch to hold so octave glitches don't split notes
+		if t.candidateCount == 0 || t.candidate != midiNote {
+			t.candidate = midiNote
+			t.candidateCount = 0
+			t.candidateStart = f.Time
+		}
+		t.candidateCount++
+		if t.candidateCount >= changeFrames {
+			start := t.candidateStart
+			t.finish(start)
+			t.begin(midiNote, Frame{Time: start, RMS: f.RMS})
+		}
+	default:
+		t.candidateCount = 0
+		// Same pitch plucked again: the level jumps after having decayed
+		n := t.current
+		decayed := n.floorRMS < n.peakRMS*0.5
+		if decayed && f.RMS > minReattackRMS && f.RMS > n.floorRMS*reattackRatio {
+			t.finish(f.Time)
+			t.begin(midiNote, f)
+			return
+		}
+		if f.RMS > n.peakRMS {
+			n.peakRMS = f.RMS
+			n.floorRMS = f.RMS
+		} else {
+			n.floorRMS = math.Min(n.floorRMS, f.RMS)
+		}
+		n.lastSeen = f.Time
+	}
+}
+
+// Finish ends any ringing note; call when recording stops
+func (t *Transcriber) Finish(at float64) {
+	if t.current != nil {
+		t.finish(math.Min(at, t.current.lastSeen+releaseTime))
+	}
+}
+
+// Count returns the number of notes detected so far
+func (t *Transcriber) Count() int {
+	return len(t.notes)
+}
+
+func (t *Transcriber) begin(midiNote int, f Frame) {
+	t.current = &activeNote{
+		rawNote:  rawNote{start: f.Time, midiNote: midiNote},
+		lastSeen: f.Time,
+		peakRMS:  f.RMS,
+		floorRMS: f.RMS,
+	}
+	t.candidateCount = 0
+}
+
+func (t *Transcriber) finish(end float64) {
+	n := t.current
+	t.current = nil
+	if n == nil || end-n.start < minNoteLength {
+		return
+	}
+	n.end = end
+	t.notes = append(t.notes, n.rawNote)
+}
+
+// Notes returns the detected notes quantized to the grid and placed on
+// the fretboard. Notes that land on the same grid step keep the first.
+func (t *Transcriber) Notes() []song.TabNote {
+	beatDuration := 60.0 / t.BPM
+	step := 1.0 / float64(t.Subdivision)
+	quantize := func(seconds float64) float64 {
+		return math.Round(seconds/beatDuration/step) * step
+	}
+
+	var notes []song.TabNote
+	lastBeat := -1.0
+	nearFret := defaultNearFret
+	for _, raw := range t.notes {
+		beat := quantize(raw.start)
+		if beat < 0 || beat <= lastBeat {
+			continue
+		}
+		pos, ok := t.Tuning.NearestPosition(raw.midiNote, maxFret, nearFret)
+		if !ok {
+			continue // Out of the instrument's range
+		}
+		length := math.Max(step, quantize(raw.end)-beat)
+
+		notes = append(notes, song.TabNote{
+			Time:     beat * beatDuration,
+			Beat:     song.Beat(beat),
+			String:   pos.String,
+			Fret:     pos.Fret,
+			Duration: length * beatDuration * 0.9,
+		})
+		lastBeat = beat
+		if pos.Fret > 0 {
+			nearFret = pos.Fret
+		}
+	}
+	return notes
+}
+
+// Song builds a chart from the notes transcribed so far
+func (t *Transcriber) Song(title string) *song.Song {
+	s := &song.Song{
+		Title:     title,
+		Artist:    "Recorded",
+		BPM:       t.BPM,
+		TuningStr: t.Tuning.Name(),
+		Notes:     t.Notes(),
+		Tuning:    t.Tuning,
+	}
+	s.CalculateDuration()
+	return s
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"guitargame/apps/desktop/internal/livestats"
+	"guitargame/apps/desktop/internal/logging"
+)
+
+// liveStatsInterval is how often the state of play is published
+const liveStatsInterval = 100 * time.Millisecond
+
+// screenNames name each screen in published snapshots
+var screenNames = map[AppState]string{
+	StateMenu:           "menu",
+	StatePreStart:       "pre-start",
+	StatePlaying:        "playing",
+	StateResults:        "results",
+	StateEditor:         "editor",
+	StateRecording:      "recording",
+	StateGenerator:      "generator",
+	StatePermission:     "permission",
+	StateTrainerResults: "trainer-results",
+	StateAudioTest:      "audio-test",
+	StatePlayback:       "playback",
+	StateDashboard:      "dashboard",
+	StateProfiles:       "profiles",
+	StateSetlistBreak:   "setlist-break",
+	StateSetlistResults: "setlist-results",
+	StateQuiz:           "quiz",
+}
+
+// startLiveStats serves the state of play for overlays on the address
+// the launch settings give, if any
+func (a *App) startLiveStats() {
+	if a.launch.StatsAddr == "" {
+		return
+	}
+	s, err := livestats.Start(a.launch.StatsAddr, a.launch.StatsOrigins)
+	if err != nil {
+		a.warn("could not serve live stats: %v", err)
+		return
+	}
+	a.liveStats = s
+	logging.Infof("Serving live stats at http://%s/state and ws://%s/ws", s.Addr(), s.Addr())
+}
+
+// closeLiveStats stops serving the state of play
+func (a *App) closeLiveStats() {
+	if a.liveStats == nil {
+		return
+	}
+	if err := a.liveStats.Close(); err != nil {
+		logging.Warnf("could not stop serving live stats: %v", err)
+	}
+	a.liveStats = nil
+}
+
+// publishLiveStats sends the state of play to overlays, a few times a
+// second
+func (a *App) publishLiveStats() {
+	if a.liveStats == nil || time.Since(a.liveStatsAt) < liveStatsInterval {
+		return
+	}
+	a.liveStatsAt = time.Now()
+	a.liveStats.Publish(a.liveSnapshot())
+}
+
+// liveSnapshot describes the state of play: the song, score and next
+// note while playing or looking at results, and what's being heard
+func (a *App) liveSnapshot() livestats.Snapshot {
+	snap := livestats.Snapshot{Screen: screenNames[a.state]}
+	if p := a.currentPitch; p.IsValid() {
+		snap.Pitch = &livestats.Pitch{Name: p.FullNoteName(), Frequency: p.Frequency, Cents: p.Cents}
+	}
+	gs := a.gameState
+	if gs == nil || (a.state != StatePlaying && a.state != StateResults && a.state != StatePreStart) {
+		return snap
+	}
+	s := gs.Song
+	snap.Song, snap.Artist = s.Title, s.Artist
+	snap.Time, snap.Duration = gs.CurrentTime, s.Duration
+	snap.Score, snap.Combo, snap.Multiplier = gs.Score, gs.Combo, gs.Multiplier()
+	snap.Accuracy = gs.Accuracy()
+	snap.Hits, snap.Misses = gs.NotesHit, gs.NotesMissed
+	if n := gs.NextNote(); n != nil {
+		snap.Note = &livestats.Note{
+			Name:   fmt.Sprintf("%s%d", s.NoteAt(n), s.OctaveAt(n)),
+			String: n.String + 1,
+			Fret:   n.Fret,
+			Time:   n.Time,
+		}
+	}
+	return snap
+}
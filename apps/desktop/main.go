@@ -1,25 +1,53 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"flag"
 	"fmt"
+	"image"
 	"image/color"
-	"log"
+	"io"
+	"math"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"gioui.org/app"
+	"gioui.org/io/key"
+	"gioui.org/io/semantic"
+	"gioui.org/io/system"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/text"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
 
+	"guitargame/apps/desktop/internal/assets"
 	"guitargame/apps/desktop/internal/audio"
-	"guitargame/apps/desktop/internal/game"
+	"guitargame/apps/desktop/internal/backing"
+	"guitargame/apps/desktop/internal/config"
+	"guitargame/apps/desktop/internal/editor"
+	"guitargame/apps/desktop/internal/generator"
+	"guitargame/apps/desktop/internal/history"
+	"guitargame/apps/desktop/internal/livestats"
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/midi"
+	"guitargame/apps/desktop/internal/mode"
+	"guitargame/apps/desktop/internal/osc"
 	"guitargame/apps/desktop/internal/render"
-	"guitargame/apps/desktop/internal/song"
+	"guitargame/apps/desktop/internal/routine"
+	"guitargame/apps/desktop/internal/scores"
+	"guitargame/apps/desktop/internal/telemetry"
+	"guitargame/core/game"
+	"guitargame/core/pitch"
+	"guitargame/core/song"
 )
 
 const (
@@ -27,6 +55,30 @@ const (
 	screenHeight = 500
 )
 
+// MinSpeed is the slowest practice speed, and speedStep how much each
+// press changes it by
+const (
+	MinSpeed  = 0.5
+	speedStep = 0.1
+)
+
+// latencyStep is how much each press changes the input latency by, in
+// milliseconds, up to maxLatencyMs
+const (
+	latencyStep  = 5
+	maxLatencyMs = 300
+)
+
+// A run with at least calibrationHits hits that are on average more than
+// calibrationOffset seconds off, more than they spread, suggests the
+// latency setting is wrong rather than the playing
+const (
+	calibrationHits   = 20
+	calibrationOffset = 0.06
+)
+
+var midiClockDevice = flag.String("midi-clock", "", "MIDI output to send beat clock to during play: the name of a port to create (e.g. guitargame), or a raw device path (e.g. /dev/snd/midiC1D0)")
+
 // AppState represents the current screen
 type AppState int
 
@@ -35,51 +87,226 @@ const (
 	StatePreStart
 	StatePlaying
 	StateResults
+	StateEditor
+	StateRecording
+	StateGenerator
+	StatePermission
+	StateTrainerResults
+	StateAudioTest
+	StatePlayback
+	StateDashboard
+	StateProfiles
+	StateSetlistBreak
+	StateSetlistResults
+	StateQuiz
 )
 
 type App struct {
-	audioInput    *audio.AudioInput
+	audioInput    *audio.AudioInput // nil if no input could be opened
+	inputErr      error             // Why there's no input
+	inputWatchdog *audio.Watchdog
+	audioOutput   *audio.AudioOutput // nil if no output device is available
 	pitchDetector *audio.PitchDetector
-	currentPitch  audio.PitchResult
+	currentPitch  pitch.Result
+	heard         string // Note last logged as heard; see logHeard
+
+	// Drum backing (nil drummer if there is no audio output)
+	drummer     *backing.Drummer
+	drumPattern *backing.Pattern
+
+	// Backing track of the song being played, if it has one
+	backingTrack *audio.SampleVoice
+
+	// Built-in assets overlaid by the user's directories
+	assets *assets.Manager
+
+	// Settings saved between runs
+	config *config.Config
+
+	// Hit, miss, and metronome sounds
+	sounds     *audio.SoundPack
+	soundPacks []string // Pack names; index 0 is the built-in pack
+	soundPack  int
+
+	// Optional MIDI beat clock for external drum machines
+	midiOut   io.WriteCloser
+	midiClock *midi.Clock
+
+	// Optional MIDI notes for what's heard; see EnableMIDINotes
+	midiNotes    *midi.NoteOut
+	midiNotesOut io.WriteCloser // Nil when sharing midiOut
 
 	theme       *material.Theme
 	tabRenderer *render.TabRenderer
+	highway     render.Highway // The tab renderer, or another view of the notes sharing its settings
 	hitDetector *game.HitDetector
 	gameState   *song.GameState
 
 	// Song selection
 	exercises     []*song.Song
 	selectedIndex int
+	songsDir      string // Directory new charts are saved to
+	songWatcher   *song.Watcher
+	search        menuSearch
+	songList      songList
+	covers        coverArt
+	preStartList  widget.List // Settings on the pre-start screen
+
+	// Chart editor
+	editor        *editor.Editor
+	editorDrag    bool
+	lastFretDigit time.Time
+	preview       *preview // Live preview window (nil unless opened)
+	window        *app.Window
+	fullscreen    bool
+	streaming     bool           // Laid out for livestreaming; see applyStreaming
+	capture       *captureWindow // Highway alone for capturing (nil unless open)
+
+	// Record-to-chart session (nil when not recording)
+	recorder *recorder
+
+	// Endless riff generator (riff is nil unless a generated riff is loaded)
+	riffSetup *riffSetup
+	riff      *generator.Riff
+
+	// Microphone access flow (nil once access is granted or skipped)
+	mic *micPermission
+
+	// Output and input check (nil unless its screen is open)
+	audioTest *audioTest
+
+	// Which chart the results screen shows, and where the results were
+	// exported to, if they have been
+	resultsChart    resultsChart
+	resultsExported string
+
+	// Passages missed in the last play, and one being practiced (nil otherwise)
+	missed      []song.Region
+	missedIndex int
+	practice    *practice
+
+	// Practice speed the next song is played at, from MinSpeed to 1
+	speed float64
+
+	// Speed trainer session (nil unless training)
+	trainer *trainer
+
+	// Call and response session (nil otherwise)
+	echo *echo
+
+	// Fretboard quiz (nil unless on the quiz screen)
+	quiz *quiz
+
+	// Scale or arpeggio shown on the fretboard: 0 for none, or one more
+	// than its index into song.Scales
+	scaleOverlay int
+
+	// Riff repeater watching the current run (nil when it's off)
+	repeater *song.Repeater
+
+	// Everything heard during the last full run, and the viewer playing
+	// it back (nil unless open)
+	recording *game.Recording
+	playback  *playback
+
+	// Best run of the song being played, raced against (nil if there's
+	// none to race)
+	ghost *game.Ghost
+
+	// Personal bests of every song (nil if they couldn't be loaded)
+	scores *scores.Store
+
+	// Every session played (nil if it couldn't be opened), and when the
+	// current one started (zero once it's recorded)
+	history      *history.DB
+	sessionStart time.Time
+
+	// Keys of the songs starred on the menu, and of those played last,
+	// latest first
+	favorites map[string]bool
+	recent    []string
+
+	// Progress screen (nil unless open)
+	dashboard *dashboard
+
+	// Profile chooser (nil unless open)
+	profiles *profileSelect
+
+	// Songs queued on the menu for a setlist, and the setlist being
+	// played (nil otherwise)
+	queue   []*song.Song
+	setlist *setlist
+
+	// The profile's practice routines
+	routines []routine.Routine
+
+	// Today's challenge and the day it was made for, and how past ones
+	// went, best first by day
+	daily       *song.Song
+	dailyDate   string
+	dailies     []history.DailyResult
+	dailyStreak int
+
+	// How songs are varied on each pass, and the varied loop being
+	// played (nil otherwise)
+	varyMode   generator.VaryMode
+	variations *variations
+
+	// Anonymous usage counts (nil unless the player opted in)
+	telemetry *telemetry.Recorder
+
+	// Startup settings, from the config file and command line
+	launch launchSettings
 
 	// UI state
 	state            AppState
 	lastNoteDetected bool
+	toasts           []toast // Notifications showing, oldest first
+	debugConsole     bool
+	closeOnce        sync.Once
+	liveStats        *livestats.Server // nil unless serving the state of play
+	liveStatsAt      time.Time         // When it was last published
+	osc              *osc.Sender       // nil unless sending OSC
+	logLevel         logging.Level     // Level to go back to when the debug console closes
 }
 
 func NewApp() (*App, error) {
-	sampleRate := float64(audio.DefaultSampleRate)
+	cfg, cfgErr := config.Load()
+	launch := launchSettingsFrom(cfg)
+
+	sampleRate := launch.SampleRate
 	bufferSize := audio.DefaultBufferSize
 
-	audioInput, err := audio.NewAudioInput(sampleRate, bufferSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create audio input: %w", err)
+	// Without an input nothing can be heard, but songs can still be
+	// browsed and charts edited
+	audioInput, inputErr := openInput(launch)
+	if inputErr != nil {
+		logging.Warnf("audio input unavailable: %v", inputErr)
 	}
 
 	pitchDetector := audio.NewPitchDetector(bufferSize, sampleRate)
 
-	if err := audioInput.Start(); err != nil {
-		audioInput.Close()
-		return nil, fmt.Errorf("failed to start audio: %w", err)
+	// Audio output is only used for auditioning notes, so run without it if unavailable
+	audioOutput, outputErr := audio.NewAudioOutput(sampleRate, audio.DefaultOutputBufferSize)
+	if outputErr == nil {
+		if outputErr = audioOutput.Start(); outputErr != nil {
+			audioOutput.Close()
+			audioOutput = nil
+		}
+	}
+
+	assetManager := assets.NewManager(assets.DefaultRoots()...)
+	if launch.SongsDir != "" {
+		assetManager.UseSongsDir(launch.SongsDir)
 	}
 
 	theme := material.NewTheme()
+	theme.Shaper = text.NewShaper(text.WithCollection(assetManager.Fonts()))
 	tabRenderer := render.NewTabRenderer(theme)
 
-	// Load songs from directory
-	exercises, err := loadSongs()
-	if err != nil {
-		log.Printf("Warning: could not load songs: %v", err)
-	}
+	// Load the user's songs on top of the built-in exercises
+	exercises, songsDir, skipped := assetManager.Songs()
+	logging.Infof("Loaded %d songs (new charts are saved to %s)", len(exercises), songsDir)
 	if len(exercises) == 0 {
 		// Fall back to default exercises
 		exercises = song.GetDefaultExercises()
@@ -87,51 +314,205 @@ func NewApp() (*App, error) {
 
 	// Initialize with first exercise
 	gameState := song.NewGameState(exercises[0])
-	hitDetector := game.NewHitDetector(gameState)
 
-	return &App{
+	sounds, soundsErr := assetManager.SoundPack(assets.DefaultSoundPack)
+
+	var drummer *backing.Drummer
+	if audioOutput != nil {
+		drummer = backing.NewDrummer(audioOutput)
+		drummer.SetSounds(sounds)
+	}
+
+	a := &App{
 		audioInput:    audioInput,
+		inputErr:      inputErr,
+		audioOutput:   audioOutput,
+		assets:        assetManager,
+		config:        cfg,
+		sounds:        sounds,
+		soundPacks:    assetManager.SoundPacks(),
 		pitchDetector: pitchDetector,
 		theme:         theme,
 		tabRenderer:   tabRenderer,
-		hitDetector:   hitDetector,
 		gameState:     gameState,
+		drummer:       drummer,
+		drumPattern:   backing.PatternByName(exercises[0].Drums),
 		exercises:     exercises,
 		selectedIndex: 0,
+		songsDir:      songsDir,
 		state:         StateMenu,
-	}, nil
+		speed:         1,
+		launch:        launch,
+		streaming:     launch.Streaming,
+	}
+	if audioInput != nil {
+		a.inputWatchdog = audio.NewWatchdog(audioInput, audio.DefaultStallTimeout)
+	}
+	a.hitDetector = game.NewHitDetector(gameState, a.feedbackY)
+	if cfgErr != nil {
+		a.warn("could not load settings: %v", cfgErr)
+	}
+	a.applyLogLevel()
+	if outputErr != nil {
+		a.warn("audio output unavailable: %v", outputErr)
+	}
+	if soundsErr != nil {
+		a.warn("could not load built-in sounds: %v", soundsErr)
+	}
+	a.reportSkippedCharts(skipped)
+	a.openScores()
+	a.openHistory()
+	a.loadDailies()
+	a.loadFavorites()
+	a.loadRecent()
+	a.loadRoutines()
+	a.applyHandedness()
+	a.applyNoteLabels()
+	a.applyHighway()
+	a.applyScroll()
+	a.applyStreaming()
+	a.watchSongs()
+	a.checkMicPermission()
+	a.restoreSession()
+	a.startTelemetry()
+	a.startLiveStats()
+	a.startOSC()
+	if names, _ := config.Profiles(); len(names) > 0 && a.state == StateMenu {
+		a.OpenProfiles()
+	}
+	return a, nil
 }
 
 func (a *App) Update() {
+	a.checkSongChanges()
+	defer a.publishLiveStats()
+
 	// Get audio and detect pitch
-	buffer := a.audioInput.GetBuffer()
-	a.currentPitch = a.pitchDetector.Detect(buffer)
+	if a.audioInput != nil {
+		buffer := a.audioInput.GetBuffer()
+		a.currentPitch = a.pitchDetector.Detect(buffer)
+		a.logHeard()
+		a.sendPitchOSC()
+		a.updateMIDINotes()
+		if a.state != StatePermission {
+			a.checkInputStall()
+		}
+	}
+	if a.preview != nil {
+		a.preview.setPitch(a.currentPitch)
+	}
+
+	if a.state == StateRecording {
+		a.updateRecording(a.currentPitch)
+	}
+	if a.state == StatePermission {
+		a.updateMicPermission()
+	}
+	if a.state == StateAudioTest {
+		a.updateAudioTest()
+	}
+	if a.state == StatePlayback {
+		a.updatePlayback()
+	}
+	if a.state == StateSetlistBreak {
+		a.updateSetlistBreak()
+	}
+	if a.state == StateQuiz {
+		a.updateQuiz()
+	}
 
 	if a.state != StatePlaying {
 		return
 	}
 
 	// Update game state
+	if a.riff != nil {
+		a.extendRiff()
+	}
 	a.gameState.Update()
+	if a.drummer != nil && a.practice == nil && a.trainer == nil && a.echo == nil && a.variations == nil && !a.repeating() {
+		a.drummer.Update(a.gameState.CurrentTime)
+	}
+
+	// A replayed passage plays itself
+	playLineX := a.highway.PlayLinePos(screenWidth)
+	if a.replaying() {
+		a.autoplay(playLineX)
+		return
+	}
+	if a.echo != nil {
+		a.soundCalls()
+	}
 
 	// Check for hits
-	playLineX := float32(screenWidth) * a.tabRenderer.PlayLineX
+	hits, misses := a.gameState.NotesHit, a.gameState.NotesMissed
 	a.hitDetector.CheckHit(a.currentPitch, playLineX)
 	a.hitDetector.Update()
+	if a.gameState.NotesHit > hits {
+		a.playSound(audio.SoundHit)
+	}
+	if a.gameState.NotesMissed > misses {
+		a.playSound(audio.SoundMiss)
+	}
+	if a.recording != nil && a.recording.Of(a.gameState) {
+		a.recording.Capture(a.currentPitch)
+	}
+	if a.trainer != nil {
+		a.updateTrainer(playLineX)
+	}
+	if a.echo != nil {
+		a.updateEcho(playLineX)
+	}
+	if a.repeater != nil {
+		a.updateRepeater(playLineX)
+	}
+	if a.variations != nil {
+		a.updateVariations(playLineX)
+	}
+	mode.Get(a.config.Mode).Update(a.gameState, a.currentPitch)
+	if a.setlistTimeUp() {
+		a.EndRiff()
+		return
+	}
 
 	// Check if song finished
 	if a.gameState.IsFinished {
-		a.state = StateResults
+		a.stopMIDIClock()
+		a.stopBackingTrack()
+		a.showResults()
+	}
+}
+
+// checkInputStall restarts the input if it has stopped delivering audio,
+// rather than letting every note be judged a miss
+func (a *App) checkInputStall() {
+	restarted, err := a.inputWatchdog.Check()
+	switch {
+	case err != nil:
+		a.notify(toastError, "audio input lost and could not be restarted: %v", err)
+	case restarted:
+		a.notify(toastInfo, "audio input stalled; restarted the stream")
 	}
 }
 
 func (a *App) Layout(gtx layout.Context) layout.Dimensions {
+	a.scaleMetric(&gtx)
 	a.Update()
+	a.updateCaptureWindow()
+	a.handleKeys(gtx)
 
 	// Background
-	paint.ColorOp{Color: color.NRGBA{R: 20, G: 20, B: 30, A: 255}}.Add(gtx.Ops)
+	paint.ColorOp{Color: render.Colors.Background}.Add(gtx.Ops)
 	paint.PaintOp{}.Add(gtx.Ops)
 
+	dims := a.layoutScreen(gtx)
+	a.layoutToasts(gtx)
+	a.layoutDebugConsole(gtx)
+	return dims
+}
+
+// layoutScreen draws the screen for the current state
+func (a *App) layoutScreen(gtx layout.Context) layout.Dimensions {
 	switch a.state {
 	case StateMenu:
 		return a.layoutMenuScreen(gtx)
@@ -141,11 +522,230 @@ func (a *App) Layout(gtx layout.Context) layout.Dimensions {
 		return a.layoutGameScreen(gtx)
 	case StateResults:
 		return a.layoutResultsScreen(gtx)
+	case StateEditor:
+		return a.layoutEditorScreen(gtx)
+	case StateRecording:
+		return a.layoutRecordingScreen(gtx)
+	case StateGenerator:
+		return a.layoutGeneratorScreen(gtx)
+	case StatePermission:
+		return a.layoutPermissionScreen(gtx)
+	case StateTrainerResults:
+		return a.layoutTrainerResultsScreen(gtx)
+	case StateAudioTest:
+		return a.layoutAudioTestScreen(gtx)
+	case StatePlayback:
+		return a.layoutPlaybackScreen(gtx)
+	case StateDashboard:
+		return a.layoutDashboardScreen(gtx)
+	case StateProfiles:
+		return a.layoutProfilesScreen(gtx)
+	case StateSetlistBreak:
+		return a.layoutSetlistBreakScreen(gtx)
+	case StateSetlistResults:
+		return a.layoutSetlistResultsScreen(gtx)
+	case StateQuiz:
+		return a.layoutQuizScreen(gtx)
 	}
 
 	return layout.Dimensions{}
 }
 
+// handleKeys processes keyboard input for the current screen
+func (a *App) handleKeys(gtx layout.Context) {
+	for {
+		ev, ok := gtx.Event(key.Filter{Optional: key.ModShift | key.ModShortcut})
+		if !ok {
+			break
+		}
+		e, ok := ev.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+		if a.handleDisplayKey(e) {
+			continue
+		}
+
+		switch a.state {
+		case StateMenu:
+			if a.search.active {
+				a.handleSearchKey(gtx, e)
+				break
+			}
+			if a.search.filters.active {
+				a.handleFilterKey(e)
+				break
+			}
+			switch e.Name {
+			case key.NameUpArrow:
+				a.moveCursor(-1)
+			case key.NameDownArrow:
+				a.moveCursor(1)
+			case key.NamePageUp:
+				a.pageSelection(-1)
+			case key.NamePageDown:
+				a.pageSelection(1)
+			case key.NameHome:
+				a.jumpSelection(func(pos, last int) int { return 0 })
+			case key.NameEnd:
+				a.jumpSelection(func(pos, last int) int { return last })
+			case key.NameReturn, key.NameEnter:
+				a.chooseRow()
+			case "/":
+				a.startSearch(gtx)
+			case "S":
+				a.search.filters.active = true
+			case "M":
+				a.ToggleFavorite()
+			case "I":
+				a.RetryInput()
+			case key.NameEscape:
+				a.endSearch(gtx, true)
+			case "E":
+				a.OpenEditor(a.exercises[a.selectedIndex])
+			case "N":
+				a.NewChart()
+			case "R":
+				a.OpenRecorder()
+			case "G":
+				a.OpenGenerator()
+			case "A":
+				a.OpenAudioTest()
+			case "U":
+				a.ToggleTelemetry()
+			case "P":
+				a.OpenDashboard()
+			case "O":
+				a.OpenProfiles()
+			case "Q":
+				a.ToggleQueued()
+			case "L":
+				a.StartSetlist()
+			case "B":
+				a.SaveQueueAsRoutine()
+			case "T":
+				a.StartRoutine()
+			case "D":
+				a.StartDaily()
+			case "F":
+				a.OpenQuiz()
+			case "C":
+				a.CycleTheme()
+			}
+		case StatePreStart:
+			switch e.Name {
+			case key.NameReturn, key.NameEnter, key.NameSpace:
+				a.StartGame()
+			case "D":
+				a.CycleDrums()
+			case "L":
+				a.CycleNoteLabels()
+			case "S":
+				a.tabRenderer.Notation = a.tabRenderer.Notation.Next()
+			case "F":
+				a.CycleHandedness()
+			case "H":
+				a.CycleSoundPack()
+			case "[":
+				a.gameState.Song.Transpose(-1)
+			case "]":
+				a.gameState.Song.Transpose(1)
+			case "I":
+				a.ToggleFretless()
+			case "O":
+				a.ToggleStrictOpenStrings()
+			case "W":
+				a.CycleTiming()
+			case ",":
+				a.ChangeLatency(-latencyStep)
+			case ".":
+				a.ChangeLatency(latencyStep)
+			case "X":
+				a.ToggleWrongNotePenalty()
+			case "K":
+				a.ToggleFailMode()
+			case "-":
+				a.ChangeSpeed(-speedStep)
+			case "=", "+":
+				a.ChangeSpeed(speedStep)
+			case "P":
+				a.ResumeLoop()
+			case "Z":
+				a.tabRenderer.DynamicZoom = !a.tabRenderer.DynamicZoom
+			case "T":
+				a.StartTrainer()
+			case "E":
+				a.StartEcho()
+			case "R":
+				a.ToggleRhythmOnly()
+			case "C":
+				a.CycleScaleOverlay()
+			case "G":
+				a.CycleHighway()
+			case "Y":
+				a.CycleScrollSpeed()
+			case "U":
+				a.ToggleConstantScroll()
+			case "V":
+				a.CycleVariations()
+			case "A":
+				a.ToggleRiffRepeater()
+			case "M":
+				a.CycleMode()
+			case key.NameEscape:
+				a.GoToMenu()
+			}
+		case StatePlaying:
+			switch e.Name {
+			case "L":
+				if a.practice == nil {
+					a.CycleNoteLabels()
+				}
+			case "C":
+				a.CycleScaleOverlay()
+			case key.NameEscape, key.NameReturn, key.NameEnter:
+				switch {
+				case a.practice != nil:
+					a.EndPractice()
+				case a.trainer != nil:
+					a.EndTrainer()
+				case a.variations != nil:
+					a.EndVariations()
+				case a.riff != nil:
+					a.EndRiff()
+				default:
+					a.logSession()
+					a.GoToMenu()
+				}
+			}
+		case StateResults:
+			a.handleResultsKey(e)
+		case StateEditor:
+			a.handleEditorKey(e)
+		case StateRecording:
+			a.handleRecorderKey(e)
+		case StateGenerator:
+			a.handleGeneratorKey(e)
+		case StatePermission:
+			a.handlePermissionKey(e)
+		case StateTrainerResults:
+			a.handleTrainerResultsKey(e)
+		case StateAudioTest:
+			a.handleAudioTestKey(e)
+		case StatePlayback:
+			a.handlePlaybackKey(e)
+		case StateDashboard:
+			a.handleDashboardKey(e)
+		case StateProfiles:
+			a.handleProfilesKey(gtx, e)
+		case StateSetlistBreak, StateSetlistResults:
+			a.handleSetlistKey(e)
+		case StateQuiz:
+			a.handleQuizKey(e)
+		}
+	}
+}
+
 func (a *App) layoutMenuScreen(gtx layout.Context) layout.Dimensions {
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		// Title
@@ -153,18 +753,24 @@ func (a *App) layoutMenuScreen(gtx layout.Context) layout.Dimensions {
 			inset := layout.Inset{Top: unit.Dp(20), Left: unit.Dp(20)}
 			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				label := material.H4(a.theme, "Bass Guitar Practice")
-				label.Color = color.NRGBA{R: 200, G: 200, B: 200, A: 255}
+				label.Color = render.Colors.Title
 				return label.Layout(gtx)
 			})
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			inset := layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(20)}
 			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-				label := material.Body2(a.theme, "Select an exercise (play a note to select)")
-				label.Color = color.NRGBA{R: 120, G: 120, B: 120, A: 255}
+				label := material.Body2(a.theme, "Select an exercise (play a note to select)  •  / search  •  S filter  •  M favorite  •  E edit  •  N new chart  •  R record  •  G endless riff  •  A audio check  •  F fretboard quiz  •  Q add to setlist  •  T today's routine  •  P progress  •  C colors: "+a.themeName()+"  •  "+a.profileHint()+"  •  "+a.telemetryLabel())
+				label.Color = render.Colors.Hint
 				return label.Layout(gtx)
 			})
 		}),
+		layout.Rigid(a.layoutNoInputBanner),
+		layout.Rigid(a.layoutSearchBox),
+		layout.Rigid(a.layoutFilterBar),
+		layout.Rigid(a.layoutDailyChallenge),
+		layout.Rigid(a.layoutTodaysRoutine),
+		layout.Rigid(a.layoutSetlistQueue),
 		// Exercise list
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 			return a.layoutExerciseList(gtx)
@@ -177,149 +783,446 @@ func (a *App) layoutMenuScreen(gtx layout.Context) layout.Dimensions {
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			inset := layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(15)}
 			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-				label := material.Body2(a.theme, "E1=41Hz  A1=55Hz  D2=73Hz  G2=98Hz")
-				label.Color = color.NRGBA{R: 60, G: 60, B: 60, A: 255}
+				hint := "E1=41Hz  A1=55Hz  D2=73Hz  G2=98Hz"
+				if a.tabRenderer.MirrorStrings {
+					hint = "G2=98Hz  D2=73Hz  A1=55Hz  E1=41Hz"
+				}
+				label := material.Body2(a.theme, hint)
+				label.Color = render.Colors.Disabled
 				return label.Layout(gtx)
 			})
 		}),
 	)
 }
 
-func (a *App) layoutExerciseList(gtx layout.Context) layout.Dimensions {
-	inset := layout.Inset{Left: unit.Dp(20), Right: unit.Dp(20)}
-	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				var children []layout.FlexChild
-				for i, ex := range a.exercises {
-					idx := i // capture for closure
-					exercise := ex
-					children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						return a.layoutExerciseItem(gtx, idx, exercise)
-					}))
-				}
-				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
-			}),
-		)
-	})
-}
-
-func (a *App) layoutExerciseItem(gtx layout.Context, index int, exercise *song.Song) layout.Dimensions {
-	isSelected := index == a.selectedIndex
-
-	bgColor := color.NRGBA{R: 35, G: 35, B: 45, A: 255}
+func (a *App) layoutExerciseItem(gtx layout.Context, exercise *song.Song, isSelected bool) layout.Dimensions {
+	bgColor := render.Colors.ListItem
 	if isSelected {
-		bgColor = color.NRGBA{R: 50, G: 70, B: 90, A: 255}
+		bgColor = render.Colors.ListItemSelected
 	}
 
-	return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		// Background
-		width := gtx.Constraints.Max.X
-		height := gtx.Dp(unit.Dp(50))
+	return layout.Inset{Bottom: unit.Dp(songRowGap)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		// Every row is the same size, so the list can page by rows
+		gtx.Constraints = layout.Exact(image.Pt(gtx.Constraints.Max.X, gtx.Dp(songRowHeight)))
 
 		defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
+		semantic.SelectedOp(isSelected).Add(gtx.Ops)
 		paint.ColorOp{Color: bgColor}.Add(gtx.Ops)
 		paint.PaintOp{}.Add(gtx.Ops)
 
 		// Selection indicator
 		if isSelected {
 			defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
-			paint.ColorOp{Color: color.NRGBA{R: 100, G: 200, B: 255, A: 50}}.Add(gtx.Ops)
+			paint.ColorOp{Color: render.Colors.ListItemGlow}.Add(gtx.Ops)
 			paint.PaintOp{}.Add(gtx.Ops)
 		}
 
 		// Content
-		inset := layout.Inset{Left: unit.Dp(15), Top: unit.Dp(10), Right: unit.Dp(15)}
-		inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-			return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceBetween}.Layout(gtx,
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							titleColor := color.NRGBA{R: 200, G: 200, B: 200, A: 255}
-							if isSelected {
-								titleColor = color.NRGBA{R: 100, G: 200, B: 255, A: 255}
-							}
-							label := material.Body1(a.theme, exercise.Title)
-							label.Color = titleColor
-							return label.Layout(gtx)
-						}),
-						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							label := material.Body2(a.theme, exercise.Artist)
-							label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
-							return label.Layout(gtx)
-						}),
-					)
-				}),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					info := fmt.Sprintf("%.0f BPM • %d notes", exercise.BPM, len(exercise.Notes))
-					label := material.Body2(a.theme, info)
-					label.Color = color.NRGBA{R: 120, G: 120, B: 120, A: 255}
-					return label.Layout(gtx)
-				}),
-			)
-		})
+		layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if exercise.Cover == "" {
+					return layout.Dimensions{}
+				}
+				return layout.UniformInset(unit.Dp(5)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return a.layoutCover(gtx, exercise, songRowHeight-10)
+				})
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return a.layoutExerciseText(gtx, exercise, isSelected)
+			}),
+		)
 
-		return layout.Dimensions{Size: gtx.Constraints.Constrain(
-			layout.Dimensions{Size: gtx.Constraints.Max}.Size,
-		)}
-		return layout.Dimensions{Size: struct{ X, Y int }{width, height}}
+		return layout.Dimensions{Size: gtx.Constraints.Max}
+	})
+}
+
+// layoutExerciseText writes a song's details in its menu row
+func (a *App) layoutExerciseText(gtx layout.Context, exercise *song.Song, isSelected bool) layout.Dimensions {
+	inset := layout.Inset{Left: unit.Dp(15), Top: unit.Dp(10), Right: unit.Dp(15)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceBetween}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						titleColor := render.Colors.Title
+						if isSelected {
+							titleColor = render.Colors.Accent
+						}
+						title := exercise.Title
+						if a.favorites[sessionKey(exercise)] {
+							title = "★ " + title
+						}
+						if pos := a.queuePosition(exercise); pos > 0 {
+							title = fmt.Sprintf("%s  [setlist %d]", title, pos)
+						}
+						label := material.Body1(a.theme, title)
+						label.Color = titleColor
+						return label.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						artist := songCredits(exercise, false)
+						if len(exercise.AltTitles) > 0 {
+							artist += "  •  " + exercise.AltTitles[0]
+						}
+						label := material.Body2(a.theme, artist)
+						label.Color = render.Colors.Disabled
+						return label.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical, Alignment: layout.End}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						info := fmt.Sprintf("%.0f BPM • %d notes • %s", exercise.BPM, len(exercise.Notes), strings.Repeat("★", exercise.Difficulty()))
+						label := material.Body2(a.theme, info)
+						label.Color = render.Colors.Hint
+						return label.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						best, ok := a.bestOf(exercise, false)
+						if !ok {
+							return layout.Dimensions{}
+						}
+						label := material.Body2(a.theme, fmt.Sprintf("%s • %d", best.Grade, best.Score))
+						label.Color = getGradeColor(best.Grade)
+						return label.Layout(gtx)
+					}),
+				)
+			}),
+		)
 	})
 }
 
 func (a *App) layoutPreStartScreen(gtx layout.Context) layout.Dimensions {
-	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle, Spacing: layout.SpaceAround}.Layout(gtx,
-		layout.Flexed(1, layout.Spacer{}.Layout),
+	settings := a.preStartSettings()
+	a.preStartList.Axis = layout.Vertical
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.gameState.Song.Cover == "" {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return a.layoutCover(gtx, a.gameState.Song, 128)
+			})
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			label := material.H5(a.theme, a.gameState.Song.Title)
-			label.Color = color.NRGBA{R: 150, G: 200, B: 255, A: 255}
+			label.Color = render.Colors.Info
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			credits := songCredits(a.gameState.Song, true)
+			if credits == "" {
+				return layout.Dimensions{}
+			}
+			label := material.Body1(a.theme, credits)
+			label.Color = render.Colors.Text
 			return layout.Center.Layout(gtx, label.Layout)
 		}),
 		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			label := material.Body1(a.theme, fmt.Sprintf("%.0f BPM  •  %d notes", a.gameState.Song.BPM, len(a.gameState.Song.Notes)))
-			label.Color = color.NRGBA{R: 120, G: 120, B: 120, A: 255}
+			label.Color = render.Colors.Hint
 			return layout.Center.Layout(gtx, label.Layout)
 		}),
-		layout.Rigid(layout.Spacer{Height: unit.Dp(40)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := "Not played through yet"
+			if best, ok := a.bestOf(a.gameState.Song, a.config.RhythmOnly); ok {
+				text = bestLabel(best)
+			}
+			if a.config.RhythmOnly {
+				text = "Rhythm only  •  " + text
+			}
+			label := material.Body1(a.theme, text)
+			label.Color = render.Colors.Highlight
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.audioInput != nil {
+				return layout.Dimensions{}
+			}
+			label := material.Body1(a.theme, "No audio input: nothing you play will be heard")
+			label.Color = render.Colors.Warning
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		// Settings scroll, so the prompt stays in view however many there are
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(a.theme, &a.preStartList).Layout(gtx, len(settings), func(gtx layout.Context, i int) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return settings[i](gtx)
+			})
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return a.tabRenderer.DrawDetectedNote(gtx, a.currentPitch.FullNoteName(), a.currentPitch.Frequency, a.currentPitch.Confidence)
 		}),
-		layout.Rigid(layout.Spacer{Height: unit.Dp(30)}.Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			label := material.Body1(a.theme, "Play any note to start!")
-			label.Color = color.NRGBA{R: 100, G: 200, B: 100, A: 255}
+			label := material.Body1(a.theme, "Play any note or press Enter to start!")
+			label.Color = render.Colors.Success
 			return layout.Center.Layout(gtx, label.Layout)
 		}),
-		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
 	)
 }
 
+// preStartSettings are the pre-start screen's rows of settings, each
+// with the key that changes it, then the scale overlay if it's on
+func (a *App) preStartSettings() []layout.Widget {
+	return []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			drums := "Off"
+			if a.drumPattern != nil {
+				drums = a.drumPattern.Name
+			}
+			if a.drummer == nil {
+				drums = "unavailable (no audio output)"
+			}
+			label := material.Body2(a.theme, fmt.Sprintf("Drums: %s  (D to change)", drums))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, fmt.Sprintf("Note labels: %s  (L to change, also while playing)", a.tabRenderer.NoteLabels))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, fmt.Sprintf("View: %s  (G to change)", highwayLabel(a.config.Highway)))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, a.scrollLabel())
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, a.scaleOverlayLabel())
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, a.displayLabel())
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, fmt.Sprintf("Hand: %s  (F to change)", handednessLabel(a.config.Handedness)))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, fmt.Sprintf("Notation: %s  (S to change)", a.tabRenderer.Notation))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			zoom := "Off"
+			if a.tabRenderer.DynamicZoom {
+				zoom = "On, busy passages spread out"
+			}
+			label := material.Body2(a.theme, fmt.Sprintf("Dynamic zoom: %s  (Z to change)", zoom))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			name := "none"
+			if a.sounds != nil {
+				name = a.sounds.Name
+			}
+			label := material.Body2(a.theme, fmt.Sprintf("Hit sounds: %s  (H to change)", name))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			s := a.gameState.Song
+			text := fmt.Sprintf("Transpose: %+d semitones  ([ / ] to change)", s.Transposition())
+			if s.Capo > 0 {
+				text = fmt.Sprintf("Capo: fret %d  •  ", s.Capo) + text
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, fmt.Sprintf("Speed: %.0f%%  (- / + to change, T for the speed trainer, E for call and response)", a.speed*100))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := "Variations: Off  (V to change)"
+			if a.varyMode != generator.VaryOff {
+				text = fmt.Sprintf("Variations: %s, the song loops and changes each pass  (V to change)", a.varyMode)
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := "Riff repeater: Off  (A to change)"
+			if a.config.RiffRepeater {
+				text = fmt.Sprintf("Riff repeater: On, loops sections with over %.0f%% missed  (A to change)", a.config.RepeaterMissPercent)
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := "Fretless scoring: Off  (I to change)"
+			switch {
+			case a.gameState.Song.Fretless:
+				text = "Fretless scoring: On (set by chart)"
+			case a.config.Fretless:
+				text = "Fretless scoring: On  (I to change)"
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := fmt.Sprintf("Timing: %s  (W to change)", timingLabel(a.timingWindows()))
+			if a.config.Timing == config.TimingCustom {
+				text = fmt.Sprintf("Timing: %s  (W to change, set custom_timing in config.yaml)", timingLabel(a.timingWindows()))
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, fmt.Sprintf("Input latency: %.0f ms  (, and . to adjust)", a.config.LatencyMs))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := "Fail mode: Off  (K to change)"
+			if a.config.FailMode {
+				text = "Fail mode: On, misses drain health and the song ends when it runs out  (K to change)"
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			m := mode.Get(a.config.Mode)
+			label := material.Body2(a.theme, fmt.Sprintf("Mode: %s, %s  (M to change)", m.Name(), m.Description()))
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := "Scoring: pitch and timing  (R to change)"
+			if a.config.RhythmOnly {
+				text = "Scoring: rhythm only, any note played on time counts  (R to change)"
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := "Wrong notes: not penalized  (X to change)"
+			if a.config.WrongNotePenalty {
+				text = fmt.Sprintf("Wrong notes: break the combo and cost %d points  (X to change)", song.WrongNotePoints)
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := "Open strings: fretted equivalents count  (O to change)"
+			if a.config.StrictOpenStrings {
+				text = "Open strings: play as written  (O to change)"
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			r, ok := a.lastLoop()
+			if !ok {
+				return layout.Dimensions{}
+			}
+			label := material.Body2(a.theme, fmt.Sprintf("Last practiced: %s  (P to loop it again)", r))
+			label.Color = render.Colors.Selected
+			return layout.Center.Layout(gtx, label.Layout)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(15)}.Layout(gtx, a.layoutScaleOverlay)
+		},
+	}
+}
+
 func (a *App) layoutGameScreen(gtx layout.Context) layout.Dimensions {
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		// Header with score
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return a.tabRenderer.DrawHeader(gtx, a.gameState)
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.tabRenderer.DrawSongProgress(gtx, a.gameState)
+		}),
+		layout.Rigid(a.layoutPracticeStatus),
+		layout.Rigid(a.layoutGhost),
 		// Tab area
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-			return a.tabRenderer.Layout(gtx, a.gameState)
+			dims := a.highway.Layout(gtx, a.gameState)
+			mode.Get(a.config.Mode).Layout(gtx, a.theme, a.gameState)
+			return dims
 		}),
-		// Detected note display
+		layout.Rigid(a.layoutScaleOverlay),
+		// Detected note display, with the intonation meter when fretless
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return a.tabRenderer.DrawDetectedNote(gtx, a.currentPitch.FullNoteName(), a.currentPitch.Frequency, a.currentPitch.Confidence)
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.tabRenderer.DrawDetectedNote(gtx, a.currentPitch.FullNoteName(), a.currentPitch.Frequency, a.currentPitch.Confidence)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if !a.gameState.Fretless {
+						return layout.Dimensions{}
+					}
+					cents, ok := a.hitDetector.CentsFromExpected(a.currentPitch)
+					return a.tabRenderer.DrawCentsMeter(gtx, cents, ok)
+				}),
+			)
 		}),
 	)
 }
 
 func (a *App) layoutResultsScreen(gtx layout.Context) layout.Dimensions {
 	accuracy := a.gameState.Accuracy()
-	grade := getGrade(accuracy)
+	grade := a.gameState.Grade()
+	if a.gameState.Failed {
+		grade = "F"
+	}
 
 	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle, Spacing: layout.SpaceAround}.Layout(gtx,
 		layout.Flexed(1, layout.Spacer{}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			label := material.H4(a.theme, "Exercise Complete!")
-			label.Color = color.NRGBA{R: 200, G: 200, B: 200, A: 255}
+			label.Color = render.Colors.Title
+			if a.gameState.Failed {
+				label = material.H4(a.theme, "Failed")
+				label.Color = render.Colors.Error
+			}
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gs := a.gameState
+			if !gs.Failed || gs.Song.Duration <= 0 {
+				return layout.Dimensions{}
+			}
+			through := min(1, gs.CurrentTime/gs.Song.Duration)
+			label := material.Body1(a.theme, fmt.Sprintf("Health ran out %.0f%% of the way through the song", through*100))
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !a.gameState.RhythmOnly {
+				return layout.Dimensions{}
+			}
+			label := material.Body1(a.theme, "Rhythm only: timing was scored, not pitch, so this run is kept apart from your bests")
+			label.Color = render.Colors.Selected
 			return layout.Center.Layout(gtx, label.Layout)
 		}),
 		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
@@ -331,20 +1234,55 @@ func (a *App) layoutResultsScreen(gtx layout.Context) layout.Dimensions {
 		layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			label := material.H5(a.theme, fmt.Sprintf("Score: %d", a.gameState.Score))
-			label.Color = color.NRGBA{R: 255, G: 215, B: 0, A: 255}
+			label.Color = render.Colors.Highlight
 			return layout.Center.Layout(gtx, label.Layout)
 		}),
 		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			label := material.Body1(a.theme, fmt.Sprintf("Accuracy: %.1f%%  •  Max Combo: %d  •  Notes: %d/%d",
-				accuracy, a.gameState.MaxCombo, a.gameState.NotesHit, a.gameState.TotalNotes))
-			label.Color = color.NRGBA{R: 150, G: 150, B: 150, A: 255}
+			label := material.Body1(a.theme, fmt.Sprintf("Accuracy: %.1f%%  •  Notes: %d/%d",
+				accuracy, a.gameState.NotesHit, a.gameState.TotalNotes))
+			label.Color = render.Colors.Text
 			return layout.Center.Layout(gtx, label.Layout)
 		}),
-		layout.Rigid(layout.Spacer{Height: unit.Dp(40)}.Layout),
+		layout.Rigid(a.layoutQualityCounts),
+		layout.Rigid(a.layoutComboAndSections),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			label := material.Body1(a.theme, "Play a note to return to menu")
-			label.Color = color.NRGBA{R: 100, G: 200, B: 100, A: 255}
+			text := "Timing: " + timingLabel(a.resultsWindows())
+			if a.gameState.NotesHit > 0 {
+				text += "  •  " + offsetLabel(a.gameState.AverageOffset())
+			}
+			if a.gameState.WrongNotePenalty {
+				text += fmt.Sprintf("  •  Wrong notes: %d", a.gameState.WrongNotes)
+			}
+			label := material.Body1(a.theme, text)
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.gameState.NotesHit == 0 || a.gameState.RhythmOnly {
+				return layout.Dimensions{}
+			}
+			label := material.Body1(a.theme, fmt.Sprintf("Intonation: %.1f cents off on average", a.gameState.AverageCents()))
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(a.layoutDailyResults),
+		layout.Rigid(a.layoutEchoResults),
+		layout.Rigid(a.layoutResultsChart),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(a.layoutMissedPassages),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := "Play a note or press Enter to return to menu"
+			if a.recording != nil && a.recording.Of(a.gameState) {
+				text += "  •  V to watch a replay  •  X to export results"
+				if a.resultsExported != "" {
+					text += " (saved to " + a.resultsExported + ")"
+				}
+			}
+			label := material.Body1(a.theme, text)
+			label.Color = render.Colors.Success
 			return layout.Center.Layout(gtx, label.Layout)
 		}),
 		layout.Flexed(1, layout.Spacer{}.Layout),
@@ -354,189 +1292,561 @@ func (a *App) layoutResultsScreen(gtx layout.Context) layout.Dimensions {
 func (a *App) SelectExercise(index int) {
 	if index >= 0 && index < len(a.exercises) {
 		a.selectedIndex = index
+		a.songList.cursor = -1
+		a.songList.reveal = true
 		a.gameState = song.NewGameState(a.exercises[index])
-		a.hitDetector = game.NewHitDetector(a.gameState)
+		a.hitDetector = game.NewHitDetector(a.gameState, a.feedbackY)
+		a.drumPattern = backing.PatternByName(a.exercises[index].Drums)
+	}
+}
+
+// CycleDrums switches the drum backing to the next feel, or off
+func (a *App) CycleDrums() {
+	next := 0
+	for i, p := range backing.Patterns {
+		if p == a.drumPattern {
+			next = i + 1
+		}
+	}
+	if next < len(backing.Patterns) {
+		a.drumPattern = backing.Patterns[next]
+	} else {
+		a.drumPattern = nil
+	}
+}
+
+// CycleSoundPack switches to the next sound pack
+func (a *App) CycleSoundPack() {
+	a.soundPack = (a.soundPack + 1) % len(a.soundPacks)
+
+	p, err := a.assets.SoundPack(a.soundPacks[a.soundPack])
+	if err != nil {
+		a.warn("could not load sound pack: %v", err)
+		return
+	}
+
+	a.sounds = p
+	if a.drummer != nil {
+		a.drummer.SetSounds(p)
+	}
+	a.playSound(audio.SoundHit)
+}
+
+// CycleHandedness switches between right- and left-handed layouts and
+// remembers the choice
+func (a *App) CycleHandedness() {
+	a.config.NextHandedness()
+	a.applyHandedness()
+	a.saveSettings()
+}
+
+// ToggleFretless switches intonation scoring for every song on or off
+func (a *App) ToggleFretless() {
+	a.config.Fretless = !a.config.Fretless
+	a.saveSettings()
+}
+
+// ToggleStrictOpenStrings switches whether open strings must be played
+// open, and fretted notes fretted, when either would give the pitch
+func (a *App) ToggleStrictOpenStrings() {
+	a.config.StrictOpenStrings = !a.config.StrictOpenStrings
+	a.saveSettings()
+}
+
+// ChangeSpeed slows down or speeds up practice, within MinSpeed and full speed
+func (a *App) ChangeSpeed(delta float64) {
+	a.speed = math.Round((a.speed+delta)*100) / 100
+	a.speed = max(MinSpeed, min(1, a.speed))
+}
+
+// applyHandedness lays out the highway for the configured hand
+func (a *App) applyHandedness() {
+	h := a.config.Handedness
+	a.tabRenderer.MirrorStrings = h == config.LeftHanded || h == config.LeftHandedHighway
+	a.tabRenderer.MirrorHighway = h == config.LeftHandedHighway
+}
+
+// CycleHighway switches between the sideways tab view and notes falling
+// towards a fretboard
+func (a *App) CycleHighway() {
+	if a.config.Highway == config.HighwayFretboard {
+		a.config.Highway = config.HighwayTab
+	} else {
+		a.config.Highway = config.HighwayFretboard
+	}
+	a.applyHighway()
+	a.saveSettings()
+}
+
+// applyHighway draws play with the configured view
+func (a *App) applyHighway() {
+	if a.config.Highway == config.HighwayFretboard {
+		a.highway = render.NewFretboardHighway(a.tabRenderer)
+		return
+	}
+	a.highway = a.tabRenderer
+}
+
+// feedbackY returns the height at which the current view shows hit
+// feedback for a string
+func (a *App) feedbackY(str int) float32 {
+	return a.highway.FeedbackY(str)
+}
+
+func highwayLabel(h string) string {
+	if h == config.HighwayFretboard {
+		return "Fretboard (notes fall towards the neck)"
+	}
+	return "Tab (notes scroll along the strings)"
+}
+
+// CycleNoteLabels switches what is written on each note: fret numbers,
+// note names, or both
+func (a *App) CycleNoteLabels() {
+	a.tabRenderer.NoteLabels = a.tabRenderer.NoteLabels.Next()
+	switch a.tabRenderer.NoteLabels {
+	case render.LabelNoteName:
+		a.config.NoteLabels = config.NoteLabelsName
+	case render.LabelBoth:
+		a.config.NoteLabels = config.NoteLabelsBoth
+	default:
+		a.config.NoteLabels = config.NoteLabelsFret
+	}
+	a.saveSettings()
+}
+
+// applyNoteLabels writes the configured labels on the notes
+func (a *App) applyNoteLabels() {
+	switch a.config.NoteLabels {
+	case config.NoteLabelsName:
+		a.tabRenderer.NoteLabels = render.LabelNoteName
+	case config.NoteLabelsBoth:
+		a.tabRenderer.NoteLabels = render.LabelBoth
+	default:
+		a.tabRenderer.NoteLabels = render.LabelFret
+	}
+}
+
+func handednessLabel(h string) string {
+	switch h {
+	case config.LeftHanded:
+		return "Left (strings mirrored)"
+	case config.LeftHandedHighway:
+		return "Left (strings and highway mirrored)"
+	default:
+		return "Right"
+	}
+}
+
+// playSound plays a sound from the current pack, if there is audio output
+func (a *App) playSound(sound audio.Sound) {
+	if a.audioOutput == nil {
+		return
+	}
+	if v := a.sounds.Voice(sound, 1, a.audioOutput.SampleRate()); v != nil {
+		a.audioOutput.Play(v)
 	}
 }
 
 func (a *App) StartGame() {
+	if a.telemetry != nil {
+		a.telemetry.SongPlayed()
+	}
+	a.sessionStart = time.Now()
+	if a.varyMode != generator.VaryOff && a.riff == nil {
+		a.StartVariations()
+		return
+	}
+	a.pitchDetector.SetRange(a.gameState.Song.FrequencyRange())
+	a.applyPlaySettings(a.gameState)
+	a.gameState.Speed = a.speed
+	a.gameState.FailMode = a.config.FailMode && a.riff == nil
+	a.saveSession(nil)
+	a.repeater = nil
+	if a.config.RiffRepeater && a.riff == nil {
+		a.repeater = song.NewRepeater(a.gameState, a.config.RepeaterMissPercent, a.config.RepeaterRecoverPercent)
+	}
+	a.recording = nil
+	if a.riff == nil && a.repeater == nil {
+		a.recording = game.NewRecording(a.gameState)
+	}
+	a.ghost = nil
+	if a.recording != nil && a.gameState.Speed >= 1 && !a.gameState.RhythmOnly {
+		a.ghost = a.loadGhost(a.gameState.Song)
+	}
+
 	a.state = StatePlaying
 	a.gameState.Start()
+	if a.drummer != nil {
+		a.drummer.Reset(a.gameState.Song, a.drumPattern)
+		a.drummer.SetSpeed(a.speed)
+	}
+	if a.midiClock != nil {
+		a.midiClock.Start(a.gameState.Song, a.gameState.StartTime, a.speed)
+	}
+	a.startBackingTrack()
 }
 
-func (a *App) GoToMenu() {
-	a.state = StateMenu
-	a.SelectExercise(a.selectedIndex)
+// applyPlaySettings sets up a game with the player's scoring settings,
+// passing on each note judged
+func (a *App) applyPlaySettings(gs *song.GameState) {
+	applyScoring(gs, a.config)
+	gs.OnJudged = func(note *song.TabNote) { a.noteJudged(gs, note) }
 }
 
-func (a *App) Close() {
-	if a.pitchDetector != nil {
-		a.pitchDetector.Close()
+// applyScoring sets up a game to be scored with a config's settings
+func applyScoring(gs *song.GameState, cfg *config.Config) {
+	gs.Fretless = gs.Song.Fretless || cfg.Fretless
+	gs.StrictOpenStrings = cfg.StrictOpenStrings
+	gs.Windows = timingWindows(cfg)
+	gs.WrongNotePenalty = cfg.WrongNotePenalty
+	gs.RhythmOnly = cfg.RhythmOnly
+	gs.InputLatency = cfg.LatencyMs / 1000
+	mode.Attach(mode.Get(cfg.Mode), gs)
+}
+
+// timingWindows returns the hit timing windows of the configured preset
+func (a *App) timingWindows() song.TimingWindows {
+	return timingWindows(a.config)
+}
+
+// timingWindows returns the hit timing windows of a config's preset
+func timingWindows(cfg *config.Config) song.TimingWindows {
+	switch cfg.Timing {
+	case config.TimingEasy:
+		return game.WindowsEasy
+	case config.TimingHard:
+		return game.WindowsHard
+	case config.TimingCustom:
+		c := cfg.CustomTiming
+		return game.ClampWindows(song.TimingWindows{Perfect: c.PerfectMs / 1000, Good: c.GoodMs / 1000, OK: c.OKMs / 1000})
+	default:
+		return game.WindowsNormal
 	}
-	if a.audioInput != nil {
-		a.audioInput.Stop()
-		a.audioInput.Close()
+}
+
+// ToggleRhythmOnly switches between scoring pitch and timing, and timing alone
+func (a *App) ToggleRhythmOnly() {
+	a.config.RhythmOnly = !a.config.RhythmOnly
+	a.saveSettings()
+}
+
+// ToggleWrongNotePenalty switches whether notes that match nothing are
+// penalized
+func (a *App) ToggleWrongNotePenalty() {
+	a.config.WrongNotePenalty = !a.config.WrongNotePenalty
+	a.saveSettings()
+}
+
+// ToggleFailMode switches whether misses can end a song early
+func (a *App) ToggleFailMode() {
+	a.config.FailMode = !a.config.FailMode
+	a.saveSettings()
+}
+
+// CycleMode switches to the next game mode
+func (a *App) CycleMode() {
+	a.config.Mode = mode.Next(mode.Get(a.config.Mode).Name())
+	a.saveSettings()
+}
+
+// ChangeLatency adjusts the input latency hits are judged with
+func (a *App) ChangeLatency(deltaMs float64) {
+	a.config.LatencyMs = max(0, min(maxLatencyMs, a.config.LatencyMs+deltaMs))
+	a.saveSettings()
+}
+
+// CycleTiming switches to the next timing window preset
+func (a *App) CycleTiming() {
+	a.config.NextTiming()
+	a.saveSettings()
+}
+
+// suggestCalibration points at the latency setting when hits were
+// consistently early or late
+func (a *App) suggestCalibration() {
+	hits := a.gameState.HitTimings()
+	if len(hits) < calibrationHits {
+		return
 	}
+	mean, stddev := song.TimingSpread(hits)
+	if math.Abs(mean) < calibrationOffset || stddev > math.Abs(mean) {
+		return
+	}
+	a.notify(toastInfo, "hits were %s; if that felt on time, adjust the input latency with , and . before starting", offsetLabel(mean))
 }
 
-func getGrade(accuracy float64) string {
+// offsetLabel says whether hits tended to be early or late
+func offsetLabel(offset float64) string {
+	ms := math.Round(offset * 1000)
 	switch {
-	case accuracy >= 95:
-		return "S"
-	case accuracy >= 90:
-		return "A"
-	case accuracy >= 80:
-		return "B"
-	case accuracy >= 70:
-		return "C"
-	case accuracy >= 60:
-		return "D"
+	case ms < 0:
+		return fmt.Sprintf("%.0f ms early on average", -ms)
+	case ms > 0:
+		return fmt.Sprintf("%.0f ms late on average", ms)
 	default:
-		return "F"
+		return "right on time on average"
 	}
 }
 
-func getGradeColor(grade string) color.NRGBA {
-	switch grade {
-	case "S":
-		return color.NRGBA{R: 255, G: 215, B: 0, A: 255}
-	case "A":
-		return color.NRGBA{R: 100, G: 255, B: 100, A: 255}
-	case "B":
-		return color.NRGBA{R: 100, G: 200, B: 255, A: 255}
-	case "C":
-		return color.NRGBA{R: 255, G: 255, B: 100, A: 255}
-	case "D":
-		return color.NRGBA{R: 255, G: 150, B: 50, A: 255}
-	default:
-		return color.NRGBA{R: 255, G: 100, B: 100, A: 255}
+// timingLabel describes timing windows, so scores can be compared
+func timingLabel(w song.TimingWindows) string {
+	return fmt.Sprintf("%s (±%.0f / %.0f / %.0f ms)", game.PresetName(w), w.Perfect*1000, w.Good*1000, w.OK*1000)
+}
+
+// startBackingTrack plays the song's backing audio from the beginning
+func (a *App) startBackingTrack() {
+	a.stopBackingTrack()
+	s := a.gameState.Song
+	if s.Audio == "" || a.audioOutput == nil {
+		return
 	}
+
+	data, err := s.ReadAsset(s.Audio)
+	if err != nil {
+		a.warn("could not load backing track: %v", err)
+		return
+	}
+	sample, err := audio.DecodeWAV(bytes.NewReader(data))
+	if err != nil {
+		a.warn("could not decode backing track %s: %v", s.Audio, err)
+		return
+	}
+	a.backingTrack = audio.NewSampleVoice(sample, 1, a.audioOutput.SampleRate())
+	a.backingTrack.SetSpeed(a.gameState.Speed)
+	a.audioOutput.Play(a.backingTrack)
 }
 
-// loadSongs tries to load songs from various locations
-func loadSongs() ([]*song.Song, error) {
-	// Try these directories in order:
-	// 1. ./songs (relative to current directory)
-	// 2. songs/ next to executable
-	// 3. ~/.config/guitargame/songs
+func (a *App) stopBackingTrack() {
+	if a.backingTrack != nil {
+		a.backingTrack.Stop()
+		a.backingTrack = nil
+	}
+}
 
-	searchPaths := []string{
-		"songs",
+func (a *App) GoToMenu() {
+	a.stopMIDIClock()
+	a.stopBackingTrack()
+	if a.drummer != nil {
+		a.drummer.Reset(nil, nil)
 	}
+	a.state = StateMenu
+	a.riff = nil
+	a.trainer = nil
+	a.echo = nil
+	a.repeater = nil
+	a.variations = nil
+	a.endSetlist()
+	a.SelectExercise(a.selectedIndex)
+}
 
-	// Add path relative to executable
-	if exe, err := os.Executable(); err == nil {
-		exeDir := filepath.Dir(exe)
-		searchPaths = append(searchPaths, filepath.Join(exeDir, "songs"))
+// EnableMIDIClock sends MIDI beat clock to a MIDI port or device while
+// playing
+func (a *App) EnableMIDIClock(device string) error {
+	out, err := midi.Open(device)
+	if err != nil {
+		return err
 	}
+	a.midiOut = out
+	a.midiClock = midi.NewClock(out)
+	return nil
+}
 
-	// Add config directory
-	if home, err := os.UserHomeDir(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(home, ".config", "guitargame", "songs"))
+func (a *App) stopMIDIClock() {
+	if a.midiClock != nil {
+		a.midiClock.Stop()
 	}
+}
 
-	for _, path := range searchPaths {
-		if info, err := os.Stat(path); err == nil && info.IsDir() {
-			songs, err := song.LoadSongsFromDirectory(path)
-			if err == nil && len(songs) > 0 {
-				fmt.Printf("Loaded %d songs from %s\n", len(songs), path)
-				return songs, nil
-			}
-		}
+// Close shuts the game down cleanly; only the first call does anything
+func (a *App) Close() {
+	a.closeOnce.Do(a.shutdown)
+}
+
+// shutdown stops audio first, so nothing more is heard or played while
+// the rest winds down, then keeps what the next run picks up from
+func (a *App) shutdown() {
+	logging.Infof("Shutting down")
+	if a.audioInput != nil {
+		a.audioInput.Stop()
+		a.audioInput.Close()
+	}
+	if a.audioOutput != nil {
+		a.audioOutput.Stop()
+		a.audioOutput.Close()
 	}
+	a.closeMIDINotes()
+	if a.midiOut != nil {
+		a.stopMIDIClock()
+		a.midiOut.Close()
+	}
+	if a.pitchDetector != nil {
+		a.pitchDetector.Close()
+	}
+	a.closePreview()
+	a.closeCaptureWindow()
+	if a.songWatcher != nil {
+		a.songWatcher.Close()
+	}
+	a.rememberSelection()
+	a.saveSettings()
+	a.endTelemetry()
+	a.closeLiveStats()
+	a.closeOSC()
+	a.closeHistory()
+}
 
-	return nil, fmt.Errorf("no songs found in any search path")
+func getGradeColor(grade string) color.NRGBA {
+	switch grade {
+	case "S":
+		return render.Colors.Highlight
+	case "A":
+		return render.Colors.NotePerfect
+	case "B":
+		return render.Colors.Accent
+	case "C":
+		return render.Colors.NoteOK
+	case "D":
+		return render.Colors.Combo
+	default:
+		return render.Colors.Error
+	}
 }
 
 func main() {
-	fmt.Println("Bass Guitar Practice Game")
-	fmt.Println("=========================")
-	fmt.Println()
+	if status, ok := runCommand(os.Args[1:]); ok {
+		os.Exit(status)
+	}
+	flag.Parse()
+	openLog()
 
-	fmt.Println("Listing audio devices...")
+	logging.Infof("Bass Guitar Practice Game starting")
 	if err := audio.ListDevices(); err != nil {
-		log.Printf("Warning: could not list devices: %v", err)
+		logging.Warnf("could not list devices: %v", err)
 	}
-	fmt.Println()
 
 	application, err := NewApp()
 	if err != nil {
-		log.Fatalf("Failed to initialize: %v", err)
+		logging.Errorf("failed to initialize: %v", err)
+		os.Exit(1)
+	}
+
+	if *midiClockDevice != "" {
+		if err := application.EnableMIDIClock(*midiClockDevice); err != nil {
+			application.warn("could not open MIDI output %s: %v", *midiClockDevice, err)
+		} else {
+			logging.Infof("Sending MIDI clock to %s", *midiClockDevice)
+		}
+	}
+	if *midiNotesDevice != "" {
+		if err := application.EnableMIDINotes(*midiNotesDevice, *midiNotesChannel); err != nil {
+			application.warn("could not open MIDI output %s: %v", *midiNotesDevice, err)
+		} else {
+			logging.Infof("Playing the notes heard on %s, channel %d", *midiNotesDevice, *midiNotesChannel)
+		}
 	}
-	defer application.Close()
 
-	fmt.Println("Starting game...")
-	fmt.Println("Exercises available:")
 	for i, ex := range application.exercises {
-		fmt.Printf("  %d. %s (%.0f BPM, %d notes)\n", i+1, ex.Title, ex.BPM, len(ex.Notes))
+		logging.Debugf("song %d: %s (%.0f BPM, %d notes)", i+1, ex.Title, ex.BPM, len(ex.Notes))
 	}
-	fmt.Println()
 
+	// Ctrl+C or a termination signal closes the window, which shuts
+	// down as closing it by hand does
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	go func() {
-		w := new(app.Window)
-		w.Option(
-			app.Title("Bass Guitar Practice"),
-			app.Size(unit.Dp(screenWidth), unit.Dp(screenHeight)),
-		)
+		status := 0
+		if err := application.run(ctx, stop); err != nil {
+			logging.Errorf("window closed: %v", err)
+			status = 1
+		}
+		application.Close()
+		logging.Close()
+		os.Exit(status)
+	}()
 
-		var ops op.Ops
+	app.Main()
+}
+
+// run opens the window and handles its events until it's closed, by the
+// player or by ctx being done. stop is called once closing has begun, so
+// a second signal isn't caught and ends the game at once.
+func (a *App) run(ctx context.Context, stop context.CancelFunc) error {
+	w := new(app.Window)
+	w.Option(
+		app.Title("Bass Guitar Practice"),
+		app.Size(unit.Dp(screenWidth), unit.Dp(screenHeight)),
+	)
+	if a.launch.Fullscreen {
+		w.Option(app.Fullscreen.Option())
+	}
+	a.window = w
+	a.fullscreen = a.launch.Fullscreen
+
+	var ops op.Ops
 
-		// 60 FPS ticker
+	// 60 FPS ticker, and closing the window when asked to
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
 		ticker := time.NewTicker(time.Second / 60)
 		defer ticker.Stop()
-
-		go func() {
-			for range ticker.C {
+		for {
+			select {
+			case <-ticker.C:
 				w.Invalidate()
+			case <-ctx.Done():
+				logging.Infof("Closing on signal")
+				stop()
+				w.Perform(system.ActionClose)
+				return
+			case <-done:
+				return
 			}
-		}()
+		}
+	}()
 
-		// Debounce note detection for menu navigation
-		lastNoteTime := time.Time{}
-		noteCooldown := 300 * time.Millisecond
+	// Debounce note detection for menu navigation
+	lastNoteTime := time.Time{}
+	noteCooldown := 300 * time.Millisecond
 
-		for {
-			switch e := w.Event().(type) {
-			case app.DestroyEvent:
-				if e.Err != nil {
-					log.Fatal(e.Err)
-				}
-				os.Exit(0)
-
-			case app.FrameEvent:
-				gtx := app.NewContext(&ops, e)
-
-				noteDetected := application.currentPitch.IsValid()
-				noteJustPlayed := noteDetected && time.Since(lastNoteTime) > noteCooldown
-
-				if noteJustPlayed {
-					lastNoteTime = time.Now()
-
-					switch application.state {
-					case StateMenu:
-						// Cycle through exercises or start selected
-						if application.lastNoteDetected {
-							// Second note - start the game
-							application.state = StatePreStart
-						} else {
-							// First note - cycle selection
-							application.selectedIndex = (application.selectedIndex + 1) % len(application.exercises)
-							application.SelectExercise(application.selectedIndex)
-						}
-					case StatePreStart:
-						application.StartGame()
-					case StateResults:
-						application.GoToMenu()
+	for {
+		switch e := w.Event().(type) {
+		case app.DestroyEvent:
+			return e.Err
+
+		case app.ConfigEvent:
+			a.fullscreen = e.Config.Mode == app.Fullscreen
+
+		case app.FrameEvent:
+			gtx := app.NewContext(&ops, e)
+
+			noteDetected := a.currentPitch.IsValid()
+			noteJustPlayed := noteDetected && time.Since(lastNoteTime) > noteCooldown
+
+			if noteJustPlayed {
+				lastNoteTime = time.Now()
+
+				switch a.state {
+				case StateMenu:
+					// Cycle through exercises or start selected
+					if a.lastNoteDetected {
+						// Second note - start the game
+						a.state = StatePreStart
+					} else {
+						// First note - cycle selection
+						a.moveSelection(1)
 					}
+				case StatePreStart:
+					a.StartGame()
+				case StateResults:
+					a.GoToMenu()
 				}
+			}
 
-				application.lastNoteDetected = noteDetected
+			a.lastNoteDetected = noteDetected
 
-				application.Layout(gtx)
-				e.Frame(gtx.Ops)
-			}
+			a.Layout(gtx)
+			e.Frame(gtx.Ops)
 		}
-	}()
-
-	app.Main()
+	}
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/permission"
+	"guitargame/apps/desktop/internal/render"
+)
+
+// micPollInterval is how often the microphone permission is rechecked
+// while the permission screen is up
+const micPollInterval = 500 * time.Millisecond
+
+// micPermission tracks the first-run microphone access flow
+type micPermission struct {
+	status    permission.Status
+	lastCheck time.Time
+	settings  error // Set if System Settings couldn't be opened
+}
+
+// checkMicPermission shows the permission screen if the app can't hear
+// the microphone, asking the system for access on first run
+func (a *App) checkMicPermission() {
+	status := permission.Microphone()
+	if status == permission.StatusAuthorized {
+		return
+	}
+	if status == permission.StatusNotDetermined {
+		permission.RequestMicrophone()
+	}
+	a.mic = &micPermission{status: status, lastCheck: time.Now()}
+	a.state = StatePermission
+}
+
+// updateMicPermission watches for access being granted and reopens the
+// input so it starts hearing the microphone
+func (a *App) updateMicPermission() {
+	if time.Since(a.mic.lastCheck) < micPollInterval {
+		return
+	}
+	a.mic.lastCheck = time.Now()
+	a.mic.status = permission.Microphone()
+	if a.mic.status != permission.StatusAuthorized {
+		return
+	}
+
+	// Streams opened before access was granted only ever deliver silence,
+	// and one may not have opened at all
+	if a.audioInput == nil {
+		a.RetryInput()
+	} else {
+		if err := a.audioInput.Restart(); err != nil {
+			a.warn("could not restart audio input: %v", err)
+		}
+		a.inputWatchdog.Reset()
+	}
+	a.mic = nil
+	a.GoToMenu()
+}
+
+func (a *App) handlePermissionKey(e key.Event) {
+	switch e.Name {
+	case "S":
+		a.mic.settings = permission.OpenSettings()
+	case key.NameReturn, key.NameEnter, key.NameEscape:
+		// Carry on without the microphone; keyboard play still works
+		a.mic = nil
+		a.GoToMenu()
+	}
+}
+
+func (a *App) layoutPermissionScreen(gtx layout.Context) layout.Dimensions {
+	lines := []string{
+		"Bass Guitar Practice listens to your instrument through the microphone",
+		"or audio interface, but macOS hasn't given it access.",
+	}
+	switch a.mic.status {
+	case permission.StatusNotDetermined:
+		lines = append(lines, "Choose Allow in the system prompt to continue.")
+	case permission.StatusRestricted:
+		lines = append(lines, "Microphone access is restricted on this Mac. Ask your administrator to allow it.")
+	default:
+		lines = append(lines, "Turn on Bass Guitar Practice under Privacy & Security → Microphone.")
+	}
+	if a.mic.settings != nil {
+		lines = append(lines, "Couldn't open System Settings: "+a.mic.settings.Error())
+	}
+
+	children := []layout.FlexChild{
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H5(a.theme, "Microphone Access Needed")
+			label.Color = render.Colors.Warning
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+	}
+	for _, text := range lines {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, text)
+			label.Color = render.Colors.Title
+			return layout.Center.Layout(gtx, label.Layout)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(30)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, "S open System Settings  Enter continue without microphone")
+			label.Color = render.Colors.Success
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"io"
+
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/midi"
+)
+
+var (
+	midiNotesDevice  = flag.String("midi-notes", "", "MIDI output to play the notes heard on: the name of a port to create for synths to connect to (e.g. guitargame), or a raw device path (e.g. /dev/snd/midiC2D0)")
+	midiNotesChannel = flag.Int("midi-channel", 1, "MIDI channel to play the notes heard on, 1 to 16")
+)
+
+// EnableMIDINotes plays the notes heard as MIDI notes on a MIDI port the
+// game creates, or on a device, so the instrument can drive a synth or
+// DAW. The output can be the one beat clock goes to.
+func (a *App) EnableMIDINotes(device string, channel int) error {
+	var out io.Writer = a.midiOut
+	if a.midiOut == nil || device != *midiClockDevice {
+		dev, err := midi.Open(device)
+		if err != nil {
+			return err
+		}
+		a.midiNotesOut = dev
+		out = dev
+	}
+	a.midiNotes = midi.NewNoteOut(out, channel)
+	return nil
+}
+
+// updateMIDINotes passes the latest pitch reading on as MIDI notes,
+// giving up if the device stops taking them
+func (a *App) updateMIDINotes() {
+	if a.midiNotes == nil {
+		return
+	}
+	if err := a.midiNotes.Update(a.currentPitch); err != nil {
+		a.warn("stopped sending MIDI notes: %v", err)
+		a.closeMIDINotes()
+	}
+}
+
+// closeMIDINotes stops the note sounding and closes the device if it's
+// not shared with the clock
+func (a *App) closeMIDINotes() {
+	if a.midiNotes == nil {
+		return
+	}
+	if err := a.midiNotes.Off(); err != nil {
+		logging.Debugf("MIDI note off: %v", err)
+	}
+	a.midiNotes = nil
+	if a.midiNotesOut != nil {
+		a.midiNotesOut.Close()
+		a.midiNotesOut = nil
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/render"
+)
+
+// openInput opens and starts the audio input the launch settings name
+func openInput(launch launchSettings) (*audio.AudioInput, error) {
+	input, err := audio.NewAudioInput(launch.Device, launch.SampleRate, audio.DefaultBufferSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audio input: %w", err)
+	}
+	if err := input.Start(); err != nil {
+		input.Close()
+		return nil, fmt.Errorf("failed to start audio: %w", err)
+	}
+	return input, nil
+}
+
+// RetryInput tries again to open the audio input, after it couldn't be
+// opened at startup
+func (a *App) RetryInput() {
+	if a.audioInput != nil {
+		return
+	}
+	input, err := openInput(a.launch)
+	if err != nil {
+		a.inputErr = err
+		a.warn("audio input still unavailable: %v", err)
+		return
+	}
+	a.audioInput, a.inputErr = input, nil
+	a.inputWatchdog = audio.NewWatchdog(input, audio.DefaultStallTimeout)
+	a.notify(toastInfo, "listening on %s", input.DeviceName())
+}
+
+// layoutNoInputBanner says when nothing can be heard, what still works,
+// and how to fix it
+func (a *App) layoutNoInputBanner(gtx layout.Context) layout.Dimensions {
+	if a.audioInput != nil {
+		return layout.Dimensions{}
+	}
+	inset := layout.Inset{Left: unit.Dp(20), Right: unit.Dp(20), Bottom: unit.Dp(10)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Body1(a.theme, fmt.Sprintf("No audio input: %v", a.inputErr))
+				label.Color = render.Colors.Warning
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(a.theme, "Songs can still be browsed and charts edited  •  I retry  •  A audio check  •  choose a device with -device or \"device:\" in the settings file")
+				label.Color = render.Colors.Hint
+				return label.Layout(gtx)
+			}),
+		)
+	})
+}
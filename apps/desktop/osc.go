@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/osc"
+	"guitargame/core/song"
+)
+
+// OSC messages sent, for lighting, visuals and DAW automation to follow
+// play. Strings are counted from 1, highest first, as in tab.
+//
+//	/pitch f:frequency s:note i:cents       each frame a note is heard
+//	/hit   s:quality i:string i:fret f:offset_ms f:cents
+//	/miss  i:string i:fret
+//	/combo i:combo i:multiplier             after each hit or miss
+
+// startOSC sends play to the address the launch settings give, if any
+func (a *App) startOSC() {
+	if a.launch.OSCAddr == "" {
+		return
+	}
+	s, err := osc.Dial(a.launch.OSCAddr)
+	if err != nil {
+		a.warn("could not send OSC to %s: %v", a.launch.OSCAddr, err)
+		return
+	}
+	a.osc = s
+	logging.Infof("Sending OSC to %s", a.launch.OSCAddr)
+}
+
+// closeOSC stops sending OSC
+func (a *App) closeOSC() {
+	if a.osc != nil {
+		a.osc.Close()
+		a.osc = nil
+	}
+}
+
+// sendOSC sends a message if OSC is on. Failures are only logged for
+// debugging, as they mostly mean nothing is listening yet.
+func (a *App) sendOSC(address string, args ...any) {
+	if a.osc == nil {
+		return
+	}
+	if err := a.osc.Send(address, args...); err != nil {
+		logging.Debugf("OSC %s: %v", address, err)
+	}
+}
+
+// sendPitchOSC sends the note being heard, if any
+func (a *App) sendPitchOSC() {
+	if p := a.currentPitch; p.IsValid() {
+		a.sendOSC("/pitch", p.Frequency, p.FullNoteName(), p.Cents)
+	}
+}
+
+// noteJudged passes on a note of a game being hit or missed
+func (a *App) noteJudged(gs *song.GameState, note *song.TabNote) {
+	if note.HitQuality == song.HitMiss {
+		a.sendOSC("/miss", note.String+1, note.Fret)
+	} else {
+		quality := strings.ToLower(strings.TrimSuffix(note.HitQuality.String(), "!"))
+		a.sendOSC("/hit", quality, note.String+1, note.Fret, note.HitOffset()*1000, note.HitCents)
+	}
+	a.sendOSC("/combo", gs.Combo, gs.Multiplier())
+}
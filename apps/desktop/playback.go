@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/export"
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/render"
+)
+
+// playbackSkip is how far the arrow keys move through a run being watched
+const playbackSkip = 2.0 // Seconds
+
+// playback steps through the recording of the last run
+type playback struct {
+	t       float64 // Song time being shown
+	playing bool
+	last    time.Time // When t last advanced while playing
+}
+
+// OpenPlayback shows the last run played back against the chart
+func (a *App) OpenPlayback() {
+	if a.recording == nil || !a.recording.Of(a.gameState) || len(a.recording.Frames) == 0 {
+		return
+	}
+	a.gameState.FloatingText = nil
+	a.playback = &playback{t: a.recording.Frames[0].Time}
+	a.recording.Rewind(a.playback.t)
+	a.state = StatePlayback
+}
+
+// closePlayback puts the run back as it finished and returns to the results
+func (a *App) closePlayback() {
+	a.recording.Rewind(a.recording.Duration())
+	a.playback = nil
+	a.state = StateResults
+}
+
+// seekPlayback moves to a song time within the recording
+func (a *App) seekPlayback(t float64) {
+	p := a.playback
+	p.t = math.Max(a.recording.Frames[0].Time, math.Min(t, a.recording.Duration()))
+	a.recording.Rewind(p.t)
+}
+
+// updatePlayback advances the time shown while playing
+func (a *App) updatePlayback() {
+	p := a.playback
+	if !p.playing {
+		return
+	}
+	now := time.Now()
+	speed := a.gameState.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	a.seekPlayback(p.t + now.Sub(p.last).Seconds()*speed)
+	p.last = now
+	if p.t >= a.recording.Duration() {
+		p.playing = false
+	}
+}
+
+func (a *App) handlePlaybackKey(e key.Event) {
+	p := a.playback
+	frames := a.recording.Frames
+	switch e.Name {
+	case key.NameSpace:
+		p.playing = !p.playing
+		p.last = time.Now()
+		if p.playing && p.t >= a.recording.Duration() {
+			a.seekPlayback(0)
+		}
+	case key.NameLeftArrow:
+		a.seekPlayback(p.t - playbackSkip)
+	case key.NameRightArrow:
+		a.seekPlayback(p.t + playbackSkip)
+	case ",":
+		p.playing = false
+		a.seekPlayback(frames[max(0, a.recording.FrameAt(p.t)-1)].Time)
+	case ".":
+		p.playing = false
+		a.seekPlayback(frames[min(len(frames)-1, a.recording.FrameAt(p.t)+1)].Time)
+	case key.NameHome:
+		a.seekPlayback(0)
+	case key.NameEscape, key.NameReturn, key.NameEnter:
+		a.closePlayback()
+	}
+}
+
+func (a *App) layoutPlaybackScreen(gtx layout.Context) layout.Dimensions {
+	p := a.playback
+	heard := a.recording.PitchAt(p.t)
+	played := "nothing"
+	if heard.IsValid() {
+		played = fmt.Sprintf("%s (%+d¢)", heard.FullNoteName(), heard.Cents)
+	}
+	expected := "nothing"
+	if note := a.gameState.Song.NextUnhitNote(p.t); note != nil {
+		s := a.gameState.Song
+		expected = fmt.Sprintf("%s%d", s.NoteAt(note), s.OctaveAt(note))
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			state := "Paused"
+			if p.playing {
+				state = "Playing"
+			}
+			text := fmt.Sprintf("Your run of %s  •  %s %s / %s", a.gameState.Song.Title, state, formatSongTime(p.t), formatSongTime(a.recording.Duration()))
+			label := material.H6(a.theme, text)
+			label.Color = render.Colors.Title
+			return layout.Inset{Left: unit.Dp(10), Top: unit.Dp(10)}.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, "Space play/pause  •  ←/→ skip 2 s  •  , / . step  •  Home start  •  Esc back to results")
+			label.Color = render.Colors.Hint
+			return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return a.highway.Layout(gtx, a.gameState)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, fmt.Sprintf("Played: %s  •  Expected: %s", played, expected))
+			label.Color = render.Colors.Info
+			return layout.Inset{Left: unit.Dp(10), Bottom: unit.Dp(10)}.Layout(gtx, label.Layout)
+		}),
+	)
+}
+
+// formatSongTime writes a song time as minutes, seconds and tenths
+func formatSongTime(t float64) string {
+	t = math.Max(0, t)
+	return fmt.Sprintf("%d:%04.1f", int(t)/60, math.Mod(t, 60))
+}
+
+// ExportResults writes how each note of the last run was played to CSV
+// and JSON files in the profile's results directory
+func (a *App) ExportResults() {
+	if a.recording == nil || !a.recording.Of(a.gameState) {
+		return
+	}
+	dir, err := a.config.Dir()
+	if err == nil {
+		var written []string
+		written, err = export.ResultsToFiles(a.recording.Results(), filepath.Join(dir, "results"), a.gameState.Song.Title, a.gameState.StartTime)
+		for _, path := range written {
+			logging.Infof("Exported %s", path)
+		}
+		if len(written) > 0 {
+			a.resultsExported = filepath.Dir(written[0])
+		}
+	}
+	if err != nil {
+		a.notify(toastError, "failed to export results: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// preview is a second window that plays the chart being edited, reloading
+// it from disk at the edited bar each time the editor saves. It runs on
+// its own goroutine, so everything it draws is guarded by mu.
+type preview struct {
+	window *app.Window
+	theme  *material.Theme
+
+	mu       sync.Mutex
+	renderer *render.TabRenderer
+	state    *song.GameState
+	detector *game.HitDetector
+	fromBar  int
+	pitch    pitch.Result // Latest reading from the main window's input
+	closed   bool
+}
+
+// OpenPreview opens the preview window beside the editor, or reloads it
+// if it's already open
+func (a *App) OpenPreview() {
+	if a.preview == nil || a.preview.isClosed() {
+		theme, renderer := a.windowRenderer()
+		a.preview = &preview{window: new(app.Window), theme: theme, renderer: renderer}
+		go a.preview.run()
+	}
+	a.reloadPreview()
+}
+
+// windowRenderer returns a theme and tab renderer for another window,
+// drawing as the main window does
+func (a *App) windowRenderer() (*material.Theme, *render.TabRenderer) {
+	theme := material.NewTheme()
+	theme.Shaper = text.NewShaper(text.WithCollection(a.assets.Fonts()))
+	render.Colors.ApplyMaterial(theme)
+	renderer := render.NewTabRenderer(theme)
+	renderer.NoteLabels = a.tabRenderer.NoteLabels
+	renderer.Notation = a.tabRenderer.Notation
+	renderer.MirrorStrings = a.tabRenderer.MirrorStrings
+	renderer.MirrorHighway = a.tabRenderer.MirrorHighway
+	renderer.DynamicZoom = a.tabRenderer.DynamicZoom
+	renderer.ConstantScroll = a.tabRenderer.ConstantScroll
+	renderer.ScrollSpeed = a.tabRenderer.ScrollSpeed
+	renderer.HideDebug = a.tabRenderer.HideDebug
+	return theme, renderer
+}
+
+// reloadPreview loads the saved chart into the preview, starting at the
+// bar the editor cursor is in
+func (a *App) reloadPreview() {
+	if a.preview == nil || a.preview.isClosed() {
+		return
+	}
+	s, err := song.LoadSong(a.editor.Path)
+	if err != nil {
+		a.warn("could not load %s for preview: %v", a.editor.Path, err)
+		return
+	}
+	a.preview.load(s, int(a.editor.CursorBeat)/song.BeatsPerBar)
+}
+
+// closePreview closes the preview window if it's open
+func (a *App) closePreview() {
+	if a.preview != nil && !a.preview.isClosed() {
+		a.preview.window.Perform(system.ActionClose)
+	}
+	a.preview = nil
+}
+
+// load plays a chart from a bar to its end, over and over
+func (p *preview) load(s *song.Song, bar int) {
+	lastBar := bar
+	if n := len(s.Notes); n > 0 {
+		lastBar = max(bar, int(s.NoteBeat(s.Notes[n-1].Time))/song.BeatsPerBar)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state = song.NewGameState(s)
+	p.detector = game.NewHitDetector(p.state, p.renderer.FeedbackY)
+	p.fromBar = bar
+	p.state.PlayLoop(s.BarRegion(bar, lastBar))
+	p.window.Invalidate()
+}
+
+// setPitch passes on the main window's latest pitch reading
+func (p *preview) setPitch(r pitch.Result) {
+	p.mu.Lock()
+	p.pitch = r
+	p.mu.Unlock()
+}
+
+func (p *preview) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// run handles the preview window's events until it's closed
+func (p *preview) run() {
+	p.window.Option(
+		app.Title("Chart Preview"),
+		app.Size(unit.Dp(screenWidth), unit.Dp(screenHeight)),
+	)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(time.Second / 60)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.window.Invalidate()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var ops op.Ops
+	for {
+		switch e := p.window.Event().(type) {
+		case app.DestroyEvent:
+			if e.Err != nil {
+				logging.Warnf("preview window: %v", e.Err)
+			}
+			p.mu.Lock()
+			p.closed = true
+			p.mu.Unlock()
+			return
+
+		case app.FrameEvent:
+			gtx := app.NewContext(&ops, e)
+			p.layout(gtx)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (p *preview) layout(gtx layout.Context) layout.Dimensions {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	paint.ColorOp{Color: render.Colors.Background}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+
+	if p.state == nil {
+		return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(p.theme, "Save the chart (Ctrl+S) to preview it")
+			label.Color = render.Colors.Hint
+			return label.Layout(gtx)
+		})
+	}
+
+	p.state.Update()
+	playLineX := p.renderer.PlayLinePos(float32(gtx.Constraints.Max.X))
+	p.detector.CheckHit(p.pitch, playLineX)
+	p.detector.Update()
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return p.renderer.DrawHeader(gtx, p.state)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := fmt.Sprintf("Previewing from bar %d  •  saving in the editor reloads here", p.fromBar+1)
+			label := material.Body2(p.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return p.renderer.Layout(gtx, p.state)
+		}),
+	)
+}
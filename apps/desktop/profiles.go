@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/config"
+	"guitargame/apps/desktop/internal/render"
+)
+
+// profileSelect chooses whose settings, scores and history are used
+type profileSelect struct {
+	names  []string // Profile names, the default profile first
+	index  int
+	naming bool // Typing a new profile's name
+	editor widget.Editor
+	err    string // Why the last name typed couldn't be used
+}
+
+// OpenProfiles lists the profiles to switch between
+func (a *App) OpenProfiles() {
+	names, err := config.Profiles()
+	if err != nil {
+		a.warn("could not list profiles: %v", err)
+	}
+	p := &profileSelect{names: append([]string{config.DefaultProfile}, names...)}
+	for i, name := range p.names {
+		if name == a.config.Profile {
+			p.index = i
+		}
+	}
+	p.editor.SingleLine = true
+	p.editor.Submit = true
+	a.profiles = p
+	a.state = StateProfiles
+}
+
+// SwitchProfile loads a profile's settings, scores and history in place
+// of the current ones
+func (a *App) SwitchProfile(name string) {
+	if name != a.config.Profile {
+		a.rememberSelection()
+		cfg, err := config.LoadProfile(name)
+		if err != nil {
+			a.warn("could not load settings: %v", err)
+		}
+		a.closeHistory()
+		a.config = cfg
+		a.openScores()
+		a.openHistory()
+		a.loadDailies()
+		a.loadFavorites()
+		a.loadRecent()
+		a.loadRoutines()
+		a.recording, a.ghost = nil, nil
+		a.speed = 1
+		a.applyHandedness()
+		a.applyNoteLabels()
+		a.applyHighway()
+		a.applyScroll()
+		a.applyTheme()
+		a.restoreSession()
+		a.startTelemetry()
+	}
+	a.profiles = nil
+	a.state = StateMenu
+}
+
+func (a *App) handleProfilesKey(gtx layout.Context, e key.Event) {
+	p := a.profiles
+	if p.naming {
+		if e.Name == key.NameEscape {
+			p.naming, p.err = false, ""
+			p.editor.SetText("")
+			gtx.Execute(key.FocusCmd{Tag: nil})
+		}
+		return
+	}
+	switch e.Name {
+	case key.NameUpArrow:
+		p.index = (p.index - 1 + len(p.names)) % len(p.names)
+	case key.NameDownArrow:
+		p.index = (p.index + 1) % len(p.names)
+	case key.NameReturn, key.NameEnter:
+		a.SwitchProfile(p.names[p.index])
+	case "N":
+		p.naming = true
+		gtx.Execute(key.FocusCmd{Tag: &p.editor})
+	case key.NameEscape:
+		a.profiles = nil
+		a.state = StateMenu
+	}
+}
+
+// createProfile makes a profile from the name typed and switches to it
+func (a *App) createProfile(gtx layout.Context) {
+	p := a.profiles
+	name := strings.TrimSpace(p.editor.Text())
+	if err := config.CreateProfile(name); err != nil {
+		p.err = err.Error()
+		return
+	}
+	gtx.Execute(key.FocusCmd{Tag: nil})
+	a.SwitchProfile(name)
+}
+
+// profileLabel names a profile for display
+func profileLabel(name string) string {
+	if name == config.DefaultProfile {
+		return "Default"
+	}
+	return name
+}
+
+func (a *App) layoutProfilesScreen(gtx layout.Context) layout.Dimensions {
+	p := a.profiles
+	for {
+		ev, ok := p.editor.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.SubmitEvent); ok {
+			a.createProfile(gtx)
+			if a.profiles == nil {
+				return layout.Dimensions{}
+			}
+		}
+	}
+
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H4(a.theme, "Who's playing?")
+			label.Color = render.Colors.Title
+			return layout.Inset{Top: unit.Dp(20), Left: unit.Dp(20), Bottom: unit.Dp(10)}.Layout(gtx, label.Layout)
+		}),
+	}
+	for i, name := range p.names {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := "  " + profileLabel(name)
+			c := render.Colors.Title
+			if i == p.index {
+				text = "▶ " + profileLabel(name)
+				c = render.Colors.Accent
+			}
+			if name == a.config.Profile {
+				text += "  (current)"
+			}
+			label := material.H6(a.theme, text)
+			label.Color = c
+			return layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(4)}.Layout(gtx, label.Layout)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !p.naming {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: unit.Dp(20), Right: unit.Dp(20)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Baseline}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						label := material.Body1(a.theme, "New profile: ")
+						label.Color = render.Colors.Hint
+						return label.Layout(gtx)
+					}),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						ed := material.Editor(a.theme, &p.editor, "name")
+						ed.Color = render.Colors.Input
+						ed.HintColor = render.Colors.InputHint
+						return ed.Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if p.err == "" {
+				return layout.Dimensions{}
+			}
+			label := material.Body2(a.theme, p.err)
+			label.Color = render.Colors.Error
+			return layout.Inset{Left: unit.Dp(20)}.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := "↑/↓ choose  •  Enter switch  •  N new profile  •  Esc back to menu"
+			if p.naming {
+				text = "Type a name and press Enter  •  Esc cancel"
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Hint
+			return layout.Inset{Left: unit.Dp(20), Top: unit.Dp(10)}.Layout(gtx, label.Layout)
+		}),
+	)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+// profileHint names the current profile for the menu's key hints
+func (a *App) profileHint() string {
+	return fmt.Sprintf("O profile: %s", profileLabel(a.config.Profile))
+}
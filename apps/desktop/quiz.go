@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+)
+
+// maxListedQuizNotes is how many of the slowest notes the quiz summary lists
+const maxListedQuizNotes = 5
+
+// quiz is a fretboard quiz in progress, or its summary once finished
+type quiz struct {
+	*game.Quiz
+	finished bool
+}
+
+// OpenQuiz starts a fretboard quiz on the selected song's instrument
+func (a *App) OpenQuiz() {
+	s := a.exercises[a.selectedIndex]
+	a.pitchDetector.SetRange(s.FrequencyRange())
+	a.quiz = &quiz{Quiz: game.NewQuiz(s.GetTuning(), time.Now().UnixNano(), time.Now())}
+	a.state = StateQuiz
+}
+
+// updateQuiz checks what's being played against the prompt
+func (a *App) updateQuiz() {
+	if a.quiz.finished {
+		return
+	}
+	if a.quiz.Check(a.currentPitch, time.Now()) {
+		a.playSound(audio.SoundHit)
+	}
+}
+
+func (a *App) handleQuizKey(e key.Event) {
+	switch e.Name {
+	case key.NameEscape, key.NameReturn, key.NameEnter:
+		if a.quiz.finished || len(a.quiz.Answers) == 0 {
+			a.quiz = nil
+			a.GoToMenu()
+			return
+		}
+		a.quiz.finished = true
+	}
+}
+
+// summary totals the answers so far
+func (q *quiz) summary() string {
+	if len(q.Answers) == 0 {
+		return "No notes found yet"
+	}
+	var total time.Duration
+	tries := 0
+	for _, ans := range q.Answers {
+		total += ans.Time
+		tries += ans.Tries
+	}
+	return fmt.Sprintf("%d found  •  %.1f s on average  •  %d wrong notes", len(q.Answers), (total / time.Duration(len(q.Answers))).Seconds(), tries)
+}
+
+func (a *App) layoutQuizScreen(gtx layout.Context) layout.Dimensions {
+	q := a.quiz
+	children := []layout.FlexChild{
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H4(a.theme, "Fretboard Quiz")
+			label.Color = render.Colors.Title
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(30)}.Layout),
+	}
+	if q.finished {
+		children = append(children, a.layoutQuizTimes()...)
+	} else {
+		children = append(children,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := fmt.Sprintf("Play %s on the %s string", q.Prompt.Note, q.StringName(q.Prompt.String))
+				label := material.H3(a.theme, text)
+				label.Color = render.Colors.Info
+				return layout.Center.Layout(gtx, label.Layout)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := fmt.Sprintf("%.0f s", q.Waiting(time.Now()).Seconds())
+				c := render.Colors.Text
+				if q.Heard != "" {
+					text = fmt.Sprintf("That was %s on another string, or another note  •  %s", q.Heard, text)
+					c = render.Colors.Selected
+				}
+				label := material.Body1(a.theme, text)
+				label.Color = c
+				return layout.Center.Layout(gtx, label.Layout)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(30)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(a.theme, q.summary()+"  •  Esc to finish")
+				label.Color = render.Colors.Hint
+				return layout.Center.Layout(gtx, label.Layout)
+			}),
+		)
+	}
+	children = append(children,
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.tabRenderer.DrawDetectedNote(gtx, a.currentPitch.FullNoteName(), a.currentPitch.Frequency, a.currentPitch.Confidence)
+		}),
+	)
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// layoutQuizTimes sums up a finished quiz with the notes slowest to find
+func (a *App) layoutQuizTimes() []layout.FlexChild {
+	q := a.quiz
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H6(a.theme, q.summary())
+			label.Color = render.Colors.Highlight
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, "Slowest to find")
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+	}
+	times := q.NoteTimes()
+	for _, t := range times[:min(len(times), maxListedQuizNotes)] {
+		text := fmt.Sprintf("%s on the %s string  •  %.1f s", t.Note, q.StringName(t.String), t.Average.Seconds())
+		if t.Count > 1 {
+			text += fmt.Sprintf(" (%d times)", t.Count)
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}))
+	}
+	return append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, "Enter to return to menu")
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+	)
+}
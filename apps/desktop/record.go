@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/backing"
+	"guitargame/apps/desktop/internal/editor"
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/apps/desktop/internal/transcribe"
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// recordPhase is the step of the record-to-chart flow
+type recordPhase int
+
+const (
+	recordSetup recordPhase = iota
+	recordRunning
+	recordReview
+)
+
+// recordSubdivisions are the quantization grids offered, in steps per beat
+var recordSubdivisions = []struct {
+	steps int
+	name  string
+}{
+	{1, "quarter notes"},
+	{2, "eighth notes"},
+	{3, "eighth-note triplets"},
+	{4, "sixteenth notes"},
+}
+
+// recordCountInBeats is how many metronome clicks precede recording
+const recordCountInBeats = backing.BeatsPerBar
+
+// recorder holds the state of a record-to-chart session
+type recorder struct {
+	phase      recordPhase
+	bpm        float64
+	subdiv     int // Index into recordSubdivisions
+	start      time.Time
+	clickTrack *song.Song // Drives the metronome
+	scribe     *transcribe.Transcriber
+	result     *song.Song
+}
+
+// OpenRecorder shows the record-to-chart setup screen
+func (a *App) OpenRecorder() {
+	if a.audioInput == nil {
+		return // Nothing to transcribe; the menu says why
+	}
+	a.recorder = &recorder{
+		bpm:    90,
+		subdiv: 1,
+	}
+	a.state = StateRecording
+}
+
+func (r *recorder) countIn() float64 {
+	return recordCountInBeats * 60 / r.bpm
+}
+
+// elapsed returns seconds since the first recorded beat (negative during the count-in)
+func (r *recorder) elapsed() float64 {
+	return time.Since(r.start).Seconds() - r.countIn()
+}
+
+func (a *App) startRecording() {
+	r := a.recorder
+	r.phase = recordRunning
+	r.start = time.Now()
+	r.scribe = transcribe.New(r.bpm, recordSubdivisions[r.subdiv].steps, song.TuningStandard)
+	r.result = nil
+
+	// The click plays for as long as the recording runs
+	r.clickTrack = &song.Song{BPM: r.bpm, Duration: 24 * time.Hour.Seconds()}
+	if a.drummer != nil {
+		a.drummer.Reset(r.clickTrack, backing.Metronome)
+	}
+}
+
+func (a *App) stopRecording() {
+	r := a.recorder
+	if a.drummer != nil {
+		a.drummer.Reset(nil, nil)
+	}
+	r.scribe.Finish(r.elapsed())
+	r.result = r.scribe.Song("Recording " + time.Now().Format("2006-01-02 15:04"))
+	r.phase = recordReview
+}
+
+// updateRecording feeds the latest pitch reading to the transcriber
+func (a *App) updateRecording(pitch pitch.Result) {
+	r := a.recorder
+	if r == nil || r.phase != recordRunning {
+		return
+	}
+	if a.drummer != nil {
+		a.drummer.Update(time.Since(r.start).Seconds())
+	}
+
+	t := r.elapsed()
+	if t < 0 {
+		return
+	}
+	// The detector looks at the last buffer of audio, so on average the
+	// sound it reports happened half a buffer ago
+	latency := float64(a.audioInput.BufferSize()) / a.audioInput.SampleRate() / 2
+	r.scribe.Add(transcribe.Frame{
+		Time:       t - latency,
+		Frequency:  pitch.Frequency,
+		Confidence: pitch.Confidence,
+		RMS:        pitch.RMS,
+	})
+}
+
+// saveRecording writes the transcribed chart next to the other songs and selects it
+func (a *App) saveRecording(edit bool) {
+	s := a.recorder.result
+	ed := editor.New(s, a.songsDir)
+	if err := ed.Save(); err != nil {
+		a.notify(toastError, "failed to save recording: %v", err)
+		return
+	}
+	logging.Infof("Saved %s", ed.Path)
+
+	a.exercises = append(a.exercises, s)
+	a.recorder = nil
+	a.SelectExercise(len(a.exercises) - 1)
+	if edit {
+		a.OpenEditor(s)
+	} else {
+		a.GoToMenu()
+	}
+}
+
+func (a *App) handleRecorderKey(e key.Event) {
+	r := a.recorder
+	switch r.phase {
+	case recordSetup:
+		switch e.Name {
+		case key.NameUpArrow:
+			r.bpm = min(r.bpm+5, 240)
+		case key.NameDownArrow:
+			r.bpm = max(r.bpm-5, 40)
+		case key.NameLeftArrow:
+			r.subdiv = (r.subdiv - 1 + len(recordSubdivisions)) % len(recordSubdivisions)
+		case key.NameRightArrow:
+			r.subdiv = (r.subdiv + 1) % len(recordSubdivisions)
+		case key.NameReturn, key.NameEnter, key.NameSpace:
+			a.startRecording()
+		case key.NameEscape:
+			a.recorder = nil
+			a.GoToMenu()
+		}
+	case recordRunning:
+		switch e.Name {
+		case key.NameReturn, key.NameEnter, key.NameSpace, key.NameEscape:
+			a.stopRecording()
+		}
+	case recordReview:
+		switch e.Name {
+		case "S":
+			a.saveRecording(false)
+		case "E":
+			a.saveRecording(true)
+		case "R":
+			r.phase = recordSetup
+		case key.NameEscape:
+			a.recorder = nil
+			a.GoToMenu()
+		}
+	}
+}
+
+func (a *App) layoutRecordingScreen(gtx layout.Context) layout.Dimensions {
+	r := a.recorder
+	if r.phase == recordRunning {
+		return a.layoutRecordingTab(gtx)
+	}
+
+	var lines []string
+	switch r.phase {
+	case recordSetup:
+		lines = []string{
+			"Record a Chart",
+			fmt.Sprintf("Tempo: %.0f BPM  (↑/↓)", r.bpm),
+			fmt.Sprintf("Quantize to: %s  (←/→)", recordSubdivisions[r.subdiv].name),
+			"Play freely after a one-bar count-in. Enter to start, Esc to cancel.",
+		}
+	case recordReview:
+		lines = []string{
+			"Recording Complete",
+			fmt.Sprintf("Captured %d notes at %.0f BPM", len(r.result.Notes), r.bpm),
+			"",
+			"S save as new song  •  E save and edit  •  R record again  •  Esc discard",
+		}
+	}
+
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H5(a.theme, lines[0])
+			label.Color = render.Colors.Info
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, lines[1])
+			label.Color = render.Colors.Title
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, lines[2])
+			label.Color = render.Colors.Title
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(30)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.tabRenderer.DrawDetectedNote(gtx, a.currentPitch.FullNoteName(), a.currentPitch.Frequency, a.currentPitch.Confidence)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, lines[3])
+			label.Color = render.Colors.Success
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+}
+
+// layoutRecordingTab shows the notes captured so far scrolling past the play line
+func (a *App) layoutRecordingTab(gtx layout.Context) layout.Dimensions {
+	r := a.recorder
+	t := r.elapsed()
+	preview := &song.GameState{
+		Song:        r.scribe.Song("Recording"),
+		CurrentTime: t,
+	}
+
+	status := fmt.Sprintf("Recording  •  beat %.0f  •  %d notes  •  Enter to stop", r.clickTrack.TimeToBeat(t)+1, r.scribe.Count())
+	if t < 0 {
+		status = fmt.Sprintf("Count-in: %d", int(-t/(60/r.bpm))+1)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Left: unit.Dp(10), Top: unit.Dp(10)}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.H6(a.theme, status)
+				label.Color = render.Colors.Error
+				return label.Layout(gtx)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return a.tabRenderer.Layout(gtx, preview)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.tabRenderer.DrawDetectedNote(gtx, a.currentPitch.FullNoteName(), a.currentPitch.Frequency, a.currentPitch.Confidence)
+		}),
+	)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/core/song"
+)
+
+// watchSongs starts reloading charts when files in the songs directory change
+func (a *App) watchSongs() {
+	if info, err := os.Stat(a.songsDir); err != nil || !info.IsDir() {
+		return
+	}
+	w, err := song.WatchDirectory(a.songsDir)
+	if err != nil {
+		a.warn("not watching %s for changes: %v", a.songsDir, err)
+		return
+	}
+	a.songWatcher = w
+}
+
+// checkSongChanges applies any changes the watcher has seen
+func (a *App) checkSongChanges() {
+	if a.songWatcher == nil {
+		return
+	}
+	select {
+	case changed := <-a.songWatcher.Changes:
+		a.reloadSongs(changed)
+	default:
+	}
+}
+
+// reloadSongs refreshes the exercise list after files changed on disk.
+// Charts whose files didn't change keep their in-memory copy, so unsaved
+// edits survive, as does a chart with unsaved edits open in the editor.
+func (a *App) reloadSongs(paths []string) {
+	changed := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		changed[p] = true
+	}
+	if ed := a.editor; ed != nil && ed.Dirty && changed[ed.Path] {
+		a.notify(toastInfo, "%s changed on disk; keeping unsaved edits", ed.Path)
+		delete(changed, ed.Path)
+	}
+
+	loaded, _, skipped := a.assets.Songs()
+	a.reportSkippedCharts(skipped)
+	old := a.exercises
+	used := make(map[*song.Song]bool)
+	var merged []*song.Song
+	for _, s := range loaded {
+		if prev := findSameSong(old, s); prev != nil && !changed[songFile(s)] {
+			s = prev
+		}
+		used[s] = true
+		merged = append(merged, s)
+	}
+	// Charts that only exist in memory (new and unsaved) stay in the list
+	for _, s := range old {
+		if !used[s] && songFile(s) == "" {
+			merged = append(merged, s)
+		}
+	}
+	if len(merged) == 0 {
+		merged = song.GetDefaultExercises()
+	}
+
+	selected := a.exercises[a.selectedIndex]
+	a.exercises = merged
+	a.selectedIndex = 0
+	for i, s := range merged {
+		if s == selected || findSameSong([]*song.Song{s}, selected) != nil {
+			a.selectedIndex = i
+			break
+		}
+	}
+
+	// Point the editor at the reloaded copy of its chart
+	if ed := a.editor; ed != nil && ed.Path != "" {
+		for _, s := range merged {
+			if s.Path == ed.Path {
+				ed.Song = s
+				break
+			}
+		}
+	}
+
+	if (a.state == StateMenu || a.state == StatePreStart) && a.riff == nil {
+		a.SelectExercise(a.selectedIndex)
+	}
+	logging.Infof("Reloaded songs (%d changed files)", len(paths))
+}
+
+// songFile is the file a song was loaded from, or "" for built-in and
+// unsaved charts
+func songFile(s *song.Song) string {
+	switch {
+	case s.Pack != "":
+		return s.Pack
+	case s.Generator != "":
+		return s.Generator
+	}
+	return s.Path
+}
+
+// findSameSong finds the song in list that was loaded from the same place as s
+func findSameSong(list []*song.Song, s *song.Song) *song.Song {
+	for _, prev := range list {
+		if songFile(prev) != songFile(s) {
+			continue
+		}
+		// Songs in a pack or from a script, and built-ins, share a source,
+		// so match by title
+		if s.Path == "" && prev.Title != s.Title {
+			continue
+		}
+		return prev
+	}
+	return nil
+}
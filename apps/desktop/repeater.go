@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// ToggleRiffRepeater switches whether sections with many misses are
+// looped slowly during a run
+func (a *App) ToggleRiffRepeater() {
+	a.config.RiffRepeater = !a.config.RiffRepeater
+	a.saveSettings()
+}
+
+// repeating reports whether the riff repeater is looping a section
+func (a *App) repeating() bool {
+	return a.repeater != nil && a.repeater.Active() != nil
+}
+
+// updateRepeater lets the riff repeater judge the run, announcing when it
+// starts looping a section, speeds up, and hands back to the run
+func (a *App) updateRepeater(playLineX float32) {
+	gs := a.gameState
+	var text string
+	switch a.repeater.Update(gs) {
+	case song.RepeatStarted:
+		// The backing track and external clock can't follow the loop
+		a.stopBackingTrack()
+		a.stopMIDIClock()
+		text = "Slowing down: " + a.repeater.Active().String()
+	case song.RepeatSpedUp:
+		text = fmt.Sprintf("Speed up! %.0f%%", gs.Speed*100)
+	case song.RepeatResumed:
+		text = "Back to the run"
+	default:
+		return
+	}
+	gs.FloatingText = append(gs.FloatingText, song.FloatingScore{
+		Text:      text,
+		X:         playLineX,
+		Y:         a.feedbackY(0),
+		StartTime: time.Now(),
+		Quality:   song.HitPerfect,
+	})
+}
+
+// layoutRepeaterStatus says which section the riff repeater is looping
+func (a *App) layoutRepeaterStatus(gtx layout.Context) layout.Dimensions {
+	text := fmt.Sprintf("Riff repeater: %s at %.0f%% speed  •  play it %.0f%% clean to speed up, then the run carries on",
+		a.repeater.Active(), a.gameState.Speed*100, a.config.RepeaterRecoverPercent)
+	label := material.Body2(a.theme, text)
+	label.Color = render.Colors.Hint
+	return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, label.Layout)
+}
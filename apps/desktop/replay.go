@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+// ReplaySpeed is how fast a missed passage is replayed, and then looped
+const ReplaySpeed = 0.5
+
+// maxListedPassages is how many missed passages the results screen lists at once
+const maxListedPassages = 5
+
+// practice is a missed passage being shown slowly and then looped
+type practice struct {
+	region song.Region
+
+	// The finished play, to return to its results afterwards (nil when
+	// practice began from the song's start screen)
+	results     *song.GameState
+	hitDetector *game.HitDetector
+	noteLabels  render.NoteLabelMode
+}
+
+// showResults finishes play and lists the passages with missed notes
+func (a *App) showResults() {
+	a.repeater = nil
+	a.resultsExported = ""
+	a.keepBestRun()
+	a.recordScore()
+	a.logSession()
+	a.suggestCalibration()
+	a.missed = a.gameState.MissedRegions()
+	a.missedIndex = 0
+	a.state = StateResults
+	if a.setlist != nil {
+		a.setlistSongDone()
+	}
+}
+
+func (a *App) handleResultsKey(e key.Event) {
+	switch e.Name {
+	case key.NameUpArrow:
+		if a.missedIndex > 0 {
+			a.missedIndex--
+		}
+	case key.NameDownArrow:
+		if a.missedIndex < len(a.missed)-1 {
+			a.missedIndex++
+		}
+	case "P":
+		a.StartReplay()
+	case "C":
+		a.resultsChart = (a.resultsChart + 1) % resultsChartCount
+	case "V":
+		a.OpenPlayback()
+	case "X":
+		a.ExportResults()
+	case key.NameEscape, key.NameReturn, key.NameEnter:
+		a.GoToMenu()
+	}
+}
+
+// StartReplay plays the selected missed passage by itself at reduced speed
+// with its note names shown, then loops it for the player to practice
+func (a *App) StartReplay() {
+	if a.missedIndex >= len(a.missed) {
+		return
+	}
+	a.startPractice(a.missed[a.missedIndex], a.gameState)
+}
+
+// startPractice replays a passage and then loops it, going back to
+// results afterwards if there are any
+func (a *App) startPractice(r song.Region, results *song.GameState) {
+	a.practice = &practice{
+		region:      r,
+		results:     results,
+		hitDetector: a.hitDetector,
+		noteLabels:  a.tabRenderer.NoteLabels,
+	}
+
+	gs := song.NewGameState(a.gameState.Song)
+	gs.Speed = ReplaySpeed
+	a.applyPlaySettings(gs)
+	a.gameState = gs
+	a.saveSession(&r)
+	a.hitDetector = game.NewHitDetector(gs, a.feedbackY)
+	a.tabRenderer.NoteLabels = render.LabelBoth
+
+	gs.PlayLoop(a.practice.region)
+	a.state = StatePlaying
+}
+
+// replaying reports whether the passage is still being played for the
+// player, before their first time round the loop
+func (a *App) replaying() bool {
+	return a.practice != nil && a.gameState.Laps <= 1
+}
+
+// autoplay sounds and names each note of the replay as it reaches the play line
+func (a *App) autoplay(playLineX float32) {
+	gs := a.gameState
+	for _, note := range gs.AutoplayDue() {
+		if a.audioOutput != nil {
+			a.audioOutput.Play(audio.NewPluck(gs.Song.FrequencyAt(note), note.Duration/ReplaySpeed, a.audioOutput.SampleRate()))
+		}
+		gs.FloatingText = append(gs.FloatingText, song.FloatingScore{
+			Text:      gs.Song.NoteAt(note),
+			X:         playLineX,
+			Y:         a.feedbackY(note.String),
+			StartTime: time.Now(),
+			Quality:   song.HitPerfect,
+		})
+	}
+}
+
+// EndPractice stops looping and goes back to the results it came from
+func (a *App) EndPractice() {
+	p := a.practice
+	a.practice = nil
+	a.tabRenderer.NoteLabels = p.noteLabels
+	if p.results == nil {
+		a.GoToMenu()
+		return
+	}
+	a.gameState = p.results
+	a.hitDetector = p.hitDetector
+	a.state = StateResults
+}
+
+// layoutPracticeStatus says what the practice loop is doing, or that the
+// song is being played slowly
+func (a *App) layoutPracticeStatus(gtx layout.Context) layout.Dimensions {
+	if a.trainer != nil {
+		return a.layoutTrainerStatus(gtx)
+	}
+	if a.echo != nil && a.practice == nil {
+		return a.layoutEchoStatus(gtx)
+	}
+	if a.repeating() {
+		return a.layoutRepeaterStatus(gtx)
+	}
+	if a.variations != nil {
+		return a.layoutVariationsStatus(gtx)
+	}
+	speed := fmt.Sprintf("%.0f%% speed", ReplaySpeed*100)
+	var text string
+	switch {
+	case a.practice == nil && a.gameState.Speed > 0 && a.gameState.Speed < 1:
+		text = fmt.Sprintf("Practicing at %.0f%% speed", a.gameState.Speed*100)
+	case a.practice == nil:
+		return layout.Dimensions{}
+	case a.replaying():
+		text = fmt.Sprintf("Replay: %s at %s, listen and watch  •  Esc to stop", a.practice.region, speed)
+	default:
+		text = fmt.Sprintf("Loop practice: %s at %s  •  time round %d  •  Esc to stop", a.practice.region, speed, a.gameState.Laps-1)
+	}
+	label := material.Body2(a.theme, text)
+	label.Color = render.Colors.Hint
+	return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, label.Layout)
+}
+
+// Charts the results screen can show, switched with C
+type resultsChart int
+
+const (
+	chartTiming    resultsChart = iota // When each hit landed
+	chartHistogram                     // How many hits landed how far off the beat
+	chartPositions                     // Hits by string and fret region
+	resultsChartCount
+)
+
+// layoutResultsChart shows the chosen chart of how the run went
+func (a *App) layoutResultsChart(gtx layout.Context) layout.Dimensions {
+	switch a.resultsChart {
+	case chartHistogram:
+		return a.layoutTimingHistogram(gtx)
+	case chartPositions:
+		return a.layoutPositionStats(gtx)
+	}
+	return a.layoutTimingScatter(gtx)
+}
+
+// layoutPositionStats shows hits and misses by string and fret region
+func (a *App) layoutPositionStats(gtx layout.Context) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, "Hits by string and fret  •  C for hit timing")
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return a.tabRenderer.DrawPositionHeatMap(gtx, a.gameState.PositionStats(), a.gameState.Song.GetTuning())
+			})
+		}),
+	)
+}
+
+// layoutTimingScatter charts how early or late each hit was, with the
+// average and spread
+func (a *App) layoutTimingScatter(gtx layout.Context) layout.Dimensions {
+	hits := a.gameState.HitTimings()
+	if len(hits) < 2 {
+		return layout.Dimensions{}
+	}
+	mean, stddev := song.TimingSpread(hits)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := fmt.Sprintf("Hit timing  •  average %+.0f ms  •  spread ±%.0f ms  •  C for a histogram", mean*1000, stddev*1000)
+			label := material.Body2(a.theme, text)
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Left: unit.Dp(40), Right: unit.Dp(40), Top: unit.Dp(5)}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return a.tabRenderer.DrawTimingScatter(gtx, hits, a.gameState.Song.Duration)
+			})
+		}),
+	)
+}
+
+// layoutMissedPassages lists the passages with missed notes on the results screen
+func (a *App) layoutMissedPassages(gtx layout.Context) layout.Dimensions {
+	if len(a.missed) == 0 {
+		return layout.Dimensions{}
+	}
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, "Missed passages  (↑/↓ to choose, P to replay slowly and loop)")
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+	}
+	first := max(0, min(a.missedIndex-maxListedPassages/2, len(a.missed)-maxListedPassages))
+	last := min(first+maxListedPassages, len(a.missed))
+	for i := first; i < last; i++ {
+		r := a.missed[i]
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			notes := "notes"
+			if r.Missed == 1 {
+				notes = "note"
+			}
+			text := fmt.Sprintf("%s  •  %d %s missed", r, r.Missed, notes)
+			c := render.Colors.Hint
+			if i == a.missedIndex {
+				text = "▶ " + text
+				c = render.Colors.Selected
+			}
+			label := material.Body2(a.theme, text)
+			label.Color = c
+			return layout.Center.Layout(gtx, label.Layout)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+// layoutTimingHistogram counts hits by how far off the beat they were
+func (a *App) layoutTimingHistogram(gtx layout.Context) layout.Dimensions {
+	hits := a.gameState.HitTimings()
+	if len(hits) < 2 {
+		return layout.Dimensions{}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, "Hits by how far off the beat  •  C for strings and frets")
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Left: unit.Dp(40), Right: unit.Dp(40), Top: unit.Dp(5)}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return a.tabRenderer.DrawTimingHistogram(gtx, hits, a.resultsWindows())
+			})
+		}),
+	)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+// resultsQualities are the hit qualities the results screen counts, best first
+var resultsQualities = []song.HitQuality{song.HitPerfect, song.HitGood, song.HitOK, song.HitMiss}
+
+// resultsWindows returns the timing windows the run was judged with
+func (a *App) resultsWindows() song.TimingWindows {
+	if a.gameState.Windows == (song.TimingWindows{}) {
+		return game.WindowsNormal
+	}
+	return a.gameState.Windows
+}
+
+// layoutQualityCounts shows how many notes were played at each quality,
+// each in its note color
+func (a *App) layoutQualityCounts(gtx layout.Context) layout.Dimensions {
+	counts := a.gameState.QualityCounts()
+	var children []layout.FlexChild
+	for i, q := range resultsQualities {
+		sep := ""
+		if i > 0 {
+			sep = "  •  "
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, fmt.Sprintf("%s%s %d", sep, strings.TrimSuffix(q.String(), "!"), counts[q]))
+			label.Color = render.QualityColor(q)
+			return label.Layout(gtx)
+		}))
+	}
+	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+	})
+}
+
+// layoutComboAndSections shows the longest combo, and the best and worst
+// played sections when there was more than one
+func (a *App) layoutComboAndSections(gtx layout.Context) layout.Dimensions {
+	text := fmt.Sprintf("Longest combo: %d", a.gameState.MaxCombo)
+	if n := a.gameState.TotalNotes; n > 0 {
+		text += fmt.Sprintf(" of %d notes", n)
+	}
+	sections := a.gameState.SectionStats()
+	if len(sections) > 1 {
+		best, worst := sections[0], sections[0]
+		for _, s := range sections[1:] {
+			if s.Accuracy > best.Accuracy {
+				best = s
+			}
+			if s.Accuracy < worst.Accuracy {
+				worst = s
+			}
+		}
+		text += fmt.Sprintf("  •  Best: %s %.0f%%", best.Region, best.Accuracy)
+		if worst.Accuracy < best.Accuracy {
+			text += fmt.Sprintf("  •  Toughest: %s %.0f%%", worst.Region, worst.Accuracy)
+		}
+	}
+	label := material.Body1(a.theme, text)
+	label.Color = render.Colors.Text
+	return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Center.Layout(gtx, label.Layout)
+	})
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/generator"
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/apps/desktop/internal/routine"
+)
+
+// routinesPath returns where the profile's practice routines are kept
+func (a *App) routinesPath() (string, error) {
+	dir, err := a.config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, routine.FileName), nil
+}
+
+// loadRoutines reads the profile's practice routines
+func (a *App) loadRoutines() {
+	a.routines = nil
+	path, err := a.routinesPath()
+	if err == nil {
+		a.routines, err = routine.Load(path)
+	}
+	if err != nil {
+		a.warn("could not load practice routines: %v", err)
+	}
+}
+
+// todaysRoutine returns the first routine scheduled for today, if any
+func (a *App) todaysRoutine() *routine.Routine {
+	today := routine.Today(a.routines, time.Now())
+	if len(today) == 0 {
+		return nil
+	}
+	return &today[0]
+}
+
+// StartRoutine plays today's routine as a setlist
+func (a *App) StartRoutine() {
+	r := a.todaysRoutine()
+	if r == nil {
+		return
+	}
+	var items []setlistItem
+	for _, it := range r.Items {
+		item, ok := a.routineItem(it)
+		if !ok {
+			a.warn("skipping %q in routine %q: no such song or riff", it.Song+it.Riff, r.Name)
+			continue
+		}
+		items = append(items, item)
+	}
+	a.playSetlist(items)
+}
+
+// routineItem turns a step of a routine into something the setlist can play
+func (a *App) routineItem(it routine.Item) (setlistItem, bool) {
+	item := setlistItem{duration: time.Duration(it.Minutes * float64(time.Minute))}
+	if it.Riff != "" {
+		setup := &riffSetup{template: -1, density: 0.4}
+		for i, t := range generator.Templates {
+			if strings.EqualFold(t.Name, it.Riff) {
+				setup.template = i
+			}
+		}
+		if setup.template < 0 {
+			return item, false
+		}
+		for i, k := range generator.Keys {
+			if strings.EqualFold(k, it.Key) {
+				setup.key = i
+			}
+		}
+		setup.bpm = it.Tempo
+		if setup.bpm <= 0 {
+			setup.bpm = generator.Templates[setup.template].BPM
+		}
+		item.riff = setup
+		return item, true
+	}
+	for _, s := range a.exercises {
+		if sessionKey(s) != it.Song {
+			continue
+		}
+		item.song = s
+		item.speed = 1
+		if it.Tempo > 0 {
+			item.speed = max(MinSpeed, min(1, it.Tempo*s.BeatDuration()/60))
+		}
+		return item, true
+	}
+	return item, false
+}
+
+// SaveQueueAsRoutine keeps the setlist queue as a new routine, at the
+// current practice speed, to be scheduled and timed in the routines file
+func (a *App) SaveQueueAsRoutine() {
+	if len(a.queue) == 0 {
+		return
+	}
+	r := routine.Routine{Name: fmt.Sprintf("Routine %d", len(a.routines)+1)}
+	for _, s := range a.queue {
+		it := routine.Item{Song: sessionKey(s)}
+		if a.speed < 1 {
+			it.Tempo = a.speed * 60 / s.BeatDuration()
+		}
+		r.Items = append(r.Items, it)
+	}
+	routines := append(append([]routine.Routine(nil), a.routines...), r)
+	path, err := a.routinesPath()
+	if err == nil {
+		err = routine.Save(path, routines)
+	}
+	if err != nil {
+		a.warn("could not save practice routine: %v", err)
+		return
+	}
+	logging.Infof("Saved %s to %s", r.Name, path)
+	a.routines = routines
+	a.queue = nil
+}
+
+// layoutTodaysRoutine surfaces the routine scheduled for today on the menu
+func (a *App) layoutTodaysRoutine(gtx layout.Context) layout.Dimensions {
+	r := a.todaysRoutine()
+	if r == nil {
+		return layout.Dimensions{}
+	}
+	steps := "steps"
+	if len(r.Items) == 1 {
+		steps = "step"
+	}
+	text := fmt.Sprintf("Today's routine: %s (%d %s", r.Name, len(r.Items), steps)
+	if m := r.Minutes(); m > 0 {
+		text += fmt.Sprintf(", %.0f min", m)
+	}
+	text += ")  •  T to start"
+	label := material.Body2(a.theme, text)
+	label.Color = render.Colors.Success
+	return layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(10)}.Layout(gtx, label.Layout)
+}
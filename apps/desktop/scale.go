@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// CycleScaleOverlay shows the next scale or arpeggio on the fretboard, or
+// hides the fretboard after the last
+func (a *App) CycleScaleOverlay() {
+	a.scaleOverlay = (a.scaleOverlay + 1) % (len(song.Scales) + 1)
+}
+
+// overlayScale returns the scale shown on the fretboard and its root in
+// the song's key, if one is shown
+func (a *App) overlayScale() (song.Scale, int, bool) {
+	if a.scaleOverlay == 0 {
+		return song.Scale{}, 0, false
+	}
+	sc := song.Scales[a.scaleOverlay-1]
+	key, minor := a.gameState.Song.Key()
+	return sc, sc.RootIn(key, minor), true
+}
+
+// scaleOverlayLabel names the scale shown, for the pre-start screen
+func (a *App) scaleOverlayLabel() string {
+	sc, root, ok := a.overlayScale()
+	if !ok {
+		return "Fretboard: hidden  (C to show scales and arpeggios)"
+	}
+	key, minor := a.gameState.Song.Key()
+	mode := "major"
+	if minor {
+		mode = "minor"
+	}
+	return fmt.Sprintf("Fretboard: %s %s, the song sounds in %s %s  (C to change, also while playing)", song.PitchClassName(root), sc.Name, song.PitchClassName(key), mode)
+}
+
+// layoutScaleOverlay shows the chosen scale's positions on the fretboard
+func (a *App) layoutScaleOverlay(gtx layout.Context) layout.Dimensions {
+	sc, root, ok := a.overlayScale()
+	if !ok {
+		return layout.Dimensions{}
+	}
+	s := a.gameState.Song
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, fmt.Sprintf("%s %s", song.PitchClassName(root), sc.Name))
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return a.tabRenderer.DrawFretboard(gtx, s.GetTuning(), sc, root, s.PitchOffset())
+				})
+			})
+		}),
+	)
+}
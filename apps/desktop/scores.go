@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"guitargame/apps/desktop/internal/scores"
+	"guitargame/core/song"
+)
+
+// openScores loads the profile's personal bests, carrying on without
+// them (rather than overwriting an unreadable file) if they can't be loaded
+func (a *App) openScores() {
+	a.scores = nil
+	dir, err := a.config.Dir()
+	if err == nil {
+		a.scores, err = scores.Open(filepath.Join(dir, scores.FileName))
+	}
+	if err != nil {
+		a.warn("could not load best scores: %v", err)
+	}
+}
+
+// recordScore keeps any personal bests the run just finished set. Like
+// the ghost, only full runs at full speed count, and rhythm-only runs are
+// kept apart.
+func (a *App) recordScore() {
+	gs := a.gameState
+	if a.scores == nil || a.recording == nil || !a.recording.Of(gs) || !gs.IsFinished || gs.Failed || gs.Speed < 1 {
+		return
+	}
+	run := scores.Best{
+		Score:    gs.Score,
+		Grade:    gs.Grade(),
+		Accuracy: gs.Accuracy(),
+		MaxCombo: gs.MaxCombo,
+	}
+	if err := a.scores.Record(scoreKey(gs.Song, gs.RhythmOnly), run); err != nil {
+		a.warn("could not save best scores: %v", err)
+	}
+}
+
+// scoreKey identifies a song's bests, separately for rhythm-only play
+func scoreKey(s *song.Song, rhythmOnly bool) string {
+	if rhythmOnly {
+		return s.ContentHash() + "/rhythm"
+	}
+	return s.ContentHash()
+}
+
+// bestOf returns a song's personal bests in full or rhythm-only play, if
+// it has been played through
+func (a *App) bestOf(s *song.Song, rhythmOnly bool) (scores.Best, bool) {
+	if a.scores == nil {
+		return scores.Best{}, false
+	}
+	return a.scores.Best(scoreKey(s, rhythmOnly))
+}
+
+// bestLabel sums up a song's personal bests
+func bestLabel(b scores.Best) string {
+	return fmt.Sprintf("Best: %d  •  Grade %s  •  %.0f%%  •  Max combo %d", b.Score, b.Grade, b.Accuracy, b.MaxCombo)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"guitargame/apps/desktop/internal/script"
+	"guitargame/core/song"
+)
+
+const scriptUsage = "usage: guitargame script [-p name=value]... [-o dir] <script.star>"
+
+// paramList collects a flag given more than once
+type paramList []string
+
+func (p *paramList) String() string { return strings.Join(*p, " ") }
+
+func (p *paramList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+var chartNamePattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// runScript handles "guitargame script", running an exercise generator
+// with the params given and listing the songs it emits, or writing them
+// out as charts
+func runScript(args []string) int {
+	flags := flag.NewFlagSet("script", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, scriptUsage)
+		flags.PrintDefaults()
+	}
+	var pairs paramList
+	flags.Var(&pairs, "p", "param for the script, as name=value (repeatable)")
+	out := flags.String("o", "", "directory to write the songs to as charts (default only list them)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	params, err := script.ParseParams(pairs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	songs, err := script.Run(flags.Arg(0), params)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, s := range songs {
+		if *out == "" {
+			fmt.Printf("%s: %.0f BPM, %d notes, %.0fs\n", s.Title, s.BPM, len(s.Notes), s.Duration)
+			continue
+		}
+		path := filepath.Join(*out, chartFileName(s.Title))
+		if err := song.SaveSong(s, path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	return 0
+}
+
+// chartFileName names a chart file after its title
+func chartFileName(title string) string {
+	name := strings.Trim(chartNamePattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if name == "" {
+		name = "untitled"
+	}
+	return name + ".yaml"
+}
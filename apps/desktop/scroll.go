@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// scrollSpeeds are the scroll speeds Y steps through
+var scrollSpeeds = []float64{0.5, 0.75, 1, 1.25, 1.5, 2}
+
+// applyScroll scrolls notes at the configured speed
+func (a *App) applyScroll() {
+	a.tabRenderer.ScrollSpeed = float32(a.scrollSpeed())
+	a.tabRenderer.ConstantScroll = a.config.ConstantScroll
+}
+
+// scrollSpeed returns the configured scroll speed, 1 if unset
+func (a *App) scrollSpeed() float64 {
+	if a.config.ScrollSpeed <= 0 {
+		return 1
+	}
+	return a.config.ScrollSpeed
+}
+
+// CycleScrollSpeed moves notes faster, wrapping round to the slowest
+func (a *App) CycleScrollSpeed() {
+	i := slices.IndexFunc(scrollSpeeds, func(s float64) bool { return s > a.scrollSpeed() })
+	if i < 0 {
+		i = 0
+	}
+	a.config.ScrollSpeed = scrollSpeeds[i]
+	a.saveScroll()
+}
+
+// ToggleConstantScroll switches between notes scrolling with the song's
+// tempo and at the same speed for every song
+func (a *App) ToggleConstantScroll() {
+	a.config.ConstantScroll = !a.config.ConstantScroll
+	a.saveScroll()
+}
+
+func (a *App) saveScroll() {
+	a.applyScroll()
+	a.saveSettings()
+}
+
+// scrollLabel describes the scroll settings, for the pre-start screen
+func (a *App) scrollLabel() string {
+	mode := "follows the tempo"
+	if a.config.ConstantScroll {
+		mode = "the same for every tempo"
+	}
+	return fmt.Sprintf("Scroll speed: %gx, %s  (Y to change, U to switch)", a.scrollSpeed(), mode)
+}
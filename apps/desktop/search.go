@@ -0,0 +1,121 @@
+package main
+
+import (
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+)
+
+// menuSearch filters the song list by title and artist, including
+// alternate titles
+type menuSearch struct {
+	editor  widget.Editor
+	active  bool // The search box has keyboard focus
+	filters menuFilters
+}
+
+// visibleExercises returns the indices of the songs that match the search
+// and filters
+func (a *App) visibleExercises() []int {
+	query := a.search.editor.Text()
+	visible := make([]int, 0, len(a.exercises))
+	for i, ex := range a.exercises {
+		if ex.Matches(query) && a.passesFilters(ex) {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+// moveSelection steps through the rows of songs that match the search,
+// skipping group headings and wrapping around
+func (a *App) moveSelection(delta int) {
+	rows := a.songRows()
+	n := len(rows)
+	c := a.songCursor(rows)
+	for range n {
+		c = (c + delta + n) % n
+		if rows[c].index >= 0 {
+			a.setCursor(rows, c)
+			return
+		}
+	}
+}
+
+// keepSelectionVisible moves the selection to the first match when the
+// search hides the selected song
+func (a *App) keepSelectionVisible() {
+	visible := a.visibleExercises()
+	for _, idx := range visible {
+		if idx == a.selectedIndex {
+			return
+		}
+	}
+	if len(visible) > 0 {
+		a.SelectExercise(visible[0])
+	}
+}
+
+// startSearch focuses the search box
+func (a *App) startSearch(gtx layout.Context) {
+	a.search.active = true
+	a.search.editor.SingleLine = true
+	a.search.editor.Submit = true
+	gtx.Execute(key.FocusCmd{Tag: &a.search.editor})
+}
+
+// endSearch hands the keyboard back to the menu, optionally clearing the query
+func (a *App) endSearch(gtx layout.Context, clear bool) {
+	a.search.active = false
+	if clear {
+		a.search.editor.SetText("")
+	}
+	gtx.Execute(key.FocusCmd{Tag: nil})
+}
+
+// handleSearchKey handles menu keys while the search box has focus;
+// typed text goes to the editor itself
+func (a *App) handleSearchKey(gtx layout.Context, e key.Event) {
+	if e.Name == key.NameEscape {
+		a.endSearch(gtx, true)
+	}
+}
+
+func (a *App) layoutSearchBox(gtx layout.Context) layout.Dimensions {
+	for {
+		ev, ok := a.search.editor.Update(gtx)
+		if !ok {
+			break
+		}
+		switch ev.(type) {
+		case widget.ChangeEvent:
+			a.keepSelectionVisible()
+		case widget.SubmitEvent:
+			a.endSearch(gtx, false)
+		}
+	}
+
+	if !a.search.active && a.search.editor.Text() == "" {
+		return layout.Dimensions{}
+	}
+	inset := layout.Inset{Left: unit.Dp(20), Right: unit.Dp(20), Bottom: unit.Dp(10)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Baseline}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Body1(a.theme, "Search: ")
+				label.Color = render.Colors.Hint
+				return label.Layout(gtx)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				ed := material.Editor(a.theme, &a.search.editor, "title or artist, in any script")
+				ed.Color = render.Colors.Input
+				ed.HintColor = render.Colors.InputHint
+				return ed.Layout(gtx)
+			}),
+		)
+	})
+}
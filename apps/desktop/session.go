@@ -0,0 +1,86 @@
+package main
+
+import "guitargame/core/song"
+
+// sessionKey identifies a song between runs: its chart file, or its title
+// for songs built in or from packs
+func sessionKey(s *song.Song) string {
+	if s.Path != "" {
+		return s.Path
+	}
+	return s.Title
+}
+
+// restoreSession selects the song last played, at the speed it was
+// practiced at
+func (a *App) restoreSession() {
+	last := a.config.Last
+	if last.Speed > 0 {
+		a.speed = max(MinSpeed, min(1, last.Speed))
+	}
+	for i, s := range a.exercises {
+		if last.Song != "" && sessionKey(s) == last.Song {
+			a.SelectExercise(i)
+			a.keepSelectionVisible()
+			return
+		}
+	}
+}
+
+// saveSession remembers the song being played and its speed, keeping the
+// looped passage only if it's the same song. Generated riffs can't be
+// played again, so they aren't remembered.
+func (a *App) saveSession(loop *song.Region) {
+	if a.riff != nil {
+		return
+	}
+	last := &a.config.Last
+	key := sessionKey(a.gameState.Song)
+	if key != last.Song {
+		last.LoopFirstBar, last.LoopLastBar = 0, 0
+	}
+	last.Song = key
+	last.Speed = a.speed
+	if loop != nil {
+		last.LoopFirstBar, last.LoopLastBar = loop.FirstBar+1, loop.LastBar+1
+	}
+	a.saveSettings()
+}
+
+// rememberSelection saves the song selected and the speed set even if
+// they weren't played, so the next run starts where this one left off
+func (a *App) rememberSelection() {
+	if a.selectedIndex >= len(a.exercises) {
+		return
+	}
+	last := &a.config.Last
+	key := sessionKey(a.exercises[a.selectedIndex])
+	if key == last.Song && a.speed == last.Speed {
+		return
+	}
+	if key != last.Song {
+		last.LoopFirstBar, last.LoopLastBar = 0, 0
+	}
+	last.Song = key
+	last.Speed = a.speed
+	a.saveSettings()
+}
+
+// lastLoop returns the passage last looped in the selected song
+func (a *App) lastLoop() (song.Region, bool) {
+	last := a.config.Last
+	if last.LoopFirstBar < 1 || last.LoopLastBar < last.LoopFirstBar || last.Song != sessionKey(a.gameState.Song) {
+		return song.Region{}, false
+	}
+	return a.gameState.Song.BarRegion(last.LoopFirstBar-1, last.LoopLastBar-1), true
+}
+
+// ResumeLoop goes back to practicing the passage looped last time
+func (a *App) ResumeLoop() {
+	r, ok := a.lastLoop()
+	if !ok {
+		return
+	}
+	a.pitchDetector.SetRange(a.gameState.Song.FrequencyRange())
+	a.startPractice(r, nil)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/generator"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// setlistBreak is the pause between songs of a setlist
+const setlistBreak = 5 * time.Second
+
+// setlist is a queue of songs being played back to back
+type setlist struct {
+	items     []setlistItem
+	index     int // Item playing, or next up during a break
+	results   []setlistResult
+	nextAt    time.Time // When the next item starts, during a break
+	started   time.Time
+	itemStart time.Time // When the current item was first started
+	again     bool      // The item is played again after the break, as it has time left
+	speed     float64   // Practice speed before the setlist, to go back to
+}
+
+// setlistItem is a song, or a generated riff, to play in a setlist
+type setlistItem struct {
+	song     *song.Song // nil for a riff
+	riff     *riffSetup
+	speed    float64       // Practice speed; 0 keeps the current one
+	duration time.Duration // How long to keep playing it; 0 to play it once
+}
+
+// title names what an item plays
+func (it setlistItem) title() string {
+	if it.song == nil {
+		return fmt.Sprintf("%s riff in %s", generator.Templates[it.riff.template].Name, generator.Keys[it.riff.key])
+	}
+	return it.song.Title
+}
+
+// setlistResult is how one song of a setlist went
+type setlistResult struct {
+	title    string
+	score    int
+	accuracy float64
+	grade    string
+	hit      int
+	total    int
+}
+
+// queuePosition returns where a song is in the setlist queue, from 1, or
+// 0 if it isn't queued
+func (a *App) queuePosition(s *song.Song) int {
+	for i, q := range a.queue {
+		if q == s {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ToggleQueued adds the selected song to the end of the setlist queue, or
+// takes it out if it's already there
+func (a *App) ToggleQueued() {
+	s := a.exercises[a.selectedIndex]
+	if i := a.queuePosition(s); i > 0 {
+		a.queue = append(a.queue[:i-1], a.queue[i:]...)
+		return
+	}
+	a.queue = append(a.queue, s)
+}
+
+// StartSetlist plays the queued songs one after another
+func (a *App) StartSetlist() {
+	items := make([]setlistItem, len(a.queue))
+	for i, s := range a.queue {
+		items[i] = setlistItem{song: s}
+	}
+	a.playSetlist(items)
+}
+
+// playSetlist starts playing items one after another
+func (a *App) playSetlist(items []setlistItem) {
+	if len(items) == 0 {
+		return
+	}
+	a.setlist = &setlist{items: items, started: time.Now(), speed: a.speed}
+	a.playSetlistItem(true)
+}
+
+// playSetlistItem starts the setlist's current item, skipping songs that
+// have since been removed from the song list. first says whether it's
+// being started afresh rather than played again to fill its time.
+func (a *App) playSetlistItem(first bool) {
+	sl := a.setlist
+	if first {
+		sl.itemStart = time.Now()
+	}
+	for ; sl.index < len(sl.items); sl.index++ {
+		it := sl.items[sl.index]
+		if it.speed > 0 {
+			a.speed = it.speed
+		}
+		if it.song == nil {
+			setup := *it.riff
+			a.riffSetup = &setup
+			a.StartRiff()
+			a.StartGame()
+			return
+		}
+		for i, s := range a.exercises {
+			if s == it.song {
+				a.SelectExercise(i)
+				a.StartGame()
+				return
+			}
+		}
+	}
+	a.state = StateSetlistResults
+}
+
+// setlistTimeUp reports whether a generated riff has had its time in the
+// setlist, as riffs never end by themselves
+func (a *App) setlistTimeUp() bool {
+	sl := a.setlist
+	if sl == nil || sl.index >= len(sl.items) || a.riff == nil {
+		return false
+	}
+	d := sl.items[sl.index].duration
+	return d > 0 && time.Since(sl.itemStart) >= d
+}
+
+// endSetlist puts the practice speed back as it was before the setlist
+func (a *App) endSetlist() {
+	if a.setlist != nil {
+		a.speed = a.setlist.speed
+		a.setlist = nil
+	}
+}
+
+// setlistSongDone records the song just finished and takes a break before
+// the next, or shows the combined results after the last
+func (a *App) setlistSongDone() {
+	sl := a.setlist
+	gs := a.gameState
+	grade := gs.Grade()
+	if gs.Failed {
+		grade = "F"
+	}
+	sl.results = append(sl.results, setlistResult{
+		title:    gs.Song.Title,
+		score:    gs.Score,
+		accuracy: gs.Accuracy(),
+		grade:    grade,
+		hit:      gs.NotesHit,
+		total:    gs.TotalNotes,
+	})
+	sl.again = a.riff == nil && time.Since(sl.itemStart) < sl.items[sl.index].duration
+	if !sl.again {
+		sl.index++
+	}
+	if sl.index >= len(sl.items) {
+		a.state = StateSetlistResults
+		return
+	}
+	sl.nextAt = time.Now().Add(setlistBreak)
+	a.state = StateSetlistBreak
+}
+
+// updateSetlistBreak starts the next song once the break is over
+func (a *App) updateSetlistBreak() {
+	if time.Now().After(a.setlist.nextAt) {
+		a.playSetlistItem(!a.setlist.again)
+	}
+}
+
+func (a *App) handleSetlistKey(e key.Event) {
+	switch e.Name {
+	case key.NameSpace, key.NameReturn, key.NameEnter:
+		if a.state == StateSetlistBreak {
+			a.playSetlistItem(!a.setlist.again)
+		} else {
+			a.GoToMenu()
+		}
+	case key.NameEscape:
+		a.GoToMenu()
+	}
+}
+
+func (a *App) layoutSetlistBreakScreen(gtx layout.Context) layout.Dimensions {
+	sl := a.setlist
+	last := sl.results[len(sl.results)-1]
+	wait := max(0, time.Until(sl.nextAt).Seconds())
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H6(a.theme, fmt.Sprintf("%s: %s  •  %d points  •  %.0f%%", last.title, last.grade, last.score, last.accuracy))
+			label.Color = getGradeColor(last.grade)
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			next := sl.items[sl.index]
+			text := fmt.Sprintf("Next (%d of %d): %s", sl.index+1, len(sl.items), next.title())
+			if sl.again {
+				left := next.duration - time.Since(sl.itemStart)
+				text = fmt.Sprintf("Again: %s  (%s left)", next.title(), formatDuration(left))
+			}
+			label := material.H4(a.theme, text)
+			label.Color = render.Colors.Info
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H5(a.theme, fmt.Sprintf("Starting in %.0f", wait))
+			label.Color = render.Colors.Title
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, "Space to start now  •  Esc to stop the setlist")
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+}
+
+func (a *App) layoutSetlistResultsScreen(gtx layout.Context) layout.Dimensions {
+	sl := a.setlist
+	score, hit, total := 0, 0, 0
+	for _, r := range sl.results {
+		score += r.score
+		hit += r.hit
+		total += r.total
+	}
+	accuracy := 0.0
+	if total > 0 {
+		accuracy = float64(hit) / float64(total) * 100
+	}
+
+	children := []layout.FlexChild{
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H4(a.theme, "Setlist Complete!")
+			label.Color = render.Colors.Title
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := fmt.Sprintf("%d songs  •  %d points  •  %.0f%% of notes hit  •  %s", len(sl.results), score, accuracy, formatDuration(time.Since(sl.started)))
+			label := material.H6(a.theme, text)
+			label.Color = render.Colors.Highlight
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+	}
+	for _, r := range sl.results {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, fmt.Sprintf("%s  %s  •  %d points  •  %.0f%%", r.grade, r.title, r.score, r.accuracy))
+			label.Color = getGradeColor(r.grade)
+			return layout.Center.Layout(gtx, label.Layout)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, "Press Enter to return to menu")
+			label.Color = render.Colors.Success
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// layoutSetlistQueue lists the songs queued for a setlist on the menu
+func (a *App) layoutSetlistQueue(gtx layout.Context) layout.Dimensions {
+	if len(a.queue) == 0 {
+		return layout.Dimensions{}
+	}
+	titles := make([]string, len(a.queue))
+	for i, s := range a.queue {
+		titles[i] = s.Title
+	}
+	text := fmt.Sprintf("Setlist: %s  •  L to play it  •  B to save it as a routine", strings.Join(titles, ", "))
+	label := material.Body2(a.theme, text)
+	label.Color = render.Colors.Info
+	return layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(10)}.Layout(gtx, label.Layout)
+}
+
+// formatDuration writes a length of time as minutes and seconds
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
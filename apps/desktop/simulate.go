@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/config"
+	"guitargame/apps/desktop/internal/export"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+const simulateUsage = "usage: guitargame simulate -song chart.yaml -input take.wav [-latency ms] [-timing preset] [-format text|csv|json]"
+
+// simulateFPS is how often the game reads the input while playing, which
+// a simulated run steps at so a take is judged as it would be live
+const simulateFPS = 60
+
+// runSimulate handles "guitargame simulate", scoring a recording of a
+// song as if it had been played into the game, with the player's scoring
+// settings unless flags say otherwise
+func runSimulate(args []string) int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load settings: %v\n", err)
+	}
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, simulateUsage)
+		flags.PrintDefaults()
+	}
+	chart := flags.String("song", "", "chart the take is of")
+	take := flags.String("input", "", "WAV recording of the take, starting when the song does")
+	latency := flags.Float64("latency", cfg.LatencyMs, "input latency to judge hits with, in milliseconds")
+	timing := flags.String("timing", cfg.Timing, "timing windows: easy, normal, hard, or custom as set in the config")
+	format := flags.String("format", "text", "text for a summary, or csv or json for how each note was played")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *chart == "" || *take == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	if *format != "text" && *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return 2
+	}
+	cfg.LatencyMs, cfg.Timing = *latency, *timing
+
+	s, err := song.LoadSong(*chart)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	f, err := os.Open(*take)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sample, err := audio.DecodeWAV(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *take, err)
+		return 1
+	}
+
+	gs, rec := simulateTake(s, sample, cfg)
+	switch *format {
+	case "csv":
+		err = export.ResultsCSV(os.Stdout, rec.Results())
+	case "json":
+		err = export.ResultsJSON(os.Stdout, rec.Results())
+	default:
+		printSimulation(gs)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// simulateTake plays a recorded take through pitch detection and hit
+// judging as the game would have heard it live, a frame at a time, and
+// returns the finished run
+func simulateTake(s *song.Song, take *audio.Sample, cfg *config.Config) (*song.GameState, *game.Recording) {
+	gs := song.NewGameState(s)
+	applyScoring(gs, cfg)
+	detector := audio.NewPitchDetector(audio.DefaultBufferSize, take.SampleRate)
+	defer detector.Close()
+	detector.SetRange(s.FrequencyRange())
+	hits := game.NewHitDetector(gs, func(int) float32 { return 0 })
+	rec := game.NewRecording(gs)
+
+	// Each frame hears the latest buffer's worth of the take, and
+	// silence once it has run out
+	window := make([]float32, audio.DefaultBufferSize)
+	gs.Start()
+	for frame := 0; gs.IsPlaying; frame++ {
+		t := float64(frame) / simulateFPS
+		gs.AdvanceTo(t)
+		end := int(t * take.SampleRate)
+		for i := range window {
+			window[i] = 0
+			if j := end - len(window) + i; j >= 0 && j < len(take.Data) {
+				window[i] = take.Data[j]
+			}
+		}
+		heard := detector.Detect(window)
+		hits.CheckHit(heard, 0)
+		hits.Update()
+		rec.Capture(heard)
+	}
+	return gs, rec
+}
+
+// printSimulation writes a summary of a simulated run like the results
+// screen's
+func printSimulation(gs *song.GameState) {
+	s := gs.Song
+	title := s.Title
+	if s.Artist != "" {
+		title += " - " + s.Artist
+	}
+	fmt.Println(title)
+	fmt.Printf("Score: %d  Accuracy: %.1f%%  Grade: %s  Max combo: %d\n", gs.Score, gs.Accuracy(), gs.Grade(), gs.MaxCombo)
+
+	counts := gs.QualityCounts()
+	var parts []string
+	for _, q := range resultsQualities {
+		parts = append(parts, fmt.Sprintf("%s %d", strings.TrimSuffix(q.String(), "!"), counts[q]))
+	}
+	fmt.Println(strings.Join(parts, "  "))
+
+	if hits := gs.HitTimings(); len(hits) >= 2 {
+		mean, stddev := song.TimingSpread(hits)
+		fmt.Printf("Timing: %s, spread ±%.0f ms (%s windows)\n", offsetLabel(mean), stddev*1000, timingLabel(gs.Windows))
+	}
+	if missed := gs.MissedRegions(); len(missed) > 0 {
+		fmt.Println("Missed notes in:")
+		for _, r := range missed {
+			fmt.Printf("  %v (%d missed)\n", r, r.Missed)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+)
+
+// Song list row geometry in dp: every row is the same height, so a page
+// of rows is known before any of them are laid out
+const (
+	songRowHeight = 50
+	songRowGap    = 8
+)
+
+// songList scrolls through the songs, laying out only the rows in view
+type songList struct {
+	list   widget.List
+	cursor int  // Row the keyboard is on, or -1 for the selected song's first row
+	page   int  // Rows that fit in the list as last laid out
+	reveal bool // Scroll the cursor into view on the next frame
+}
+
+// songGroup is a heading the song list is divided under
+type songGroup int
+
+const (
+	groupFavorites songGroup = iota
+	groupRecent
+	groupAll
+)
+
+// songGroupNames are the groups' headings, and songGroupKeys how they're
+// named in the config when collapsed
+var (
+	songGroupNames = [...]string{groupFavorites: "Favorites", groupRecent: "Recently played", groupAll: "All songs"}
+	songGroupKeys  = [...]string{groupFavorites: "favorites", groupRecent: "recent", groupAll: "all"}
+)
+
+// songRow is a row of the song list: a song, or a group's heading when
+// index is -1
+type songRow struct {
+	group songGroup
+	index int // Into a.exercises
+	count int // Songs in the group, for headings
+}
+
+// songRows lists the songs that match the search and filters, under
+// headings for favorites and those recently played when there are any
+func (a *App) songRows() []songRow {
+	visible := a.visibleExercises()
+	var favorites, recent []int
+	for _, i := range visible {
+		if a.favorites[sessionKey(a.exercises[i])] {
+			favorites = append(favorites, i)
+		}
+	}
+	for _, key := range a.recent {
+		for _, i := range visible {
+			if sessionKey(a.exercises[i]) == key {
+				recent = append(recent, i)
+				break
+			}
+		}
+	}
+
+	var rows []songRow
+	if len(favorites) == 0 && len(recent) == 0 {
+		for _, i := range visible {
+			rows = append(rows, songRow{group: groupAll, index: i})
+		}
+		return rows
+	}
+	add := func(g songGroup, songs []int) {
+		if len(songs) == 0 {
+			return
+		}
+		rows = append(rows, songRow{group: g, index: -1, count: len(songs)})
+		if a.groupCollapsed(g) {
+			return
+		}
+		for _, i := range songs {
+			rows = append(rows, songRow{group: g, index: i})
+		}
+	}
+	add(groupFavorites, favorites)
+	add(groupRecent, recent)
+	add(groupAll, visible)
+	return rows
+}
+
+// songCursor returns the row the keyboard is on, following the selection
+// when it was changed some other way
+func (a *App) songCursor(rows []songRow) int {
+	c := a.songList.cursor
+	if c >= 0 && c < len(rows) && (rows[c].index < 0 || rows[c].index == a.selectedIndex) {
+		return c
+	}
+	for i, r := range rows {
+		if r.index == a.selectedIndex {
+			return i
+		}
+	}
+	return 0
+}
+
+// setCursor moves the keyboard to a row, selecting its song
+func (a *App) setCursor(rows []songRow, c int) {
+	if rows[c].index >= 0 {
+		a.SelectExercise(rows[c].index)
+	}
+	a.songList.cursor = c
+	a.songList.reveal = true
+}
+
+// moveCursor steps through the rows, headings included, wrapping around
+func (a *App) moveCursor(delta int) {
+	rows := a.songRows()
+	if len(rows) == 0 {
+		return
+	}
+	n := len(rows)
+	a.setCursor(rows, (a.songCursor(rows)+delta+n)%n)
+}
+
+// pageSelection moves the cursor a page of rows up or down, stopping at
+// the ends
+func (a *App) pageSelection(pages int) {
+	a.jumpSelection(func(pos, last int) int {
+		return max(0, min(last, pos+pages*a.songList.page))
+	})
+}
+
+// jumpSelection moves the cursor to the row pick returns, given the
+// cursor's row and the last row
+func (a *App) jumpSelection(pick func(pos, last int) int) {
+	rows := a.songRows()
+	if len(rows) == 0 {
+		return
+	}
+	a.setCursor(rows, pick(a.songCursor(rows), len(rows)-1))
+}
+
+// chooseRow starts the song the cursor is on, or opens or closes the
+// group if it's on a heading
+func (a *App) chooseRow() {
+	rows := a.songRows()
+	if len(rows) == 0 {
+		return
+	}
+	row := rows[a.songCursor(rows)]
+	if row.index < 0 {
+		a.ToggleGroup(row.group)
+		return
+	}
+	a.state = StatePreStart
+}
+
+// groupCollapsed reports whether a group's songs are hidden under its heading
+func (a *App) groupCollapsed(g songGroup) bool {
+	return slices.Contains(a.config.CollapsedGroups, songGroupKeys[g])
+}
+
+// ToggleGroup hides or shows a group's songs
+func (a *App) ToggleGroup(g songGroup) {
+	key := songGroupKeys[g]
+	if i := slices.Index(a.config.CollapsedGroups, key); i >= 0 {
+		a.config.CollapsedGroups = slices.Delete(a.config.CollapsedGroups, i, i+1)
+	} else {
+		a.config.CollapsedGroups = append(a.config.CollapsedGroups, key)
+	}
+	a.saveSettings()
+}
+
+// layoutExerciseList shows the song rows, scrolled to keep the cursor in view
+func (a *App) layoutExerciseList(gtx layout.Context) layout.Dimensions {
+	rows := a.songRows()
+	cursor := a.songCursor(rows)
+	l := &a.songList
+	l.list.Axis = layout.Vertical
+	l.page = max(1, gtx.Constraints.Max.Y/gtx.Dp(songRowHeight+songRowGap))
+	if l.reveal {
+		l.reveal = false
+		first := l.list.Position.First
+		if cursor < first {
+			l.list.Position = layout.Position{First: cursor}
+		} else if cursor >= first+l.page {
+			l.list.Position = layout.Position{First: cursor - l.page + 1}
+		}
+	}
+
+	inset := layout.Inset{Left: unit.Dp(20), Right: unit.Dp(20)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return material.List(a.theme, &l.list).Layout(gtx, len(rows), func(gtx layout.Context, i int) layout.Dimensions {
+			row := rows[i]
+			if row.index < 0 {
+				return a.layoutGroupHeading(gtx, row, i == cursor)
+			}
+			return a.layoutExerciseItem(gtx, a.exercises[row.index], i == cursor)
+		})
+	})
+}
+
+// layoutGroupHeading shows a group's name and size, and whether it's open
+func (a *App) layoutGroupHeading(gtx layout.Context, row songRow, selected bool) layout.Dimensions {
+	return layout.Inset{Bottom: unit.Dp(songRowGap)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min.Y = gtx.Dp(songRowHeight)
+		gtx.Constraints.Max.Y = gtx.Constraints.Min.Y
+		arrow, action := "▼", "hide"
+		if a.groupCollapsed(row.group) {
+			arrow, action = "▶", "show"
+		}
+		text := fmt.Sprintf("%s %s (%d)", arrow, songGroupNames[row.group], row.count)
+		if selected {
+			text += "  •  Enter to " + action
+		}
+		label := material.H6(a.theme, text)
+		label.Color = render.Colors.Hint
+		if selected {
+			label.Color = render.Colors.Accent
+		}
+		return layout.W.Layout(gtx, label.Layout)
+	})
+}
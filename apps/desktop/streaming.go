@@ -0,0 +1,202 @@
+package main
+
+import (
+	"image/color"
+	"slices"
+	"sync"
+
+	"gioui.org/app"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+
+	"guitargame/apps/desktop/internal/config"
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// defaultChromaKey is the background keyed out when streaming, unless the
+// config sets another: the usual chroma-key green
+const defaultChromaKey = "#00b140"
+
+// streamingTextScale enlarges text when streaming, so it stays readable
+// in a shrunk-down stream
+const streamingTextScale = 1.35
+
+// ToggleStreaming switches streaming mode on or off, and starts the same
+// way next time
+func (a *App) ToggleStreaming() {
+	a.streaming = !a.streaming
+	a.config.Streaming = a.streaming
+	a.applyStreaming()
+	a.saveSettings()
+	if a.streaming {
+		a.notify(toastInfo, "streaming mode on: key out %s", a.chromaKeyName())
+	} else {
+		a.notify(toastInfo, "streaming mode off")
+	}
+}
+
+// ToggleCaptureWindow shows or hides the highway in a window of its own,
+// turning streaming mode on with it
+func (a *App) ToggleCaptureWindow() {
+	a.config.CaptureWindow = !a.config.CaptureWindow
+	if a.config.CaptureWindow && !a.streaming {
+		a.ToggleStreaming()
+		return
+	}
+	a.applyStreaming()
+	a.saveSettings()
+}
+
+// applyStreaming lays the game out for streaming or not: the chroma-key
+// background, no debugging readouts, and the capture window if wanted.
+// Text is enlarged by scaleMetric.
+func (a *App) applyStreaming() {
+	a.applyTheme()
+	a.tabRenderer.HideDebug = a.streaming
+	if a.streaming && a.debugConsole {
+		a.ToggleDebugConsole()
+	}
+	if a.streaming && a.config.CaptureWindow {
+		a.openCaptureWindow()
+	} else {
+		a.closeCaptureWindow()
+	}
+}
+
+// chromaKey returns the background to key out when streaming
+func (a *App) chromaKey() color.NRGBA {
+	c, err := render.ParseColor(a.chromaKeyName())
+	if err != nil {
+		a.warn("chroma key %q: %v", a.config.ChromaKey, err)
+		c, _ = render.ParseColor(defaultChromaKey)
+	}
+	return c
+}
+
+// chromaKeyName returns the chroma key as the config writes it
+func (a *App) chromaKeyName() string {
+	if a.config.ChromaKey == "" {
+		return defaultChromaKey
+	}
+	return a.config.ChromaKey
+}
+
+// captureWindow shows the score and highway alone on the chroma key, for
+// streaming software to capture while the game is played from the main
+// window. It draws copies of the game's state, handed over each frame,
+// on its own goroutine.
+type captureWindow struct {
+	window   *app.Window
+	renderer *render.TabRenderer
+	highway  render.Highway
+
+	mu     sync.Mutex
+	state  *song.GameState // Nil between songs
+	closed bool
+}
+
+// openCaptureWindow opens the capture window if it isn't open
+func (a *App) openCaptureWindow() {
+	if a.capture != nil && !a.capture.isClosed() {
+		return
+	}
+	_, renderer := a.windowRenderer()
+	var highway render.Highway = renderer
+	if a.config.Highway == config.HighwayFretboard {
+		highway = render.NewFretboardHighway(renderer)
+	}
+	a.capture = &captureWindow{window: new(app.Window), renderer: renderer, highway: highway}
+	go a.capture.run()
+	logging.Infof("Opened the capture window")
+}
+
+// closeCaptureWindow closes the capture window if it's open
+func (a *App) closeCaptureWindow() {
+	if a.capture != nil && !a.capture.isClosed() {
+		a.capture.window.Perform(system.ActionClose)
+	}
+	a.capture = nil
+}
+
+// updateCaptureWindow hands the capture window the latest state of play
+func (a *App) updateCaptureWindow() {
+	if a.capture == nil || a.capture.isClosed() {
+		return
+	}
+	var state *song.GameState
+	if a.state == StatePlaying {
+		state = snapshotGame(a.gameState)
+	}
+	a.capture.mu.Lock()
+	a.capture.state = state
+	a.capture.mu.Unlock()
+	a.capture.window.Invalidate()
+}
+
+// snapshotGame copies as much of a game's state as is drawn, so it can be
+// drawn while the game plays on
+func snapshotGame(gs *song.GameState) *song.GameState {
+	s := *gs.Song
+	s.Notes = slices.Clone(gs.Song.Notes)
+	snap := *gs
+	snap.Song = &s
+	snap.FloatingText = slices.Clone(gs.FloatingText)
+	return &snap
+}
+
+func (c *captureWindow) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// run handles the capture window's events until it's closed
+func (c *captureWindow) run() {
+	c.window.Option(
+		app.Title("Bass Guitar Practice - Capture"),
+		app.Size(unit.Dp(screenWidth), unit.Dp(screenHeight*2/3)),
+	)
+
+	var ops op.Ops
+	for {
+		switch e := c.window.Event().(type) {
+		case app.DestroyEvent:
+			if e.Err != nil {
+				logging.Warnf("capture window: %v", e.Err)
+			}
+			c.mu.Lock()
+			c.closed = true
+			c.mu.Unlock()
+			return
+
+		case app.FrameEvent:
+			gtx := app.NewContext(&ops, e)
+			c.layout(gtx)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (c *captureWindow) layout(gtx layout.Context) layout.Dimensions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paint.ColorOp{Color: render.Colors.Background}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	if c.state == nil {
+		return layout.Dimensions{Size: gtx.Constraints.Max}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.renderer.DrawHeader(gtx, c.state)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return c.highway.Layout(gtx, c.state)
+		}),
+	)
+}
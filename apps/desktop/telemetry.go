@@ -0,0 +1,55 @@
+package main
+
+import (
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/config"
+	"guitargame/apps/desktop/internal/telemetry"
+)
+
+// startTelemetry starts counting usage if the player has opted in and
+// an endpoint is set
+func (a *App) startTelemetry() {
+	if !a.config.Telemetry || a.config.TelemetryEndpoint == "" || a.telemetry != nil {
+		return
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		a.warn("could not start usage stats: %v", err)
+		return
+	}
+	a.telemetry, err = telemetry.Start(dir, a.config.TelemetryEndpoint, audio.DetectorAlgorithm)
+	if err != nil {
+		a.warn("could not start usage stats: %v", err)
+	}
+}
+
+// endTelemetry records that the run ended cleanly
+func (a *App) endTelemetry() {
+	if a.telemetry != nil {
+		a.telemetry.End()
+		a.telemetry = nil
+	}
+}
+
+// ToggleTelemetry opts in to or out of sending anonymous usage stats
+func (a *App) ToggleTelemetry() {
+	a.config.Telemetry = !a.config.Telemetry
+	a.saveSettings()
+	if a.config.Telemetry {
+		a.startTelemetry()
+	} else {
+		a.endTelemetry()
+	}
+}
+
+// telemetryLabel says whether usage stats are shared, for the menu
+func (a *App) telemetryLabel() string {
+	switch {
+	case !a.config.Telemetry:
+		return "U share usage stats: off"
+	case a.config.TelemetryEndpoint == "":
+		return "U share usage stats: on (no endpoint set)"
+	default:
+		return "U share usage stats: on"
+	}
+}
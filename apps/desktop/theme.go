@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"guitargame/apps/desktop/internal/config"
+	"guitargame/apps/desktop/internal/render"
+)
+
+// applyTheme draws everything with the configured theme, falling back to
+// the dark theme if it can't be found
+func (a *App) applyTheme() {
+	t, err := themeFor(a.config)
+	if err != nil {
+		a.warn("theme %q: %v", a.config.Theme, err)
+	}
+	if a.streaming {
+		t.Background = a.chromaKey()
+	}
+	render.Colors = t
+	t.ApplyMaterial(a.theme)
+}
+
+// CycleTheme switches to the next built-in or custom theme
+func (a *App) CycleTheme() {
+	names := themeNames(a.config)
+	i := slices.Index(names, a.themeName())
+	a.config.Theme = names[(i+1)%len(names)]
+	a.applyTheme()
+	a.saveSettings()
+}
+
+// themeName returns the name of the theme in use
+func (a *App) themeName() string {
+	if a.config.Theme == "" {
+		return render.ThemeDark
+	}
+	return a.config.Theme
+}
+
+// themeFor builds the theme a config names. A custom theme's colors that
+// can't be read are left as its base theme has them.
+func themeFor(c *config.Config) (render.Theme, error) {
+	if t, ok := render.BuiltinTheme(c.Theme); ok {
+		return t, nil
+	}
+	custom, ok := c.Themes[c.Theme]
+	if !ok {
+		return render.DarkTheme, errors.New("no such theme")
+	}
+	t, ok := render.BuiltinTheme(custom.Base)
+	if !ok {
+		return render.DarkTheme, fmt.Errorf("no built-in theme %q to start from", custom.Base)
+	}
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(custom.Colors)) {
+		if err := t.Set(name, custom.Colors[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return t, errors.Join(errs...)
+}
+
+// themeNames lists the built-in themes followed by the player's own
+func themeNames(c *config.Config) []string {
+	names := render.BuiltinThemes()
+	for _, name := range slices.Sorted(maps.Keys(c.Themes)) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
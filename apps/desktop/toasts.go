@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/logging"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/song"
+)
+
+// How long notifications stay up, and how many are shown at once
+const (
+	toastTime = 6 * time.Second
+	toastFade = 500 * time.Millisecond
+	maxToasts = 4
+)
+
+// toastLevel is how serious a notification is
+type toastLevel int
+
+const (
+	toastInfo toastLevel = iota
+	toastWarning
+	toastError
+)
+
+// toast is a notification shown in the corner of the window
+type toast struct {
+	text  string
+	level toastLevel
+	shown time.Time // Zero until first drawn, so ones raised at startup aren't missed
+}
+
+// notify logs a message and shows it in the window for a few seconds, so
+// problems are seen without a terminal. A message already showing is
+// shown afresh rather than repeated.
+func (a *App) notify(level toastLevel, format string, args ...any) {
+	text := fmt.Sprintf(format, args...)
+	switch level {
+	case toastWarning:
+		logging.Warnf("%s", text)
+	case toastError:
+		logging.Errorf("%s", text)
+	default:
+		logging.Infof("%s", text)
+	}
+	for i, t := range a.toasts {
+		if t.text == text {
+			a.toasts = append(a.toasts[:i], a.toasts[i+1:]...)
+			break
+		}
+	}
+	a.toasts = append(a.toasts, toast{text: text, level: level})
+	if len(a.toasts) > maxToasts {
+		a.toasts = a.toasts[len(a.toasts)-maxToasts:]
+	}
+}
+
+// warn notifies the player of a problem the game carries on through
+func (a *App) warn(format string, args ...any) {
+	a.notify(toastWarning, format, args...)
+}
+
+// saveSettings saves the config, warning if it can't be
+func (a *App) saveSettings() {
+	if err := a.config.Save(); err != nil {
+		a.warn("could not save settings: %v", err)
+	}
+}
+
+// reportSkippedCharts says which charts couldn't be loaded; the log has
+// the details of each
+func (a *App) reportSkippedCharts(skipped []song.SkippedChart) {
+	for _, c := range skipped {
+		logging.Warnf("skipped chart %v", c)
+	}
+	switch len(skipped) {
+	case 0:
+	case 1:
+		a.warn("could not load %v", skipped[0])
+	default:
+		a.warn("could not load %d charts, including %s; see the log for why", len(skipped), skipped[0].Path)
+	}
+}
+
+// layoutToasts draws the notifications over the bottom right of the
+// screen, newest at the bottom, fading each out when its time is up
+func (a *App) layoutToasts(gtx layout.Context) {
+	now := time.Now()
+	live := a.toasts[:0]
+	for _, t := range a.toasts {
+		if t.shown.IsZero() {
+			t.shown = now
+		}
+		if now.Sub(t.shown) < toastTime {
+			live = append(live, t)
+		}
+	}
+	a.toasts = live
+	if len(live) == 0 {
+		return
+	}
+
+	var children []layout.FlexChild
+	for _, t := range live {
+		fade := min(1, float32(toastTime-now.Sub(t.shown))/float32(toastFade))
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return a.layoutToast(gtx, t, fade)
+			})
+		}))
+	}
+	gtx.Constraints.Min = image.Point{}
+	gtx.Constraints.Max.X = min(gtx.Constraints.Max.X, gtx.Dp(480))
+	layout.SE.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical, Alignment: layout.End}.Layout(gtx, children...)
+		})
+	})
+}
+
+// layoutToast draws one notification on a panel edged in its level's color
+func (a *App) layoutToast(gtx layout.Context, t toast, fade float32) layout.Dimensions {
+	edge := render.Colors.Info
+	switch t.level {
+	case toastWarning:
+		edge = render.Colors.Warning
+	case toastError:
+		edge = render.Colors.Error
+	}
+	alpha := func(c color.NRGBA) color.NRGBA {
+		c.A = uint8(float32(c.A) * fade)
+		return c
+	}
+
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			size := gtx.Constraints.Min
+			r := gtx.Dp(6)
+			paint.FillShape(gtx.Ops, alpha(render.Colors.ListItemSelected), clip.UniformRRect(image.Rectangle{Max: size}, r).Op(gtx.Ops))
+			paint.FillShape(gtx.Ops, alpha(edge), clip.Rect{Max: image.Pt(gtx.Dp(4), size.Y)}.Op())
+			return layout.Dimensions{Size: size}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Left: unit.Dp(14), Right: unit.Dp(12), Top: unit.Dp(8), Bottom: unit.Dp(8)}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(a.theme, sentenceCase(t.text))
+				label.Color = alpha(render.Colors.Title)
+				return label.Layout(gtx)
+			})
+		}),
+	)
+}
+
+// sentenceCase capitalizes a message written to run on from the level in
+// the log, to stand on its own
+func sentenceCase(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
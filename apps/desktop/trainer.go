@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+// MaxTrainerSpeed is the fastest the speed trainer goes, relative to the
+// chart's tempo
+const MaxTrainerSpeed = 1.5
+
+// trainer loops a passage, speeding up each time it's played cleanly
+type trainer struct {
+	region song.Region
+	laps   []render.TempoLap
+}
+
+// StartTrainer loops the passage last practiced, or the whole song,
+// starting at the practice speed
+func (a *App) StartTrainer() {
+	s := a.gameState.Song
+	if len(s.Notes) == 0 {
+		return
+	}
+	r, ok := a.lastLoop()
+	if !ok {
+		lastBar := int(s.NoteBeat(s.Notes[len(s.Notes)-1].Time)) / song.BeatsPerBar
+		r = s.BarRegion(0, lastBar)
+	}
+	a.trainer = &trainer{region: r}
+
+	a.pitchDetector.SetRange(s.FrequencyRange())
+	gs := song.NewGameState(s)
+	gs.Speed = a.speed
+	a.applyPlaySettings(gs)
+	a.gameState = gs
+	a.hitDetector = game.NewHitDetector(gs, a.feedbackY)
+	gs.PlayLoop(r)
+	a.state = StatePlaying
+}
+
+// updateTrainer scores each finished time round the loop, raising the
+// tempo when it reached the target accuracy
+func (a *App) updateTrainer(playLineX float32) {
+	gs := a.gameState
+	t := a.trainer
+	for len(t.laps) < len(gs.LapScores) {
+		accuracy := gs.LapScores[len(t.laps)]
+		lap := render.TempoLap{
+			BPM:      gs.Song.BPM * gs.Speed,
+			Accuracy: accuracy,
+			Passed:   accuracy >= a.config.TrainerTarget,
+		}
+		t.laps = append(t.laps, lap)
+		if !lap.Passed || gs.Speed >= MaxTrainerSpeed {
+			continue
+		}
+
+		gs.Speed = min(MaxTrainerSpeed, gs.Speed+a.config.TrainerStepBPM/gs.Song.BPM)
+		gs.FloatingText = append(gs.FloatingText, song.FloatingScore{
+			Text:      fmt.Sprintf("Tempo up! %.0f BPM", gs.Song.BPM*gs.Speed),
+			X:         playLineX,
+			Y:         a.feedbackY(0),
+			StartTime: time.Now(),
+			Quality:   song.HitPerfect,
+		})
+	}
+}
+
+// EndTrainer stops looping and shows how the tempo went up
+func (a *App) EndTrainer() {
+	a.state = StateTrainerResults
+}
+
+func (a *App) handleTrainerResultsKey(e key.Event) {
+	switch e.Name {
+	case key.NameEscape, key.NameReturn, key.NameEnter:
+		a.GoToMenu()
+	}
+}
+
+// layoutTrainerStatus shows the trainer's tempo and how the last lap went
+func (a *App) layoutTrainerStatus(gtx layout.Context) layout.Dimensions {
+	gs := a.gameState
+	text := fmt.Sprintf("Speed trainer: %s at %.0f BPM  •  %.0f%% accuracy clears it (+%.0f BPM)  •  Esc to finish",
+		a.trainer.region, gs.Song.BPM*gs.Speed, a.config.TrainerTarget, a.config.TrainerStepBPM)
+	if n := len(a.trainer.laps); n > 0 {
+		text += fmt.Sprintf("  •  last time %.0f%%", a.trainer.laps[n-1].Accuracy)
+	}
+	label := material.Body2(a.theme, text)
+	label.Color = render.Colors.Hint
+	return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, label.Layout)
+}
+
+func (a *App) layoutTrainerResultsScreen(gtx layout.Context) layout.Dimensions {
+	laps := a.trainer.laps
+	summary := "No laps finished"
+	if len(laps) > 0 {
+		best := 0.0
+		for _, l := range laps {
+			if l.Passed {
+				best = max(best, l.BPM)
+			}
+		}
+		summary = fmt.Sprintf("%d laps  •  started at %.0f BPM", len(laps), laps[0].BPM)
+		if best > 0 {
+			summary += fmt.Sprintf("  •  cleared up to %.0f BPM", best)
+		}
+	}
+
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H4(a.theme, "Speed Trainer")
+			label.Color = render.Colors.Title
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(a.theme, fmt.Sprintf("%s  •  %s", a.trainer.region, summary))
+			label.Color = render.Colors.Text
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(30)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Left: unit.Dp(40), Right: unit.Dp(40)}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return a.tabRenderer.DrawTempoGraph(gtx, laps)
+			})
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(a.theme, "Tempo of each time round; ticked laps cleared the target  •  Enter to return to menu")
+			label.Color = render.Colors.Hint
+			return layout.Center.Layout(gtx, label.Layout)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+}
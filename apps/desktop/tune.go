@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"guitargame/apps/desktop/internal/audio"
+	"guitargame/apps/desktop/internal/config"
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+const tuneUsage = "usage: guitargame tune [-device name] [-sample-rate hz] [-instrument bass|guitar] [-tuning name]"
+
+// Tuner readout: how often it's updated, and how many characters either
+// side of the centre of its meter
+const (
+	tuneInterval   = 50 * time.Millisecond
+	tuneMeterWidth = 12
+)
+
+// runTune handles "guitargame tune", printing the note heard and how far
+// off it is until interrupted. On a terminal the readout is redrawn in
+// place; otherwise each change is a new line.
+func runTune(args []string) int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load settings: %v\n", err)
+	}
+	flags := flag.NewFlagSet("tune", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, tuneUsage)
+		flags.PrintDefaults()
+	}
+	device := flags.String("device", cfg.Device, "audio input device: a name, part of one, or its number in the device list")
+	sampleRate := flags.Float64("sample-rate", cfg.SampleRate, "audio sample rate in Hz (default 48000)")
+	instrument := flags.String("instrument", "bass", "instrument whose strings to name: bass or guitar")
+	tuning := flags.String("tuning", "", "tuning to name strings in: a name such as drop-d, or notes with octaves from the highest string such as G2,D2,A1,E1")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	if *sampleRate <= 0 {
+		*sampleRate = audio.DefaultSampleRate
+	}
+	target := &song.Song{InstrumentName: *instrument, TuningStr: *tuning}
+	if err := target.CheckTuning(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	input, err := openInput(launchSettings{Device: *device, SampleRate: *sampleRate})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer input.Close()
+	defer input.Stop()
+
+	detector := audio.NewPitchDetector(audio.DefaultBufferSize, *sampleRate)
+	defer detector.Close()
+	detector.SetRange(target.FrequencyRange())
+
+	fmt.Printf("Listening on %s; Ctrl+C to stop\n", input.DeviceName())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(tuneInterval)
+	defer ticker.Stop()
+
+	live := isTerminal(os.Stdout)
+	last := ""
+	for {
+		select {
+		case <-ctx.Done():
+			if live {
+				fmt.Println()
+			}
+			return 0
+		case <-ticker.C:
+		}
+		line := tuneReadout(detector.Detect(input.GetBuffer()), target.GetTuning())
+		switch {
+		case live:
+			// Pad over whatever was longer last time
+			fmt.Printf("\r%-*s", len(last), line)
+		case line != last:
+			fmt.Println(line)
+		}
+		last = line
+	}
+}
+
+// tuneReadout describes a pitch reading: the note, the open string it
+// is if any, a meter of how far off it is, and the frequency
+func tuneReadout(r pitch.Result, tuning song.Tuning) string {
+	if !r.IsValid() {
+		return "--"
+	}
+	name := r.FullNoteName()
+	for i, st := range tuning {
+		if st.MIDINote() == r.MIDINote() {
+			name += fmt.Sprintf(" (string %d)", i+1)
+			break
+		}
+	}
+	status := ""
+	switch {
+	case r.Cents >= -song.InTuneCents && r.Cents <= song.InTuneCents:
+		status = "in tune"
+	case r.Cents < 0:
+		status = "flat"
+	default:
+		status = "sharp"
+	}
+	return fmt.Sprintf("%-14s %s %+3d cents  %-7s  %.1f Hz", name, centsBar(r.Cents), r.Cents, status, r.Frequency)
+}
+
+// centsBar draws a needle on a scale from 50 cents flat to 50 sharp
+func centsBar(cents int) string {
+	pos := tuneMeterWidth + int(math.Round(float64(cents)/50*tuneMeterWidth))
+	pos = max(0, min(2*tuneMeterWidth, pos))
+	bar := []byte(strings.Repeat("-", 2*tuneMeterWidth+1))
+	bar[tuneMeterWidth] = '|'
+	bar[pos] = '*'
+	return "[" + string(bar) + "]"
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/desktop/internal/generator"
+	"guitargame/apps/desktop/internal/render"
+	"guitargame/core/game"
+	"guitargame/core/song"
+)
+
+// variations loops a chart, rewriting it a little on each pass so it has
+// to be read and heard rather than played from memory
+type variations struct {
+	variator *generator.Variator
+	passes   int    // Passes finished so far
+	last     string // What the current pass changed
+}
+
+// CycleVariations switches how the song is varied on each pass; anything
+// but Off loops the song when it starts
+func (a *App) CycleVariations() {
+	a.varyMode = a.varyMode.Next()
+}
+
+// StartVariations loops the whole song at the practice speed, playing it
+// as written the first time round
+func (a *App) StartVariations() {
+	s := a.gameState.Song
+	if len(s.Notes) == 0 {
+		return
+	}
+	v := generator.NewVariator(s, a.varyMode, time.Now().UnixNano())
+	a.variations = &variations{variator: v, last: "As written"}
+
+	varied := v.Song()
+	lastBar := int(varied.NoteBeat(varied.Notes[len(varied.Notes)-1].Time)) / song.BeatsPerBar
+	a.pitchDetector.SetRange(varied.FrequencyRange())
+	gs := song.NewGameState(varied)
+	gs.Speed = a.speed
+	a.applyPlaySettings(gs)
+	a.gameState = gs
+	a.hitDetector = game.NewHitDetector(gs, a.feedbackY)
+	gs.PlayLoop(varied.BarRegion(0, lastBar))
+	a.state = StatePlaying
+}
+
+// updateVariations rewrites the chart each time the loop comes round
+func (a *App) updateVariations(playLineX float32) {
+	gs := a.gameState
+	v := a.variations
+	if v.passes >= len(gs.LapScores) {
+		return
+	}
+	v.passes = len(gs.LapScores)
+	v.last = v.variator.Vary()
+	gs.FloatingText = append(gs.FloatingText, song.FloatingScore{
+		Text:      v.last,
+		X:         playLineX,
+		Y:         a.feedbackY(0),
+		StartTime: time.Now(),
+		Quality:   song.HitPerfect,
+	})
+}
+
+// EndVariations stops looping and shows the results of every pass
+func (a *App) EndVariations() {
+	a.variations = nil
+	a.showResults()
+}
+
+// layoutVariationsStatus says how the current pass differs from the chart
+func (a *App) layoutVariationsStatus(gtx layout.Context) layout.Dimensions {
+	v := a.variations
+	text := fmt.Sprintf("Variations (%s): pass %d  •  %s  •  Esc to finish", v.variator.Mode, v.passes+1, v.last)
+	if v.passes > 0 {
+		text += fmt.Sprintf("  •  last pass %.0f%%", a.gameState.LapScores[v.passes-1])
+	}
+	label := material.Body2(a.theme, text)
+	label.Color = render.Colors.Hint
+	return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, label.Layout)
+}
@@ -0,0 +1,22 @@
+module guitargame/apps/mobile
+
+go 1.25.5
+
+require (
+	gioui.org v0.9.0
+	guitargame/core v0.0.0
+)
+
+require (
+	gioui.org/shader v1.0.8 // indirect
+	github.com/fsnotify/fsnotify v1.9.0 // indirect
+	github.com/go-text/typesetting v0.3.0 // indirect
+	golang.org/x/exp/shiny v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
+	golang.org/x/image v0.26.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The shared game engine lives at the top of the repository
+replace guitargame/core => ../../core
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"guitargame/core/song"
+)
+
+// Highway geometry, as the desktop draws it
+const (
+	playLinePos      = 0.75 // Fraction of the width from the left
+	pixelsPerBeat    = 80   // In dp
+	noteRadius       = 18   // In dp, a little bigger than the desktop's to read at arm's length
+	maxStringSpacing = 64   // In dp
+)
+
+func (a *App) layoutGameScreen(gtx layout.Context) layout.Dimensions {
+	if a.stopButton.Clicked(gtx) {
+		a.GoToMenu()
+		return layout.Dimensions{}
+	}
+
+	gs := a.gameState
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.layoutGameHeader(gtx, gs)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return a.layoutHighway(gtx, gs)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			heard := "--"
+			if a.heard.IsValid() {
+				heard = a.heard.FullNoteName()
+			}
+			return layout.Inset{Left: unit.Dp(20), Bottom: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return a.label(gtx, material.Body1(a.theme, "Playing: "+heard), colorDetectedNote)
+			})
+		}),
+	)
+}
+
+// layoutGameHeader shows the song, score and combo, and a button to stop
+func (a *App) layoutGameHeader(gtx layout.Context, gs *song.GameState) layout.Dimensions {
+	combo := fmt.Sprintf("Combo: %d", gs.Combo)
+	if m := gs.Multiplier(); m > 1 {
+		combo += fmt.Sprintf("  x%d", m)
+	}
+	return layout.Inset{Left: unit.Dp(20), Right: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return a.label(gtx, material.Body1(a.theme, gs.Song.Title), colorTitle)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Right: unit.Dp(20)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return a.label(gtx, material.Body1(a.theme, combo), colorCombo)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Right: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return a.label(gtx, material.H6(a.theme, fmt.Sprintf("Score: %d", gs.Score)), colorHighlight)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return a.button(gtx, &a.stopButton, "Stop")
+			}),
+		)
+	})
+}
+
+// layoutHighway draws the strings and the notes scrolling towards the
+// play line, sizing the strings to fit the screen
+func (a *App) layoutHighway(gtx layout.Context, gs *song.GameState) layout.Dimensions {
+	size := gtx.Constraints.Max
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+
+	tuning := gs.Song.GetTuning()
+	a.stringSpacing = min(float32(size.Y)/float32(len(tuning)), float32(gtx.Dp(maxStringSpacing)))
+	a.tabTop = (float32(size.Y) - a.stringSpacing*float32(len(tuning))) / 2
+	a.playLineX = float32(size.X) * playLinePos
+	radius := float32(gtx.Dp(noteRadius))
+
+	// Strings, labelled with their open notes
+	for i, t := range tuning {
+		y := int(a.stringY(i))
+		fillRect(gtx, image.Rect(gtx.Dp(40), y-1, size.X, y+1), colorString)
+		a.centeredLabel(gtx, material.Body2(a.theme, t.Note), colorString, f32.Pt(float32(gtx.Dp(20)), float32(y)))
+	}
+	top, bottom := int(a.tabTop), int(a.tabTop+a.stringSpacing*float32(len(tuning)))
+	x := int(a.playLineX)
+	fillRect(gtx, image.Rect(x-gtx.Dp(2), top, x+gtx.Dp(2), bottom), colorPlayLine)
+
+	// Notes, coloured once judged
+	pixelsPerSecond := float32(gtx.Dp(pixelsPerBeat)) * float32(gs.Song.BPM) / 60
+	for i := range gs.Song.Notes {
+		note := &gs.Song.Notes[i]
+		x := a.playLineX + float32(note.Time-gs.CurrentTime)*pixelsPerSecond
+		if x < -radius || x > float32(size.X)+radius {
+			continue
+		}
+		center := f32.Pt(x, a.stringY(note.String))
+		circle(gtx, center, radius, noteColor(note))
+		a.centeredLabel(gtx, material.Body1(a.theme, fmt.Sprint(note.Fret)), colorNoteText, center)
+	}
+
+	// Hit feedback, fading over a second
+	for _, f := range gs.FloatingText {
+		age := float32(time.Since(f.StartTime).Seconds())
+		c := colorTitle
+		c.A = uint8(255 * max(0, 1-age))
+		a.centeredLabel(gtx, material.Body1(a.theme, f.Text), c, f32.Pt(f.X, f.Y-age*float32(gtx.Dp(30))))
+	}
+
+	return layout.Dimensions{Size: size}
+}
+
+// centeredLabel draws text centred on a point
+func (a *App) centeredLabel(gtx layout.Context, l material.LabelStyle, c color.NRGBA, center f32.Point) {
+	gtx.Constraints.Min = image.Point{}
+	l.Color = c
+	macro := op.Record(gtx.Ops)
+	dims := l.Layout(gtx)
+	call := macro.Stop()
+	offset := image.Pt(int(center.X)-dims.Size.X/2, int(center.Y)-dims.Size.Y/2)
+	defer op.Offset(offset).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
+
+// noteColor returns a note's color by whether and how well it was hit
+func noteColor(note *song.TabNote) color.NRGBA {
+	if !note.Hit {
+		return colorNoteDefault
+	}
+	switch note.HitQuality {
+	case song.HitPerfect:
+		return colorNotePerfect
+	case song.HitGood:
+		return colorNoteGood
+	case song.HitOK:
+		return colorNoteOK
+	default:
+		return colorNoteMiss
+	}
+}
+
+func fillRect(gtx layout.Context, r image.Rectangle, c color.NRGBA) {
+	paint.FillShape(gtx.Ops, c, clip.Rect(r).Op())
+}
+
+func circle(gtx layout.Context, center f32.Point, radius float32, c color.NRGBA) {
+	r := image.Rect(int(center.X-radius), int(center.Y-radius), int(center.X+radius), int(center.Y+radius))
+	paint.FillShape(gtx.Ops, c, clip.Ellipse(r).Op(gtx.Ops))
+}
@@ -0,0 +1,82 @@
+package mic
+
+/*
+#cgo LDFLAGS: -laaudio
+
+#include <aaudio/AAudio.h>
+
+static AAudioStream *openInput(int32_t rate, int32_t *actualRate, aaudio_result_t *result) {
+	AAudioStreamBuilder *builder;
+	*result = AAudio_createStreamBuilder(&builder);
+	if (*result != AAUDIO_OK) {
+		return NULL;
+	}
+	AAudioStreamBuilder_setDirection(builder, AAUDIO_DIRECTION_INPUT);
+	AAudioStreamBuilder_setFormat(builder, AAUDIO_FORMAT_PCM_FLOAT);
+	AAudioStreamBuilder_setChannelCount(builder, 1);
+	AAudioStreamBuilder_setSampleRate(builder, rate);
+	AAudioStreamBuilder_setPerformanceMode(builder, AAUDIO_PERFORMANCE_MODE_LOW_LATENCY);
+	// Processing meant for voices muddies an instrument's pitch
+	AAudioStreamBuilder_setInputPreset(builder, AAUDIO_INPUT_PRESET_UNPROCESSED);
+
+	AAudioStream *stream = NULL;
+	*result = AAudioStreamBuilder_openStream(builder, &stream);
+	AAudioStreamBuilder_delete(builder);
+	if (*result != AAUDIO_OK) {
+		return NULL;
+	}
+	*result = AAudioStream_requestStart(stream);
+	if (*result != AAUDIO_OK) {
+		AAudioStream_close(stream);
+		return NULL;
+	}
+	*actualRate = AAudioStream_getSampleRate(stream);
+	return stream;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// readTimeout is how long a read waits for samples to arrive
+const readTimeout = 50 * time.Millisecond
+
+// aaudioSource is an AAudio input stream, read in blocking mode
+type aaudioSource struct {
+	stream *C.AAudioStream
+}
+
+func openSource(sampleRate float64) (source, float64, error) {
+	var rate C.int32_t
+	var result C.aaudio_result_t
+	stream := C.openInput(C.int32_t(sampleRate), &rate, &result)
+	if stream == nil {
+		return nil, 0, fmt.Errorf("could not open the microphone: %w", aaudioError(result))
+	}
+	return &aaudioSource{stream: stream}, float64(rate), nil
+}
+
+func (s *aaudioSource) read(buf []float32) (int, error) {
+	n := C.AAudioStream_read(s.stream, unsafe.Pointer(&buf[0]), C.int32_t(len(buf)), C.int64_t(readTimeout.Nanoseconds()))
+	if n < 0 {
+		return 0, aaudioError(C.aaudio_result_t(n))
+	}
+	return int(n), nil
+}
+
+func (s *aaudioSource) close() error {
+	C.AAudioStream_requestStop(s.stream)
+	if result := C.AAudioStream_close(s.stream); result != C.AAUDIO_OK {
+		return aaudioError(result)
+	}
+	return nil
+}
+
+func aaudioError(result C.aaudio_result_t) error {
+	return errors.New(C.GoString(C.AAudio_convertResultToText(result)))
+}
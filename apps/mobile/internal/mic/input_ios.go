@@ -0,0 +1,115 @@
+package mic
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework AudioToolbox -framework Foundation
+
+#import <AVFoundation/AVFoundation.h>
+#import <AudioToolbox/AudioToolbox.h>
+#include <pthread.h>
+
+// Samples from the audio queue's thread wait in a ring for Go to read
+#define RING_SIZE 16384
+
+static float ring[RING_SIZE];
+static unsigned long ringWritten, ringRead;
+static pthread_mutex_t ringLock = PTHREAD_MUTEX_INITIALIZER;
+static AudioQueueRef queue;
+
+static void onInput(void *user, AudioQueueRef q, AudioQueueBufferRef buf, const AudioTimeStamp *start, UInt32 packets, const AudioStreamPacketDescription *desc) {
+	const float *samples = buf->mAudioData;
+	UInt32 n = buf->mAudioDataByteSize / sizeof(float);
+	pthread_mutex_lock(&ringLock);
+	for (UInt32 i = 0; i < n; i++) {
+		ring[ringWritten++ % RING_SIZE] = samples[i];
+	}
+	if (ringWritten - ringRead > RING_SIZE) {
+		ringRead = ringWritten - RING_SIZE; // Go fell behind; drop the oldest
+	}
+	pthread_mutex_unlock(&ringLock);
+	AudioQueueEnqueueBuffer(q, buf, 0, NULL);
+}
+
+static OSStatus openInput(double rate) {
+	// Measurement mode turns off the processing meant for voices, which
+	// muddies an instrument's pitch
+	AVAudioSession *session = [AVAudioSession sharedInstance];
+	NSError *err = nil;
+	if (![session setCategory:AVAudioSessionCategoryPlayAndRecord mode:AVAudioSessionModeMeasurement options:AVAudioSessionCategoryOptionDefaultToSpeaker error:&err] ||
+		![session setActive:YES error:&err]) {
+		return (OSStatus)err.code;
+	}
+
+	AudioStreamBasicDescription format = {0};
+	format.mSampleRate = rate;
+	format.mFormatID = kAudioFormatLinearPCM;
+	format.mFormatFlags = kAudioFormatFlagIsFloat | kAudioFormatFlagIsPacked;
+	format.mChannelsPerFrame = 1;
+	format.mBitsPerChannel = 32;
+	format.mBytesPerFrame = 4;
+	format.mBytesPerPacket = 4;
+	format.mFramesPerPacket = 1;
+	OSStatus status = AudioQueueNewInput(&format, onInput, NULL, NULL, NULL, 0, &queue);
+	if (status != noErr) {
+		return status;
+	}
+	for (int i = 0; i < 3; i++) {
+		AudioQueueBufferRef buf;
+		AudioQueueAllocateBuffer(queue, 1024 * sizeof(float), &buf);
+		AudioQueueEnqueueBuffer(queue, buf, 0, NULL);
+	}
+	status = AudioQueueStart(queue, NULL);
+	if (status != noErr) {
+		AudioQueueDispose(queue, true);
+		queue = NULL;
+	}
+	return status;
+}
+
+static int readInput(float *out, int max) {
+	pthread_mutex_lock(&ringLock);
+	int n = 0;
+	while (n < max && ringRead < ringWritten) {
+		out[n++] = ring[ringRead++ % RING_SIZE];
+	}
+	pthread_mutex_unlock(&ringLock);
+	return n;
+}
+
+static OSStatus closeInput(void) {
+	OSStatus status = AudioQueueStop(queue, true);
+	AudioQueueDispose(queue, true);
+	queue = NULL;
+	[[AVAudioSession sharedInstance] setActive:NO error:nil];
+	return status;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// queueSource is an Audio Queue input. There's one microphone, so its
+// state is kept on the C side.
+type queueSource struct{}
+
+func openSource(sampleRate float64) (source, float64, error) {
+	if status := C.openInput(C.double(sampleRate)); status != C.noErr {
+		return nil, 0, fmt.Errorf("could not open the microphone: error %d", int(status))
+	}
+	return queueSource{}, sampleRate, nil
+}
+
+func (queueSource) read(buf []float32) (int, error) {
+	return int(C.readInput((*C.float)(unsafe.Pointer(&buf[0])), C.int(len(buf)))), nil
+}
+
+func (queueSource) close() error {
+	if status := C.closeInput(); status != C.noErr {
+		return errors.New("could not close the microphone")
+	}
+	return nil
+}
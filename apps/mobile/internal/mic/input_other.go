@@ -0,0 +1,7 @@
+//go:build !android && !ios
+
+package mic
+
+func openSource(sampleRate float64) (source, float64, error) {
+	return nil, 0, errUnsupported
+}
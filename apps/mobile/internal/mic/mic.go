@@ -0,0 +1,123 @@
+// Package mic reads the microphone on phones and tablets, where there's
+// no PortAudio, and asks the player for access to it
+package mic
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSampleRate = 48000
+	// DefaultBufferSize is enough samples for the core's pitch detector to
+	// reach a bass's low E
+	DefaultBufferSize = 4096
+)
+
+// readPause is how long to wait when a platform has no new samples yet,
+// and readChunk the most samples taken from it at once
+const (
+	readPause = 5 * time.Millisecond
+	readChunk = 1024
+)
+
+// source is a platform's open microphone stream
+type source interface {
+	// read fills buf with the next samples and returns how many it got,
+	// which is 0 if none have arrived yet
+	read(buf []float32) (int, error)
+	close() error
+}
+
+// Input keeps the most recent buffer of samples from the microphone
+type Input struct {
+	src        source
+	sampleRate float64
+
+	mu     sync.Mutex
+	latest []float32 // Oldest first
+	buffer []float32
+	err    error // Why reading stopped, if it has
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// Open starts listening to the microphone. The platform may capture at a
+// different rate than asked for; SampleRate says which.
+func Open(sampleRate float64, bufferSize int) (*Input, error) {
+	src, rate, err := openSource(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	in := &Input{
+		src:        src,
+		sampleRate: rate,
+		latest:     make([]float32, bufferSize),
+		buffer:     make([]float32, bufferSize),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go in.run()
+	return in, nil
+}
+
+// run reads from the platform until closed, sliding new samples into the
+// latest buffer
+func (in *Input) run() {
+	defer close(in.stopped)
+	chunk := make([]float32, min(readChunk, len(in.latest)))
+	for {
+		select {
+		case <-in.done:
+			return
+		default:
+		}
+		n, err := in.src.read(chunk)
+		if err != nil {
+			in.mu.Lock()
+			in.err = err
+			in.mu.Unlock()
+			return
+		}
+		if n == 0 {
+			time.Sleep(readPause)
+			continue
+		}
+		in.mu.Lock()
+		copy(in.latest, in.latest[n:])
+		copy(in.latest[len(in.latest)-n:], chunk[:n])
+		in.mu.Unlock()
+	}
+}
+
+// GetBuffer returns the latest samples. The slice is reused by the next
+// call.
+func (in *Input) GetBuffer() []float32 {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	copy(in.buffer, in.latest)
+	return in.buffer
+}
+
+func (in *Input) SampleRate() float64 {
+	return in.sampleRate
+}
+
+// Err returns why the microphone stopped delivering audio, or nil
+func (in *Input) Err() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.err
+}
+
+func (in *Input) Close() error {
+	close(in.done)
+	<-in.stopped
+	return in.src.close()
+}
+
+// errUnsupported is returned where there's no native microphone code,
+// such as when trying the mobile layout on a desktop
+var errUnsupported = errors.New("no microphone input on this platform; build for Android or iOS")
@@ -0,0 +1,21 @@
+package mic
+
+// Status is the microphone authorization state
+type Status int
+
+const (
+	StatusNotDetermined Status = iota // Not asked yet, or not answered
+	StatusDenied                      // The player said no
+	StatusAuthorized
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusNotDetermined:
+		return "not determined"
+	case StatusDenied:
+		return "denied"
+	default:
+		return "authorized"
+	}
+}
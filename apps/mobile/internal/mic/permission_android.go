@@ -0,0 +1,138 @@
+package mic
+
+/*
+#include <jni.h>
+#include <stdint.h>
+
+// withEnv gets a JNI environment for this thread, attaching it to the VM
+// if it isn't already, and a frame for the local references made in it
+static JNIEnv *withEnv(JavaVM *vm, int *attached) {
+	JNIEnv *env;
+	*attached = 0;
+	if ((*vm)->GetEnv(vm, (void **)&env, JNI_VERSION_1_6) != JNI_OK) {
+		if ((*vm)->AttachCurrentThread(vm, &env, NULL) != JNI_OK) {
+			return NULL;
+		}
+		*attached = 1;
+	}
+	(*env)->PushLocalFrame(env, 16);
+	return env;
+}
+
+// done releases what withEnv took, and reports whether a Java exception
+// was thrown since
+static int done(JavaVM *vm, JNIEnv *env, int attached) {
+	int failed = (*env)->ExceptionCheck(env);
+	if (failed) {
+		(*env)->ExceptionClear(env);
+	}
+	(*env)->PopLocalFrame(env, NULL);
+	if (attached) {
+		(*vm)->DetachCurrentThread(vm);
+	}
+	return failed;
+}
+
+static const char *recordAudio = "android.permission.RECORD_AUDIO";
+
+static int checkMicrophone(uintptr_t jvm, uintptr_t context) {
+	JavaVM *vm = (JavaVM *)jvm;
+	int attached;
+	JNIEnv *env = withEnv(vm, &attached);
+	if (env == NULL) {
+		return 0;
+	}
+	jobject ctx = (jobject)context;
+	jmethodID check = (*env)->GetMethodID(env, (*env)->GetObjectClass(env, ctx), "checkSelfPermission", "(Ljava/lang/String;)I");
+	jint granted = (*env)->CallIntMethod(env, ctx, check, (*env)->NewStringUTF(env, recordAudio));
+	if (done(vm, env, attached)) {
+		return 0;
+	}
+	return granted == 0; // PackageManager.PERMISSION_GRANTED
+}
+
+// requestMicrophone asks through the activity showing the view
+static int requestMicrophone(uintptr_t jvm, uintptr_t view) {
+	JavaVM *vm = (JavaVM *)jvm;
+	int attached;
+	JNIEnv *env = withEnv(vm, &attached);
+	if (env == NULL) {
+		return 1;
+	}
+	jobject v = (jobject)view;
+	jmethodID getContext = (*env)->GetMethodID(env, (*env)->GetObjectClass(env, v), "getContext", "()Landroid/content/Context;");
+	jobject activity = (*env)->CallObjectMethod(env, v, getContext);
+	jmethodID request = (*env)->GetMethodID(env, (*env)->GetObjectClass(env, activity), "requestPermissions", "([Ljava/lang/String;I)V");
+	jobjectArray perms = (*env)->NewObjectArray(env, 1, (*env)->FindClass(env, "java/lang/String"), (*env)->NewStringUTF(env, recordAudio));
+	(*env)->CallVoidMethod(env, activity, request, perms, 1);
+	return done(vm, env, attached);
+}
+
+// openSettings opens the app's page in the system settings
+static int openSettings(uintptr_t jvm, uintptr_t context) {
+	JavaVM *vm = (JavaVM *)jvm;
+	int attached;
+	JNIEnv *env = withEnv(vm, &attached);
+	if (env == NULL) {
+		return 1;
+	}
+	jobject ctx = (jobject)context;
+	jclass ctxClass = (*env)->GetObjectClass(env, ctx);
+	jmethodID getPackageName = (*env)->GetMethodID(env, ctxClass, "getPackageName", "()Ljava/lang/String;");
+	jobject pkg = (*env)->CallObjectMethod(env, ctx, getPackageName);
+
+	jclass uriClass = (*env)->FindClass(env, "android/net/Uri");
+	jmethodID fromParts = (*env)->GetStaticMethodID(env, uriClass, "fromParts", "(Ljava/lang/String;Ljava/lang/String;Ljava/lang/String;)Landroid/net/Uri;");
+	jobject uri = (*env)->CallStaticObjectMethod(env, uriClass, fromParts, (*env)->NewStringUTF(env, "package"), pkg, NULL);
+
+	jclass intentClass = (*env)->FindClass(env, "android/content/Intent");
+	jmethodID newIntent = (*env)->GetMethodID(env, intentClass, "<init>", "(Ljava/lang/String;Landroid/net/Uri;)V");
+	jobject intent = (*env)->NewObject(env, intentClass, newIntent, (*env)->NewStringUTF(env, "android.settings.APPLICATION_DETAILS_SETTINGS"), uri);
+	jmethodID addFlags = (*env)->GetMethodID(env, intentClass, "addFlags", "(I)Landroid/content/Intent;");
+	(*env)->CallObjectMethod(env, intent, addFlags, 0x10000000); // FLAG_ACTIVITY_NEW_TASK
+
+	jmethodID startActivity = (*env)->GetMethodID(env, ctxClass, "startActivity", "(Landroid/content/Intent;)V");
+	(*env)->CallVoidMethod(env, ctx, startActivity, intent);
+	return done(vm, env, attached);
+}
+*/
+import "C"
+
+import (
+	"errors"
+
+	"gioui.org/app"
+)
+
+// Microphone returns whether the app holds RECORD_AUDIO. Android doesn't
+// say whether a missing permission was refused or never asked for, so
+// that's StatusNotDetermined.
+func Microphone() Status {
+	if C.checkMicrophone(C.uintptr_t(app.JavaVM()), C.uintptr_t(app.AppContext())) != 0 {
+		return StatusAuthorized
+	}
+	return StatusNotDetermined
+}
+
+// RequestMicrophone shows the system prompt asking for the microphone,
+// over the activity showing the window's view. The answer arrives
+// asynchronously; poll Microphone to see it.
+func RequestMicrophone(view app.ViewEvent) error {
+	v, ok := view.(app.AndroidViewEvent)
+	if !ok || !v.Valid() {
+		return errors.New("the window isn't showing yet")
+	}
+	if C.requestMicrophone(C.uintptr_t(app.JavaVM()), C.uintptr_t(v.View)) != 0 {
+		return errors.New("could not ask for the microphone")
+	}
+	return nil
+}
+
+// OpenSettings opens the app's page in the system settings, where the
+// microphone can be allowed after the prompt was refused for good
+func OpenSettings() error {
+	if C.openSettings(C.uintptr_t(app.JavaVM()), C.uintptr_t(app.AppContext())) != 0 {
+		return errors.New("could not open the system settings")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package mic
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework UIKit -framework Foundation
+
+#import <AVFoundation/AVFoundation.h>
+#import <UIKit/UIKit.h>
+
+static int microphoneStatus(void) {
+	switch ([[AVAudioSession sharedInstance] recordPermission]) {
+	case AVAudioSessionRecordPermissionGranted:
+		return 2;
+	case AVAudioSessionRecordPermissionDenied:
+		return 1;
+	default:
+		return 0;
+	}
+}
+
+static void requestMicrophone(void) {
+	[[AVAudioSession sharedInstance] requestRecordPermission:^(BOOL granted) {}];
+}
+
+static void openSettings(void) {
+	dispatch_async(dispatch_get_main_queue(), ^{
+		NSURL *url = [NSURL URLWithString:UIApplicationOpenSettingsURLString];
+		[[UIApplication sharedApplication] openURL:url options:@{} completionHandler:nil];
+	});
+}
+*/
+import "C"
+
+import "gioui.org/app"
+
+// Microphone returns the current microphone authorization. The values
+// match the order of Status.
+func Microphone() Status {
+	return Status(C.microphoneStatus())
+}
+
+// RequestMicrophone shows the system prompt asking for the microphone if
+// the player hasn't answered it yet. The answer arrives asynchronously;
+// poll Microphone to see it.
+func RequestMicrophone(app.ViewEvent) error {
+	C.requestMicrophone()
+	return nil
+}
+
+// OpenSettings opens the app's page in Settings, where the microphone can
+// be allowed after it was refused
+func OpenSettings() error {
+	C.openSettings()
+	return nil
+}
@@ -0,0 +1,316 @@
+// Command mobile is the game on a phone or tablet, for practicing with
+// just the device on a music stand. Songs, scoring and hit detection come
+// from the core module as on the desktop; the microphone is read natively
+// (AAudio on Android, Audio Queues on iOS), pitch is found by the core's
+// YIN detector, and everything is driven by touch. Build it with
+// "just mobile android" or "just mobile ios".
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/mobile/internal/mic"
+	"guitargame/core/game"
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// micPollInterval is how often the microphone permission is rechecked
+// while the permission screen is up
+const micPollInterval = 500 * time.Millisecond
+
+type AppState int
+
+const (
+	StatePermission AppState = iota
+	StateMenu
+	StatePreStart
+	StatePlaying
+	StateResults
+)
+
+type App struct {
+	theme *material.Theme
+	state AppState
+	view  app.ViewEvent // The native view, needed to show the permission prompt on Android
+
+	songs       []*song.Song
+	songList    widget.List
+	songButtons []widget.Clickable
+	selected    int
+	message     string // Shown on the menu, such as why a chart didn't load
+
+	input    *mic.Input
+	detector *pitch.Detector
+	heard    pitch.Result
+
+	micStatus    mic.Status
+	lastMicCheck time.Time
+	micErr       error // Why the prompt or settings couldn't be shown
+
+	allowButton    widget.Clickable
+	settingsButton widget.Clickable
+	skipButton     widget.Clickable
+	startButton    widget.Clickable
+	backButton     widget.Clickable
+	stopButton     widget.Clickable
+	doneButton     widget.Clickable
+
+	gameState   *song.GameState
+	hitDetector *game.HitDetector
+
+	// Where the highway's strings and play line were last drawn, in pixels
+	tabTop        float32
+	stringSpacing float32
+	playLineX     float32
+}
+
+func NewApp() *App {
+	a := &App{theme: material.NewTheme()}
+	a.theme.Palette.Bg = colorBackground
+	a.theme.Palette.Fg = colorTitle
+	a.theme.Palette.ContrastBg = colorButton
+	a.theme.Palette.ContrastFg = colorTitle
+	a.songList.Axis = layout.Vertical
+
+	var errs []error
+	a.songs, errs = loadSongs()
+	a.songButtons = make([]widget.Clickable, len(a.songs))
+	if len(errs) > 0 {
+		a.message = errors.Join(errs...).Error()
+	}
+
+	a.micStatus = mic.Microphone()
+	if a.micStatus == mic.StatusAuthorized {
+		a.state = StateMenu
+	} else {
+		a.state = StatePermission
+	}
+	return a
+}
+
+// openInput starts listening, once the microphone may be used
+func (a *App) openInput() {
+	if a.input != nil {
+		return
+	}
+	input, err := mic.Open(mic.DefaultSampleRate, mic.DefaultBufferSize)
+	if err != nil {
+		a.message = err.Error()
+		return
+	}
+	a.input = input
+	a.detector = pitch.NewDetector(input.SampleRate())
+	if a.gameState != nil {
+		a.detector.SetRange(a.gameState.Song.FrequencyRange())
+	}
+}
+
+func (a *App) closeInput() {
+	if a.input == nil {
+		return
+	}
+	if err := a.input.Close(); err != nil {
+		log.Printf("could not close the microphone: %v", err)
+	}
+	a.input = nil
+	a.heard = pitch.Result{}
+}
+
+// setView notes the window's native view. Without one the app is in the
+// background, where it stops listening; a song can't be heard there, so
+// it's abandoned.
+func (a *App) setView(e app.ViewEvent) {
+	a.view = e
+	if !e.Valid() {
+		a.closeInput()
+		if a.state == StatePlaying || a.state == StatePreStart {
+			a.GoToMenu()
+		}
+		return
+	}
+	if mic.Microphone() == mic.StatusAuthorized {
+		a.openInput()
+	}
+}
+
+// Update reads the microphone and moves play on, once a frame
+func (a *App) Update() {
+	if a.input != nil {
+		if err := a.input.Err(); err != nil {
+			a.message = "the microphone stopped: " + err.Error()
+			a.closeInput()
+		} else {
+			a.heard = a.detector.Detect(a.input.GetBuffer())
+		}
+	}
+
+	switch a.state {
+	case StatePermission:
+		a.updateMicPermission()
+	case StatePreStart:
+		// Playing a note starts, so hands can stay on the instrument
+		if a.heard.IsValid() {
+			a.StartGame()
+		}
+	case StatePlaying:
+		a.gameState.Update()
+		a.hitDetector.CheckHit(a.heard, a.playLineX)
+		a.hitDetector.Update()
+		if a.gameState.IsFinished {
+			a.state = StateResults
+		}
+	}
+}
+
+// updateMicPermission watches for access being granted, then starts
+// listening
+func (a *App) updateMicPermission() {
+	if time.Since(a.lastMicCheck) < micPollInterval {
+		return
+	}
+	a.lastMicCheck = time.Now()
+	a.micStatus = mic.Microphone()
+	if a.micStatus != mic.StatusAuthorized {
+		return
+	}
+	a.openInput()
+	a.GoToMenu()
+}
+
+// RequestMicrophone shows the system prompt over the app
+func (a *App) RequestMicrophone() {
+	a.micErr = mic.RequestMicrophone(a.view)
+}
+
+func (a *App) SelectSong(index int) {
+	a.selected = index
+	a.state = StatePreStart
+}
+
+// StartGame plays the selected song from the top
+func (a *App) StartGame() {
+	s := a.songs[a.selected]
+	a.gameState = song.NewGameState(s)
+	a.hitDetector = game.NewHitDetector(a.gameState, a.stringY)
+	if a.detector != nil {
+		a.detector.SetRange(s.FrequencyRange())
+	}
+	a.gameState.Start()
+	a.state = StatePlaying
+}
+
+func (a *App) GoToMenu() {
+	a.gameState = nil
+	a.hitDetector = nil
+	a.state = StateMenu
+}
+
+// stringY gives the height of a string on the highway, where hit feedback
+// is shown
+func (a *App) stringY(str int) float32 {
+	return a.tabTop + (float32(str)+0.5)*a.stringSpacing
+}
+
+func (a *App) Layout(gtx layout.Context) layout.Dimensions {
+	a.Update()
+	a.handleKeys(gtx)
+
+	paint.ColorOp{Color: colorBackground}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+
+	// Pitch is read every frame, so keep them coming
+	gtx.Execute(op.InvalidateCmd{})
+
+	switch a.state {
+	case StatePermission:
+		return a.layoutPermissionScreen(gtx)
+	case StateMenu:
+		return a.layoutMenuScreen(gtx)
+	case StatePreStart:
+		return a.layoutPreStartScreen(gtx)
+	case StatePlaying:
+		return a.layoutGameScreen(gtx)
+	case StateResults:
+		return a.layoutResultsScreen(gtx)
+	}
+	return layout.Dimensions{}
+}
+
+// handleKeys handles the system back button or gesture, which steps back
+// a screen; on the menu it's left to the system, which leaves the app
+func (a *App) handleKeys(gtx layout.Context) {
+	if a.state == StateMenu {
+		return
+	}
+	for {
+		ev, ok := gtx.Event(key.Filter{Name: key.NameBack})
+		if !ok {
+			break
+		}
+		if e, ok := ev.(key.Event); ok && e.State == key.Press {
+			a.GoToMenu()
+		}
+	}
+}
+
+func (a *App) Close() {
+	a.closeInput()
+}
+
+func main() {
+	go func() {
+		w := new(app.Window)
+		w.Option(
+			app.Title("Bass Guitar Practice"),
+			// A phone on a music stand lies on its side, with room for the highway
+			app.LandscapeOrientation.Option(),
+		)
+		if err := run(w); err != nil {
+			log.Print(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// run handles the window's events until it's closed
+func run(w *app.Window) error {
+	a := NewApp()
+	defer a.Close()
+	if a.message != "" {
+		log.Printf("songs: %s", a.message)
+	}
+
+	var ops op.Ops
+	for {
+		switch e := w.Event().(type) {
+		case app.DestroyEvent:
+			return e.Err
+		case app.ViewEvent:
+			a.setView(e)
+		case app.FrameEvent:
+			gtx := app.NewContext(&ops, e)
+			a.Layout(gtx)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// songInfo is a song's tempo and length, as the menu lists it
+func songInfo(s *song.Song) string {
+	return fmt.Sprintf("%.0f BPM • %d notes • %s", s.BPM, len(s.Notes), s.GetTuning().Name())
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"guitargame/apps/mobile/internal/mic"
+)
+
+// Touch targets, comfortably bigger than a fingertip
+const (
+	songRowHeight = 72
+	songRowGap    = 6
+	buttonPadding = 16
+)
+
+func (a *App) layoutPermissionScreen(gtx layout.Context) layout.Dimensions {
+	if a.allowButton.Clicked(gtx) {
+		a.RequestMicrophone()
+	}
+	if a.settingsButton.Clicked(gtx) {
+		a.micErr = mic.OpenSettings()
+	}
+	if a.skipButton.Clicked(gtx) {
+		// Carry on without the microphone; the songs can still be browsed
+		a.GoToMenu()
+	}
+
+	lines := []string{
+		"Bass Guitar Practice listens to your instrument through the microphone.",
+	}
+	if a.micStatus == mic.StatusDenied {
+		lines = append(lines, "Access was refused. Turn on the microphone for this app in Settings.")
+	} else {
+		lines = append(lines, "Tap Allow, then allow it in the system prompt. If no prompt appears, allow it in Settings.")
+	}
+	if a.micErr != nil {
+		lines = append(lines, a.micErr.Error())
+	}
+
+	children := []layout.FlexChild{
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.label(gtx, material.H5(a.theme, "Microphone Access Needed"), colorWarning)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+	}
+	for _, text := range lines {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.label(gtx, material.Body1(a.theme, text), colorTitle)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(30)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.button(gtx, &a.allowButton, "Allow microphone")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.button(gtx, &a.settingsButton, "Open Settings")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.button(gtx, &a.skipButton, "Not now")
+				}),
+			)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+func (a *App) layoutMenuScreen(gtx layout.Context) layout.Dimensions {
+	for i := range a.songButtons {
+		if a.songButtons[i].Clicked(gtx) {
+			a.SelectSong(i)
+		}
+	}
+
+	return layout.Inset{Left: unit.Dp(20), Right: unit.Dp(20)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return a.label(gtx, material.H5(a.theme, "Bass Guitar Practice"), colorTitle)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, a.layoutMicStatus)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.List(a.theme, &a.songList).Layout(gtx, len(a.songs), a.layoutSongRow)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if a.message == "" {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return a.label(gtx, material.Body2(a.theme, a.message), colorError)
+				})
+			}),
+		)
+	})
+}
+
+// layoutMicStatus says whether the microphone is on, and what it hears
+func (a *App) layoutMicStatus(gtx layout.Context) layout.Dimensions {
+	text, c := "Tap a song to play it  •  Listening", colorHint
+	switch {
+	case a.input == nil:
+		text, c = "Tap a song to play it  •  The microphone is off", colorWarning
+	case a.heard.IsValid():
+		text, c = "Tap a song to play it  •  Hearing "+a.heard.FullNoteName(), colorDetectedNote
+	}
+	return a.label(gtx, material.Body2(a.theme, text), c)
+}
+
+func (a *App) layoutSongRow(gtx layout.Context, i int) layout.Dimensions {
+	s := a.songs[i]
+	return layout.Inset{Bottom: unit.Dp(songRowGap)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints = layout.Exact(image.Pt(gtx.Constraints.Max.X, gtx.Dp(songRowHeight)))
+		return material.Clickable(gtx, &a.songButtons[i], func(gtx layout.Context) layout.Dimensions {
+			fill(gtx, colorListItem)
+			return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return a.label(gtx, material.Body1(a.theme, s.Title), colorTitle)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						info := songInfo(s)
+						if s.Artist != "" {
+							info = s.Artist + "  •  " + info
+						}
+						return a.label(gtx, material.Body2(a.theme, info), colorHint)
+					}),
+				)
+			})
+		})
+	})
+}
+
+func (a *App) layoutPreStartScreen(gtx layout.Context) layout.Dimensions {
+	if a.startButton.Clicked(gtx) {
+		a.StartGame()
+		return layout.Dimensions{}
+	}
+	if a.backButton.Clicked(gtx) {
+		a.GoToMenu()
+		return layout.Dimensions{}
+	}
+
+	s := a.songs[a.selected]
+	prompt := "Play any note or tap Start"
+	if a.input == nil {
+		prompt = "Tap Start (the microphone is off, so nothing will be heard)"
+	}
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.label(gtx, material.H4(a.theme, s.Title), colorTitle)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.label(gtx, material.Body1(a.theme, songInfo(s)), colorText)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.label(gtx, material.Body1(a.theme, prompt), colorNotePerfect)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.button(gtx, &a.startButton, "Start")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.button(gtx, &a.backButton, "Back")
+				}),
+			)
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+}
+
+func (a *App) layoutResultsScreen(gtx layout.Context) layout.Dimensions {
+	if a.doneButton.Clicked(gtx) {
+		a.GoToMenu()
+		return layout.Dimensions{}
+	}
+
+	gs := a.gameState
+	grade := gs.Grade()
+	lines := []string{
+		fmt.Sprintf("Score: %d", gs.Score),
+		fmt.Sprintf("Accuracy: %.1f%%  •  Max combo: %d", gs.Accuracy(), gs.MaxCombo),
+		fmt.Sprintf("Hit %d  •  Missed %d of %d", gs.NotesHit, gs.NotesMissed, gs.TotalNotes),
+	}
+
+	children := []layout.FlexChild{
+		layout.Flexed(1, layout.Spacer{}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.label(gtx, material.H5(a.theme, gs.Song.Title), colorTitle)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.label(gtx, material.H2(a.theme, grade), colorHighlight)
+		}),
+	}
+	for _, text := range lines {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.label(gtx, material.Body1(a.theme, text), colorText)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.button(gtx, &a.doneButton, "Done")
+		}),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// label lays out text in a color
+func (a *App) label(gtx layout.Context, l material.LabelStyle, c color.NRGBA) layout.Dimensions {
+	l.Color = c
+	return l.Layout(gtx)
+}
+
+// button lays out a large button, spaced from its neighbours
+func (a *App) button(gtx layout.Context, click *widget.Clickable, text string) layout.Dimensions {
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		b := material.Button(a.theme, click, text)
+		b.Inset = layout.UniformInset(unit.Dp(buttonPadding))
+		return b.Layout(gtx)
+	})
+}
+
+// fill paints the whole of the constraints in a color
+func fill(gtx layout.Context, c color.NRGBA) {
+	defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
+	paint.ColorOp{Color: c}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+
+	"guitargame/core/song"
+)
+
+// shipped holds the repository's charts, copied in by the build
+//
+//go:embed songs
+var shipped embed.FS
+
+// loadSongs returns the built-in exercises followed by the shipped
+// charts in file name order. Charts that don't parse are left out.
+func loadSongs() ([]*song.Song, []error) {
+	songs := song.GetDefaultExercises()
+	names, _ := fs.Glob(shipped, "songs/*.yaml")
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		data, err := shipped.ReadFile(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s, err := song.ParseSong(data)
+		if err != nil {
+			errs = append(errs, &fs.PathError{Op: "parse", Path: path.Base(name), Err: err})
+			continue
+		}
+		songs = append(songs, s)
+	}
+	return songs, errs
+}
@@ -0,0 +1,32 @@
+package main
+
+import "image/color"
+
+// Colors, as the desktop's dark theme has them
+var (
+	colorBackground   = rgb(20, 20, 30)
+	colorTitle        = rgb(200, 200, 200)
+	colorText         = rgb(150, 150, 150)
+	colorHint         = rgb(120, 120, 120)
+	colorHighlight    = rgb(255, 215, 0)
+	colorAccent       = rgb(100, 200, 255)
+	colorSuccess      = rgb(100, 200, 100)
+	colorWarning      = rgb(255, 200, 100)
+	colorError        = rgb(255, 100, 100)
+	colorCombo        = rgb(255, 150, 50)
+	colorListItem     = rgb(35, 35, 45)
+	colorButton       = rgb(50, 70, 90)
+	colorString       = rgb(140, 140, 160)
+	colorPlayLine     = rgb(100, 220, 255)
+	colorNoteDefault  = rgb(255, 255, 255)
+	colorNotePerfect  = rgb(50, 255, 100)
+	colorNoteGood     = rgb(180, 255, 50)
+	colorNoteOK       = rgb(255, 220, 50)
+	colorNoteMiss     = rgb(255, 80, 80)
+	colorNoteText     = rgb(30, 30, 40)
+	colorDetectedNote = rgb(100, 255, 150)
+)
+
+func rgb(r, g, b uint8) color.NRGBA {
+	return color.NRGBA{R: r, G: g, B: b, A: 255}
+}
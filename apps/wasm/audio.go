@@ -0,0 +1,89 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"syscall/js"
+)
+
+// bufferSize is how many samples each pitch reading looks at: enough for
+// the detector to reach a bass's low E
+const bufferSize = 4096
+
+// micInput reads the microphone through Web Audio. The browser only
+// allows it after the player has interacted with the page, so it's
+// started from a key press or click.
+type micInput struct {
+	context  js.Value
+	analyser js.Value
+	floats   js.Value // Float32Array the analyser fills
+	bytes    js.Value // The same memory, for copying into Go
+
+	raw     []byte
+	samples []float32
+
+	started bool
+	ready   bool
+	err     string // Why the microphone couldn't be used, if it couldn't
+}
+
+// start asks for the microphone. It's ready once the player allows it.
+func (m *micInput) start() {
+	if m.started {
+		return
+	}
+	m.started = true
+
+	m.context = js.Global().Get("AudioContext").New()
+	constraints := map[string]any{
+		"audio": map[string]any{
+			// Processing meant for voices muddies an instrument's pitch
+			"echoCancellation": false,
+			"noiseSuppression": false,
+			"autoGainControl":  false,
+		},
+	}
+	devices := js.Global().Get("navigator").Get("mediaDevices")
+	if devices.IsUndefined() {
+		m.err = "this browser can't use a microphone here (the page must be served over HTTPS or from localhost)"
+		return
+	}
+
+	await(devices.Call("getUserMedia", constraints), func(stream js.Value, err error) {
+		if err != nil {
+			m.err = "could not use the microphone: " + err.Error()
+			return
+		}
+		source := m.context.Call("createMediaStreamSource", stream)
+		m.analyser = m.context.Call("createAnalyser")
+		m.analyser.Set("fftSize", bufferSize)
+		source.Call("connect", m.analyser)
+
+		m.floats = js.Global().Get("Float32Array").New(bufferSize)
+		m.bytes = js.Global().Get("Uint8Array").New(m.floats.Get("buffer"))
+		m.raw = make([]byte, bufferSize*4)
+		m.samples = make([]float32, bufferSize)
+		m.ready = true
+	})
+}
+
+// sampleRate returns the rate the browser is capturing at
+func (m *micInput) sampleRate() float64 {
+	return m.context.Get("sampleRate").Float()
+}
+
+// read returns the latest buffer of samples, or nil until the microphone
+// is ready
+func (m *micInput) read() []float32 {
+	if !m.ready {
+		return nil
+	}
+	m.analyser.Call("getFloatTimeDomainData", m.floats)
+	js.CopyBytesToGo(m.raw, m.bytes)
+	for i := range m.samples {
+		m.samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(m.raw[i*4:]))
+	}
+	return m.samples
+}
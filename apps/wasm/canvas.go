@@ -0,0 +1,93 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"math"
+	"syscall/js"
+)
+
+// Colors, as the desktop's dark theme has them
+const (
+	colorBackground   = "rgb(20, 20, 30)"
+	colorTitle        = "rgb(200, 200, 200)"
+	colorText         = "rgb(150, 150, 150)"
+	colorHint         = "rgb(120, 120, 120)"
+	colorHighlight    = "rgb(255, 215, 0)"
+	colorCombo        = "rgb(255, 150, 50)"
+	colorListItem     = "rgb(35, 35, 45)"
+	colorListSelected = "rgb(50, 70, 90)"
+	colorSelected     = "rgb(255, 150, 100)"
+	colorError        = "rgb(255, 100, 100)"
+	colorString       = "rgb(140, 140, 160)"
+	colorPlayLine     = "rgb(100, 220, 255)"
+	colorNoteDefault  = "rgb(255, 255, 255)"
+	colorNotePerfect  = "rgb(50, 255, 100)"
+	colorNoteGood     = "rgb(180, 255, 50)"
+	colorNoteOK       = "rgb(255, 220, 50)"
+	colorNoteMiss     = "rgb(255, 80, 80)"
+	colorNoteText     = "rgb(30, 30, 40)"
+	colorDetectedNote = "rgb(100, 255, 150)"
+)
+
+// Fonts, in CSS pixels
+const (
+	fontTitle = "bold 32px sans-serif"
+	fontBody  = "18px sans-serif"
+	fontSmall = "14px sans-serif"
+	fontNote  = "bold 16px sans-serif"
+)
+
+// canvas draws on a 2D canvas filling the page, sized for the screen's
+// pixel density
+type canvas struct {
+	element js.Value
+	ctx     js.Value
+	width   float64 // In CSS pixels
+	height  float64
+}
+
+func newCanvas(element js.Value) *canvas {
+	return &canvas{element: element, ctx: element.Call("getContext", "2d")}
+}
+
+// resize matches the canvas to its size on the page, and clears it
+func (c *canvas) resize() {
+	ratio := js.Global().Get("devicePixelRatio").Float()
+	c.width = c.element.Get("clientWidth").Float()
+	c.height = c.element.Get("clientHeight").Float()
+	c.element.Set("width", int(c.width*ratio))
+	c.element.Set("height", int(c.height*ratio))
+	c.ctx.Call("setTransform", ratio, 0, 0, ratio, 0, 0)
+}
+
+func (c *canvas) fill(color string) {
+	c.fillRect(0, 0, c.width, c.height, color)
+}
+
+func (c *canvas) fillRect(x, y, w, h float64, color string) {
+	c.ctx.Set("fillStyle", color)
+	c.ctx.Call("fillRect", x, y, w, h)
+}
+
+func (c *canvas) circle(x, y, radius float64, color string) {
+	c.ctx.Set("fillStyle", color)
+	c.ctx.Call("beginPath")
+	c.ctx.Call("arc", x, y, radius, 0, 2*math.Pi)
+	c.ctx.Call("fill")
+}
+
+// text draws text aligned "left", "center" or "right" of x, centred
+// vertically on y
+func (c *canvas) text(s string, x, y float64, font, align, color string) {
+	c.ctx.Set("font", font)
+	c.ctx.Set("textAlign", align)
+	c.ctx.Set("textBaseline", "middle")
+	c.ctx.Set("fillStyle", color)
+	c.ctx.Call("fillText", s, x, y)
+}
+
+// alpha sets the opacity of what's drawn next
+func (c *canvas) alpha(a float64) {
+	c.ctx.Set("globalAlpha", a)
+}
@@ -0,0 +1,160 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"guitargame/core/song"
+)
+
+// drawMenu lists the songs, with the selected one highlighted
+func (a *App) drawMenu() {
+	c := a.canvas
+	c.text("Bass Guitar Practice", 40, 50, fontTitle, "left", colorTitle)
+	c.text("↑/↓ choose a song  •  Enter to play  •  O to open a chart file", 40, 90, fontSmall, "left", colorHint)
+	a.drawMicStatus(40, 115)
+
+	const rowHeight = 36
+	top := 150.0
+	visible := max(1, int((c.height-top-50)/rowHeight))
+	first := max(0, min(a.selected-visible/2, len(a.songs)-visible))
+	for i := first; i < len(a.songs) && i < first+visible; i++ {
+		s := a.songs[i]
+		y := top + float64(i-first)*rowHeight
+		bg, fg := colorListItem, colorText
+		if i == a.selected {
+			bg, fg = colorListSelected, colorTitle
+		}
+		c.fillRect(40, y, c.width-80, rowHeight-4, bg)
+		label := s.Title
+		if s.Artist != "" {
+			label += " - " + s.Artist
+		}
+		c.text(label, 55, y+rowHeight/2-2, fontBody, "left", fg)
+		c.text(fmt.Sprintf("%.0f BPM  •  %d notes", s.BPM, len(s.Notes)), c.width-55, y+rowHeight/2-2, fontSmall, "right", colorHint)
+	}
+	if a.message != "" {
+		c.text(a.message, 40, c.height-25, fontSmall, "left", colorError)
+	}
+}
+
+// drawMicStatus says whether the microphone is on, and what it hears
+func (a *App) drawMicStatus(x, y float64) {
+	c := a.canvas
+	switch {
+	case a.mic.err != "":
+		c.text(a.mic.err, x, y, fontSmall, "left", colorError)
+	case !a.mic.started:
+		c.text("Press any key or click to turn the microphone on", x, y, fontSmall, "left", colorSelected)
+	case !a.mic.ready:
+		c.text("Waiting for permission to use the microphone…", x, y, fontSmall, "left", colorHint)
+	case a.heard.IsValid():
+		c.text("Hearing "+a.heard.FullNoteName(), x, y, fontSmall, "left", colorDetectedNote)
+	default:
+		c.text("Listening", x, y, fontSmall, "left", colorHint)
+	}
+}
+
+// drawPreStart shows the song about to be played
+func (a *App) drawPreStart() {
+	c := a.canvas
+	s := a.songs[a.selected]
+	c.text(s.Title, c.width/2, c.height/2-60, fontTitle, "center", colorTitle)
+	c.text(fmt.Sprintf("%.0f BPM  •  tuning %s", s.BPM, s.GetTuning().Name()), c.width/2, c.height/2-20, fontBody, "center", colorText)
+	c.text("Play any note or press Enter to start!", c.width/2, c.height/2+30, fontBody, "center", colorNotePerfect)
+	c.text("Esc to go back", c.width/2, c.height/2+60, fontSmall, "center", colorHint)
+	a.drawMicStatus(c.width/2-120, c.height/2+100)
+}
+
+// drawGame draws the score and the highway of notes scrolling towards
+// the play line
+func (a *App) drawGame() {
+	c := a.canvas
+	gs := a.state
+	s := gs.Song
+
+	// Score header
+	c.text(s.Title, 20, 25, fontBody, "left", colorTitle)
+	c.text(fmt.Sprintf("Score: %d", gs.Score), c.width-20, 25, fontBody, "right", colorHighlight)
+	combo := fmt.Sprintf("Combo: %d", gs.Combo)
+	if m := gs.Multiplier(); m > 1 {
+		combo += fmt.Sprintf("  x%d", m)
+	}
+	c.text(combo, c.width-20, 55, fontSmall, "right", colorCombo)
+	if s.Duration > 0 {
+		c.fillRect(20, 45, (c.width-220)*min(1, gs.CurrentTime/s.Duration), 4, colorPlayLine)
+	}
+
+	// Strings, labelled with their open notes
+	tuning := s.GetTuning()
+	playX := c.width * playLineX
+	for i, t := range tuning {
+		y := float64(headerHeight + i*stringSpacing + stringSpacing/2)
+		c.fillRect(40, y-1, c.width-40, 2, colorString)
+		c.text(t.Note, 20, y, fontSmall, "center", colorString)
+	}
+	tabTop := float64(headerHeight)
+	tabHeight := float64(len(tuning) * stringSpacing)
+	c.fillRect(playX-2, tabTop, 4, tabHeight, colorPlayLine)
+
+	// Notes, coloured once judged
+	pixelsPerSecond := pixelsPerBeat * s.BPM / 60
+	for i := range s.Notes {
+		note := &s.Notes[i]
+		x := playX + (note.Time-gs.CurrentTime)*pixelsPerSecond
+		if x < -noteRadius || x > c.width+noteRadius {
+			continue
+		}
+		y := tabTop + float64(note.String*stringSpacing+stringSpacing/2)
+		c.circle(x, y, noteRadius, noteColor(note))
+		c.text(fmt.Sprint(note.Fret), x, y+1, fontNote, "center", colorNoteText)
+	}
+
+	// Hit feedback, fading over a second
+	for _, f := range gs.FloatingText {
+		age := time.Since(f.StartTime).Seconds()
+		c.alpha(max(0, 1-age))
+		c.text(f.Text, float64(f.X), float64(f.Y)-age*30, fontSmall, "center", colorTitle)
+	}
+	c.alpha(1)
+
+	// What's being heard
+	heard := "--"
+	if a.heard.IsValid() {
+		heard = a.heard.FullNoteName()
+	}
+	c.text("Playing: "+heard, 20, tabTop+tabHeight+40, fontBody, "left", colorDetectedNote)
+	c.text("Esc to stop", c.width-20, tabTop+tabHeight+40, fontSmall, "right", colorHint)
+}
+
+// drawResults shows how the song went
+func (a *App) drawResults() {
+	c := a.canvas
+	gs := a.state
+	cy := c.height / 2
+	c.text("Results", c.width/2, cy-110, fontTitle, "center", colorTitle)
+	c.text(gs.Grade(), c.width/2, cy-50, "bold 64px sans-serif", "center", colorHighlight)
+	c.text(fmt.Sprintf("Score: %d", gs.Score), c.width/2, cy+10, fontBody, "center", colorText)
+	c.text(fmt.Sprintf("Accuracy: %.1f%%  •  Max combo: %d", gs.Accuracy(), gs.MaxCombo), c.width/2, cy+40, fontBody, "center", colorText)
+	c.text(fmt.Sprintf("Hit %d  •  Missed %d of %d", gs.NotesHit, gs.NotesMissed, gs.TotalNotes), c.width/2, cy+70, fontBody, "center", colorText)
+	c.text("Enter to return to the menu", c.width/2, cy+120, fontSmall, "center", colorHint)
+}
+
+// noteColor returns a note's color by whether and how well it was hit
+func noteColor(note *song.TabNote) string {
+	if !note.Hit {
+		return colorNoteDefault
+	}
+	switch note.HitQuality {
+	case song.HitPerfect:
+		return colorNotePerfect
+	case song.HitGood:
+		return colorNoteGood
+	case song.HitOK:
+		return colorNoteOK
+	default:
+		return colorNoteMiss
+	}
+}
@@ -0,0 +1,14 @@
+module guitargame/apps/wasm
+
+go 1.25.5
+
+require guitargame/core v0.0.0
+
+require (
+	github.com/fsnotify/fsnotify v1.9.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace guitargame/core => ../../core
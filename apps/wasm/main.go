@@ -0,0 +1,280 @@
+//go:build js && wasm
+
+// Command wasm is the game in a web browser, for trying it without
+// installing PortAudio and aubio. Songs, scoring and hit detection come
+// from the core module as on the desktop; the microphone is read through
+// Web Audio, pitch is found by the core's YIN detector, and play is drawn
+// on a canvas. Build it with "just wasm build" and serve dist/.
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"syscall/js"
+
+	"guitargame/core/game"
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// Highway geometry in CSS pixels, as the desktop draws it
+const (
+	headerHeight  = 90
+	stringSpacing = 40
+	playLineX     = 0.75 // Fraction of the width from the left
+	pixelsPerBeat = 80
+	noteRadius    = 16
+)
+
+// Screen is which screen is showing
+type Screen int
+
+const (
+	ScreenMenu Screen = iota
+	ScreenPreStart
+	ScreenPlaying
+	ScreenResults
+)
+
+// App is the game running in the page
+type App struct {
+	canvas   *canvas
+	mic      micInput
+	detector *pitch.Detector
+	heard    pitch.Result
+
+	songs    []*song.Song
+	selected int
+	message  string // Shown on the menu, such as why a chart didn't load
+
+	screen Screen
+	state  *song.GameState
+	hits   *game.HitDetector
+
+	frame js.Func
+	files js.Value // File input for opening charts
+}
+
+func main() {
+	doc := js.Global().Get("document")
+	a := &App{
+		canvas: newCanvas(doc.Call("getElementById", "game")),
+		songs:  song.GetDefaultExercises(),
+		files:  doc.Call("getElementById", "chart-file"),
+	}
+	a.frame = js.FuncOf(a.drawFrame)
+	doc.Call("addEventListener", "keydown", js.FuncOf(a.handleKey))
+	doc.Call("addEventListener", "pointerdown", js.FuncOf(func(js.Value, []js.Value) any {
+		a.mic.start()
+		return nil
+	}))
+	a.files.Call("addEventListener", "change", js.FuncOf(a.openChart))
+	a.loadShippedSongs()
+
+	js.Global().Call("requestAnimationFrame", a.frame)
+	select {} // Everything else happens in callbacks
+}
+
+// loadShippedSongs adds the charts the build copied into songs/, listed
+// one to a line in songs/index.txt, after the built-in ones
+func (a *App) loadShippedSongs() {
+	fetchText("songs/index.txt", func(index string, err error) {
+		if err != nil {
+			return // Built without them
+		}
+		var names []string
+		for line := range strings.Lines(index) {
+			if name := strings.TrimSpace(line); name != "" {
+				names = append(names, name)
+			}
+		}
+		a.loadSongs(names)
+	})
+}
+
+// loadSongs fetches charts one after another, so they're listed in order
+func (a *App) loadSongs(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	fetchText("songs/"+names[0], func(data string, err error) {
+		if err == nil {
+			err = a.addChart(names[0], []byte(data))
+		}
+		if err != nil {
+			a.message = fmt.Sprintf("%s: %v", names[0], err)
+		}
+		a.loadSongs(names[1:])
+	})
+}
+
+// addChart parses a YAML or JSON chart and adds it to the menu
+func (a *App) addChart(name string, data []byte) error {
+	parse := song.ParseSong
+	if strings.EqualFold(path.Ext(name), ".json") {
+		parse = song.ParseSongJSON
+	}
+	s, err := parse(data)
+	if err != nil {
+		return err
+	}
+	a.songs = append(a.songs, s)
+	return nil
+}
+
+// openChart adds a chart the player picked from their own files, and
+// selects it
+func (a *App) openChart(this js.Value, args []js.Value) any {
+	list := a.files.Get("files")
+	if list.Length() == 0 {
+		return nil
+	}
+	file := list.Index(0)
+	name := file.Get("name").String()
+	await(file.Call("text"), func(v js.Value, err error) {
+		if err == nil {
+			err = a.addChart(name, []byte(v.String()))
+		}
+		if err != nil {
+			a.message = fmt.Sprintf("%s: %v", name, err)
+			return
+		}
+		a.message = ""
+		a.selected = len(a.songs) - 1
+	})
+	a.files.Set("value", "")
+	return nil
+}
+
+// handleKey handles a key press. The first also turns the microphone on,
+// as browsers only allow that in answer to the player.
+func (a *App) handleKey(this js.Value, args []js.Value) any {
+	a.mic.start()
+	e := args[0]
+	switch a.screen {
+	case ScreenMenu:
+		switch e.Get("key").String() {
+		case "ArrowUp":
+			a.selected = (a.selected - 1 + len(a.songs)) % len(a.songs)
+		case "ArrowDown":
+			a.selected = (a.selected + 1) % len(a.songs)
+		case "Enter":
+			a.screen = ScreenPreStart
+		case "o", "O":
+			a.files.Call("click")
+		default:
+			return nil
+		}
+	case ScreenPreStart:
+		switch e.Get("key").String() {
+		case "Enter", " ":
+			a.startGame()
+		case "Escape":
+			a.screen = ScreenMenu
+		default:
+			return nil
+		}
+	case ScreenPlaying, ScreenResults:
+		switch e.Get("key").String() {
+		case "Escape", "Enter":
+			a.screen = ScreenMenu
+		default:
+			return nil
+		}
+	}
+	e.Call("preventDefault")
+	return nil
+}
+
+// startGame plays the selected song from the top
+func (a *App) startGame() {
+	s := a.songs[a.selected]
+	a.state = song.NewGameState(s)
+	a.hits = game.NewHitDetector(a.state, func(str int) float32 {
+		return float32(headerHeight + str*stringSpacing + stringSpacing/2)
+	})
+	if a.detector != nil {
+		a.detector.SetRange(s.FrequencyRange())
+	}
+	a.state.Start()
+	a.screen = ScreenPlaying
+}
+
+// drawFrame reads the microphone, moves play on and draws, once for each
+// frame the browser draws
+func (a *App) drawFrame(this js.Value, args []js.Value) any {
+	defer js.Global().Call("requestAnimationFrame", a.frame)
+
+	if samples := a.mic.read(); samples != nil {
+		if a.detector == nil {
+			a.detector = pitch.NewDetector(a.mic.sampleRate())
+		}
+		a.heard = a.detector.Detect(samples)
+	}
+
+	a.canvas.resize()
+	a.canvas.fill(colorBackground)
+	switch a.screen {
+	case ScreenMenu:
+		a.drawMenu()
+	case ScreenPreStart:
+		if a.heard.IsValid() {
+			a.startGame()
+			break
+		}
+		a.drawPreStart()
+	case ScreenPlaying:
+		a.state.Update()
+		a.hits.CheckHit(a.heard, float32(a.canvas.width*playLineX))
+		a.hits.Update()
+		if a.state.IsFinished {
+			a.screen = ScreenResults
+		}
+		a.drawGame()
+	case ScreenResults:
+		a.drawResults()
+	}
+	return nil
+}
+
+// fetchText fetches a URL's body as text
+func fetchText(url string, done func(string, error)) {
+	await(js.Global().Call("fetch", url), func(resp js.Value, err error) {
+		if err != nil {
+			done("", err)
+			return
+		}
+		if !resp.Get("ok").Bool() {
+			done("", fmt.Errorf("%s: %s", url, resp.Get("statusText").String()))
+			return
+		}
+		await(resp.Call("text"), func(text js.Value, err error) {
+			if err != nil {
+				done("", err)
+				return
+			}
+			done(text.String(), nil)
+		})
+	})
+}
+
+// await calls done with what a promise resolves to, or why it failed
+func await(promise js.Value, done func(js.Value, error)) {
+	var then, catch js.Func
+	release := func() {
+		then.Release()
+		catch.Release()
+	}
+	then = js.FuncOf(func(this js.Value, args []js.Value) any {
+		release()
+		done(args[0], nil)
+		return nil
+	})
+	catch = js.FuncOf(func(this js.Value, args []js.Value) any {
+		release()
+		done(js.Undefined(), fmt.Errorf("%s", args[0].Call("toString").String()))
+		return nil
+	})
+	promise.Call("then", then).Call("catch", catch)
+}
@@ -1,7 +1,7 @@
 /**
  * Song, tablature, and tuning types.
  *
- * Modeled after the Go types in apps/desktop/internal/song/types.go
+ * Modeled after the Go types in core/song/types.go
  */
 
 import type { BPM, Seconds } from './branded.js';
@@ -0,0 +1,38 @@
+package game
+
+import "sort"
+
+// Ghost is how a run's score built up over the song, kept from a best run
+// to race against on later plays
+type Ghost struct {
+	Score  int          `json:"score"`
+	Points []GhostPoint `json:"points"`
+}
+
+// GhostPoint is the score a run had reached by a song time
+type GhostPoint struct {
+	Time  float64 `json:"time"`
+	Score int     `json:"score"`
+}
+
+// Ghost returns how the recorded run's score built up
+func (r *Recording) Ghost() *Ghost {
+	g := &Ghost{Score: r.game.Score}
+	for _, j := range r.Judged {
+		if n := len(g.Points); n > 0 && g.Points[n-1].Time == j.Time {
+			g.Points[n-1].Score = j.Score
+			continue
+		}
+		g.Points = append(g.Points, GhostPoint{Time: j.Time, Score: j.Score})
+	}
+	return g
+}
+
+// ScoreAt returns the score the ghost had reached by a song time
+func (g *Ghost) ScoreAt(t float64) int {
+	i := sort.Search(len(g.Points), func(i int) bool { return g.Points[i].Time > t })
+	if i == 0 {
+		return 0
+	}
+	return g.Points[i-1].Score
+}
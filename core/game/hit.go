@@ -0,0 +1,239 @@
+// Package game judges played notes against a chart, turning pitch
+// readings into hits and misses on the song's game state
+package game
+
+import (
+	"math"
+
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// Timing windows in seconds
+const (
+	PerfectWindow = 0.050 // ±50ms
+	GoodWindow    = 0.100 // ±100ms
+	OKWindow      = 0.150 // ±150ms
+	MissWindow    = 0.300 // After 300ms, note is missed
+)
+
+// Timing window presets, from forgiving to strict
+var (
+	WindowsEasy   = song.TimingWindows{Perfect: 0.075, Good: 0.150, OK: 0.225}
+	WindowsNormal = song.TimingWindows{Perfect: PerfectWindow, Good: GoodWindow, OK: OKWindow}
+	WindowsHard   = song.TimingWindows{Perfect: 0.030, Good: 0.060, OK: 0.100}
+)
+
+// PresetName names the preset a set of windows matches, or "Custom"
+func PresetName(w song.TimingWindows) string {
+	switch w {
+	case WindowsEasy:
+		return "Easy"
+	case WindowsNormal, song.TimingWindows{}:
+		return "Normal"
+	case WindowsHard:
+		return "Hard"
+	default:
+		return "Custom"
+	}
+}
+
+// ClampWindows keeps custom windows in order, each no wider than the
+// next, and all inside MissWindow
+func ClampWindows(w song.TimingWindows) song.TimingWindows {
+	w.OK = math.Max(0, math.Min(w.OK, MissWindow))
+	w.Good = math.Max(0, math.Min(w.Good, w.OK))
+	w.Perfect = math.Max(0, math.Min(w.Perfect, w.Good))
+	return w
+}
+
+// OpenStringBrightness is the pitch.HarmonicBrightness above which a note is
+// taken to be played on an open string. It's a rough guide: open strings
+// ring with stronger upper harmonics than fretted notes, but pickups and
+// playing style move the line.
+const OpenStringBrightness = 2.2
+
+// wrongNoteReadings is how many readings in a row a note must be heard
+// before it can count as a wrong note, so the wobble at the start of a
+// note isn't penalized
+const wrongNoteReadings = 3
+
+// HitDetector handles matching played notes to expected notes
+type HitDetector struct {
+	state *song.GameState
+
+	// stringY gives the screen height of a string, where hit feedback is shown
+	stringY func(str int) float32
+
+	// The note being heard, for how many readings, and whether it has
+	// been scored yet, so a ringing note is only judged once
+	heard         string
+	heardReadings int
+	heardJudged   bool
+
+	// Level of the last reading, to find onsets in rhythm-only play
+	lastRMS float64
+}
+
+// NewHitDetector creates a new hit detector that places hit feedback
+// using the renderer's string positions
+func NewHitDetector(state *song.GameState, stringY func(str int) float32) *HitDetector {
+	return &HitDetector{state: state, stringY: stringY}
+}
+
+// CheckHit checks if the detected pitch matches any pending note
+func (h *HitDetector) CheckHit(detected pitch.Result, playLineX float32) {
+	if h.state.RhythmOnly {
+		h.checkOnset(detected, playLineX)
+		return
+	}
+	if !detected.IsValid() {
+		h.heard, h.heardReadings = "", 0
+		return
+	}
+	if name := detected.FullNoteName(); name != h.heard {
+		h.heard, h.heardReadings, h.heardJudged = name, 0, false
+	}
+	h.heardReadings++
+
+	currentTime := h.state.JudgedTime()
+
+	// Find notes within the hit window
+	for i := range h.state.Song.Notes {
+		note := &h.state.Song.Notes[i]
+
+		// Skip already hit notes, and notes outside a looped passage
+		if note.Hit || !h.state.InPlay(note) {
+			continue
+		}
+
+		// Check if note is within timing window
+		timeDiff := note.Time - currentTime
+		absTimeDiff := math.Abs(timeDiff)
+
+		// Note is too far in the future
+		if timeDiff > MissWindow {
+			continue
+		}
+
+		// Note was missed (too far in the past)
+		if timeDiff < -MissWindow {
+			// Mark as missed
+			h.state.RegisterHit(note, song.HitMiss, playLineX, h.stringY(note.String))
+			continue
+		}
+
+		// Check if the played note matches
+		if cents, ok := h.notesMatch(detected, note); ok {
+			quality := h.getHitQuality(absTimeDiff)
+			// Fretless play scores intonation through the points instead
+			if !h.state.Fretless {
+				quality = min(quality, song.IntonationQuality(cents))
+			}
+			note.HitCents = cents
+			h.state.RegisterHit(note, quality, playLineX, h.stringY(note.String))
+			h.heardJudged = true
+			return // Only hit one note per detection
+		}
+	}
+
+	if h.state.WrongNotePenalty && !h.heardJudged && h.heardReadings >= wrongNoteReadings {
+		h.heardJudged = true
+		h.checkWrongNote(detected, playLineX)
+	}
+}
+
+// checkWrongNote penalizes a note that matched nothing, unless it's the
+// next note in another octave: the detector slipping an octave isn't the
+// player's mistake
+func (h *HitDetector) checkWrongNote(detected pitch.Result, playLineX float32) {
+	y := h.stringY(0)
+	if note := h.GetExpectedNote(); note != nil {
+		cents := h.centsFrom(detected, note)
+		if math.Abs(math.Remainder(cents, 1200)) < song.SnapCents {
+			return
+		}
+		y = h.stringY(note.String)
+	}
+	h.state.RegisterWrongNote(playLineX, y)
+}
+
+// notesMatch checks if the detected pitch matches the expected note,
+// returning how many cents off it was
+func (h *HitDetector) notesMatch(detected pitch.Result, note *song.TabNote) (float64, bool) {
+	centsDiff := h.centsFrom(detected, note)
+
+	// Fretted play allows ±50 cents (half a semitone); fretless play
+	// allows more and lets the score reflect intonation instead
+	window := float64(song.SnapCents)
+	if h.state.Fretless {
+		window = song.FretlessCents
+	}
+	if math.Abs(centsDiff) >= window {
+		return centsDiff, false
+	}
+	return centsDiff, !h.wrongOpenString(detected, note)
+}
+
+// wrongOpenString reports whether a note written open was played fretted
+// on another string, or the reverse, when that isn't allowed
+func (h *HitDetector) wrongOpenString(detected pitch.Result, note *song.TabNote) bool {
+	strict := h.state.StrictOpenStrings
+	if note.Substitute != nil {
+		strict = !*note.Substitute
+	}
+	if !strict || !h.state.Song.HasOpenEquivalent(note) {
+		return false
+	}
+	soundsOpen := detected.Brightness >= OpenStringBrightness
+	return soundsOpen != (note.Fret == 0)
+}
+
+// centsFrom returns how far the detected pitch is from a note, in cents
+func (h *HitDetector) centsFrom(detected pitch.Result, note *song.TabNote) float64 {
+	// Use the song's tuning, capo, and transposition to determine the expected pitch
+	expectedFreq := h.state.Song.FrequencyAt(note)
+	return 1200 * math.Log2(detected.Frequency/expectedFreq)
+}
+
+// CentsFromExpected returns how far the detected pitch is from the next
+// note to play, for the intonation meter
+func (h *HitDetector) CentsFromExpected(detected pitch.Result) (float64, bool) {
+	note := h.GetExpectedNote()
+	if note == nil || !detected.IsValid() {
+		return 0, false
+	}
+	return h.centsFrom(detected, note), true
+}
+
+// getHitQuality determines hit quality based on timing
+func (h *HitDetector) getHitQuality(absTimeDiff float64) song.HitQuality {
+	w := h.state.Windows
+	if w == (song.TimingWindows{}) {
+		w = WindowsNormal
+	}
+	return w.Judge(absTimeDiff)
+}
+
+// Update checks for missed notes
+func (h *HitDetector) Update() {
+	currentTime := h.state.JudgedTime()
+
+	for i := range h.state.Song.Notes {
+		note := &h.state.Song.Notes[i]
+
+		if note.Hit || !h.state.InPlay(note) {
+			continue
+		}
+
+		// Check if note was missed
+		if currentTime-note.Time > MissWindow {
+			h.state.RegisterHit(note, song.HitMiss, 0, h.stringY(note.String))
+		}
+	}
+}
+
+// GetExpectedNote returns the next note the player should play
+func (h *HitDetector) GetExpectedNote() *song.TabNote {
+	return h.state.Song.NextUnhitNote(h.state.CurrentTime)
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// QuizMaxFret is the highest fret the fretboard quiz asks for
+const QuizMaxFret = 12
+
+// quizReadings is how many readings in a row a note must be heard before
+// it's taken as the answer, so passing through other notes isn't judged
+const quizReadings = 3
+
+// quizNotes are the note names the quiz asks for
+var quizNotes = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+// Quiz asks for notes by name on a string, with no tab to read, and
+// times how long each takes to find
+type Quiz struct {
+	Tuning  song.Tuning
+	Prompt  QuizPrompt
+	Answers []QuizAnswer
+	Heard   string // Last wrong note heard for the prompt, if any
+
+	rng      *rand.Rand
+	asked    time.Time
+	tries    int
+	reading  int // MIDI note being heard
+	readings int
+	judged   bool // The note being heard has been judged
+}
+
+// QuizPrompt is a note to find on a string
+type QuizPrompt struct {
+	String int // Index into the tuning
+	Note   string
+}
+
+// QuizAnswer is how a prompt was answered
+type QuizAnswer struct {
+	QuizPrompt
+	Time  time.Duration // From the prompt to the right note
+	Tries int           // Wrong notes played first
+}
+
+// NewQuiz starts a quiz on an instrument's strings
+func NewQuiz(tuning song.Tuning, seed int64, now time.Time) *Quiz {
+	q := &Quiz{Tuning: tuning, rng: rand.New(rand.NewSource(seed))}
+	q.next(now)
+	return q
+}
+
+// StringName names a string by its open note, with the octave when
+// another string has the same note
+func (q *Quiz) StringName(str int) string {
+	s := q.Tuning[str]
+	for i, other := range q.Tuning {
+		if i != str && other.Note == s.Note {
+			return fmt.Sprintf("%s%d", s.Note, s.Octave)
+		}
+	}
+	return s.Note
+}
+
+// next picks a new prompt, never the same as the last
+func (q *Quiz) next(now time.Time) {
+	last := q.Prompt
+	for q.Prompt == last {
+		q.Prompt = QuizPrompt{
+			String: q.rng.Intn(len(q.Tuning)),
+			Note:   quizNotes[q.rng.Intn(len(quizNotes))],
+		}
+	}
+	q.asked, q.tries, q.Heard = now, 0, ""
+	q.judged = true // Whatever is still ringing was for the last prompt
+}
+
+// Check judges a pitch reading against the prompt, moving on to the next
+// once the right note is played. It returns whether the reading answered
+// the prompt.
+func (q *Quiz) Check(p pitch.Result, now time.Time) bool {
+	if !p.IsValid() {
+		q.readings, q.judged = 0, false
+		return false
+	}
+	midi := int(math.Round(12*math.Log2(p.Frequency/440) + 69))
+	if midi != q.reading {
+		q.reading, q.readings, q.judged = midi, 0, false
+	}
+	q.readings++
+	if q.judged || q.readings < quizReadings {
+		return false
+	}
+	q.judged = true
+
+	if !q.onString(midi, p.Brightness) {
+		q.tries++
+		q.Heard = p.NoteName()
+		return false
+	}
+	q.Answers = append(q.Answers, QuizAnswer{QuizPrompt: q.Prompt, Time: now.Sub(q.asked), Tries: q.tries})
+	q.next(now)
+	return true
+}
+
+// onString reports whether a note is the prompt's note played on its
+// string. Which string a pitch was played on can only be estimated: an
+// open string rings brighter than the same pitch fretted, so a bright
+// note that's open on another string is taken to have been played there.
+func (q *Quiz) onString(midi int, brightness float64) bool {
+	open := q.Tuning[q.Prompt.String].MIDINote()
+	fret := midi - open
+	if fret < 0 || fret > QuizMaxFret || quizNotes[midi%12] != q.Prompt.Note {
+		return false
+	}
+	if fret == 0 {
+		return true
+	}
+	for _, pos := range q.Tuning.Positions(midi, QuizMaxFret) {
+		if pos.Fret == 0 && brightness >= OpenStringBrightness {
+			return false
+		}
+	}
+	return true
+}
+
+// Waiting returns how long the current prompt has been waiting
+func (q *Quiz) Waiting(now time.Time) time.Duration {
+	return now.Sub(q.asked)
+}
+
+// QuizNoteTime is the average time to find one note on one string
+type QuizNoteTime struct {
+	QuizPrompt
+	Average time.Duration
+	Count   int
+}
+
+// NoteTimes averages the response times of each note asked, slowest first
+func (q *Quiz) NoteTimes() []QuizNoteTime {
+	var times []QuizNoteTime
+	index := make(map[QuizPrompt]int)
+	for _, a := range q.Answers {
+		i, ok := index[a.QuizPrompt]
+		if !ok {
+			i = len(times)
+			index[a.QuizPrompt] = i
+			times = append(times, QuizNoteTime{QuizPrompt: a.QuizPrompt})
+		}
+		t := &times[i]
+		t.Average = (t.Average*time.Duration(t.Count) + a.Time) / time.Duration(t.Count+1)
+		t.Count++
+	}
+	sort.SliceStable(times, func(i, j int) bool { return times[i].Average > times[j].Average })
+	return times
+}
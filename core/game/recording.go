@@ -0,0 +1,106 @@
+package game
+
+import (
+	"sort"
+
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// Recording keeps what was heard and how each note was judged during a
+// run, so the run can be played back afterwards
+type Recording struct {
+	Frames []Frame
+	Judged []Judgement
+
+	game   *song.GameState
+	judged []bool // Notes already recorded as judged
+}
+
+// Frame is one pitch reading, at a song time
+type Frame struct {
+	Time  float64
+	Pitch pitch.Result
+}
+
+// Judgement is a note being hit or missed, at a song time
+type Judgement struct {
+	Time    float64
+	Note    int // Index into the song's notes
+	Quality song.HitQuality
+	HitTime float64
+	Cents   float64
+	Score   int // The run's score once the note was judged
+}
+
+// NewRecording starts recording a run of a game
+func NewRecording(g *song.GameState) *Recording {
+	return &Recording{game: g, judged: make([]bool, len(g.Song.Notes))}
+}
+
+// Of reports whether this is the recording of a game
+func (r *Recording) Of(g *song.GameState) bool {
+	return r.game == g
+}
+
+// Capture records a pitch reading and any notes judged since the last
+// one. Call it once per update, after hits are checked.
+func (r *Recording) Capture(p pitch.Result) {
+	g := r.game
+	r.Frames = append(r.Frames, Frame{Time: g.CurrentTime, Pitch: p})
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if !note.Hit || i >= len(r.judged) || r.judged[i] {
+			continue
+		}
+		r.judged[i] = true
+		r.Judged = append(r.Judged, Judgement{
+			Time:    g.CurrentTime,
+			Note:    i,
+			Quality: note.HitQuality,
+			HitTime: note.HitTime,
+			Cents:   note.HitCents,
+			Score:   g.Score,
+		})
+	}
+}
+
+// Duration returns the song time of the last frame
+func (r *Recording) Duration() float64 {
+	if len(r.Frames) == 0 {
+		return 0
+	}
+	return r.Frames[len(r.Frames)-1].Time
+}
+
+// FrameAt returns the index of the last frame at or before a song time
+func (r *Recording) FrameAt(t float64) int {
+	i := sort.Search(len(r.Frames), func(i int) bool { return r.Frames[i].Time > t })
+	return max(0, i-1)
+}
+
+// PitchAt returns what was heard at a song time
+func (r *Recording) PitchAt(t float64) pitch.Result {
+	if len(r.Frames) == 0 {
+		return pitch.Result{}
+	}
+	return r.Frames[r.FrameAt(t)].Pitch
+}
+
+// Rewind sets the game's notes to how they had been judged by a song
+// time, so the chart can be drawn as it was at that moment
+func (r *Recording) Rewind(t float64) {
+	g := r.game
+	g.Song.ResetProgress()
+	for _, j := range r.Judged {
+		if j.Time > t {
+			break
+		}
+		note := &g.Song.Notes[j.Note]
+		note.Hit = true
+		note.HitQuality = j.Quality
+		note.HitTime = j.HitTime
+		note.HitCents = j.Cents
+	}
+	g.CurrentTime = t
+}
@@ -0,0 +1,48 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"guitargame/core/song"
+)
+
+// NoteResult is how one note of a recorded run was played
+type NoteResult struct {
+	Time     float64 `json:"time"`   // Song time of the note, in seconds
+	String   int     `json:"string"` // Counted from 1, highest first, as in tab
+	Fret     int     `json:"fret"`
+	Expected string  `json:"expected"`  // Note the chart asked for, e.g. "A1"
+	Detected string  `json:"detected"`  // Note heard when it was judged; empty if nothing was
+	OffsetMs float64 `json:"offset_ms"` // How late it was hit (negative is early); 0 for misses
+	Cents    float64 `json:"cents"`     // How far the pitch played was from the note
+	Quality  string  `json:"quality"`
+}
+
+// Results returns how each note judged during the recording was played,
+// in song order
+func (r *Recording) Results() []NoteResult {
+	s := r.game.Song
+	results := make([]NoteResult, 0, len(r.Judged))
+	for _, j := range r.Judged {
+		note := &s.Notes[j.Note]
+		res := NoteResult{
+			Time:     note.Time,
+			String:   note.String + 1,
+			Fret:     note.Fret,
+			Expected: fmt.Sprintf("%s%d", s.NoteAt(note), s.OctaveAt(note)),
+			Quality:  j.Quality.String(),
+		}
+		if heard := r.PitchAt(j.Time); heard.IsValid() {
+			res.Detected = heard.FullNoteName()
+		}
+		if j.Quality != song.HitMiss {
+			res.OffsetMs = math.Round((j.HitTime-note.Time)*1000*10) / 10
+			res.Cents = math.Round(j.Cents*10) / 10
+		}
+		results = append(results, res)
+	}
+	sort.SliceStable(results, func(i, k int) bool { return results[i].Time < results[k].Time })
+	return results
+}
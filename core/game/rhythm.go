@@ -0,0 +1,50 @@
+package game
+
+import (
+	"math"
+
+	"guitargame/core/pitch"
+	"guitargame/core/song"
+)
+
+// Onsets in rhythm-only play: the input must reach onsetLevel (where the
+// pitch detector's confidence passes a half) having risen by onsetRise
+// since the last reading, so a ringing note isn't counted again
+const (
+	onsetLevel = 0.01 // RMS
+	onsetRise  = 2.0
+)
+
+// IsOnset reports whether a reading is a note being played, rather than
+// one ringing on, given the level of the reading before
+func IsOnset(detected pitch.Result, lastRMS float64) bool {
+	return detected.RMS >= onsetLevel && detected.RMS >= lastRMS*onsetRise
+}
+
+// checkOnset judges rhythm-only play, where any confident onset hits the
+// next note due whatever its pitch
+func (h *HitDetector) checkOnset(detected pitch.Result, playLineX float32) {
+	onset := IsOnset(detected, h.lastRMS)
+	h.lastRMS = detected.RMS
+	if !onset {
+		return
+	}
+
+	currentTime := h.state.JudgedTime()
+	for i := range h.state.Song.Notes {
+		note := &h.state.Song.Notes[i]
+		if note.Hit || !h.state.InPlay(note) {
+			continue
+		}
+		timeDiff := note.Time - currentTime
+		if timeDiff > MissWindow {
+			return
+		}
+		if timeDiff < -MissWindow {
+			h.state.RegisterHit(note, song.HitMiss, playLineX, h.stringY(note.String))
+			continue
+		}
+		h.state.RegisterHit(note, h.getHitQuality(math.Abs(timeDiff)), playLineX, h.stringY(note.String))
+		return
+	}
+}
@@ -0,0 +1,11 @@
+module guitargame/core
+
+go 1.25.5
+
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	golang.org/x/text v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.36.0 // indirect
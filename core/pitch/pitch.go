@@ -0,0 +1,93 @@
+// Package pitch describes detected pitches and converts between
+// frequencies and note names. It does no audio input itself, so any
+// frontend can feed it results from its own pitch detector.
+package pitch
+
+import "math"
+
+var noteNames = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+// Result is one reading from a pitch detector
+type Result struct {
+	Frequency  float64
+	Confidence float64
+	Note       string
+	Octave     int
+	Cents      int
+	RMS        float64
+	Brightness float64 // See HarmonicBrightness
+}
+
+// NewResult fills in the note name, octave, and cents for a frequency
+func NewResult(freq, confidence, rms, brightness float64) Result {
+	note, octave, cents := FrequencyToNote(freq)
+	return Result{
+		Frequency:  freq,
+		Confidence: confidence,
+		Note:       note,
+		Octave:     octave,
+		Cents:      cents,
+		RMS:        rms,
+		Brightness: brightness,
+	}
+}
+
+// FrequencyToNote returns the nearest note to a frequency, its octave,
+// and how many cents off it the frequency is. Frequencies outside
+// 20 Hz-5 kHz have no note.
+func FrequencyToNote(freq float64) (string, int, int) {
+	if freq < 20 || freq > 5000 {
+		return "", 0, 0
+	}
+
+	midiNote := 12*math.Log2(freq/440) + 69
+	noteNum := int(math.Round(midiNote))
+	cents := int((midiNote - float64(noteNum)) * 100)
+
+	name := noteNames[((noteNum%12)+12)%12]
+	octave := (noteNum / 12) - 1
+
+	return name, octave, cents
+}
+
+// NoteToFrequency returns the frequency of a note in an octave, or 0 for
+// an unknown note name
+func NoteToFrequency(note string, octave int) float64 {
+	noteIndex := -1
+	for i, n := range noteNames {
+		if n == note {
+			noteIndex = i
+			break
+		}
+	}
+	if noteIndex == -1 {
+		return 0
+	}
+
+	midiNote := (octave+1)*12 + noteIndex
+	return 440 * math.Pow(2, float64(midiNote-69)/12)
+}
+
+func (r Result) NoteName() string {
+	if r.Note == "" {
+		return "--"
+	}
+	return r.Note
+}
+
+func (r Result) FullNoteName() string {
+	if r.Note == "" {
+		return "--"
+	}
+	return r.Note + string(rune('0'+r.Octave))
+}
+
+func (r Result) IsValid() bool {
+	return r.Note != "" && r.Confidence > 0.5
+}
+
+// MIDINote returns the MIDI note number of the nearest note, as named by
+// Note and Octave
+func (r Result) MIDINote() int {
+	return int(math.Round(12*math.Log2(r.Frequency/440) + 69))
+}
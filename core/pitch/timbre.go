@@ -0,0 +1,42 @@
+package pitch
+
+import "math"
+
+// brightnessHarmonics is how many harmonics HarmonicBrightness weighs
+const brightnessHarmonics = 8
+
+// HarmonicBrightness measures how much of a note's sound is in its upper
+// harmonics, as the amplitude-weighted mean harmonic number of the first
+// few harmonics: 1 for a pure tone, higher for brighter sounds. An open
+// string rings brighter than the same pitch fretted on a thicker string.
+func HarmonicBrightness(samples []float32, sampleRate, freq float64) float64 {
+	if freq <= 0 || len(samples) == 0 {
+		return 0
+	}
+	var weighted, total float64
+	for h := 1; h <= brightnessHarmonics; h++ {
+		f := freq * float64(h)
+		if f >= sampleRate/2 {
+			break
+		}
+		amp := goertzel(samples, sampleRate, f)
+		weighted += float64(h) * amp
+		total += amp
+	}
+	if total == 0 {
+		return 0
+	}
+	return weighted / total
+}
+
+// goertzel returns the amplitude of one frequency in the samples
+func goertzel(samples []float32, sampleRate, freq float64) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*freq/sampleRate)
+	var s1, s2 float64
+	for _, x := range samples {
+		s0 := float64(x) + coeff*s1 - s2
+		s2, s1 = s1, s0
+	}
+	power := s1*s1 + s2*s2 - coeff*s1*s2
+	return math.Sqrt(math.Max(power, 0)) / float64(len(samples))
+}
@@ -0,0 +1,119 @@
+package pitch
+
+import "math"
+
+// Range a Detector listens in until SetRange picks an instrument's, as
+// the desktop's detector does
+const (
+	DefaultMinFrequency = 20
+	DefaultMaxFrequency = 1000
+)
+
+// yinThreshold is how aperiodic a period can be and still be taken as the
+// pitch; lower is stricter
+const yinThreshold = 0.15
+
+// Detector finds the pitch of audio with the YIN algorithm. It's plain
+// Go, for frontends without a native detector such as the browser build.
+// Half of each buffer is compared against itself shifted, so the lowest
+// pitch it can find has a period of half the buffer: 4096 samples reach a
+// bass's low E at 48 kHz.
+type Detector struct {
+	sampleRate       float64
+	minFreq, maxFreq float64
+	diff             []float64 // Reused between calls
+}
+
+// NewDetector creates a detector for audio at a sample rate in Hz
+func NewDetector(sampleRate float64) *Detector {
+	return &Detector{sampleRate: sampleRate, minFreq: DefaultMinFrequency, maxFreq: DefaultMaxFrequency}
+}
+
+// SetRange limits detection to an instrument's frequency range in Hz
+func (d *Detector) SetRange(minFreq, maxFreq float64) {
+	d.minFreq, d.maxFreq = minFreq, maxFreq
+}
+
+// Detect reads the pitch of a buffer of samples. Confidence follows the
+// level, as the desktop's detector's does, and is 0 when no pitch in
+// range was found.
+func (d *Detector) Detect(samples []float32) Result {
+	rms := RMS(samples)
+	freq := d.yin(samples)
+	conf := 0.0
+	if freq >= d.minFreq && freq <= d.maxFreq && rms >= 0.001 {
+		conf = math.Min(rms*50, 1)
+	}
+	return NewResult(freq, conf, rms, HarmonicBrightness(samples, d.sampleRate, freq))
+}
+
+// yin returns the frequency of the strongest period in the samples, or 0
+// if none is clear enough
+func (d *Detector) yin(samples []float32) float64 {
+	window := len(samples) / 2
+	minTau := max(2, int(d.sampleRate/d.maxFreq))
+	maxTau := min(window, int(d.sampleRate/d.minFreq)+1)
+	if minTau >= maxTau {
+		return 0
+	}
+	if cap(d.diff) < maxTau {
+		d.diff = make([]float64, maxTau)
+	}
+	diff := d.diff[:maxTau]
+
+	// Difference of the window against itself shifted by each period,
+	// then normalized by its running mean so short periods aren't favored
+	diff[0] = 1
+	var sum float64
+	for tau := 1; tau < maxTau; tau++ {
+		var dt float64
+		for j := range window {
+			delta := float64(samples[j]) - float64(samples[j+tau])
+			dt += delta * delta
+		}
+		sum += dt
+		if sum == 0 {
+			diff[tau] = 1
+		} else {
+			diff[tau] = dt * float64(tau) / sum
+		}
+	}
+
+	// The first dip below the threshold, followed to its bottom
+	for tau := minTau; tau < maxTau; tau++ {
+		if diff[tau] >= yinThreshold {
+			continue
+		}
+		for tau+1 < maxTau && diff[tau+1] < diff[tau] {
+			tau++
+		}
+		return d.sampleRate / interpolate(diff, tau)
+	}
+	return 0
+}
+
+// interpolate refines a period to between samples by fitting a parabola
+// through it and its neighbours
+func interpolate(diff []float64, tau int) float64 {
+	if tau < 1 || tau+1 >= len(diff) {
+		return float64(tau)
+	}
+	a, b, c := diff[tau-1], diff[tau], diff[tau+1]
+	denom := a - 2*b + c
+	if denom == 0 {
+		return float64(tau)
+	}
+	return float64(tau) + (a-c)/(2*denom)
+}
+
+// RMS returns the root mean square level of the samples
+func RMS(samples []float32) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
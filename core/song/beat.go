@@ -0,0 +1,124 @@
+package song
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Beat is a position in beats. Charts can write it as a number (4.5) or
+// as a whole beat plus a fraction ("4+2/3"), so triplets and other odd
+// subdivisions land exactly instead of on a rounded decimal.
+type Beat float64
+
+// fractionDenominators are the subdivisions written as fractions when a
+// chart is saved; halves, quarters and the like are exact as decimals
+var fractionDenominators = []int{3, 5, 6, 7, 9, 12, 24}
+
+// fractionEpsilon is how close a beat must be to a fraction to be saved as one
+const fractionEpsilon = 1e-6
+
+// ParseBeat reads a beat written as a number, a fraction ("2/3"), or a
+// whole number plus a fraction ("4+2/3")
+func ParseBeat(text string) (Beat, error) {
+	text = strings.ReplaceAll(text, " ", "")
+	whole, frac, hasFrac := strings.Cut(text, "+")
+	if !hasFrac && strings.Contains(whole, "/") {
+		whole, frac, hasFrac = "0", whole, true
+	}
+
+	w, err := strconv.ParseFloat(whole, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid beat %q", text)
+	}
+	if !hasFrac {
+		return Beat(w), nil
+	}
+	num, den, ok := strings.Cut(frac, "/")
+	n, errN := strconv.Atoi(num)
+	d, errD := strconv.Atoi(den)
+	if !ok || errN != nil || errD != nil || n < 0 || d <= 0 {
+		return 0, fmt.Errorf("invalid beat %q: fraction must be like 2/3", text)
+	}
+	return Beat(w + float64(n)/float64(d)), nil
+}
+
+// String writes the beat the way a chart would: a fraction for triplets
+// and other odd subdivisions, otherwise a number rounded to a thousandth
+func (b Beat) String() string {
+	v := float64(b)
+	whole := math.Floor(v)
+	frac := v - whole
+	if frac > fractionEpsilon && frac < 1-fractionEpsilon {
+		for _, d := range fractionDenominators {
+			n := math.Round(frac * float64(d))
+			if math.Abs(frac*float64(d)-n) < fractionEpsilon*float64(d) {
+				// Halves, quarters, and eighths are left to the decimal form
+				if g := gcd(int(n), d); d/g%3 != 0 && d/g%5 != 0 && d/g%7 != 0 {
+					break
+				}
+				if whole == 0 {
+					return fmt.Sprintf("%d/%d", int(n), d)
+				}
+				return fmt.Sprintf("%s+%d/%d", strconv.FormatFloat(whole, 'f', -1, 64), int(n), d)
+			}
+		}
+	}
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// isFraction reports whether the beat is saved in its fraction form
+func (b Beat) isFraction() bool {
+	return strings.Contains(b.String(), "/")
+}
+
+// UnmarshalYAML accepts a number or a fraction string
+func (b *Beat) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := ParseBeat(value.Value)
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+// MarshalYAML writes fractions as strings and other beats as numbers
+func (b Beat) MarshalYAML() (any, error) {
+	if b.isFraction() {
+		return b.String(), nil
+	}
+	return strconv.ParseFloat(b.String(), 64)
+}
+
+// UnmarshalJSON accepts a number or a fraction string
+func (b *Beat) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		text = string(data)
+	}
+	parsed, err := ParseBeat(text)
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+// MarshalJSON writes fractions as strings and other beats as numbers
+func (b Beat) MarshalJSON() ([]byte, error) {
+	if b.isFraction() {
+		return json.Marshal(b.String())
+	}
+	return []byte(b.String()), nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
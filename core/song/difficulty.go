@@ -0,0 +1,30 @@
+package song
+
+// MaxDifficulty is the hardest a song rates
+const MaxDifficulty = 5
+
+// difficultyRates are the notes per second at which a song rates 2, 3, 4
+// and 5: roughly quarter notes, eighths and sixteenths at moderate tempos
+var difficultyRates = []float64{1, 2, 3.5, 5}
+
+// Difficulty rates how hard the song is from 1 to MaxDifficulty by how
+// many notes a second it asks for, measured from its first note to its
+// last. Charts don't carry a rating of their own, so this is what search
+// filters by.
+func (s *Song) Difficulty() int {
+	if len(s.Notes) < 2 {
+		return 1
+	}
+	span := s.Notes[len(s.Notes)-1].Time - s.Notes[0].Time
+	if span <= 0 {
+		return MaxDifficulty
+	}
+	rate := float64(len(s.Notes)-1) / span
+	level := 1
+	for _, r := range difficultyRates {
+		if rate >= r {
+			level++
+		}
+	}
+	return level
+}
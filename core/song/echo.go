@@ -0,0 +1,67 @@
+package song
+
+import "math"
+
+// Echo is a song rearranged for call and response: each bar with notes is
+// first played to the player and then left for them to play back
+type Echo struct {
+	// Song holds just the responses; the bars of the calls are left empty
+	// so the player has to go by ear
+	Song *Song
+	// Calls are the notes to sound for the player, timed in Song
+	Calls []TabNote
+	// Phrases are the responses, each with the bar of the original song
+	// it echoes
+	Phrases []EchoPhrase
+}
+
+// EchoPhrase is one bar the player answers
+type EchoPhrase struct {
+	Region
+	Bar int // Zero-based bar of the original song
+}
+
+// CallAndResponse rearranges a copy of the song for call and response,
+// leaving the song itself untouched. Bars without notes are left out.
+func (s *Song) CallAndResponse() *Echo {
+	bars := make(map[int][]TabNote)
+	var order []int
+	for _, n := range s.Notes {
+		bar := int(math.Floor(s.NoteBeat(n.Time)+beatEpsilon)) / BeatsPerBar
+		if _, ok := bars[bar]; !ok {
+			order = append(order, bar)
+		}
+		bars[bar] = append(bars[bar], n)
+	}
+
+	cp := *s
+	cp.Notes = nil
+	cp.Sections = nil
+	cp.Tempo = nil
+	e := &Echo{Song: &cp}
+	for i, bar := range order {
+		// Each call and its response keep the tempo the bar had
+		for _, shift := range []int{2 * i, 2*i + 1} {
+			offset := float64((shift - bar) * BeatsPerBar)
+			cp.Tempo = append(cp.Tempo, TempoChange{Beat: float64(shift * BeatsPerBar), BPM: s.BPMAt(float64(bar * BeatsPerBar))})
+			for _, c := range s.Tempo {
+				if c.Beat > float64(bar*BeatsPerBar) && c.Beat < float64((bar+1)*BeatsPerBar) {
+					cp.Tempo = append(cp.Tempo, TempoChange{Beat: c.Beat + offset, BPM: c.BPM})
+				}
+			}
+		}
+	}
+	for i, bar := range order {
+		for _, n := range bars[bar] {
+			beat := s.NoteBeat(n.Time)
+			n.Beat = 0
+			n.Time = cp.NoteTime(beat + float64((2*i-bar)*BeatsPerBar))
+			e.Calls = append(e.Calls, n)
+			n.Time = cp.NoteTime(beat + float64((2*i+1-bar)*BeatsPerBar))
+			cp.Notes = append(cp.Notes, n)
+		}
+		e.Phrases = append(e.Phrases, EchoPhrase{Region: cp.BarRegion(2*i+1, 2*i+1), Bar: bar})
+	}
+	cp.CalculateDuration()
+	return e
+}
@@ -0,0 +1,21 @@
+package song
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ContentHash identifies a chart by what's in it rather than where it's
+// kept, so anything stored against it no longer applies once the chart
+// is edited. Play state isn't included.
+func (s *Song) ContentHash() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		// Only unencodable values (NaN times) get here; fall back to
+		// hashing what identifies the song
+		data = []byte(s.Title + "\x00" + s.Artist)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
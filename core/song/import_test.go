@@ -0,0 +1,141 @@
+package song
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files from the current importers")
+
+// Each chart in testdata/import is decoded and re-encoded as JSON, which
+// must match its file in testdata/golden. After a deliberate schema
+// change, run go test -update and review the golden diff.
+func TestImportGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/import/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if !IsChartFile(file) {
+			continue
+		}
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			s, err := LoadSong(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name, s)
+		})
+	}
+}
+
+// MIDI files in testdata/import are imported for bass, which must find
+// the bass track among the others and place its notes the same way
+func TestImportMIDIGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/import/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if !IsMIDIFile(file) {
+			continue
+		}
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, _, err := ImportMIDI(data, MIDIOptions{Instrument: "bass"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name, s)
+		})
+	}
+}
+
+// The charts in testdata/import/pack are zipped up and loaded as a pack
+func TestImportPackGolden(t *testing.T) {
+	packPath := filepath.Join(t.TempDir(), "fixtures.zip")
+	if err := zipDir("testdata/import/pack", packPath); err != nil {
+		t.Fatal(err)
+	}
+	songs, err := LoadSongPack(packPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(songs) != 1 {
+		t.Fatalf("loaded %d charts from the pack, want 1 (pack.yaml isn't a chart)", len(songs))
+	}
+	for _, s := range songs {
+		if s.packDir != "riffs" {
+			t.Errorf("%s: pack directory %q, want riffs", s.Title, s.packDir)
+		}
+		checkGolden(t, "pack-"+strings.ReplaceAll(strings.ToLower(s.Title), " ", "-")+".yaml", s)
+	}
+}
+
+// checkGolden compares a chart's JSON with testdata/golden/<name>.json
+func checkGolden(t *testing.T, name string, s *Song) {
+	t.Helper()
+	got, err := encodeJSON(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s doesn't match %s\ngot:\n%s\nwant:\n%s", name, golden, got, want)
+	}
+}
+
+// zipDir writes the files under dir to a zip archive, with paths relative to dir
+func zipDir(dir, zipPath string) error {
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out, err := w.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,128 @@
+package song
+
+import (
+	"math"
+	"strings"
+)
+
+// Instrument describes a stringed instrument that charts are written for
+type Instrument struct {
+	Name    string
+	Tuning  Tuning            // Standard tuning, high to low
+	Tunings map[string]Tuning // Named tunings charts may ask for
+
+	// Pitch detection ignores anything outside this range (Hz), which
+	// covers the open low string up to the top fret of the high string
+	// with some room for overtones
+	MinFreq float64
+	MaxFreq float64
+}
+
+// Strings returns how many strings the instrument has in standard tuning
+func (i *Instrument) Strings() int {
+	return len(i.Tuning)
+}
+
+// ParseTuning resolves a tuning name or custom tuning for this instrument,
+// defaulting to its standard tuning
+func (i *Instrument) ParseTuning(s string) Tuning {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "" {
+		return i.Tuning
+	}
+	if t, ok := i.Tunings[name]; ok {
+		return t
+	}
+	if strings.Contains(name, ",") {
+		return ParseTuning(name)
+	}
+	return i.Tuning
+}
+
+// highestHarmonic is how far above an open string, in semitones, the
+// highest natural harmonic commonly played sounds (the one over the
+// third fret), so harmonics played for high notes are still heard
+const highestHarmonic = 31
+
+// FrequencyRange returns the pitches (Hz) to listen for when playing the
+// song: the instrument's range, widened for tunings that go below or above
+// it, and up to the harmonics of the highest string
+func (s *Song) FrequencyRange() (minFreq, maxFreq float64) {
+	inst := s.Instrument()
+	minFreq, maxFreq = inst.MinFreq, inst.MaxFreq
+	for _, st := range s.GetTuning() {
+		low := midiToFrequency(st.MIDINote() + s.PitchOffset())
+		high := midiToFrequency(st.MIDINote() + s.PitchOffset() + max(MaxFret, highestHarmonic))
+		minFreq = math.Min(minFreq, low*0.9) // Leave room for flat strings
+		maxFreq = math.Max(maxFreq, high*1.1)
+	}
+	return minFreq, maxFreq
+}
+
+// midiToFrequency converts a MIDI note number to Hz (A4 = 440)
+func midiToFrequency(midiNote int) float64 {
+	return 440.0 * math.Pow(2, float64(midiNote-69)/12.0)
+}
+
+// TuningGuitarStandard is standard 6-string guitar tuning (E-B-G-D-A-E)
+var TuningGuitarStandard = Tuning{
+	{Note: "E", Octave: 4},
+	{Note: "B", Octave: 3},
+	{Note: "G", Octave: 3},
+	{Note: "D", Octave: 3},
+	{Note: "A", Octave: 2},
+	{Note: "E", Octave: 2},
+}
+
+// Supported instruments
+var (
+	// InstrumentBass is a 4-string bass guitar; it is the default
+	InstrumentBass = &Instrument{
+		Name:    "bass",
+		Tuning:  TuningStandard,
+		Tunings: TuningsByName,
+		MinFreq: 20,
+		MaxFreq: 650,
+	}
+
+	// InstrumentGuitar is a 6-string guitar
+	InstrumentGuitar = &Instrument{
+		Name:   "guitar",
+		Tuning: TuningGuitarStandard,
+		Tunings: map[string]Tuning{
+			"standard": TuningGuitarStandard,
+			"drop-d": {
+				{Note: "E", Octave: 4},
+				{Note: "B", Octave: 3},
+				{Note: "G", Octave: 3},
+				{Note: "D", Octave: 3},
+				{Note: "A", Octave: 2},
+				{Note: "D", Octave: 2},
+			},
+			"half-step-down": {
+				{Note: "Eb", Octave: 4},
+				{Note: "Bb", Octave: 3},
+				{Note: "Gb", Octave: 3},
+				{Note: "Db", Octave: 3},
+				{Note: "Ab", Octave: 2},
+				{Note: "Eb", Octave: 2},
+			},
+		},
+		MinFreq: 70,
+		MaxFreq: 1400,
+	}
+
+	// InstrumentsByName maps instrument names to instruments
+	InstrumentsByName = map[string]*Instrument{
+		"bass":   InstrumentBass,
+		"guitar": InstrumentGuitar,
+	}
+)
+
+// LookupInstrument returns the named instrument, defaulting to bass
+func LookupInstrument(name string) *Instrument {
+	if inst, ok := InstrumentsByName[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return inst
+	}
+	return InstrumentBass
+}
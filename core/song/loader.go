@@ -0,0 +1,347 @@
+package song
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSong loads a song from a YAML or JSON file
+func LoadSong(path string) (*Song, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	song, err := parseSongFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+	song.Path = path
+	return song, nil
+}
+
+// parseSongFile decodes a chart in the format its file name implies
+func parseSongFile(name string, data []byte) (*Song, error) {
+	if isJSON(name) {
+		return ParseSongJSON(data)
+	}
+	return ParseSong(data)
+}
+
+// ParseSong decodes a YAML chart
+func ParseSong(data []byte) (*Song, error) {
+	var song Song
+	if err := yaml.Unmarshal(data, &song); err != nil {
+		return nil, err
+	}
+	song.prepare()
+	return &song, nil
+}
+
+// ParseSongJSON decodes a JSON chart, which uses the same schema as YAML
+func ParseSongJSON(data []byte) (*Song, error) {
+	var song Song
+	if err := json.Unmarshal(data, &song); err != nil {
+		return nil, err
+	}
+	song.prepare()
+	return &song, nil
+}
+
+// prepare fills in the runtime fields of a freshly decoded chart
+func (s *Song) prepare() {
+	// Tempo changes must be in beat order for beat/time conversion
+	sort.SliceStable(s.Tempo, func(i, j int) bool {
+		return s.Tempo[i].Beat < s.Tempo[j].Beat
+	})
+
+	// Convert beat numbers to time if specified
+	if s.BPM > 0 {
+		for i := range s.Notes {
+			// If beat is specified but time is not, convert beat to time
+			if s.Notes[i].Beat > 0 && s.Notes[i].Time == 0 {
+				s.Notes[i].Time = s.NoteTime(float64(s.Notes[i].Beat))
+			}
+			// Default duration to one beat if not specified
+			if s.Notes[i].Duration == 0 {
+				beat := s.TimeToBeat(s.Notes[i].Time)
+				s.Notes[i].Duration = 60.0 / s.BPMAt(beat) * 0.9
+			}
+		}
+	}
+
+	// Sort notes by time
+	s.SortNotes()
+
+	// Parse tuning
+	s.Tuning = s.Instrument().ParseTuning(s.TuningStr)
+
+	s.CalculateDuration()
+}
+
+// PackMetadataFile describes a song pack's contents; it sits at the root
+// of the archive and isn't a chart
+const PackMetadataFile = "pack.yaml"
+
+// SkippedChart is a chart that couldn't be loaded, and why
+type SkippedChart struct {
+	Path string // File, or pack and the chart's name inside it
+	Err  error
+}
+
+func (c SkippedChart) Error() string {
+	return fmt.Sprintf("%s: %v", c.Path, c.Err)
+}
+
+// LoadSongPack loads every chart in a .zip song pack. Charts can refer
+// to backing audio and cover art stored alongside them in the archive.
+func LoadSongPack(packPath string) ([]*Song, error) {
+	songs, _, err := loadSongPack(packPath)
+	return songs, err
+}
+
+// loadSongPack loads a song pack, skipping broken charts but keeping the
+// rest of the pack
+func loadSongPack(packPath string) ([]*Song, []SkippedChart, error) {
+	r, err := zip.OpenReader(packPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	var songs []*Song
+	var skipped []SkippedChart
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || f.Name == PackMetadataFile || !IsChartFile(f.Name) {
+			continue
+		}
+
+		data, err := readZipFile(f)
+		if err == nil {
+			var song *Song
+			if song, err = parseSongFile(f.Name, data); err == nil {
+				song.Pack = packPath
+				song.packDir = path.Dir(f.Name)
+				songs = append(songs, song)
+				continue
+			}
+		}
+		skipped = append(skipped, SkippedChart{Path: packPath + ":" + f.Name, Err: err})
+	}
+	return songs, skipped, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// IsChartFile reports whether a file name has a chart extension
+// (.yaml, .yml, or .json)
+func IsChartFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml" || ext == ".json"
+}
+
+// IsScriptFile reports whether a file name has the extension of an
+// exercise generator script (.star), which frontends run to make songs
+func IsScriptFile(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".star")
+}
+
+func isJSON(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".json")
+}
+
+// ReadAsset reads a file referenced by the chart (such as its backing
+// audio or cover art), relative to the chart's location
+func (s *Song) ReadAsset(name string) ([]byte, error) {
+	if s.Pack != "" {
+		r, err := zip.OpenReader(s.Pack)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if f.Name == path.Join(s.packDir, name) {
+				return readZipFile(f)
+			}
+		}
+		return nil, fmt.Errorf("%s: %s not found in pack", s.Pack, name)
+	}
+	if s.Path == "" {
+		return nil, fmt.Errorf("%s: no chart file to find %s next to", s.Title, name)
+	}
+	return os.ReadFile(filepath.Join(filepath.Dir(s.Path), name))
+}
+
+// LoadSongsFromDirectory loads all .yaml, .yml, and .json charts from a directory,
+// plus the charts inside any .zip song packs
+func LoadSongsFromDirectory(dir string) ([]*Song, error) {
+	songs, _, err := LoadLibrary(dir)
+	return songs, err
+}
+
+// LoadLibrary loads charts and song packs from a directory as
+// LoadSongsFromDirectory does, also returning the charts it had to skip
+func LoadLibrary(dir string) ([]*Song, []SkippedChart, error) {
+	var songs []*Song
+	var skipped []SkippedChart
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if strings.EqualFold(filepath.Ext(path), ".zip") {
+			pack, bad, err := loadSongPack(path)
+			if err != nil {
+				skipped = append(skipped, SkippedChart{Path: path, Err: err})
+				continue
+			}
+			songs = append(songs, pack...)
+			skipped = append(skipped, bad...)
+			continue
+		}
+		if !IsChartFile(path) {
+			continue
+		}
+
+		song, err := LoadSong(path)
+		if err != nil {
+			// Skip it but carry on loading other songs
+			skipped = append(skipped, SkippedChart{Path: path, Err: err})
+			continue
+		}
+
+		songs = append(songs, song)
+	}
+
+	// Sort songs by title
+	sort.Slice(songs, func(i, j int) bool {
+		return songs[i].Title < songs[j].Title
+	})
+
+	return songs, skipped, nil
+}
+
+// SaveSong saves a song to a YAML file, or JSON if path ends in .json
+func SaveSong(song *Song, path string) error {
+	if isJSON(path) {
+		data, err := encodeJSON(song)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0644)
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(song); err != nil {
+		return err
+	}
+
+	// Write each note on its own line, matching the hand-written charts
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "notes" {
+			for _, note := range doc.Content[i+1].Content {
+				note.Style = yaml.FlowStyle
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// encodeJSON writes a chart as indented JSON with each note on its own
+// line, matching the layout of the YAML charts
+func encodeJSON(song *Song) ([]byte, error) {
+	chart := *song
+	chart.Notes = nil
+	head, err := json.MarshalIndent(&chart, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var notes bytes.Buffer
+	notes.WriteString("[")
+	for i := range song.Notes {
+		note, err := json.Marshal(&song.Notes[i])
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			notes.WriteString(",")
+		}
+		notes.WriteString("\n    ")
+		notes.Write(note)
+	}
+	if len(song.Notes) > 0 {
+		notes.WriteString("\n  ")
+	}
+	notes.WriteString("]")
+
+	out := bytes.Replace(head, []byte(`"notes": null`), []byte(`"notes": `+notes.String()), 1)
+	return append(out, '\n'), nil
+}
+
+// GetDefaultExercises returns built-in exercises if no songs directory exists
+func GetDefaultExercises() []*Song {
+	return []*Song{
+		createEMinorScale(),
+	}
+}
+
+// createEMinorScale creates a simple E minor scale exercise
+func createEMinorScale() *Song {
+	beatDuration := 60.0 / 80.0
+
+	notes := []TabNote{
+		{Time: 0 * beatDuration, String: StringE, Fret: 0, Duration: beatDuration * 0.9},
+		{Time: 1 * beatDuration, String: StringE, Fret: 2, Duration: beatDuration * 0.9},
+		{Time: 2 * beatDuration, String: StringE, Fret: 3, Duration: beatDuration * 0.9},
+		{Time: 3 * beatDuration, String: StringE, Fret: 5, Duration: beatDuration * 0.9},
+		{Time: 4 * beatDuration, String: StringA, Fret: 0, Duration: beatDuration * 0.9},
+		{Time: 5 * beatDuration, String: StringA, Fret: 2, Duration: beatDuration * 0.9},
+		{Time: 6 * beatDuration, String: StringA, Fret: 3, Duration: beatDuration * 0.9},
+		{Time: 7 * beatDuration, String: StringA, Fret: 5, Duration: beatDuration * 0.9},
+	}
+
+	song := &Song{
+		Title:  "E Minor Scale (Default)",
+		Artist: "Built-in",
+		BPM:    80,
+		Notes:  notes,
+		Tuning: TuningStandard,
+	}
+	song.CalculateDuration()
+	return song
+}
@@ -0,0 +1,399 @@
+package song
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MIDIOptions say which part of a MIDI file to import and what it's
+// played on
+type MIDIOptions struct {
+	Instrument string // "bass" (default) or "guitar"
+	Tuning     string // As in a chart; the instrument's standard tuning if empty
+	Track      int    // Track to import, counted from 1; 0 picks the part for the instrument
+}
+
+// midiDrumChannel is the General MIDI percussion channel (10, counted
+// from 1), whose notes are drums rather than pitches
+const midiDrumChannel = 9
+
+// midiDefaultTempo is the tempo of a MIDI file that doesn't set one, in
+// microseconds a quarter note
+const midiDefaultTempo = 500000
+
+// midiPrograms are the General MIDI programs for each instrument, used to
+// find its part in a file with several
+var midiPrograms = map[string][2]int{
+	"bass":   {32, 39},
+	"guitar": {24, 31},
+}
+
+// midiNote is a note read from a MIDI track, in ticks
+type midiNote struct {
+	start, end int
+	key        int
+}
+
+// midiTrack is what the importer keeps of a MIDI track
+type midiTrack struct {
+	name    string
+	program int // Last program change, or -1
+	notes   []midiNote
+}
+
+// midiTempo is a tempo change, in ticks and microseconds a quarter note
+type midiTempo struct {
+	tick, tempo int
+}
+
+// midiMarker is a marker meta event, which becomes a section
+type midiMarker struct {
+	tick int
+	name string
+}
+
+// IsMIDIFile reports whether a file name has the extension of a
+// Standard MIDI File, which can be imported as a chart
+func IsMIDIFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".mid" || ext == ".midi"
+}
+
+// ImportMIDI makes a chart from one part of a Standard MIDI File. Beats
+// are quarter notes, the file's tempo changes and markers carry over,
+// and each note goes on the string that keeps the hand nearest where it
+// was. It also returns how many notes couldn't be played on the
+// instrument and were left out.
+func ImportMIDI(data []byte, opts MIDIOptions) (*Song, int, error) {
+	s := &Song{InstrumentName: opts.Instrument, TuningStr: opts.Tuning}
+	if err := s.CheckTuning(); err != nil {
+		return nil, 0, err
+	}
+
+	division, tracks, tempos, markers, err := parseMIDI(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	part, err := pickMIDITrack(tracks, s.Instrument().Name, opts.Track)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.Title = tracks[0].name
+
+	beat := func(tick int) float64 {
+		return float64(tick) / float64(division)
+	}
+	s.BPM = 60e6 / midiDefaultTempo
+	for _, t := range tempos {
+		bpm := 60e6 / float64(t.tempo)
+		if t.tick == 0 {
+			s.BPM = bpm
+			continue
+		}
+		s.Tempo = append(s.Tempo, TempoChange{Beat: beat(t.tick), BPM: bpm})
+	}
+	for _, m := range markers {
+		if name := strings.TrimSpace(m.name); name != "" {
+			s.Sections = append(s.Sections, Section{Beat: beat(m.tick), Name: name})
+		}
+	}
+
+	tuning := s.GetTuning()
+	dropped := 0
+	fret := 0
+	used := map[int]bool{} // Strings taken by notes starting on the same tick
+	for i, n := range part.notes {
+		if i > 0 && n.start != part.notes[i-1].start {
+			clear(used)
+		}
+		pos, ok := placeMIDINote(tuning, n.key, fret, used)
+		if !ok {
+			dropped++
+			continue
+		}
+		used[pos.String] = true
+		fret = pos.Fret
+		duration := s.BeatToTime(beat(n.end)) - s.BeatToTime(beat(n.start))
+		s.Notes = append(s.Notes, TabNote{
+			Beat:     Beat(beat(n.start)),
+			String:   pos.String,
+			Fret:     pos.Fret,
+			Duration: math.Round(duration*1000) / 1000, // To the millisecond, as charts are written
+		})
+	}
+	if len(s.Notes) == 0 {
+		return nil, dropped, fmt.Errorf("track %q has no notes a %s can play", part.name, s.Instrument().Name)
+	}
+	s.prepare()
+	return s, dropped, nil
+}
+
+// placeMIDINote finds where to play a note near a fret on a string that
+// isn't already used, preferring lower frets when two are as near
+func placeMIDINote(tuning Tuning, key, nearFret int, used map[int]bool) (Position, bool) {
+	var free Tuning
+	var index []int // The string each free one is
+	for i, st := range tuning {
+		if !used[i] {
+			free = append(free, st)
+			index = append(index, i)
+		}
+	}
+	pos, ok := free.NearestPosition(key, MaxFret, nearFret)
+	if !ok {
+		return Position{}, false
+	}
+	pos.String = index[pos.String]
+	return pos, true
+}
+
+// pickMIDITrack chooses the track to import: the one asked for, or else
+// the first named for the instrument, then the first with its General
+// MIDI program, then the first with any pitched notes
+func pickMIDITrack(tracks []midiTrack, instrument string, number int) (*midiTrack, error) {
+	if number > 0 {
+		if number > len(tracks) {
+			return nil, fmt.Errorf("track %d asked for, but the file has %d", number, len(tracks))
+		}
+		t := &tracks[number-1]
+		if len(t.notes) == 0 {
+			return nil, fmt.Errorf("track %d has no notes", number)
+		}
+		return t, nil
+	}
+
+	programs := midiPrograms[instrument]
+	var byProgram, first *midiTrack
+	for i := range tracks {
+		t := &tracks[i]
+		if len(t.notes) == 0 {
+			continue
+		}
+		if strings.Contains(strings.ToLower(t.name), instrument) {
+			return t, nil
+		}
+		if byProgram == nil && t.program >= programs[0] && t.program <= programs[1] {
+			byProgram = t
+		}
+		if first == nil {
+			first = t
+		}
+	}
+	switch {
+	case byProgram != nil:
+		return byProgram, nil
+	case first != nil:
+		return first, nil
+	}
+	return nil, errors.New("the file has no notes outside the drum channel")
+}
+
+// parseMIDI reads a Standard MIDI File's timing, tracks, tempo map and
+// markers. Notes on the drum channel are left out.
+func parseMIDI(data []byte) (division int, tracks []midiTrack, tempos []midiTempo, markers []midiMarker, err error) {
+	chunkType, header, rest, err := readMIDIChunk(data)
+	if err != nil || chunkType != "MThd" || len(header) < 6 {
+		return 0, nil, nil, nil, errors.New("not a Standard MIDI File")
+	}
+	format := binary.BigEndian.Uint16(header[0:])
+	count := int(binary.BigEndian.Uint16(header[2:]))
+	division = int(binary.BigEndian.Uint16(header[4:]))
+	switch {
+	case format > 1:
+		return 0, nil, nil, nil, fmt.Errorf("MIDI format %d (separate songs in one file) isn't supported", format)
+	case division&0x8000 != 0:
+		return 0, nil, nil, nil, errors.New("MIDI files timed in SMPTE frames aren't supported")
+	case division == 0:
+		return 0, nil, nil, nil, errors.New("MIDI file has no ticks per beat")
+	}
+
+	for len(tracks) < count && len(rest) > 0 {
+		var body []byte
+		chunkType, body, rest, err = readMIDIChunk(rest)
+		if err != nil {
+			return 0, nil, nil, nil, err
+		}
+		if chunkType != "MTrk" {
+			continue // Unknown chunks are to be skipped
+		}
+		t, err := parseMIDITrack(body, &tempos, &markers)
+		if err != nil {
+			return 0, nil, nil, nil, fmt.Errorf("MIDI track %d: %w", len(tracks)+1, err)
+		}
+		tracks = append(tracks, t)
+	}
+	if len(tracks) == 0 {
+		return 0, nil, nil, nil, errors.New("MIDI file has no tracks")
+	}
+
+	sort.SliceStable(tempos, func(i, j int) bool { return tempos[i].tick < tempos[j].tick })
+	sort.SliceStable(markers, func(i, j int) bool { return markers[i].tick < markers[j].tick })
+	return division, tracks, tempos, markers, nil
+}
+
+// readMIDIChunk splits the next chunk off a MIDI file
+func readMIDIChunk(data []byte) (chunkType string, body, rest []byte, err error) {
+	if len(data) < 8 {
+		return "", nil, nil, errors.New("MIDI file is truncated")
+	}
+	size := binary.BigEndian.Uint32(data[4:])
+	if uint64(size) > uint64(len(data)-8) {
+		return "", nil, nil, errors.New("MIDI file is truncated")
+	}
+	return string(data[:4]), data[8 : 8+size], data[8+size:], nil
+}
+
+// parseMIDITrack reads a track's events, adding its tempo changes and
+// markers to those of the whole file
+func parseMIDITrack(data []byte, tempos *[]midiTempo, markers *[]midiMarker) (midiTrack, error) {
+	t := midiTrack{program: -1}
+	r := midiReader{data: data}
+	tick := 0
+	var status byte
+	held := map[[2]int][]int{} // Start ticks of sounding notes by channel and key
+
+	for r.more() {
+		delta, err := r.varint()
+		if err != nil {
+			return t, err
+		}
+		tick += delta
+
+		b, err := r.byte()
+		if err != nil {
+			return t, err
+		}
+		switch {
+		case b == 0xFF:
+			kind, err := r.byte()
+			if err != nil {
+				return t, err
+			}
+			body, err := r.block()
+			if err != nil {
+				return t, err
+			}
+			switch {
+			case kind == 0x03 && t.name == "":
+				t.name = strings.TrimSpace(string(body))
+			case kind == 0x06:
+				*markers = append(*markers, midiMarker{tick: tick, name: string(body)})
+			case kind == 0x51 && len(body) == 3:
+				if tempo := int(body[0])<<16 | int(body[1])<<8 | int(body[2]); tempo > 0 {
+					*tempos = append(*tempos, midiTempo{tick: tick, tempo: tempo})
+				}
+			case kind == 0x2F:
+				r.data = nil // End of track
+			}
+			continue
+		case b == 0xF0 || b == 0xF7:
+			if _, err := r.block(); err != nil {
+				return t, err
+			}
+			continue
+		case b&0x80 != 0:
+			status = b
+			if b, err = r.byte(); err != nil {
+				return t, err
+			}
+		case status == 0:
+			return t, errors.New("data byte without a status")
+		}
+
+		// b is the event's first data byte, given or after running status
+		channel := int(status & 0x0F)
+		switch status & 0xF0 {
+		case 0xC0:
+			if channel != midiDrumChannel {
+				t.program = int(b)
+			}
+		case 0xD0:
+		default:
+			velocity, err := r.byte()
+			if err != nil {
+				return t, err
+			}
+			if channel == midiDrumChannel {
+				continue
+			}
+			key := [2]int{channel, int(b)}
+			switch {
+			case status&0xF0 == 0x90 && velocity > 0:
+				held[key] = append(held[key], tick)
+			case status&0xF0 == 0x80 || status&0xF0 == 0x90:
+				if starts := held[key]; len(starts) > 0 {
+					t.notes = append(t.notes, midiNote{start: starts[0], end: tick, key: int(b)})
+					held[key] = starts[1:]
+				}
+			}
+		}
+	}
+
+	// Notes still held when the track ends stop there
+	for key, starts := range held {
+		for _, start := range starts {
+			t.notes = append(t.notes, midiNote{start: start, end: tick, key: key[1]})
+		}
+	}
+	sort.SliceStable(t.notes, func(i, j int) bool {
+		if t.notes[i].start != t.notes[j].start {
+			return t.notes[i].start < t.notes[j].start
+		}
+		return t.notes[i].key > t.notes[j].key // Highest first, as strings count
+	})
+	return t, nil
+}
+
+// midiReader reads the parts of MIDI events from a track
+type midiReader struct {
+	data []byte
+}
+
+func (r *midiReader) more() bool {
+	return len(r.data) > 0
+}
+
+func (r *midiReader) byte() (byte, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("event is truncated")
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b, nil
+}
+
+// varint reads a variable-length quantity: seven bits a byte, high
+// bit set on all but the last, no more than four bytes
+func (r *midiReader) varint() (int, error) {
+	v := 0
+	for range 4 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<7 | int(b&0x7F)
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, errors.New("variable-length number is too long")
+}
+
+// block reads a length-prefixed meta or system exclusive body
+func (r *midiReader) block() ([]byte, error) {
+	n, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > len(r.data) {
+		return nil, errors.New("event is truncated")
+	}
+	body := r.data[:n]
+	r.data = r.data[n:]
+	return body, nil
+}
@@ -0,0 +1,166 @@
+package song
+
+import (
+	"fmt"
+	"math"
+)
+
+// BeatsPerBar is the bar length passages are measured in. Charts don't
+// record a time signature, so like the drum backing this assumes 4/4.
+const BeatsPerBar = 4
+
+// Looping passages starts a beat early, and waits long enough after the
+// passage for its last notes to be judged before going round again
+const (
+	loopLeadInBeats = 1
+	loopTail        = 0.3 // Seconds, the same as the miss window
+)
+
+// beatEpsilon keeps notes a hair before a barline in the bar they belong to
+const beatEpsilon = 0.001
+
+// Region is a passage of whole bars
+type Region struct {
+	FirstBar, LastBar int // Zero-based and inclusive
+	Start, End        float64
+	Missed            int    // Notes missed in the passage
+	Name              string // Section name, if the passage is a named section
+}
+
+// String names the passage by its bar numbers as players count them,
+// after its section name if it has one
+func (r Region) String() string {
+	bars := fmt.Sprintf("Bars %d-%d", r.FirstBar+1, r.LastBar+1)
+	if r.FirstBar == r.LastBar {
+		bars = fmt.Sprintf("Bar %d", r.FirstBar+1)
+	}
+	if r.Name != "" {
+		return fmt.Sprintf("%s (%s)", r.Name, bars)
+	}
+	return bars
+}
+
+// BarRegion returns the passage covering a span of bars
+func (s *Song) BarRegion(firstBar, lastBar int) Region {
+	return Region{
+		FirstBar: firstBar,
+		LastBar:  lastBar,
+		Start:    s.NoteTime(float64(firstBar * BeatsPerBar)),
+		End:      s.NoteTime(float64((lastBar + 1) * BeatsPerBar)),
+	}
+}
+
+// MissedRegions groups the missed notes of a finished play into passages
+// of whole bars, joining misses in the same or neighbouring bars
+func (g *GameState) MissedRegions() []Region {
+	var regions []Region
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if !note.Hit || note.HitQuality != HitMiss {
+			continue
+		}
+		bar := int(math.Floor(g.Song.NoteBeat(note.Time)+beatEpsilon)) / BeatsPerBar
+		if n := len(regions); n > 0 && bar <= regions[n-1].LastBar+1 {
+			regions[n-1].LastBar = bar
+			regions[n-1].Missed++
+			continue
+		}
+		regions = append(regions, Region{FirstBar: bar, LastBar: bar, Missed: 1})
+	}
+	for i, r := range regions {
+		missed := r.Missed
+		regions[i] = g.Song.BarRegion(r.FirstBar, r.LastBar)
+		regions[i].Missed = missed
+	}
+	return regions
+}
+
+// InPlay reports whether a note is being played, rather than lying
+// outside the passage being looped
+func (g *GameState) InPlay(note *TabNote) bool {
+	if g.Loop == nil {
+		return true
+	}
+	return note.Time >= g.Loop.Start-beatEpsilon && note.Time < g.Loop.End-beatEpsilon
+}
+
+// NextNote returns the first note still to be played, or nil if there's
+// none. Notes outside a looped passage are never played, so are skipped.
+func (g *GameState) NextNote() *TabNote {
+	for i := range g.Song.Notes {
+		if n := &g.Song.Notes[i]; !n.Hit && g.InPlay(n) {
+			return n
+		}
+	}
+	return nil
+}
+
+// PlayLoop starts repeating a passage from a beat before it
+func (g *GameState) PlayLoop(r Region) {
+	g.Loop = &r
+	g.Laps = 0
+	g.LapScores = nil
+	g.TotalNotes = 0
+	for i := range g.Song.Notes {
+		if g.InPlay(&g.Song.Notes[i]) {
+			g.TotalNotes++
+		}
+	}
+	g.restartLoop()
+}
+
+// restartLoop clears the passage's notes and plays it again
+func (g *GameState) restartLoop() {
+	if g.Laps > 0 {
+		g.LapScores = append(g.LapScores, g.LapAccuracy())
+	}
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if g.InPlay(note) {
+			note.Hit = false
+			note.HitQuality = HitMiss
+			note.HitTime = 0
+			note.HitCents = 0
+		}
+	}
+	g.Laps++
+	beat := g.Song.TimeToBeat(g.Loop.Start)
+	g.StartAt(g.Song.BeatToTime(math.Max(0, beat-loopLeadInBeats)))
+}
+
+// LapAccuracy returns the percentage of the passage's notes hit so far on
+// this time round the loop
+func (g *GameState) LapAccuracy() float64 {
+	hit, total := 0, 0
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if !g.InPlay(note) {
+			continue
+		}
+		total++
+		if note.Hit && note.HitQuality != HitMiss {
+			hit++
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+	return float64(hit) / float64(total) * 100
+}
+
+// AutoplayDue marks the notes in play that have reached the play line as
+// played, without scoring them, and returns them so they can be sounded
+func (g *GameState) AutoplayDue() []*TabNote {
+	var due []*TabNote
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if note.Hit || note.Time > g.CurrentTime || !g.InPlay(note) {
+			continue
+		}
+		note.Hit = true
+		note.HitQuality = HitPerfect
+		note.HitTime = note.Time
+		due = append(due, note)
+	}
+	return due
+}
@@ -0,0 +1,196 @@
+package song
+
+import "math"
+
+// phraseBars is the passage length sections are judged over in songs
+// without named sections
+const phraseBars = 4
+
+// The riff repeater first loops a section at this fraction of the run's
+// speed, and speeds back up by repeatSpeedStep of it each clean time round
+const (
+	repeatSlowdown  = 0.7
+	repeatSpeedStep = 0.1
+)
+
+// SectionRegions returns the song's passages for judging play: its named
+// sections, or four-bar phrases if it has none
+func (s *Song) SectionRegions() []Region {
+	if len(s.Notes) == 0 {
+		return nil
+	}
+	lastBar := int(math.Floor(s.NoteBeat(s.Notes[len(s.Notes)-1].Time)+beatEpsilon)) / BeatsPerBar
+	end := float64((lastBar + 1) * BeatsPerBar)
+
+	type bound struct {
+		beat float64
+		name string
+	}
+	var starts []bound
+	if len(s.Sections) == 0 {
+		for bar := 0; bar <= lastBar; bar += phraseBars {
+			starts = append(starts, bound{beat: float64(bar * BeatsPerBar)})
+		}
+	} else {
+		if s.Sections[0].Beat > 0 {
+			starts = append(starts, bound{})
+		}
+		for _, sec := range s.Sections {
+			if sec.Beat < end {
+				starts = append(starts, bound{sec.Beat, sec.Name})
+			}
+		}
+	}
+
+	regions := make([]Region, len(starts))
+	for i, b := range starts {
+		to := end
+		if i+1 < len(starts) {
+			to = starts[i+1].beat
+		}
+		regions[i] = Region{
+			FirstBar: int(b.beat+beatEpsilon) / BeatsPerBar,
+			LastBar:  int(math.Ceil(to-beatEpsilon)-1) / BeatsPerBar,
+			Start:    s.NoteTime(b.beat),
+			End:      s.NoteTime(to),
+			Name:     b.name,
+		}
+	}
+	return regions
+}
+
+// RegionAccuracy returns the percentage of notes hit in a passage, and
+// how many of its notes have been judged
+func (g *GameState) RegionAccuracy(r Region) (accuracy float64, judged int) {
+	hit := 0
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if !note.Hit || note.Time < r.Start-beatEpsilon || note.Time >= r.End-beatEpsilon {
+			continue
+		}
+		judged++
+		if note.HitQuality != HitMiss {
+			hit++
+		}
+	}
+	if judged == 0 {
+		return 100, 0
+	}
+	return float64(hit) / float64(judged) * 100, judged
+}
+
+// scoreState is the part of a game's progress the riff repeater sets
+// aside while it loops a section
+type scoreState struct {
+	score, combo, maxCombo  int
+	notesHit, notesMissed   int
+	wrongNotes              int
+	health                  float64
+	failMode                bool
+	totalCents, pitchCredit float64
+	totalOffset             float64
+}
+
+// RepeatEvent is what a riff repeater did on an update
+type RepeatEvent int
+
+const (
+	RepeatNone    RepeatEvent = iota
+	RepeatStarted             // Began looping a section slowly
+	RepeatSpedUp              // A clean time round brought the speed up
+	RepeatResumed             // Back to the full run after the section
+)
+
+// Repeater is Rocksmith-style dynamic difficulty: a section with too many
+// misses is looped slowly, sped back up as it's played cleanly, and then
+// the run carries on from the end of the section. Scoring is put back as
+// it was before the loop, so practicing doesn't change the run's result.
+type Repeater struct {
+	MissPercent    float64 // Misses in a section, as a percentage, that start a loop
+	RecoverPercent float64 // Accuracy on a time round that counts as clean
+
+	sections []Region
+	next     int     // Index of the next section to judge
+	active   *Region // Section being looped; nil during the run
+	runSpeed float64
+	laps     int // Laps of the loop looked at so far
+	saved    scoreState
+}
+
+// NewRepeater watches a game's sections for ones that need repeating
+func NewRepeater(g *GameState, missPercent, recoverPercent float64) *Repeater {
+	return &Repeater{
+		MissPercent:    missPercent,
+		RecoverPercent: recoverPercent,
+		sections:       g.Song.SectionRegions(),
+	}
+}
+
+// Active returns the section being looped, or nil during the run
+func (r *Repeater) Active() *Region {
+	return r.active
+}
+
+// Update judges sections as play passes them and steers the loop of one
+// being repeated. Call it after the game state is updated.
+func (r *Repeater) Update(g *GameState) RepeatEvent {
+	if g.Failed {
+		return RepeatNone
+	}
+	if r.active == nil {
+		for r.next < len(r.sections) && (g.IsFinished || g.CurrentTime > r.sections[r.next].End+loopTail) {
+			sec := r.sections[r.next]
+			r.next++
+			if accuracy, judged := g.RegionAccuracy(sec); judged > 0 && 100-accuracy > r.MissPercent {
+				r.repeat(g, sec)
+				return RepeatStarted
+			}
+		}
+		return RepeatNone
+	}
+
+	for r.laps < len(g.LapScores) {
+		accuracy := g.LapScores[r.laps]
+		r.laps++
+		if accuracy < r.RecoverPercent {
+			continue
+		}
+		if g.Speed >= r.runSpeed-beatEpsilon {
+			r.resume(g)
+			return RepeatResumed
+		}
+		g.Speed = math.Min(r.runSpeed, g.Speed+repeatSpeedStep*r.runSpeed)
+		return RepeatSpedUp
+	}
+	return RepeatNone
+}
+
+// repeat sets the run aside and loops a section slowly
+func (r *Repeater) repeat(g *GameState, sec Region) {
+	r.saved = scoreState{g.Score, g.Combo, g.MaxCombo, g.NotesHit, g.NotesMissed, g.WrongNotes, g.Health, g.FailMode, g.totalCents, g.pitchCredit, g.totalOffset}
+	r.runSpeed = g.Speed
+	if r.runSpeed <= 0 {
+		r.runSpeed = 1
+	}
+	r.active = &sec
+	r.laps = 0
+	g.FailMode = false // Practicing the section can't fail the run
+	g.Speed = r.runSpeed * repeatSlowdown
+	g.PlayLoop(sec)
+}
+
+// resume puts the run's scoring back and carries on after the section
+func (r *Repeater) resume(g *GameState) {
+	sec := *r.active
+	r.active = nil
+	s := r.saved
+	g.Score, g.Combo, g.MaxCombo, g.NotesHit, g.NotesMissed, g.WrongNotes, g.Health, g.FailMode, g.totalCents, g.pitchCredit, g.totalOffset = s.score, s.combo, s.maxCombo, s.notesHit, s.notesMissed, s.wrongNotes, s.health, s.failMode, s.totalCents, s.pitchCredit, s.totalOffset
+
+	g.Loop = nil
+	g.Laps = 0
+	g.LapScores = nil
+	g.TotalNotes = len(g.Song.Notes)
+	g.Speed = r.runSpeed
+	beat := g.Song.TimeToBeat(sec.End)
+	g.StartAt(g.Song.BeatToTime(math.Max(0, beat-loopLeadInBeats)))
+}
@@ -0,0 +1,114 @@
+package song
+
+import "math"
+
+// Scale is a scale or arpeggio, as semitones above its root
+type Scale struct {
+	Name      string
+	Intervals []int
+	Minor     bool // Built on a minor key's root rather than a major one's
+}
+
+// Scales are the scales and arpeggios that can be shown on the fretboard
+var Scales = []Scale{
+	{Name: "Major scale", Intervals: []int{0, 2, 4, 5, 7, 9, 11}},
+	{Name: "Major pentatonic", Intervals: []int{0, 2, 4, 7, 9}},
+	{Name: "Major arpeggio", Intervals: []int{0, 4, 7}},
+	{Name: "Dominant 7th arpeggio", Intervals: []int{0, 4, 7, 10}},
+	{Name: "Natural minor scale", Intervals: []int{0, 2, 3, 5, 7, 8, 10}, Minor: true},
+	{Name: "Minor pentatonic", Intervals: []int{0, 3, 5, 7, 10}, Minor: true},
+	{Name: "Blues scale", Intervals: []int{0, 3, 5, 6, 7, 10}, Minor: true},
+	{Name: "Minor arpeggio", Intervals: []int{0, 3, 7}, Minor: true},
+	{Name: "Minor 7th arpeggio", Intervals: []int{0, 3, 7, 10}, Minor: true},
+}
+
+// Contains reports whether a pitch class (0 = C) is in the scale built on a root
+func (sc Scale) Contains(root, pitchClass int) bool {
+	for _, i := range sc.Intervals {
+		if (root+i)%12 == ((pitchClass%12)+12)%12 {
+			return true
+		}
+	}
+	return false
+}
+
+// RootIn returns the root the scale is built on in a key: a minor scale
+// in a major key starts on the relative minor, and the reverse
+func (sc Scale) RootIn(key int, minor bool) int {
+	switch {
+	case sc.Minor && !minor:
+		return (key + 9) % 12
+	case !sc.Minor && minor:
+		return (key + 3) % 12
+	}
+	return key
+}
+
+// Key profiles (Krumhansl-Kessler): how well each degree of a major and a
+// minor key fits, from the tonic up
+var (
+	majorProfile = []float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = []float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// Key estimates the song's key from how long each pitch class sounds, as
+// charts don't record one. Lines tend to start and end on the tonic, so
+// the first and last notes count for more. It returns the tonic's pitch class (0 = C) and
+// whether the key is minor; songs without notes are taken to be in E.
+func (s *Song) Key() (root int, minor bool) {
+	if len(s.Notes) == 0 {
+		return 4, false
+	}
+	var weights [12]float64
+	for i := range s.Notes {
+		note := &s.Notes[i]
+		w := note.Duration
+		if w <= 0 {
+			w = s.BeatDuration()
+		}
+		if i == 0 || i == len(s.Notes)-1 {
+			w *= 4
+		}
+		weights[s.MIDINoteAt(note)%12] += w
+	}
+
+	best := math.Inf(-1)
+	for tonic := 0; tonic < 12; tonic++ {
+		for _, p := range []struct {
+			profile []float64
+			minor   bool
+		}{{majorProfile, false}, {minorProfile, true}} {
+			if c := correlate(weights[:], p.profile, tonic); c > best {
+				root, minor, best = tonic, p.minor, c
+			}
+		}
+	}
+	return root, minor
+}
+
+// correlate returns the correlation of pitch class weights with a key
+// profile rotated to a tonic
+func correlate(weights, profile []float64, tonic int) float64 {
+	var mw, mp float64
+	for i := range weights {
+		mw += weights[i] / 12
+		mp += profile[i] / 12
+	}
+	var num, dw, dp float64
+	for i := range weights {
+		w := weights[(tonic+i)%12] - mw
+		p := profile[i] - mp
+		num += w * p
+		dw += w * w
+		dp += p * p
+	}
+	if dw == 0 || dp == 0 {
+		return 0
+	}
+	return num / math.Sqrt(dw*dp)
+}
+
+// PitchClassName returns the sharp spelling of a pitch class (0 = C)
+func PitchClassName(pc int) string {
+	return noteNames[((pc%12)+12)%12]
+}
@@ -0,0 +1,55 @@
+package song
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// searchFold strips accents and folds case and character width so
+// "Beyonce" finds "Beyoncé" and half-width katakana finds full-width
+var searchFold = transform.Chain(
+	norm.NFD,
+	runes.Remove(runes.In(unicode.Mn)),
+	width.Fold,
+	cases.Fold(),
+	norm.NFC,
+)
+
+// foldForSearch normalizes text for matching
+func foldForSearch(s string) string {
+	folded, _, err := transform.String(searchFold, s)
+	if err != nil {
+		return strings.ToLower(s)
+	}
+	return folded
+}
+
+// Names returns every title and artist the song is known by
+func (s *Song) Names() []string {
+	names := make([]string, 0, 2+len(s.AltTitles)+len(s.AltArtists))
+	names = append(names, s.Title, s.Artist)
+	names = append(names, s.AltTitles...)
+	return append(names, s.AltArtists...)
+}
+
+// Matches reports whether every word of a search query appears in the
+// song's title, artist, or any of their alternate forms
+func (s *Song) Matches(query string) bool {
+	words := strings.Fields(foldForSearch(query))
+	if len(words) == 0 {
+		return true
+	}
+	haystack := foldForSearch(strings.Join(s.Names(), "\n"))
+	for _, w := range words {
+		if !strings.Contains(haystack, w) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package song
+
+// FretRegions are the areas of the neck play is broken down by: open
+// strings, then positions a hand covers without shifting
+var FretRegions = []FretRegion{
+	{Name: "Open", First: 0, Last: 0},
+	{Name: "1-4", First: 1, Last: 4},
+	{Name: "5-8", First: 5, Last: 8},
+	{Name: "9-12", First: 9, Last: 12},
+	{Name: "13+", First: 13, Last: MaxFret},
+}
+
+// FretRegion is a span of frets, inclusive
+type FretRegion struct {
+	Name        string
+	First, Last int
+}
+
+// NoteCount tallies hits and misses
+type NoteCount struct {
+	Hit, Missed int
+}
+
+// Total returns how many notes were judged
+func (c NoteCount) Total() int {
+	return c.Hit + c.Missed
+}
+
+// PositionStats breaks down judged notes by where on the neck they are
+type PositionStats struct {
+	// Cells[string][region] counts notes on each string in each of FretRegions
+	Cells [][]NoteCount
+}
+
+// OnString returns the totals for one string
+func (p PositionStats) OnString(str int) NoteCount {
+	var c NoteCount
+	for _, cell := range p.Cells[str] {
+		c.Hit += cell.Hit
+		c.Missed += cell.Missed
+	}
+	return c
+}
+
+// InRegion returns the totals for one fret region across all strings
+func (p PositionStats) InRegion(region int) NoteCount {
+	var c NoteCount
+	for _, row := range p.Cells {
+		c.Hit += row[region].Hit
+		c.Missed += row[region].Missed
+	}
+	return c
+}
+
+// PositionStats counts the hits and misses so far on each string and
+// fret region, to show where on the neck mistakes happen
+func (g *GameState) PositionStats() PositionStats {
+	stats := PositionStats{Cells: make([][]NoteCount, len(g.Song.GetTuning()))}
+	for i := range stats.Cells {
+		stats.Cells[i] = make([]NoteCount, len(FretRegions))
+	}
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if !note.Hit || note.String < 0 || note.String >= len(stats.Cells) {
+			continue
+		}
+		region := fretRegion(note.Fret)
+		if note.HitQuality == HitMiss {
+			stats.Cells[note.String][region].Missed++
+		} else {
+			stats.Cells[note.String][region].Hit++
+		}
+	}
+	return stats
+}
+
+// fretRegion returns the index in FretRegions of the region holding a fret
+func fretRegion(fret int) int {
+	for i, r := range FretRegions {
+		if fret <= r.Last {
+			return i
+		}
+	}
+	return len(FretRegions) - 1
+}
+
+// QualityCounts tallies judged notes by how well they were played,
+// indexed by HitQuality
+type QualityCounts [HitPerfect + 1]int
+
+// QualityCounts counts the notes judged so far at each hit quality
+func (g *GameState) QualityCounts() QualityCounts {
+	var counts QualityCounts
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if note.Hit && note.HitQuality >= HitMiss && note.HitQuality <= HitPerfect {
+			counts[note.HitQuality]++
+		}
+	}
+	return counts
+}
+
+// SectionStat is how well one of a song's passages was played
+type SectionStat struct {
+	Region
+	Accuracy float64
+	Judged   int
+}
+
+// SectionStats returns the accuracy in each of the song's sections that
+// has had notes judged, in song order
+func (g *GameState) SectionStats() []SectionStat {
+	var stats []SectionStat
+	for _, r := range g.Song.SectionRegions() {
+		accuracy, judged := g.RegionAccuracy(r)
+		if judged > 0 {
+			stats = append(stats, SectionStat{Region: r, Accuracy: accuracy, Judged: judged})
+		}
+	}
+	return stats
+}
@@ -0,0 +1,37 @@
+package song
+
+import "math"
+
+// HitTiming is how early or late one note was hit
+type HitTiming struct {
+	Time   float64 // Song time of the note
+	Offset float64 // Seconds late; negative is early
+}
+
+// HitTimings returns the timing of every note hit so far, in song order
+func (g *GameState) HitTimings() []HitTiming {
+	var hits []HitTiming
+	for i := range g.Song.Notes {
+		note := &g.Song.Notes[i]
+		if note.Hit && note.HitQuality != HitMiss {
+			hits = append(hits, HitTiming{Time: note.Time, Offset: note.HitOffset()})
+		}
+	}
+	return hits
+}
+
+// TimingSpread returns the mean and standard deviation of hit offsets, in
+// seconds: the mean shows rushing or dragging, the deviation consistency
+func TimingSpread(hits []HitTiming) (mean, stddev float64) {
+	if len(hits) == 0 {
+		return 0, 0
+	}
+	for _, h := range hits {
+		mean += h.Offset
+	}
+	mean /= float64(len(hits))
+	for _, h := range hits {
+		stddev += (h.Offset - mean) * (h.Offset - mean)
+	}
+	return mean, math.Sqrt(stddev / float64(len(hits)))
+}
@@ -0,0 +1,1046 @@
+// Package song holds charts and their play: loading and saving charts,
+// tunings and instruments, beat and time conversion, and the state and
+// scoring of a game in progress
+package song
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bass guitar strings (standard tuning)
+const (
+	StringG = 0 // G string (highest, thinnest)
+	StringD = 1 // D string
+	StringA = 2 // A string
+	StringE = 3 // E string (lowest, thickest)
+)
+
+// StringTuning represents the tuning of a single string
+type StringTuning struct {
+	Note   string // Note name (C, C#, D, etc.)
+	Octave int    // Octave number
+}
+
+// Semitone returns the semitone value (0-11) for this note
+func (s StringTuning) Semitone() int {
+	noteMap := map[string]int{
+		"C": 0, "C#": 1, "Db": 1,
+		"D": 2, "D#": 3, "Eb": 3,
+		"E": 4, "Fb": 4,
+		"F": 5, "F#": 6, "Gb": 6,
+		"G": 7, "G#": 8, "Ab": 8,
+		"A": 9, "A#": 10, "Bb": 10,
+		"B": 11, "Cb": 11,
+	}
+	return noteMap[s.Note]
+}
+
+// MIDINote returns the MIDI note number of the open string
+func (s StringTuning) MIDINote() int {
+	return (s.Octave+1)*12 + s.Semitone()
+}
+
+// Tuning represents the tuning for all strings (high to low)
+type Tuning []StringTuning
+
+// Name returns the predefined name of the tuning (e.g. "drop-d"), or the
+// custom form accepted by ParseTuning (e.g. "G2,D2,A1,C1")
+func (t Tuning) Name() string {
+	for _, inst := range InstrumentsByName {
+		for name, predefined := range inst.Tunings {
+			if t.equal(predefined) {
+				return name
+			}
+		}
+	}
+	parts := make([]string, len(t))
+	for i, s := range t {
+		parts[i] = fmt.Sprintf("%s%d", s.Note, s.Octave)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (t Tuning) equal(other Tuning) bool {
+	if len(t) != len(other) {
+		return false
+	}
+	for i := range t {
+		if t[i].MIDINote() != other[i].MIDINote() {
+			return false
+		}
+	}
+	return true
+}
+
+// Position is a string and fret on the fretboard
+type Position struct {
+	String int
+	Fret   int
+}
+
+// Positions returns every string and fret (up to maxFret) that plays a MIDI note
+func (t Tuning) Positions(midiNote, maxFret int) []Position {
+	var positions []Position
+	for i, s := range t {
+		fret := midiNote - s.MIDINote()
+		if fret >= 0 && fret <= maxFret {
+			positions = append(positions, Position{String: i, Fret: fret})
+		}
+	}
+	return positions
+}
+
+// NearestPosition picks where to play a MIDI note, staying close to the
+// fret the hand is already at so lines don't jump around the neck
+func (t Tuning) NearestPosition(midiNote, maxFret, nearFret int) (Position, bool) {
+	positions := t.Positions(midiNote, maxFret)
+	if len(positions) == 0 {
+		return Position{}, false
+	}
+	best := positions[0]
+	bestCost := math.MaxInt
+	for _, p := range positions {
+		cost := abs(p.Fret - nearFret)
+		// Open strings need no hand movement
+		if p.Fret == 0 {
+			cost = 1
+		}
+		if cost < bestCost {
+			best, bestCost = p, cost
+		}
+	}
+	return best, true
+}
+
+// Predefined tunings
+var (
+	// TuningStandard is standard 4-string bass tuning (G-D-A-E)
+	TuningStandard = Tuning{
+		{Note: "G", Octave: 2},
+		{Note: "D", Octave: 2},
+		{Note: "A", Octave: 1},
+		{Note: "E", Octave: 1},
+	}
+
+	// TuningDropD is drop D tuning (G-D-A-D)
+	TuningDropD = Tuning{
+		{Note: "G", Octave: 2},
+		{Note: "D", Octave: 2},
+		{Note: "A", Octave: 1},
+		{Note: "D", Octave: 1},
+	}
+
+	// TuningHalfStepDown is half step down tuning (Gb-Db-Ab-Eb)
+	TuningHalfStepDown = Tuning{
+		{Note: "Gb", Octave: 2},
+		{Note: "Db", Octave: 2},
+		{Note: "Ab", Octave: 1},
+		{Note: "Eb", Octave: 1},
+	}
+
+	// TuningFullStepDown is full step down tuning (F-C-G-D)
+	TuningFullStepDown = Tuning{
+		{Note: "F", Octave: 2},
+		{Note: "C", Octave: 2},
+		{Note: "G", Octave: 1},
+		{Note: "D", Octave: 1},
+	}
+
+	// Tuning5StringStandard is standard 5-string bass tuning (G-D-A-E-B)
+	Tuning5StringStandard = Tuning{
+		{Note: "G", Octave: 2},
+		{Note: "D", Octave: 2},
+		{Note: "A", Octave: 1},
+		{Note: "E", Octave: 1},
+		{Note: "B", Octave: 0},
+	}
+
+	// Tuning6StringStandard is standard 6-string bass tuning (C-G-D-A-E-B)
+	Tuning6StringStandard = Tuning{
+		{Note: "C", Octave: 3},
+		{Note: "G", Octave: 2},
+		{Note: "D", Octave: 2},
+		{Note: "A", Octave: 1},
+		{Note: "E", Octave: 1},
+		{Note: "B", Octave: 0},
+	}
+
+	// TuningsByName maps tuning names to tuning values
+	TuningsByName = map[string]Tuning{
+		"standard":       TuningStandard,
+		"drop-d":         TuningDropD,
+		"half-step-down": TuningHalfStepDown,
+		"full-step-down": TuningFullStepDown,
+		"5-string":       Tuning5StringStandard,
+		"6-string":       Tuning6StringStandard,
+	}
+)
+
+// ParseTuning parses a tuning from a string or slice
+// Accepts: "standard", "drop-d", or custom like "G2,D2,A1,D1"
+func ParseTuning(s string) Tuning {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	// Check predefined tunings
+	if t, ok := TuningsByName[s]; ok {
+		return t
+	}
+
+	// Try to parse custom tuning (e.g., "G2,D2,A1,E1")
+	parts := strings.Split(s, ",")
+	if len(parts) >= 4 {
+		tuning := make(Tuning, len(parts))
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if len(part) >= 2 {
+				// Last character is octave
+				octave := int(part[len(part)-1] - '0')
+				note := strings.ToUpper(part[:len(part)-1])
+				tuning[i] = StringTuning{Note: note, Octave: octave}
+			}
+		}
+		return tuning
+	}
+
+	// Default to standard
+	return TuningStandard
+}
+
+// Hit quality levels
+type HitQuality int
+
+const (
+	HitMiss HitQuality = iota
+	HitOK
+	HitGood
+	HitPerfect
+)
+
+func (h HitQuality) String() string {
+	switch h {
+	case HitPerfect:
+		return "Perfect!"
+	case HitGood:
+		return "Good"
+	case HitOK:
+		return "OK"
+	default:
+		return "Miss"
+	}
+}
+
+func (h HitQuality) Score() int {
+	switch h {
+	case HitPerfect:
+		return 100
+	case HitGood:
+		return 50
+	case HitOK:
+		return 25
+	default:
+		return 0
+	}
+}
+
+// Pitch windows in cents (100 cents = 1 semitone)
+const (
+	SnapCents     = 50 // Fretted: anything closer to the note than its neighbours counts
+	FretlessCents = 80 // Fretless: a wider window, scored by how close the pitch was
+	InTuneCents   = 5  // Close enough to score full points on a fretless hit
+)
+
+// Intonation limits on a fretted hit: a Perfect must be within
+// PerfectCents of the note, and a Good within GoodCents
+const (
+	PerfectCents = 15
+	GoodCents    = 30
+)
+
+// IntonationQuality returns the best quality a fretted hit this many
+// cents off the note can score
+func IntonationQuality(cents float64) HitQuality {
+	off := math.Abs(cents)
+	switch {
+	case off <= PerfectCents:
+		return HitPerfect
+	case off <= GoodCents:
+		return HitGood
+	default:
+		return HitOK
+	}
+}
+
+// PitchCredit is how much a fretted hit counts toward the grade: fully
+// within PerfectCents of the note, falling to half at the edge of the window
+func PitchCredit(cents float64) float64 {
+	off := math.Abs(cents)
+	if off <= PerfectCents {
+		return 1
+	}
+	return 1 - 0.5*math.Min((off-PerfectCents)/(SnapCents-PerfectCents), 1)
+}
+
+// IntonationScore scales a fretless hit's points by how close to pitch it
+// was: full points when in tune, falling to half at the edge of the window
+func IntonationScore(cents float64) float64 {
+	off := math.Abs(cents)
+	if off <= InTuneCents {
+		return 1
+	}
+	return 1 - 0.5*math.Min((off-InTuneCents)/(FretlessCents-InTuneCents), 1)
+}
+
+// TimingWindows are how far from a note, in seconds, a hit still scores
+// each quality
+type TimingWindows struct {
+	Perfect, Good, OK float64
+}
+
+// Judge returns the quality a hit this far off the beat earns, in
+// seconds either way
+func (w TimingWindows) Judge(offset float64) HitQuality {
+	offset = math.Abs(offset)
+	switch {
+	case offset <= w.Perfect:
+		return HitPerfect
+	case offset <= w.Good:
+		return HitGood
+	case offset <= w.OK:
+		return HitOK
+	default:
+		return HitMiss
+	}
+}
+
+// TabNote represents a single note in tablature
+type TabNote struct {
+	Time     float64 `yaml:"time,omitempty" json:"time,omitempty"`         // Time in seconds from song start
+	Beat     Beat    `yaml:"beat,omitempty" json:"beat,omitempty"`         // Beat number, e.g. 4.5 or "4+2/3" (converted to time using BPM)
+	String   int     `yaml:"string" json:"string"`                         // 0 = highest string (G on bass, high E on guitar)
+	Fret     int     `yaml:"fret" json:"fret"`                             // Fret number (0 = open string)
+	Duration float64 `yaml:"duration,omitempty" json:"duration,omitempty"` // Note duration in seconds (optional)
+	// Substitute overrides the open-string setting for this note: whether
+	// the same pitch on another string (open for fretted, or fretted for
+	// open) still counts
+	Substitute *bool `yaml:"substitute,omitempty" json:"substitute,omitempty"`
+
+	// Runtime state (not serialized)
+	Hit        bool       `yaml:"-" json:"-"`
+	HitQuality HitQuality `yaml:"-" json:"-"`
+	HitTime    float64    `yaml:"-" json:"-"`
+	HitCents   float64    `yaml:"-" json:"-"` // How far the played pitch was from the note
+}
+
+// noteNames are the sharp spellings of the twelve pitch classes from C
+var noteNames = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+// NoteWithTuning returns the note name for this tab position using the given tuning
+func (n *TabNote) NoteWithTuning(tuning Tuning) string {
+	if n.String >= len(tuning) {
+		return "?"
+	}
+
+	openString := tuning[n.String]
+	baseNote := openString.Semitone()
+
+	if n.Fret == 0 {
+		return openString.Note
+	}
+
+	noteIndex := (baseNote + n.Fret) % 12
+	return noteNames[noteIndex]
+}
+
+// OctaveWithTuning returns the octave for this tab position using the given tuning
+func (n *TabNote) OctaveWithTuning(tuning Tuning) int {
+	if n.String >= len(tuning) {
+		return 1
+	}
+
+	openString := tuning[n.String]
+	baseOctave := openString.Octave
+	baseNote := openString.Semitone()
+
+	notePos := baseNote + n.Fret
+
+	// Add octaves for every 12 semitones
+	return baseOctave + notePos/12
+}
+
+// MIDINoteWithTuning returns the MIDI note number for this tab position using the given tuning
+func (n *TabNote) MIDINoteWithTuning(tuning Tuning) int {
+	if n.String >= len(tuning) {
+		return 0
+	}
+	return tuning[n.String].MIDINote() + n.Fret
+}
+
+// HitOffset returns how late the note was played, in song seconds;
+// negative means early
+func (n *TabNote) HitOffset() float64 {
+	return n.HitTime - n.Time
+}
+
+// Note returns the note name using standard tuning (for backwards compatibility)
+func (n *TabNote) Note() string {
+	return n.NoteWithTuning(TuningStandard)
+}
+
+// Octave returns the octave using standard tuning (for backwards compatibility)
+func (n *TabNote) Octave() int {
+	return n.OctaveWithTuning(TuningStandard)
+}
+
+// TempoChange sets a new tempo from a beat onwards
+type TempoChange struct {
+	Beat float64 `yaml:"beat" json:"beat"`
+	BPM  float64 `yaml:"bpm" json:"bpm"`
+}
+
+// Section names a part of the song (verse, chorus...) from a beat onwards
+type Section struct {
+	Beat float64 `yaml:"beat" json:"beat"`
+	Name string  `yaml:"name" json:"name"`
+}
+
+// Song represents a complete song with tablature
+type Song struct {
+	Title          string        `yaml:"title" json:"title"`
+	Artist         string        `yaml:"artist" json:"artist"`
+	AltTitles      []string      `yaml:"alt_titles,omitempty" json:"alt_titles,omitempty"`   // Other forms of the title (original script, romanized, translated)
+	AltArtists     []string      `yaml:"alt_artists,omitempty" json:"alt_artists,omitempty"` // Other forms of the artist name
+	Album          string        `yaml:"album,omitempty" json:"album,omitempty"`
+	Genre          string        `yaml:"genre,omitempty" json:"genre,omitempty"`
+	Year           int           `yaml:"year,omitempty" json:"year,omitempty"`             // Year of release
+	BPM            float64       `yaml:"bpm" json:"bpm"`                                   // Starting tempo
+	Tempo          []TempoChange `yaml:"tempo,omitempty" json:"tempo,omitempty"`           // Tempo changes after the start, in beat order
+	Sections       []Section     `yaml:"sections,omitempty" json:"sections,omitempty"`     // Named parts of the song, in beat order
+	Swing          float64       `yaml:"swing,omitempty" json:"swing,omitempty"`           // Percent of each beat the on-beat eighth takes: 50 (or unset) is straight, 67 a triplet shuffle
+	TuningStr      string        `yaml:"tuning,omitempty" json:"tuning,omitempty"`         // Tuning name or custom (e.g., "standard", "drop-d", "G2,D2,A1,D1")
+	InstrumentName string        `yaml:"instrument,omitempty" json:"instrument,omitempty"` // "bass" (default) or "guitar"
+	Drums          string        `yaml:"drums,omitempty" json:"drums,omitempty"`           // Default drum backing feel (e.g., "rock", "funk", "swing")
+	Audio          string        `yaml:"audio,omitempty" json:"audio,omitempty"`           // Backing track (WAV), relative to the chart
+	Cover          string        `yaml:"cover,omitempty" json:"cover,omitempty"`           // Cover art image, relative to the chart
+	Capo           int           `yaml:"capo,omitempty" json:"capo,omitempty"`             // Fret the capo is at; chart frets are relative to it
+	Fretless       bool          `yaml:"fretless,omitempty" json:"fretless,omitempty"`     // Score intonation in cents instead of snapping to the nearest note
+	Notes          []TabNote     `yaml:"notes" json:"notes"`
+
+	// Runtime state
+	Duration  float64 `yaml:"-" json:"-"`
+	Tuning    Tuning  `yaml:"-" json:"-"` // Parsed tuning (set during load)
+	Path      string  `yaml:"-" json:"-"` // File the song was loaded from (empty for built-ins and packs)
+	Pack      string  `yaml:"-" json:"-"` // Song pack archive the song was loaded from, if any
+	Generator string  `yaml:"-" json:"-"` // Script the song was generated by, if any
+	packDir   string  // Directory of the chart within its pack
+	transpose int     // Semitones the sounding pitch is shifted for practice
+}
+
+// GetTuning returns the song's tuning, defaulting to the instrument's
+// standard tuning if not set
+func (s *Song) GetTuning() Tuning {
+	if s.Tuning != nil {
+		return s.Tuning
+	}
+	return s.Instrument().ParseTuning(s.TuningStr)
+}
+
+// Instrument returns the instrument the chart is written for
+func (s *Song) Instrument() *Instrument {
+	return LookupInstrument(s.InstrumentName)
+}
+
+// Transpose shifts the pitches the song expects by a number of semitones
+// without changing the frets shown, as if the capo moved. It's for
+// practicing in another key and isn't saved with the chart.
+func (s *Song) Transpose(semitones int) {
+	s.transpose += semitones
+}
+
+// Transposition returns how far the song has been transposed, in semitones
+func (s *Song) Transposition() int {
+	return s.transpose
+}
+
+// PitchOffset returns how many semitones the sounding notes sit above the
+// written frets, from the capo and any transposition
+func (s *Song) PitchOffset() int {
+	return s.Capo + s.transpose
+}
+
+// NoteAt returns the note name for a given TabNote using this song's tuning
+func (s *Song) NoteAt(note *TabNote) string {
+	if s.PitchOffset() == 0 {
+		return note.NoteWithTuning(s.GetTuning())
+	}
+	return noteNames[s.MIDINoteAt(note)%12]
+}
+
+// OctaveAt returns the octave for a given TabNote using this song's tuning
+func (s *Song) OctaveAt(note *TabNote) int {
+	if s.PitchOffset() == 0 {
+		return note.OctaveWithTuning(s.GetTuning())
+	}
+	return s.MIDINoteAt(note)/12 - 1
+}
+
+// MIDINoteAt returns the sounding MIDI note for a given TabNote, allowing
+// for the capo and transposition
+func (s *Song) MIDINoteAt(note *TabNote) int {
+	return note.MIDINoteWithTuning(s.GetTuning()) + s.PitchOffset()
+}
+
+// HasOpenEquivalent reports whether a note could be played either open
+// or fretted: an open string another string can fret, or a fretted note
+// another string plays open
+func (s *Song) HasOpenEquivalent(note *TabNote) bool {
+	tuning := s.GetTuning()
+	if note.String < 0 || note.String >= len(tuning) {
+		return false
+	}
+	midiNote := note.MIDINoteWithTuning(tuning)
+	for _, p := range tuning.Positions(midiNote, MaxFret-s.Capo) {
+		if p.String != note.String && (note.Fret == 0) != (p.Fret == 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// FrequencyAt returns the expected frequency in Hz for a given TabNote using this song's tuning
+func (s *Song) FrequencyAt(note *TabNote) float64 {
+	return midiToFrequency(s.MIDINoteAt(note))
+}
+
+// BeatDuration returns the length of one beat in seconds at the starting tempo
+func (s *Song) BeatDuration() float64 {
+	return 60.0 / s.startBPM()
+}
+
+// startBPM returns the starting tempo, defaulting to 120 if unset
+func (s *Song) startBPM() float64 {
+	if s.BPM <= 0 {
+		return 120
+	}
+	return s.BPM
+}
+
+// BPMAt returns the tempo in effect at a beat
+func (s *Song) BPMAt(beat float64) float64 {
+	bpm := s.startBPM()
+	for _, change := range s.Tempo {
+		if change.Beat > beat {
+			break
+		}
+		if change.BPM > 0 {
+			bpm = change.BPM
+		}
+	}
+	return bpm
+}
+
+// BeatToTime converts a beat number to seconds from song start, following the tempo map
+func (s *Song) BeatToTime(beat float64) float64 {
+	t := 0.0
+	segmentStart := 0.0
+	bpm := s.startBPM()
+	for _, change := range s.Tempo {
+		if change.Beat >= beat {
+			break
+		}
+		if change.BPM <= 0 {
+			continue
+		}
+		t += (change.Beat - segmentStart) * 60.0 / bpm
+		segmentStart = change.Beat
+		bpm = change.BPM
+	}
+	return t + (beat-segmentStart)*60.0/bpm
+}
+
+// TimeToBeat converts seconds from song start to a beat number, following the tempo map
+func (s *Song) TimeToBeat(t float64) float64 {
+	elapsed := 0.0
+	segmentStart := 0.0
+	bpm := s.startBPM()
+	for _, change := range s.Tempo {
+		if change.BPM <= 0 {
+			continue
+		}
+		changeTime := elapsed + (change.Beat-segmentStart)*60.0/bpm
+		if changeTime >= t {
+			break
+		}
+		elapsed = changeTime
+		segmentStart = change.Beat
+		bpm = change.BPM
+	}
+	return segmentStart + (t-elapsed)*bpm/60.0
+}
+
+// swingRatio returns the fraction of each beat the on-beat eighth takes
+func (s *Song) swingRatio() float64 {
+	if s.Swing <= 0 || s.Swing >= 100 {
+		return 0.5
+	}
+	return s.Swing / 100
+}
+
+// SwungBeat delays the off-beats of a written beat position by the song's
+// swing. Positions within each half of the beat are stretched or squeezed
+// along with it, so sixteenths swing too.
+func (s *Song) SwungBeat(beat float64) float64 {
+	r := s.swingRatio()
+	if r == 0.5 || beat < 0 {
+		return beat
+	}
+	whole, frac := math.Modf(beat)
+	if frac < 0.5 {
+		return whole + frac*2*r
+	}
+	return whole + r + (frac-0.5)*2*(1-r)
+}
+
+// StraightBeat undoes SwungBeat, giving the written position of a beat
+func (s *Song) StraightBeat(beat float64) float64 {
+	r := s.swingRatio()
+	if r == 0.5 || beat < 0 {
+		return beat
+	}
+	whole, frac := math.Modf(beat)
+	if frac < r {
+		return whole + frac/(2*r)
+	}
+	return whole + 0.5 + (frac-r)/(2*(1-r))
+}
+
+// NoteTime returns when a note written at a beat is played, with swing
+func (s *Song) NoteTime(beat float64) float64 {
+	return s.BeatToTime(s.SwungBeat(beat))
+}
+
+// NoteBeat returns the written beat of a note played at a time, taking
+// out any swing
+func (s *Song) NoteBeat(t float64) float64 {
+	return s.StraightBeat(s.TimeToBeat(t))
+}
+
+// SortNotes orders the notes by time
+func (s *Song) SortNotes() {
+	sort.SliceStable(s.Notes, func(i, j int) bool {
+		return s.Notes[i].Time < s.Notes[j].Time
+	})
+}
+
+// IsStringCrossing reports whether the note at index i moves to a different
+// string from the note before it (notes must be sorted by time)
+func (s *Song) IsStringCrossing(i int) bool {
+	if i <= 0 || i >= len(s.Notes) {
+		return false
+	}
+	prev, note := &s.Notes[i-1], &s.Notes[i]
+	// Notes struck together are a chord, not a crossing
+	if note.Time-prev.Time < 0.001 {
+		return false
+	}
+	return prev.String != note.String
+}
+
+// NoteAtTime returns notes that should be played at the given time
+func (s *Song) NotesInRange(startTime, endTime float64) []*TabNote {
+	var notes []*TabNote
+	for i := range s.Notes {
+		if s.Notes[i].Time >= startTime && s.Notes[i].Time <= endTime {
+			notes = append(notes, &s.Notes[i])
+		}
+	}
+	return notes
+}
+
+// NextUnhitNote returns the next note that hasn't been hit yet
+func (s *Song) NextUnhitNote(currentTime float64) *TabNote {
+	for i := range s.Notes {
+		if !s.Notes[i].Hit && s.Notes[i].Time >= currentTime-0.5 {
+			return &s.Notes[i]
+		}
+	}
+	return nil
+}
+
+// ResetProgress clears the hit state left on notes by a previous play
+func (s *Song) ResetProgress() {
+	for i := range s.Notes {
+		s.Notes[i].Hit = false
+		s.Notes[i].HitQuality = HitMiss
+		s.Notes[i].HitTime = 0
+		s.Notes[i].HitCents = 0
+	}
+}
+
+// CalculateDuration sets the song duration based on the last note
+func (s *Song) CalculateDuration() {
+	if len(s.Notes) == 0 {
+		s.Duration = 0
+		return
+	}
+	lastNote := s.Notes[len(s.Notes)-1]
+	s.Duration = lastNote.Time + lastNote.Duration + 2.0 // 2 second buffer
+}
+
+// GameState holds the current game state
+type GameState struct {
+	Song         *Song
+	StartTime    time.Time
+	CurrentTime  float64
+	Score        int
+	Combo        int
+	MaxCombo     int
+	NotesHit     int
+	NotesMissed  int
+	TotalNotes   int
+	IsPlaying    bool
+	IsFinished   bool
+	FloatingText []FloatingScore
+
+	// BrokenCombo is the length of the last combo long enough to earn a
+	// multiplier that a miss ended, at ComboBrokenAt
+	BrokenCombo   int
+	ComboBrokenAt time.Time
+
+	// Fretless scores hits by intonation as well as timing
+	Fretless bool
+	// StrictOpenStrings refuses open strings played fretted and the reverse
+	StrictOpenStrings bool
+	// Windows judges hit timing; the zero value uses the normal windows
+	Windows TimingWindows
+	// WrongNotePenalty makes played notes that match nothing break the
+	// combo and cost WrongNotePoints
+	WrongNotePenalty bool
+	WrongNotes       int
+	// RhythmOnly counts any played note as a hit on the next note due,
+	// whatever its pitch, judging timing alone
+	RhythmOnly bool
+	// FailMode ends the song early, as Failed, once misses have drained
+	// Health (0 to 1) to nothing
+	FailMode bool
+	Health   float64
+	Failed   bool
+	// InputLatency is how long, in seconds, after a note is played it's
+	// heard; hits are judged as of that much earlier
+	InputLatency float64
+	// OnJudged, when set, is called with each note as it's hit or missed,
+	// once the score and combo have taken it into account
+	OnJudged func(note *TabNote)
+	// Judge, when set, may change the quality a note was judged at before
+	// it's scored, and Points what it scores, given the points it would
+	// otherwise earn; game modes use them to change the rules
+	Judge  func(note *TabNote, quality HitQuality) HitQuality
+	Points func(note *TabNote, quality HitQuality, points int) int
+
+	// Speed scales how fast song time passes (1, or 0 for unset, is full
+	// speed), and Loop, when set, limits play to a passage that repeats
+	Speed       float64
+	Loop        *Region
+	Laps        int       // Times round the loop, counting the current one
+	LapScores   []float64 // Accuracy of each finished time round the loop
+	startAt     float64   // Song time play began from
+	totalCents  float64   // Sum of the absolute cents deviation of hits
+	totalOffset float64   // Sum of how late hits were, in seconds (negative is early)
+	pitchCredit float64   // Sum of the pitch credit of hits, for the grade
+}
+
+// FloatingScore represents floating score text
+type FloatingScore struct {
+	Text      string
+	X, Y      float32
+	StartTime time.Time
+	Quality   HitQuality
+	Judged    bool // Feedback on a played note, drawn with its hit quality's symbol
+}
+
+// NewGameState creates a new game state for a song
+func NewGameState(song *Song) *GameState {
+	song.ResetProgress()
+	song.CalculateDuration()
+	return &GameState{
+		Song:         song,
+		TotalNotes:   len(song.Notes),
+		FloatingText: make([]FloatingScore, 0),
+		Health:       1,
+	}
+}
+
+// Start begins the game
+func (g *GameState) Start() {
+	g.StartAt(0)
+}
+
+// StartAt begins the game from a point in the song
+func (g *GameState) StartAt(t float64) {
+	g.StartTime = time.Now()
+	g.startAt = t
+	g.CurrentTime = t
+	g.IsPlaying = true
+	g.IsFinished = false
+}
+
+// Update updates the game state
+func (g *GameState) Update() {
+	if !g.IsPlaying {
+		return
+	}
+
+	speed := g.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	g.AdvanceTo(g.startAt + time.Since(g.StartTime).Seconds()*speed)
+}
+
+// AdvanceTo moves play on to a song time without looking at the clock,
+// for running a song faster than real time, as when scoring a recorded
+// take
+func (g *GameState) AdvanceTo(t float64) {
+	if !g.IsPlaying {
+		return
+	}
+	g.CurrentTime = t
+
+	// Go round a loop again, or check for finished
+	if g.Loop != nil {
+		if g.CurrentTime > g.Loop.End+loopTail {
+			g.restartLoop()
+		}
+	} else if g.CurrentTime > g.Song.Duration {
+		g.IsPlaying = false
+		g.IsFinished = true
+	}
+
+	// Clean up old floating text (fade after 1 second)
+	newFloating := make([]FloatingScore, 0)
+	for _, f := range g.FloatingText {
+		if time.Since(f.StartTime).Seconds() < 1.0 {
+			newFloating = append(newFloating, f)
+		}
+	}
+	g.FloatingText = newFloating
+}
+
+// JudgedTime returns the song time notes being heard now were played at
+func (g *GameState) JudgedTime() float64 {
+	return g.CurrentTime - g.InputLatency
+}
+
+// RegisterHit records a note hit
+func (g *GameState) RegisterHit(note *TabNote, quality HitQuality, x, y float32) {
+	if g.Judge != nil {
+		quality = g.Judge(note, quality)
+	}
+	note.Hit = true
+	note.HitQuality = quality
+	note.HitTime = g.JudgedTime()
+
+	points := quality.Score()
+	if quality != HitMiss {
+		g.totalCents += math.Abs(note.HitCents)
+		g.totalOffset += note.HitOffset()
+		credit := PitchCredit(note.HitCents)
+		if g.Fretless {
+			credit = IntonationScore(note.HitCents)
+			points = int(math.Round(float64(points) * credit))
+		}
+		g.pitchCredit += credit
+	}
+
+	if quality != HitMiss {
+		g.Combo++
+		if g.Combo > g.MaxCombo {
+			g.MaxCombo = g.Combo
+		}
+		points *= g.Multiplier()
+		g.NotesHit++
+	} else {
+		g.breakCombo()
+		g.NotesMissed++
+	}
+	if g.Points != nil {
+		points = g.Points(note, quality, points)
+	}
+
+	g.Score += points
+	g.updateHealth(quality)
+
+	// Add floating text
+	text := quality.String()
+	if points > 0 && g.Multiplier() > 1 {
+		text += fmt.Sprintf(" x%d", g.Multiplier())
+	}
+	if quality != HitMiss {
+		text += fmt.Sprintf(" %+.0fms %+.0f¢", note.HitOffset()*1000, note.HitCents)
+	}
+	g.FloatingText = append(g.FloatingText, FloatingScore{
+		Text:      text,
+		X:         x,
+		Y:         y,
+		StartTime: time.Now(),
+		Quality:   quality,
+		Judged:    true,
+	})
+	if g.OnJudged != nil {
+		g.OnJudged(note)
+	}
+}
+
+// ComboThresholds are the combo lengths at which the score multiplier
+// rises to x2, x3 and x4
+var ComboThresholds = []int{10, 25, 50}
+
+// Multiplier returns what the current combo multiplies each hit's points by
+func (g *GameState) Multiplier() int {
+	m := 1
+	for _, t := range ComboThresholds {
+		if g.Combo >= t {
+			m++
+		}
+	}
+	return m
+}
+
+// NextMultiplierAt returns the combo length at which the multiplier next
+// rises, or 0 once it's at its highest
+func (g *GameState) NextMultiplierAt() int {
+	for _, t := range ComboThresholds {
+		if g.Combo < t {
+			return t
+		}
+	}
+	return 0
+}
+
+// breakCombo ends the current combo, noting it if it had earned a multiplier
+func (g *GameState) breakCombo() {
+	if g.Multiplier() > 1 {
+		g.BrokenCombo = g.Combo
+		g.ComboBrokenAt = time.Now()
+	}
+	g.Combo = 0
+}
+
+// How much health a miss drains and a hit restores in fail mode; a clean
+// hit wins back less than a miss costs, so a run of misses is what fails
+const (
+	healthMissDrain = 0.08
+	healthHitGain   = 0.02
+)
+
+// updateHealth drains or restores health for a judged note, failing the
+// song when it runs out
+func (g *GameState) updateHealth(quality HitQuality) {
+	if !g.FailMode || g.Failed {
+		return
+	}
+	if quality == HitMiss {
+		g.Health = math.Max(0, g.Health-healthMissDrain)
+	} else {
+		g.Health = math.Min(1, g.Health+healthHitGain)
+	}
+	if g.Health == 0 {
+		g.Failed = true
+		g.IsPlaying = false
+		g.IsFinished = true
+	}
+}
+
+// WrongNotePoints is what a wrong note costs when they're penalized
+const WrongNotePoints = 25
+
+// RegisterWrongNote penalizes a played note that matched nothing
+func (g *GameState) RegisterWrongNote(x, y float32) {
+	g.WrongNotes++
+	g.breakCombo()
+	g.Score = max(0, g.Score-WrongNotePoints)
+	g.FloatingText = append(g.FloatingText, FloatingScore{
+		Text:      fmt.Sprintf("Wrong note -%d", WrongNotePoints),
+		X:         x,
+		Y:         y,
+		StartTime: time.Now(),
+		Quality:   HitMiss,
+		Judged:    true,
+	})
+}
+
+// Accuracy returns the hit accuracy as a percentage
+func (g *GameState) Accuracy() float64 {
+	total := g.NotesHit + g.NotesMissed
+	if total == 0 {
+		return 100.0
+	}
+	return float64(g.NotesHit) / float64(total) * 100.0
+}
+
+// GradeAccuracy is Accuracy scaled down by how far off pitch hits were,
+// so sloppy fretting or a badly tuned bass lowers the grade
+func (g *GameState) GradeAccuracy() float64 {
+	if g.NotesHit == 0 {
+		return g.Accuracy()
+	}
+	return g.Accuracy() * g.pitchCredit / float64(g.NotesHit)
+}
+
+// Grade is the letter grade, from S down to F, for the accuracy
+// GradeAccuracy gives
+func (g *GameState) Grade() string {
+	return Grade(g.GradeAccuracy())
+}
+
+// Grade returns the letter grade for an accuracy percentage
+func Grade(accuracy float64) string {
+	switch {
+	case accuracy >= 95:
+		return "S"
+	case accuracy >= 90:
+		return "A"
+	case accuracy >= 80:
+		return "B"
+	case accuracy >= 70:
+		return "C"
+	case accuracy >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+// AverageOffset returns how late hits were on average, in seconds;
+// negative means early, showing a habit of rushing or dragging
+func (g *GameState) AverageOffset() float64 {
+	if g.NotesHit == 0 {
+		return 0
+	}
+	return g.totalOffset / float64(g.NotesHit)
+}
+
+// AverageCents returns how far off pitch hits were on average, in cents
+func (g *GameState) AverageCents() float64 {
+	if g.NotesHit == 0 {
+		return 0
+	}
+	return g.totalCents / float64(g.NotesHit)
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
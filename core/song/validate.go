@@ -0,0 +1,229 @@
+package song
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxFret is the highest fret a chart may use
+const MaxFret = 24
+
+// Validate checks a chart for mistakes that would make it unplayable,
+// returning every problem found joined into one error
+func (s *Song) Validate() error {
+	var errs []error
+	for _, issue := range s.Check() {
+		if issue.Severity == SeverityError {
+			errs = append(errs, issue)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Severity says how bad an Issue is
+type Severity string
+
+// Errors make a chart unplayable; warnings point at what's probably a
+// mistake, or hard to play
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem Check found in a chart
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Note     int      `json:"note,omitempty"` // Counted from 1 in time order; 0 if not about one note
+	Beat     Beat     `json:"beat,omitempty"` // The note's beat, if the chart gives one
+	Time     float64  `json:"time,omitempty"` // Where in the song, in seconds
+	Message  string   `json:"message"`
+}
+
+// Error describes the issue, saying which note it's about
+func (i Issue) Error() string {
+	switch {
+	case i.Note == 0:
+		return i.Message
+	case i.Beat > 0:
+		return fmt.Sprintf("note %d (beat %s): %s", i.Note, i.Beat, i.Message)
+	default:
+		return fmt.Sprintf("note %d: %s", i.Note, i.Message)
+	}
+}
+
+// Playability limits Check warns about: fretted notes sounding together
+// further apart than maxStretch frets, and shifts of more than that
+// between notes closer than quickShift seconds
+const (
+	maxStretch = 5
+	quickShift = 0.1
+)
+
+// chordWindow is how close, in seconds, notes are taken to sound together
+const chordWindow = 0.03
+
+// Check finds everything wrong with a chart: the errors Validate
+// reports, including notes that start together on one string, and
+// warnings about stretches and shifts a hand can't make
+func (s *Song) Check() []Issue {
+	var issues []Issue
+	fail := func(format string, args ...any) {
+		issues = append(issues, Issue{Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+	}
+	failNote := func(i int, format string, args ...any) {
+		issues = append(issues, s.noteIssue(SeverityError, i, format, args...))
+	}
+
+	if strings.TrimSpace(s.Title) == "" {
+		fail("missing title")
+	}
+	switch {
+	case s.BPM == 0:
+		fail("missing bpm")
+	case s.BPM < 0:
+		fail("bpm must be positive")
+	}
+	for _, tc := range s.Tempo {
+		if tc.BPM <= 0 {
+			fail("tempo change at beat %g: bpm must be positive", tc.Beat)
+		}
+	}
+
+	if s.Swing != 0 && (s.Swing < 50 || s.Swing >= 100) {
+		fail("swing %g%% out of range 50-99", s.Swing)
+	}
+	if s.Year < 0 {
+		fail("year %d is negative", s.Year)
+	}
+	for _, sec := range s.Sections {
+		if strings.TrimSpace(sec.Name) == "" {
+			fail("section at beat %g has no name", sec.Beat)
+		}
+		if sec.Beat < 0 {
+			fail("section %q: negative beat", sec.Name)
+		}
+	}
+
+	for _, msg := range s.tuningProblems() {
+		fail("%s", msg)
+	}
+	tuning := s.GetTuning()
+
+	if s.Capo < 0 || s.Capo > MaxFret {
+		fail("capo %d out of range 0-%d", s.Capo, MaxFret)
+	}
+
+	if len(s.Notes) == 0 {
+		fail("chart has no notes")
+	}
+	for i, n := range s.Notes {
+		if n.String < 0 || n.String >= len(tuning) {
+			failNote(i, "string %d out of range for %d-string tuning", n.String, len(tuning))
+		}
+		if n.Fret < 0 || s.Capo+n.Fret > MaxFret {
+			failNote(i, "fret %d out of range 0-%d", n.Fret, MaxFret-s.Capo)
+		}
+		if n.Time < 0 || n.Beat < 0 {
+			failNote(i, "negative position")
+		}
+		if n.Duration < 0 {
+			failNote(i, "negative duration")
+		}
+	}
+	// Without a tempo, notes placed by beat have no time to compare
+	if s.BPM > 0 {
+		issues = append(issues, s.checkOverlaps()...)
+		issues = append(issues, s.checkReach()...)
+	}
+	return issues
+}
+
+// checkOverlaps finds notes that start together on one string, which
+// can't be played
+func (s *Song) checkOverlaps() []Issue {
+	var issues []Issue
+	last := map[int]int{} // String to the index of the latest note on it
+	for i, n := range s.Notes {
+		if j, ok := last[n.String]; ok && n.Time-s.Notes[j].Time < chordWindow {
+			issues = append(issues, s.noteIssue(SeverityError, i, "starts with note %d on the same string", j+1))
+		}
+		last[n.String] = i
+	}
+	return issues
+}
+
+// checkReach finds fretted notes sounding together too far apart for
+// one hand, and shifts too far to make in the time between notes
+func (s *Song) checkReach() []Issue {
+	var issues []Issue
+	for i, n := range s.Notes {
+		if n.Fret <= 0 {
+			continue
+		}
+		// The furthest fretted note played just before
+		from, span := -1, maxStretch
+		for j := i - 1; j >= 0 && n.Time-s.Notes[j].Time < quickShift; j-- {
+			if p := s.Notes[j]; p.Fret > 0 && abs(n.Fret-p.Fret) > span {
+				from, span = j, abs(n.Fret-p.Fret)
+			}
+		}
+		if from < 0 {
+			continue
+		}
+		if gap := n.Time - s.Notes[from].Time; gap < chordWindow {
+			issues = append(issues, s.noteIssue(SeverityWarning, i, "stretch of %d frets from note %d, which sounds with it", span, from+1))
+		} else {
+			issues = append(issues, s.noteIssue(SeverityWarning, i, "shift of %d frets from note %d in %.0f ms", span, from+1, gap*1000))
+		}
+	}
+	return issues
+}
+
+// noteIssue describes a problem with the i'th note
+func (s *Song) noteIssue(sev Severity, i int, format string, args ...any) Issue {
+	n := s.Notes[i]
+	return Issue{Severity: sev, Note: i + 1, Beat: n.Beat, Time: n.Time, Message: fmt.Sprintf(format, args...)}
+}
+
+// CheckTuning checks the song's instrument and tuning on their own, for
+// when there's no chart yet, as when tuning up
+func (s *Song) CheckTuning() error {
+	var errs []error
+	for _, msg := range s.tuningProblems() {
+		errs = append(errs, errors.New(msg))
+	}
+	return errors.Join(errs...)
+}
+
+// tuningProblems describes what's wrong with the instrument and tuning:
+// an instrument or tuning name that isn't known, or a custom tuning with
+// a string that isn't a note
+func (s *Song) tuningProblems() []string {
+	var problems []string
+	inst := s.Instrument()
+	if s.InstrumentName != "" && inst.Name != strings.ToLower(strings.TrimSpace(s.InstrumentName)) {
+		problems = append(problems, fmt.Sprintf("unknown instrument %q", s.InstrumentName))
+	}
+
+	if s.TuningStr == "" {
+		return problems
+	}
+	if _, ok := inst.Tunings[strings.ToLower(strings.TrimSpace(s.TuningStr))]; ok {
+		return problems
+	}
+	if len(strings.Split(s.TuningStr, ",")) < 4 {
+		return append(problems, fmt.Sprintf("unknown tuning %q", s.TuningStr))
+	}
+	for i, st := range s.GetTuning() {
+		if !st.known() {
+			problems = append(problems, fmt.Sprintf("tuning %q: string %d has unknown note %q", s.TuningStr, i+1, st.Note))
+		}
+	}
+	return problems
+}
+
+// known reports whether the string's note name is recognized
+func (s StringTuning) known() bool {
+	return s.Semitone() != 0 || s.Note == "C"
+}
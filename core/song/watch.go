@@ -0,0 +1,99 @@
+package song
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchSettle is how long a directory must be quiet before changes are
+// reported, since editors often write a file in several steps
+const watchSettle = 200 * time.Millisecond
+
+// Watcher reports changes to the charts, song packs and generator scripts
+// in a directory
+type Watcher struct {
+	// Changes receives the paths of files that were created, modified,
+	// or removed, batched once the directory settles
+	Changes <-chan []string
+
+	fs        *fsnotify.Watcher
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// WatchDirectory starts watching a songs directory
+func WatchDirectory(dir string) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(dir); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	changes := make(chan []string)
+	w := &Watcher{
+		Changes: changes,
+		fs:      fsw,
+		done:    make(chan struct{}),
+	}
+	go w.run(changes)
+	return w, nil
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.fs.Close()
+	})
+	return err
+}
+
+func (w *Watcher) run(changes chan<- []string) {
+	pending := make(map[string]bool)
+	settle := time.NewTimer(watchSettle)
+	settle.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if !isSongFile(ev.Name) || ev.Has(fsnotify.Chmod) && !ev.Has(fsnotify.Write) {
+				continue
+			}
+			pending[ev.Name] = true
+			settle.Reset(watchSettle)
+		case _, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+		case <-settle.C:
+			batch := make([]string, 0, len(pending))
+			for path := range pending {
+				batch = append(batch, path)
+			}
+			pending = make(map[string]bool)
+
+			select {
+			case changes <- batch:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+func isSongFile(name string) bool {
+	return IsChartFile(name) || IsScriptFile(name) || strings.EqualFold(filepath.Ext(name), ".zip")
+}